@@ -35,17 +35,30 @@ type response struct {
 
 // AgentGuideCmd prints the embedded guide for agents and automation.
 func AgentGuideCmd(ch *cmdutil.Helper) *cobra.Command {
+	var schema bool
 	cmd := &cobra.Command{
 		Use:   "agent-guide",
 		Short: "Show guidance for AI agents and automation",
 		Long: `Show guidance for AI agents and automation using pscale.
 
 Use --format json for a machine-readable bootstrap response with first commands,
-supported engines, hosted MCP details, and PlanetScale skills install hints.`,
+supported engines, hosted MCP details, and PlanetScale skills install hints.
+
+Use --schema for a JSON description of every command: its arguments, its
+flags, whether it changes state, and the JSON Schema of its --format json
+output where it's known.`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if ch.Printer.Format() == printer.JSON {
-				return ch.Printer.PrintJSON(response{
+			if schema {
+				resp := buildSchema(ch, cmd.Root())
+				if ch.Printer.Format() == printer.YAML {
+					return ch.Printer.PrintYAML(resp)
+				}
+				return ch.Printer.PrintJSON(resp)
+			}
+
+			if ch.Printer.Format() != printer.Human {
+				return ch.Printer.PrintResource(response{
 					Status:              "ok",
 					Guide:               clicontent.AgentGuide,
 					FirstCommand:        cmdutil.AgentAuthCheckCmd(),
@@ -77,5 +90,10 @@ supported engines, hosted MCP details, and PlanetScale skills install hints.`,
 		},
 	}
 
+	cmd.Flags().BoolVar(&schema, "schema", false, "Print the schema of every command as JSON, or YAML with --format yaml")
+	cmdutil.SetJSONOutput(cmd, response{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
 	return cmd
 }
@@ -0,0 +1,370 @@
+package agentguide
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+// schemaResponse describes every command of pscale, for generating typed
+// wrappers.
+type schemaResponse struct {
+	Version string `json:"version"`
+	// GlobalFlags are the flags of every command.
+	GlobalFlags []*flagSchema      `json:"global_flags"`
+	Commands    []*commandSchema   `json:"commands"`
+	Errors      *errorOutputSchema `json:"error_output"`
+}
+
+type commandSchema struct {
+	// Path is the command line of the command, without arguments:
+	// "pscale branch create".
+	Path       string        `json:"path"`
+	Short      string        `json:"short"`
+	Aliases    []string      `json:"aliases,omitempty"`
+	Args       []*argSchema  `json:"args"`
+	Flags      []*flagSchema `json:"flags"`
+	Deprecated string        `json:"deprecated,omitempty"`
+	// Mutates reports whether the command may change state, on PlanetScale
+	// or locally.
+	Mutates bool `json:"mutates"`
+	// Output is the JSON Schema of what the command prints with --format
+	// json, when it's known.
+	Output *jsonSchema `json:"output_schema,omitempty"`
+}
+
+type argSchema struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	// Variadic arguments can be given more than once.
+	Variadic bool     `json:"variadic,omitempty"`
+	Enum     []string `json:"enum,omitempty"`
+}
+
+type flagSchema struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	// Type is the type of the value, as pflag names it: "string", "bool",
+	// "stringSlice", "duration" and so on.
+	Type       string   `json:"type"`
+	Default    string   `json:"default"`
+	Usage      string   `json:"usage"`
+	Required   bool     `json:"required"`
+	Enum       []string `json:"enum,omitempty"`
+	Deprecated string   `json:"deprecated,omitempty"`
+}
+
+// errorOutputSchema is what commands print when they fail with --format
+// json.
+type errorOutputSchema struct {
+	ExitCodes map[string]int `json:"exit_codes"`
+	Output    *jsonSchema    `json:"output_schema"`
+}
+
+// openFlags are the flags whose completions are the values that exist, rather
+// than every valid value.
+var openFlags = []string{"profile"}
+
+// buildSchema walks the commands of root.
+func buildSchema(ch *cmdutil.Helper, root *cobra.Command) *schemaResponse {
+	// Completions that list resources from the API or the config file would
+	// make the schema depend on the account it's generated with. Without
+	// them, they complete nothing.
+	client, configFS := ch.Client, ch.ConfigFS
+	ch.Client = func() (*ps.Client, error) { return nil, errors.New("no client for the schema") }
+	ch.ConfigFS = config.NewConfigFS(emptyFS{})
+	defer func() { ch.Client, ch.ConfigFS = client, configFS }()
+
+	resp := &schemaResponse{
+		Version:     root.Version,
+		GlobalFlags: flagSchemas(root, root.PersistentFlags()),
+		Commands:    []*commandSchema{},
+		Errors: &errorOutputSchema{
+			ExitCodes: map[string]int{
+				"error":           cmdutil.FatalErrExitCode,
+				"action_required": cmdutil.ActionRequestedExitCode,
+			},
+			Output: outputSchema(cmdutil.JSONErrorResponse{}),
+		},
+	}
+	walkCommands(root, func(cmd *cobra.Command) {
+		resp.Commands = append(resp.Commands, newCommandSchema(root, cmd))
+	})
+	return resp
+}
+
+// walkCommands calls fn with every command below cmd that can be run.
+func walkCommands(cmd *cobra.Command, fn func(*cobra.Command)) {
+	for _, child := range cmd.Commands() {
+		if child.Hidden || child.Name() == "help" {
+			continue
+		}
+		if child.Runnable() {
+			fn(child)
+		}
+		walkCommands(child, fn)
+	}
+}
+
+func newCommandSchema(root, cmd *cobra.Command) *commandSchema {
+	// The flags of root are the global flags. The persistent flags of other
+	// parents, such as --org, are listed with every command.
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	flags.AddFlagSet(cmd.LocalFlags())
+	cmd.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if root.PersistentFlags().Lookup(f.Name) == nil {
+			flags.AddFlag(f)
+		}
+	})
+
+	return &commandSchema{
+		Path:       cmd.CommandPath(),
+		Short:      cmd.Short,
+		Aliases:    cmd.Aliases,
+		Args:       argSchemas(cmd),
+		Flags:      flagSchemas(cmd, flags),
+		Deprecated: cmd.Deprecated,
+		Mutates:    !cmdutil.IsReadOnly(cmd),
+		Output:     outputSchema(cmdutil.JSONOutput(cmd)...),
+	}
+}
+
+// argSchemas returns the arguments of cmd from its usage line, where
+// "<database>" is required, "[branch]" optional and "<access> ..." or
+// "<access>..." variadic.
+func argSchemas(cmd *cobra.Command) []*argSchema {
+	args := []*argSchema{}
+	for _, word := range strings.Fields(cmd.Use)[1:] {
+		word, variadic := strings.CutSuffix(word, "...")
+		if word == "" {
+			word = "..."
+		}
+		var arg *argSchema
+		switch {
+		case word == "..." || (len(args) > 0 && word == "<"+args[len(args)-1].Name+">"):
+			if len(args) > 0 {
+				args[len(args)-1].Variadic = true
+			}
+			continue
+		case word == "[flags]" || word == "[options]":
+			continue
+		case strings.HasPrefix(word, "<"):
+			arg = &argSchema{Name: strings.Trim(word, "<>"), Required: true}
+		case strings.HasPrefix(word, "["):
+			arg = &argSchema{Name: strings.Trim(word, "[<>]")}
+		default:
+			continue
+		}
+
+		if strings.Contains(arg.Name, "|") && len(cmd.ValidArgs) > 0 {
+			arg.Enum = completionValues(cmd.ValidArgs)
+		}
+		arg.Variadic = variadic
+		args = append(args, arg)
+	}
+	return args
+}
+
+func flagSchemas(cmd *cobra.Command, flags *pflag.FlagSet) []*flagSchema {
+	schemas := []*flagSchema{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+
+		_, required := f.Annotations[cobra.BashCompOneRequiredFlag]
+		_, usage := pflag.UnquoteUsage(f)
+		s := &flagSchema{
+			Name:       f.Name,
+			Shorthand:  f.Shorthand,
+			Type:       f.Value.Type(),
+			Default:    f.DefValue,
+			Usage:      strings.TrimSuffix(usage, " (required)"),
+			Required:   required,
+			Deprecated: f.Deprecated,
+		}
+		if complete, ok := cmd.GetFlagCompletionFunc(f.Name); ok && !slices.Contains(openFlags, f.Name) {
+			values, directive := complete(cmd, nil, "")
+			if directive&cobra.ShellCompDirectiveError == 0 {
+				s.Enum = completionValues(values)
+			}
+		}
+		schemas = append(schemas, s)
+	})
+	return schemas
+}
+
+// completionValues returns the values of completions, without their
+// descriptions, sorted.
+func completionValues(completions []cobra.Completion) []string {
+	if len(completions) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(completions))
+	for _, c := range completions {
+		value, _, _ := strings.Cut(c, "\t")
+		values = append(values, value)
+	}
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// emptyFS is a file system without files.
+type emptyFS struct{}
+
+func (emptyFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// jsonSchema is a JSON Schema, with the keywords needed to describe Go
+// values encoded with encoding/json.
+type jsonSchema struct {
+	// Type is a type name, or a list of them.
+	Type                 interface{}            `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	rawJSONType   = reflect.TypeFor[json.RawMessage]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+	textType      = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// outputSchema returns the JSON Schema of the JSON encoding of values, or nil
+// if there are none.
+func outputSchema(values ...interface{}) *jsonSchema {
+	var schemas []*jsonSchema
+	for _, v := range values {
+		schemas = append(schemas, typeSchema(reflect.TypeOf(v), map[reflect.Type]bool{}))
+	}
+	switch len(schemas) {
+	case 0:
+		return nil
+	case 1:
+		return schemas[0]
+	default:
+		return &jsonSchema{AnyOf: schemas}
+	}
+}
+
+// typeSchema returns the schema of the JSON encoding of t. seen are the
+// struct types being described, so recursive types end.
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) *jsonSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		return &jsonSchema{}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// The encoding is up to the type.
+		return &jsonSchema{}
+	case t.Implements(textType) || reflect.PointerTo(t).Implements(textType):
+		return &jsonSchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are encoded as base64 strings.
+			return &jsonSchema{Type: "string"}
+		}
+		return &jsonSchema{Type: "array", Items: typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: typeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return &jsonSchema{Type: "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}}
+		addFields(s, t, seen)
+		return s
+	default:
+		// Interfaces can hold anything.
+		return &jsonSchema{}
+	}
+}
+
+// addFields adds the fields of the struct type t to s, as encoding/json
+// encodes them. The fields of embedded structs without a name are promoted.
+func addFields(s *jsonSchema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opt, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft, seen)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		opts := strings.Split(opt, ",")
+		fieldSchema := typeSchema(field.Type, seen)
+		if slices.Contains(opts, "string") && fieldSchema.Type != nil && fieldSchema.Type != "object" && fieldSchema.Type != "array" {
+			// Scalars with the string option are quoted.
+			fieldSchema = &jsonSchema{Type: "string"}
+		}
+		if slices.Contains(opts, "omitempty") || slices.Contains(opts, "omitzero") {
+			s.Properties[name] = fieldSchema
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			// These are encoded as null when they're nil.
+			if typ, ok := fieldSchema.Type.(string); ok {
+				fieldSchema.Type = []string{typ, "null"}
+			}
+		}
+		s.Properties[name] = fieldSchema
+		s.Required = append(s.Required, name)
+	}
+}
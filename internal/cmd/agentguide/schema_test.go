@@ -0,0 +1,178 @@
+package agentguide
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+type schemaTestBase struct {
+	ID string `json:"id"`
+}
+
+type schemaTestResource struct {
+	schemaTestBase
+	Name      string            `json:"name"`
+	Note      string            `json:"note,omitempty"`
+	Size      int64             `json:"size,string"`
+	Parent    *schemaTestBase   `json:"parent"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt time.Time         `json:"created_at"`
+	Secret    string            `json:"-"`
+}
+
+func TestAgentGuideSchema(t *testing.T) {
+	format := printer.JSON
+	ch := &cmdutil.Helper{
+		Printer: printer.NewPrinter(&format),
+	}
+
+	root := &cobra.Command{Use: "pscale", Version: "1.2.3"}
+	root.PersistentFlags().String("format", "human", "Show output in a specific format")
+
+	parent := &cobra.Command{Use: "thing"}
+	parent.PersistentFlags().String("org", "", "The organization")
+	root.AddCommand(parent)
+
+	create := &cobra.Command{
+		Use:  "create <database> [<name>] [flags]",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	create.Flags().String("kind", "a", "The `kind` of thing")
+	create.RegisterFlagCompletionFunc("kind", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return []cobra.Completion{
+			cobra.CompletionWithDesc("b", "B"),
+			"a",
+		}, cobra.ShellCompDirectiveNoFileComp
+	})
+	create.Flags().String("secret", "", "Hidden")
+	create.Flags().MarkHidden("secret")
+	create.Flags().String("region", "", "The region")
+	create.MarkFlagRequired("region")
+	cmdutil.SetJSONOutput(create, &schemaTestResource{})
+	parent.AddCommand(create)
+
+	list := &cobra.Command{
+		Use:  "list <database> <id>...",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	cmdutil.SetReadOnly(list)
+	parent.AddCommand(list)
+
+	// Only commands marked with SetReadOnly are read only, whatever their
+	// name.
+	parent.AddCommand(&cobra.Command{
+		Use:  "export",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	})
+
+	settings := &cobra.Command{
+		Use:  "settings",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	cmdutil.SetReadOnly(settings)
+	parent.AddCommand(settings)
+
+	parent.AddCommand(&cobra.Command{
+		Use:    "internal",
+		Hidden: true,
+		RunE:   func(cmd *cobra.Command, args []string) error { return nil },
+	})
+
+	resp := buildSchema(ch, root)
+	if resp.Version != "1.2.3" {
+		t.Fatalf("version = %q", resp.Version)
+	}
+	if len(resp.GlobalFlags) != 1 || resp.GlobalFlags[0].Name != "format" {
+		t.Fatalf("global flags = %#v", resp.GlobalFlags)
+	}
+
+	commands := map[string]*commandSchema{}
+	for _, c := range resp.Commands {
+		commands[c.Path] = c
+	}
+	if len(commands) != 4 || commands["pscale thing internal"] != nil {
+		t.Fatalf("commands = %#v", commands)
+	}
+
+	c := commands["pscale thing create"]
+	if !c.Mutates {
+		t.Fatal("expected create to mutate")
+	}
+	if len(c.Args) != 2 || c.Args[0].Name != "database" || !c.Args[0].Required || c.Args[1].Name != "name" || c.Args[1].Required {
+		t.Fatalf("create args = %#v", c.Args)
+	}
+
+	flags := map[string]*flagSchema{}
+	for _, f := range c.Flags {
+		flags[f.Name] = f
+	}
+	if len(flags) != 3 || flags["secret"] != nil || flags["org"] == nil || flags["format"] != nil {
+		t.Fatalf("create flags = %#v", c.Flags)
+	}
+	if f := flags["kind"]; f.Usage != "The kind of thing" || f.Default != "a" || !slices.Equal(f.Enum, []string{"a", "b"}) {
+		t.Fatalf("kind flag = %#v", f)
+	}
+	if f := flags["region"]; !f.Required || f.Enum != nil {
+		t.Fatalf("region flag = %#v", f)
+	}
+
+	l := commands["pscale thing list"]
+	if l.Mutates || l.Output != nil {
+		t.Fatalf("list = %#v", l)
+	}
+	if len(l.Args) != 2 || !l.Args[1].Variadic || l.Args[0].Variadic {
+		t.Fatalf("list args = %#v", l.Args)
+	}
+	if commands["pscale thing settings"].Mutates {
+		t.Fatal("expected settings to be read only")
+	}
+	if !commands["pscale thing export"].Mutates {
+		t.Fatal("expected export to mutate")
+	}
+
+	out, err := json.Marshal(c.Output)
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	props := got["properties"].(map[string]interface{})
+	for _, name := range []string{"id", "name", "note", "size", "parent", "labels", "created_at"} {
+		if props[name] == nil {
+			t.Fatalf("missing property %q in %s", name, out)
+		}
+	}
+	if props["Secret"] != nil || props["secret"] != nil {
+		t.Fatalf("unexpected secret property in %s", out)
+	}
+	if typ := props["size"].(map[string]interface{})["type"]; typ != "string" {
+		t.Fatalf("size type = %v", typ)
+	}
+	if f := props["created_at"].(map[string]interface{})["format"]; f != "date-time" {
+		t.Fatalf("created_at format = %v", f)
+	}
+	if typ := props["parent"].(map[string]interface{})["type"]; !slices.Equal(toStrings(typ), []string{"object", "null"}) {
+		t.Fatalf("parent type = %v", typ)
+	}
+	required := toStrings(got["required"])
+	if slices.Contains(required, "note") || !slices.Contains(required, "id") || !slices.Contains(required, "name") {
+		t.Fatalf("required = %v", required)
+	}
+}
+
+func toStrings(v interface{}) []string {
+	values, _ := v.([]interface{})
+	var s []string
+	for _, v := range values {
+		s = append(s, v.(string))
+	}
+	return s
+}
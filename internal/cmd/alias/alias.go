@@ -0,0 +1,213 @@
+package alias
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/mattn/go-shellwords"
+	"github.com/spf13/cobra"
+)
+
+// AliasCmd encapsulates the commands for managing command aliases.
+func AliasCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias <command>",
+		Short: "Create shortcuts for pscale commands",
+		Long: `Create shortcuts for pscale commands.
+
+Aliases are saved in the global config file and run the command they stand
+for. $1, $2, ... in the command are replaced by the arguments given to the
+alias, and every argument that isn't referenced is appended in order. Aliases
+never replace built-in commands.`,
+	}
+
+	cmd.AddCommand(SetCmd(ch))
+	cmd.AddCommand(ListCmd(ch))
+	cmd.AddCommand(DeleteCmd(ch))
+
+	return cmd
+}
+
+// alias returns a table-serializable alias model.
+type alias struct {
+	Name      string `header:"name" json:"name"`
+	Expansion string `header:"expansion" json:"expansion"`
+}
+
+// SetCmd creates or changes an alias.
+func SetCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <name> <expansion>",
+		Short: "Create or change an alias",
+		Example: `  pscale alias set top 'branch connections top $1 main --keyspace ks --shard -80 --interval 2s'
+  pscale top mydb`,
+		Args: cmdutil.RequiredArgs("name", "expansion"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 2 {
+				return fmt.Errorf("quote the expansion of the alias so it's a single argument")
+			}
+			name, expansion := args[0], args[1]
+
+			if name == "" || strings.ContainsAny(name, " \t.$") || strings.HasPrefix(name, "-") {
+				return fmt.Errorf("invalid alias name %q", name)
+			}
+			if cmdutil.IsBuiltinCommand(cmd.Root(), name) {
+				return fmt.Errorf("%q is a pscale command and can't be used as an alias", name)
+			}
+
+			words, err := shellwords.Parse(expansion)
+			if err != nil {
+				return fmt.Errorf("can't parse expansion: %w", err)
+			}
+			if len(words) == 0 {
+				return fmt.Errorf("the expansion of an alias can't be empty")
+			}
+			if target, _, err := cmd.Root().Find(words); err != nil || target == cmd.Root() {
+				return fmt.Errorf("the expansion must start with a pscale command, such as 'branch list'")
+			}
+
+			path, err := configPath(cmd)
+			if err != nil {
+				return err
+			}
+			cfg, err := readConfig(ch, path)
+			if err != nil {
+				return err
+			}
+			_, exists := cfg.Aliases[name]
+
+			if err := config.SetFileValue(path, "aliases."+name, expansion); err != nil {
+				return err
+			}
+
+			if exists {
+				ch.Printer.Printf("Changed alias %s\n", printer.Bold(name))
+			} else {
+				ch.Printer.Printf("Added alias %s\n", printer.Bold(name))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// ListCmd lists the aliases.
+func ListCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the aliases",
+		Args:    cobra.NoArgs,
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := configPath(cmd)
+			if err != nil {
+				return err
+			}
+			cfg, err := readConfig(ch, path)
+			if err != nil {
+				return err
+			}
+
+			if len(cfg.Aliases) == 0 && ch.Printer.Format() == printer.Human {
+				ch.Printer.Println("No aliases exist")
+				return nil
+			}
+
+			return ch.Printer.PrintResource(toAliases(cfg.Aliases))
+		},
+	}
+	cmdutil.SetJSONOutput(cmd, []*alias{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
+}
+
+// DeleteCmd deletes an alias.
+func DeleteCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete <name>",
+		Short:             "Delete an alias",
+		Args:              cmdutil.RequiredArgs("name"),
+		ValidArgsFunction: CompleteAliases(ch),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			path, err := configPath(cmd)
+			if err != nil {
+				return err
+			}
+			removed, err := config.UnsetFileValue(path, "aliases."+name)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("alias %s doesn't exist", name)
+			}
+
+			ch.Printer.Printf("Deleted alias %s\n", printer.Bold(name))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// CompleteAliases completes the names of the aliases, with the command they
+// stand for as description.
+func CompleteAliases(ch *cmdutil.Helper) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		path, err := configPath(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := readConfig(ch, path)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var completions []string
+		for _, a := range toAliases(cfg.Aliases) {
+			if strings.HasPrefix(a.Name, toComplete) && !cmdutil.IsBuiltinCommand(cmd.Root(), a.Name) {
+				completions = append(completions, cobra.CompletionWithDesc(a.Name, "Alias for "+a.Expansion))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func toAliases(aliases map[string]string) []*alias {
+	out := make([]*alias, 0, len(aliases))
+	for name, expansion := range aliases {
+		out = append(out, &alias{Name: name, Expansion: expansion})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// configPath returns the config file given with --config or the global
+// config file.
+func configPath(cmd *cobra.Command) (string, error) {
+	if f := cmd.Flag("config"); f != nil && f.Value.String() != "" {
+		return f.Value.String(), nil
+	}
+	return config.DefaultConfigPath()
+}
+
+func readConfig(ch *cmdutil.Helper, path string) (*config.FileConfig, error) {
+	cfg, err := ch.ConfigFS.NewFileConfig(path)
+	if os.IsNotExist(err) {
+		return &config.FileConfig{}, nil
+	}
+	return cfg, err
+}
@@ -0,0 +1,67 @@
+package alias
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+func TestAliasCmd(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(c.TempDir(), "pscale.yml")
+	c.Assert(os.WriteFile(path, []byte("org: acme\n"), 0o644), qt.IsNil)
+
+	var buf bytes.Buffer
+	format := printer.JSON
+	p := printer.NewPrinter(&format)
+	p.SetResourceOutput(&buf)
+	p.SetHumanOutput(&buf)
+	ch := &cmdutil.Helper{Printer: p, ConfigFS: config.NewConfigFS(osFS{})}
+
+	run := func(args ...string) error {
+		buf.Reset()
+		root := testRoot()
+		root.SilenceErrors = true
+		root.SilenceUsage = true
+		root.AddCommand(AliasCmd(ch))
+		root.SetArgs(append([]string{"--config", path, "alias"}, args...))
+		return root.Execute()
+	}
+
+	c.Assert(run("set", "bl", "branch list --format json"), qt.IsNil)
+	c.Assert(run("set", "top", "branch connections top $1"), qt.IsNil)
+	c.Assert(run("set", "branch", "branch list"), qt.ErrorMatches, `"branch" is a pscale command and can't be used as an alias`)
+	c.Assert(run("set", "br", "branch list"), qt.ErrorMatches, `"br" is a pscale command.*`)
+	c.Assert(run("set", "x", "deploy-it now"), qt.ErrorMatches, "the expansion must start with a pscale command.*")
+	c.Assert(run("set", "a.b", "branch list"), qt.ErrorMatches, `invalid alias name "a.b"`)
+
+	c.Assert(run("list"), qt.IsNil)
+	c.Assert(buf.String(), qt.JSONEquals, []*alias{
+		{Name: "bl", Expansion: "branch list --format json"},
+		{Name: "top", Expansion: "branch connections top $1"},
+	})
+
+	c.Assert(run("delete", "top"), qt.IsNil)
+	c.Assert(run("delete", "top"), qt.ErrorMatches, "alias top doesn't exist")
+
+	data, err := os.ReadFile(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, "org: acme\naliases:\n  bl: branch list --format json\n")
+
+	args, err := Resolve(testRoot(), ch.ConfigFS, []string{"--config", path, "bl"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(args, qt.DeepEquals, []string{"--config", path, "branch", "list", "--format", "json"})
+}
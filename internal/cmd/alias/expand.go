@@ -0,0 +1,130 @@
+package alias
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+
+	"github.com/mattn/go-shellwords"
+	"github.com/spf13/cobra"
+)
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// Resolve reads the aliases from the config file named by --config in args,
+// or else the global config file, and expands the alias args invoke, see
+// Expand.
+func Resolve(root *cobra.Command, configFS *config.ConfigFS, args []string) ([]string, error) {
+	path := configFlag(root, args)
+	if path == "" {
+		var err error
+		path, err = config.DefaultConfigPath()
+		if err != nil {
+			return args, nil
+		}
+	}
+
+	cfg, err := configFS.NewFileConfig(path)
+	if err != nil || len(cfg.Aliases) == 0 {
+		// A broken config file is reported once the command runs.
+		return args, nil
+	}
+	return Expand(root, cfg.Aliases, args)
+}
+
+// Expand replaces the alias args invoke with the command it stands for.
+// args are the arguments of pscale without the program name, and may start
+// with global flags. $1, $2, ... in the expansion are replaced by the
+// arguments following the alias, and the arguments that aren't referenced
+// are appended. Built-in commands always win over aliases.
+func Expand(root *cobra.Command, aliases map[string]string, args []string) ([]string, error) {
+	i := cmdutil.CommandArgIndex(root, args)
+	if i < 0 {
+		return args, nil
+	}
+
+	// Shell completion calls `pscale __complete <args>`. Expand the alias
+	// when it's not the word being completed, unless its arguments are
+	// needed to do so.
+	if args[i] == cobra.ShellCompRequestCmd || args[i] == cobra.ShellCompNoDescRequestCmd {
+		rest := args[i+1:]
+		if len(rest) < 2 {
+			return args, nil
+		}
+		expanded, err := Expand(root, aliases, rest[:len(rest)-1])
+		if err != nil {
+			return args, nil
+		}
+		out := append([]string{}, args[:i+1]...)
+		out = append(out, expanded...)
+		return append(out, rest[len(rest)-1]), nil
+	}
+
+	name := args[i]
+	expansion, ok := aliases[name]
+	if !ok || cmdutil.IsBuiltinCommand(root, name) {
+		return args, nil
+	}
+
+	words, err := shellwords.Parse(expansion)
+	if err != nil {
+		return nil, fmt.Errorf("alias %s: %w", name, err)
+	}
+
+	rest := args[i+1:]
+	if maxPlaceholder(words) > len(rest) {
+		return nil, fmt.Errorf("alias %s is missing arguments, it expands to: %s", name, expansion)
+	}
+	used := make([]bool, len(rest))
+	for j, word := range words {
+		words[j] = placeholder.ReplaceAllStringFunc(word, func(m string) string {
+			n, _ := strconv.Atoi(m[1:])
+			if n == 0 {
+				return m
+			}
+			used[n-1] = true
+			return rest[n-1]
+		})
+	}
+
+	out := append([]string{}, args[:i]...)
+	out = append(out, words...)
+	for j, arg := range rest {
+		if !used[j] {
+			out = append(out, arg)
+		}
+	}
+	return out, nil
+}
+
+// configFlag returns the value of --config in args.
+func configFlag(root *cobra.Command, args []string) string {
+	end := len(args)
+	if i := cmdutil.CommandArgIndex(root, args); i >= 0 {
+		end = i
+	}
+	for i := 0; i < end; i++ {
+		if v, ok := strings.CutPrefix(args[i], "--config="); ok {
+			return v
+		}
+		if args[i] == "--config" && i+1 < end {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func maxPlaceholder(words []string) int {
+	n := 0
+	for _, word := range words {
+		for _, m := range placeholder.FindAllStringSubmatch(word, -1) {
+			v, _ := strconv.Atoi(m[1])
+			n = max(n, v)
+		}
+	}
+	return n
+}
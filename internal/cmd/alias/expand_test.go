@@ -0,0 +1,106 @@
+package alias
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/cobra"
+)
+
+func testRoot() *cobra.Command {
+	root := &cobra.Command{Use: "pscale"}
+	root.PersistentFlags().String("config", "", "")
+	root.PersistentFlags().StringP("format", "f", "human", "")
+	root.PersistentFlags().Bool("debug", false, "")
+
+	branch := &cobra.Command{Use: "branch", Aliases: []string{"br"}}
+	branch.AddCommand(&cobra.Command{Use: "list", Run: func(*cobra.Command, []string) {}})
+	root.AddCommand(branch)
+	return root
+}
+
+func TestExpand(t *testing.T) {
+	c := qt.New(t)
+
+	aliases := map[string]string{
+		"top":    `branch connections top $1 main --keyspace ks --shard "-80"`,
+		"bl":     "branch list",
+		"branch": "database list",
+		"br":     "database list",
+		"pair":   "branch diff $2 $1",
+		"second": "branch show db $2",
+	}
+
+	tests := []struct {
+		args []string
+		want []string
+		err  string
+	}{
+		{
+			args: []string{"top", "mydb", "--interval", "2s"},
+			want: []string{"branch", "connections", "top", "mydb", "main", "--keyspace", "ks", "--shard", "-80", "--interval", "2s"},
+		},
+		{
+			args: []string{"--format", "json", "--debug", "bl", "mydb"},
+			want: []string{"--format", "json", "--debug", "branch", "list", "mydb"},
+		},
+		{
+			args: []string{"-f", "json", "bl"},
+			want: []string{"-f", "json", "branch", "list"},
+		},
+		{
+			args: []string{"pair", "a", "b", "c"},
+			want: []string{"branch", "diff", "b", "a", "c"},
+		},
+		{
+			// Arguments before a referenced one are appended too.
+			args: []string{"second", "--web", "main", "--org", "acme"},
+			want: []string{"branch", "show", "db", "main", "--web", "--org", "acme"},
+		},
+		{
+			// Built-in commands and their aliases win.
+			args: []string{"branch", "list"},
+			want: []string{"branch", "list"},
+		},
+		{
+			args: []string{"br", "list"},
+			want: []string{"br", "list"},
+		},
+		{
+			args: []string{"--", "bl"},
+			want: []string{"--", "bl"},
+		},
+		{
+			args: []string{"__complete", "top", "mydb", ""},
+			want: []string{"__complete", "branch", "connections", "top", "mydb", "main", "--keyspace", "ks", "--shard", "-80", ""},
+		},
+		{
+			// The alias itself is being completed.
+			args: []string{"__complete", "bl"},
+			want: []string{"__complete", "bl"},
+		},
+		{
+			args: []string{"top"},
+			err:  "alias top is missing arguments, it expands to: .*",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := Expand(testRoot(), aliases, tt.args)
+		if tt.err != "" {
+			c.Assert(err, qt.ErrorMatches, tt.err, qt.Commentf("%v", tt.args))
+			continue
+		}
+		c.Assert(err, qt.IsNil, qt.Commentf("%v", tt.args))
+		c.Assert(got, qt.DeepEquals, tt.want, qt.Commentf("%v", tt.args))
+	}
+}
+
+func TestConfigFlag(t *testing.T) {
+	c := qt.New(t)
+
+	root := testRoot()
+	c.Assert(configFlag(root, []string{"--config", "a.yml", "bl"}), qt.Equals, "a.yml")
+	c.Assert(configFlag(root, []string{"--config=b.yml", "bl"}), qt.Equals, "b.yml")
+	c.Assert(configFlag(root, []string{"bl", "--config", "a.yml"}), qt.Equals, "")
+}
@@ -26,6 +26,9 @@ type ApiOpts struct {
 	Field     []string
 	Input     string
 	ReadStdin bool
+	Paginate  bool
+	JQ        string
+	Template  string
 }
 
 // ApiCmd helps users perform API calls using the CLI.
@@ -61,6 +64,14 @@ func ApiCmd(ch *cmdutil.Helper, userAgent string, defaultHeaders map[string]stri
 
 			Fields specified with --field/-F will be updated in the content of --input/-I if the content is JSON. If not, an error
 			will be returned.
+
+		OUTPUT
+
+			With --paginate, the following pages of a list are requested too, using the cursor or page number of each response,
+			and their "data" arrays are merged into a single response.
+
+			The response can be filtered with a jq expression given with --jq/-q, or rendered with a Go template given with
+			--template/-t. Templates can use the "json" and "join" functions besides the built-in ones.
 		`),
 		Example: heredoc.Docf(`
 		# get the current user
@@ -95,6 +106,14 @@ func ApiCmd(ch *cmdutil.Helper, userAgent string, defaultHeaders map[string]stri
 
 		$ pscale api organizations/{org}/databases/{db}/branches --input=- -F 'name="my-name"'
 
+		# print the names of all branches of a database
+
+		$ pscale api organizations/{org}/databases/{db}/branches --paginate --jq '.data[].name'
+
+		# print the state of each deploy request
+
+		$ pscale api organizations/{org}/databases/{db}/deploy-requests --template '{{range .data}}{{.number}} {{.state}}{{"\n"}}{{end}}'
+
 		`),
 		Args:              cobra.ExactArgs(1),
 		PersistentPreRunE: cmdutil.CheckAuthentication(ch.Config),
@@ -110,6 +129,10 @@ func ApiCmd(ch *cmdutil.Helper, userAgent string, defaultHeaders map[string]stri
 	cmd.Flags().StringArrayVarP(&opts.Header, "header", "H", nil, "HTTP headers to add to the request")
 	cmd.Flags().StringArrayVarP(&opts.Field, "field", "F", nil, "HTTP body to send with the request, in `key=value` format where value is a JSON entity, unless value starts with a @ in which case the string after @ represents a file that will be read. Nested types are represented as 'root.depth1.depth2=value'")
 	cmd.Flags().StringVarP(&opts.Input, "input", "I", "", "HTTP body to send with the request, as a file that will be read and then sent.")
+	cmd.Flags().BoolVar(&opts.Paginate, "paginate", false, "Request all the pages of a list and merge their data arrays")
+	cmd.Flags().StringVarP(&opts.JQ, "jq", "q", "", "Filter the response with a jq `expression`")
+	cmd.Flags().StringVarP(&opts.Template, "template", "t", "", "Format the response with a Go `template`")
+	cmd.MarkFlagsMutuallyExclusive("jq", "template")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
@@ -119,6 +142,10 @@ func ApiCmd(ch *cmdutil.Helper, userAgent string, defaultHeaders map[string]stri
 			method = http.MethodPost
 		}
 
+		if opts.Paginate && method != http.MethodGet {
+			return fmt.Errorf("--paginate can only be used with GET requests")
+		}
+
 		u, err := parseURL(ch, opts, args[0])
 		if err != nil {
 			return fmt.Errorf("parsing URL: %w", err)
@@ -139,18 +166,6 @@ func ApiCmd(ch *cmdutil.Helper, userAgent string, defaultHeaders map[string]stri
 			return fmt.Errorf("parsing HTTP request header: %w", err)
 		}
 
-		if ch.Debug() {
-			debugReq, err := httputil.DumpRequestOut(req, true)
-			if err != nil {
-				return fmt.Errorf("dumping request output: %w", err)
-			}
-			debugReq = append(debugReq, '\n')
-			_, err = os.Stderr.Write(debugReq)
-			if err != nil {
-				return fmt.Errorf("writing request output to stderr: %w", err)
-			}
-		}
-
 		// Only the exact API host may receive credentials on redirects.
 		// The oauth2 transport attaches Authorization to every hop, so we
 		// must refuse redirects to sibling subdomains (and any other host)
@@ -178,43 +193,129 @@ func ApiCmd(ch *cmdutil.Helper, userAgent string, defaultHeaders map[string]stri
 			cl = &http.Client{}
 		}
 
-		res, err := cl.Do(req)
+		res, err := send(ctx, cl, req, ch.Debug())
 		if err != nil {
-			return fmt.Errorf("sending HTTP request: %w", err)
+			return err
 		}
 		defer res.Body.Close()
 
-		// Check if we received a redirect response
-		if res.StatusCode >= 300 && res.StatusCode < 400 {
-			location := res.Header.Get("Location")
-			if location != "" {
-				// Do not pass original request headers: secrets in -H values
-				// must not be sent to an attacker-controlled Location host.
-				newRes, handleErr := handleRedirect(ctx, location, ch.Debug())
-				if handleErr != nil {
-					return handleErr
+		out := cmd.OutOrStdout()
+		if !opts.Paginate && opts.JQ == "" && opts.Template == "" {
+			if _, err := io.Copy(out, res.Body); err != nil {
+				return fmt.Errorf("reading HTTP response body: %w", err)
+			}
+
+			if res.StatusCode > 399 {
+				return fmt.Errorf("HTTP %s", res.Status)
+			}
+
+			return nil
+		}
+
+		v, err := readResponse(out, res)
+		if err != nil {
+			return err
+		}
+
+		if opts.Paginate {
+			header := req.Header
+			var pages []map[string]interface{}
+			for {
+				page, err := pageObject(v)
+				if err != nil {
+					return err
+				}
+				pages = append(pages, page)
+
+				next, ok := nextPage(req.URL, page)
+				if !ok {
+					break
+				}
+
+				req, err = http.NewRequestWithContext(ctx, method, next.String(), nil)
+				if err != nil {
+					return fmt.Errorf("preparing HTTP request: %w", err)
 				}
+				req.Header = header.Clone()
 
-				// If handleRedirect returned a new response, use it instead
-				if newRes != nil {
-					res.Body.Close()
-					res = newRes
+				res, err = send(ctx, cl, req, ch.Debug())
+				if err != nil {
+					return err
+				}
+				v, err = readResponse(out, res)
+				res.Body.Close()
+				if err != nil {
+					return err
 				}
 			}
+			v = mergePages(pages)
 		}
 
-		if _, err := io.Copy(os.Stdout, res.Body); err != nil {
-			return fmt.Errorf("reading HTTP response body: %w", err)
+		return filterOutput(ctx, out, opts, v)
+	}
+
+	return cmd
+}
+
+// send sends req with cl and follows redirects to other hosts without
+// credentials.
+func send(ctx context.Context, cl *http.Client, req *http.Request, debug bool) (*http.Response, error) {
+	if debug {
+		debugReq, err := httputil.DumpRequestOut(req, true)
+		if err != nil {
+			return nil, fmt.Errorf("dumping request output: %w", err)
+		}
+		debugReq = append(debugReq, '\n')
+		_, err = os.Stderr.Write(debugReq)
+		if err != nil {
+			return nil, fmt.Errorf("writing request output to stderr: %w", err)
 		}
+	}
 
-		if res.StatusCode > 399 {
-			return fmt.Errorf("HTTP %s", res.Status)
+	res, err := cl.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending HTTP request: %w", err)
+	}
+
+	// Check if we received a redirect response
+	if res.StatusCode >= 300 && res.StatusCode < 400 {
+		location := res.Header.Get("Location")
+		if location != "" {
+			// Do not pass original request headers: secrets in -H values
+			// must not be sent to an attacker-controlled Location host.
+			newRes, handleErr := handleRedirect(ctx, location, debug)
+			if handleErr != nil {
+				res.Body.Close()
+				return nil, handleErr
+			}
+
+			// If handleRedirect returned a new response, use it instead
+			if newRes != nil {
+				res.Body.Close()
+				res = newRes
+			}
 		}
+	}
 
-		return nil
+	return res, nil
+}
+
+// readResponse returns the decoded JSON body of res. The body of an error
+// response is written to out as is.
+func readResponse(out io.Writer, res *http.Response) (interface{}, error) {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading HTTP response body: %w", err)
 	}
 
-	return cmd
+	if res.StatusCode > 399 {
+		if _, err := out.Write(body); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("HTTP %s", res.Status)
+	}
+
+	return decodeJSON(body)
 }
 
 // makeRedirectCheck blocks redirects away from the exact original host.
@@ -1,12 +1,15 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -177,3 +180,82 @@ func TestHandleRedirect(t *testing.T) {
 
 	require.Equal(t, "Redirect target content", string(body))
 }
+
+func TestApiCmdPaginate(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		pages map[string]string
+		want  string
+	}{
+		{
+			name: "cursor pagination",
+			args: []string{"--paginate"},
+			pages: map[string]string{
+				"":  `{"has_next":true,"cursor_end":"b","data":[{"name":"a"},{"name":"b"}]}`,
+				"b": `{"has_next":false,"cursor_end":"c","data":[{"name":"c"}]}`,
+			},
+			want: `{"cursor_end":"c","data":[{"name":"a"},{"name":"b"},{"name":"c"}],"has_next":false}` + "\n",
+		},
+		{
+			name: "page pagination with jq",
+			args: []string{"--paginate", "--jq", ".data[].name"},
+			pages: map[string]string{
+				"":  `{"next_page":2,"data":[{"name":"a"}]}`,
+				"2": `{"next_page":null,"data":[{"name":"b"}]}`,
+			},
+			want: "a\nb\n",
+		},
+		{
+			name: "template without pagination",
+			args: []string{"--template", `{{range .data}}{{.name}}={{.size}} {{json .tags}}{{"\n"}}{{end}}`},
+			pages: map[string]string{
+				"": `{"next_page":2,"data":[{"name":"a","size":12345678901234567890,"tags":["x"]}]}`,
+			},
+			want: "a=12345678901234567890 [\"x\"]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				require.Equal(t, "/v1/organizations/acme/databases", r.URL.Path)
+
+				key := r.URL.Query().Get("starting_after") + r.URL.Query().Get("page")
+				page, ok := tt.pages[key]
+				require.True(t, ok, "unexpected page %q", key)
+				w.Write([]byte(page))
+			}))
+			defer ts.Close()
+
+			debug := false
+			ch := &cmdutil.Helper{
+				Config: &config.Config{
+					BaseURL:      ts.URL,
+					AccessToken:  "token",
+					Organization: "acme",
+				},
+			}
+			ch.SetDebug(&debug)
+
+			var out bytes.Buffer
+			cmd := ApiCmd(ch, "pscale-test", nil)
+			cmd.SetOut(&out)
+			cmd.SetArgs(append([]string{"organizations/{org}/databases"}, tt.args...))
+			require.NoError(t, cmd.Execute())
+			require.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestApiCmdPaginateRequiresGET(t *testing.T) {
+	debug := false
+	ch := &cmdutil.Helper{Config: &config.Config{BaseURL: "http://localhost", AccessToken: "token"}}
+	ch.SetDebug(&debug)
+
+	cmd := ApiCmd(ch, "pscale-test", nil)
+	cmd.SilenceUsage = true
+	cmd.SetArgs([]string{"user", "--paginate", "-X", "POST"})
+	require.EqualError(t, cmd.Execute(), "--paginate can only be used with GET requests")
+}
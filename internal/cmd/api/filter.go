@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/itchyny/gojq"
+	"github.com/planetscale/cli/internal/printer"
+)
+
+// decodeJSON decodes a response body, keeping numbers as they were sent.
+func decodeJSON(body []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("response isn't JSON: %w", err)
+	}
+	return v, nil
+}
+
+// writeJSON writes v as compact JSON followed by a newline.
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// filterOutput writes the response v to w, filtered through the jq
+// expression or rendered with the Go template in opts.
+func filterOutput(ctx context.Context, w io.Writer, opts *ApiOpts, v interface{}) error {
+	switch {
+	case opts.JQ != "":
+		return runJQ(ctx, w, opts.JQ, v)
+	case opts.Template != "":
+		return printer.ExecuteTemplate(w, opts.Template, v)
+	}
+	return writeJSON(w, v)
+}
+
+// runJQ writes every result of the jq expression expr on v to w, one per
+// line. Strings are written as is, other values as JSON.
+func runJQ(ctx context.Context, w io.Writer, expr string, v interface{}) error {
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("parsing --jq expression: %w", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return fmt.Errorf("compiling --jq expression: %w", err)
+	}
+
+	iter := code.RunWithContext(ctx, v)
+	for {
+		result, ok := iter.Next()
+		if !ok {
+			return nil
+		}
+		if err, ok := result.(error); ok {
+			if err, ok := err.(*gojq.HaltError); ok && err.Value() == nil {
+				return nil
+			}
+			return fmt.Errorf("--jq: %w", err)
+		}
+
+		if s, ok := result.(string); ok {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeJSON(w, result); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// nextPage returns the URL of the page after the one at u, whose response is
+// page, and false if it's the last one. Endpoints paginate either with
+// cursors (has_next, cursor_end and the starting_after parameter) or with
+// page numbers (next_page and the page parameter).
+func nextPage(u *url.URL, page map[string]interface{}) (*url.URL, bool) {
+	q := u.Query()
+	switch {
+	case page["has_next"] == true:
+		cursor, ok := page["cursor_end"].(string)
+		if !ok || cursor == "" {
+			return nil, false
+		}
+		q.Set("starting_after", cursor)
+	case page["next_page"] != nil:
+		n, ok := page["next_page"].(json.Number)
+		if !ok {
+			return nil, false
+		}
+		q.Set("page", n.String())
+	default:
+		return nil, false
+	}
+
+	next := *u
+	next.RawQuery = q.Encode()
+	if next.String() == u.String() {
+		return nil, false
+	}
+	return &next, true
+}
+
+// mergePages merges the data arrays of pages into the last page, which has
+// the pagination fields of the end of the list.
+func mergePages(pages []map[string]interface{}) map[string]interface{} {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	data := []interface{}{}
+	for _, page := range pages {
+		items, _ := page["data"].([]interface{})
+		data = append(data, items...)
+	}
+
+	merged := pages[len(pages)-1]
+	merged["data"] = data
+	return merged
+}
+
+// pageObject returns v as a page of a paginated response: an object with a
+// data array.
+func pageObject(v interface{}) (map[string]interface{}, error) {
+	page, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("can't paginate, the response isn't a JSON object")
+	}
+	if _, ok := page["data"].([]interface{}); !ok {
+		return nil, fmt.Errorf("can't paginate, the response has no data array")
+	}
+	return page, nil
+}
@@ -128,6 +128,9 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 
 		return actions, cobra.ShellCompDirectiveDefault
 	})
+	cmdutil.SetJSONOutput(cmd, []*ps.AuditLog{})
+	cmdutil.SetReadOnly(cmd)
+
 	return cmd
 }
 
@@ -16,8 +16,8 @@ func CheckCmd(ch *cmdutil.Helper) *cobra.Command {
 			ctx := cmd.Context()
 			resp := buildAuthCheckResponse(ctx, ch)
 
-			if ch.Printer.Format() == printer.JSON {
-				if err := ch.Printer.PrintJSON(resp); err != nil {
+			if ch.Printer.Format() != printer.Human {
+				if err := ch.Printer.PrintResource(resp); err != nil {
 					return err
 				}
 				return authCheckExitCode(resp)
@@ -44,6 +44,8 @@ func CheckCmd(ch *cmdutil.Helper) *cobra.Command {
 			return nil
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, AuthCheckResponse{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -33,6 +33,10 @@ func LoginCmd(ch *cmdutil.Helper) *cobra.Command {
 				return errors.New("the 'login' command requires an interactive shell (use --format json; browser opens when possible, then polls until approved)")
 			}
 
+			if ch.Config.Profile != config.DefaultProfile && ch.Config.ServiceTokenIsSet() {
+				return fmt.Errorf("profile %s authenticates with a service token, there is nothing to log in to", ch.Config.Profile)
+			}
+
 			clientID, clientSecret = resolveOAuthClient(clientID, clientSecret)
 			authenticator, err := psauth.New(cleanhttp.DefaultClient(), clientID, clientSecret, psauth.SetBaseURL(authURL))
 			if err != nil {
@@ -96,7 +100,7 @@ func LoginCmd(ch *cmdutil.Helper) *cobra.Command {
 				return err
 			}
 
-			err = config.WriteAccessToken(accessToken)
+			err = config.WriteAccessTokenKey(ch.Config.AccessTokenKey(), accessToken)
 			if err != nil {
 				if jsonMode {
 					return finishLoginErrorJSON(ch, "TOKEN_SAVE_FAILED", "Failed to save access token", err)
@@ -170,20 +174,28 @@ func writeDefaultOrganizationIfNeeded(ctx context.Context, ch *cmdutil.Helper, a
 	cfg, err := ch.ConfigFS.DefaultConfig()
 	if err != nil {
 		writeConfig = true
+		cfg = &config.FileConfig{}
 	}
 
-	if !writeConfig && cfg.Organization != "" {
-		hasOrg, _ := hasOrg(ctx, cfg.Organization, accessToken, authURL)
+	org := cfg.Organization
+	if p := loginProfile(ch, cfg); p != nil {
+		org = p.Organization
+	}
+
+	if !writeConfig && org != "" {
+		hasOrg, _ := hasOrg(ctx, org, accessToken, authURL)
 		writeConfig = !hasOrg
 	}
 
-	if writeConfig || cfg.Organization == "" {
-		return writeDefaultOrganization(ctx, accessToken, authURL)
+	if writeConfig || org == "" {
+		return writeDefaultOrganization(ctx, ch, cfg, accessToken, authURL)
 	}
 	return nil
 }
 
-func writeDefaultOrganization(ctx context.Context, accessToken, authURL string) error {
+// writeDefaultOrganization saves the first organization of the user as the
+// organization of the profile they logged in to, keeping the rest of cfg.
+func writeDefaultOrganization(ctx context.Context, ch *cmdutil.Helper, cfg *config.FileConfig, accessToken, authURL string) error {
 	orgs, err := listCurrentOrgs(ctx, accessToken, authURL)
 	if err != nil {
 		return err
@@ -191,11 +203,13 @@ func writeDefaultOrganization(ctx context.Context, accessToken, authURL string)
 
 	if len(orgs) > 0 {
 		defaultOrg := orgs[0].Name
-		writableConfig := &config.FileConfig{
-			Organization: defaultOrg,
+		if p := loginProfile(ch, cfg); p != nil {
+			p.Organization = defaultOrg
+		} else {
+			cfg.Organization = defaultOrg
 		}
 
-		err := writableConfig.WriteDefault()
+		err := cfg.WriteDefault()
 		if err != nil {
 			return err
 		}
@@ -204,6 +218,19 @@ func writeDefaultOrganization(ctx context.Context, accessToken, authURL string)
 	return nil
 }
 
+// loginProfile returns the named profile being logged in to, or nil for the
+// default profile.
+func loginProfile(ch *cmdutil.Helper, cfg *config.FileConfig) *config.Profile {
+	if ch.Config == nil || ch.Config.Profile == "" || ch.Config.Profile == config.DefaultProfile {
+		return nil
+	}
+	p, err := cfg.LookupProfile(ch.Config.Profile)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
 func hasOrg(ctx context.Context, org, accessToken, authURL string) (bool, error) {
 	currentOrgs, err := listCurrentOrgs(ctx, accessToken, authURL)
 	if err != nil {
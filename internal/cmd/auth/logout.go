@@ -46,7 +46,7 @@ func LogoutCmd(ch *cmdutil.Helper) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			err = config.DeleteAccessToken()
+			err = config.DeleteAccessTokenKey(ch.Config.AccessTokenKey())
 			if err != nil {
 				return err
 			}
@@ -57,6 +57,7 @@ func CreateCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&createReq.Name, "name", "", "Optional name for the backup")
+	cmdutil.SetJSONOutput(cmd, &ps.Backup{})
 
 	return cmd
 }
@@ -104,5 +104,7 @@ func DeleteCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "Delete a backup without confirmation")
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
+
 	return cmd
 }
@@ -1,6 +1,7 @@
 package backup
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/planetscale/cli/internal/cmdutil"
@@ -13,6 +14,10 @@ import (
 
 // ListCmd encapsulates the command for listing backups for a branch.
 func ListCmd(ch *cmdutil.Helper) *cobra.Command {
+	var flags struct {
+		all bool
+	}
+
 	cmd := &cobra.Command{
 		Use:     "list <database> <branch>",
 		Short:   "List all backups of a branch",
@@ -44,12 +49,8 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 
 			end := ch.Printer.PrintProgress(fmt.Sprintf("Fetching backups for %s", printer.BoldBlue(branch)))
 			defer end()
-			backups, err := client.Backups.List(ctx, &planetscale.ListBackupsRequest{
-				Organization: ch.Config.Organization,
-				Database:     database,
-				Branch:       branch,
-			})
-			if err != nil {
+
+			handleErr := func(err error) error {
 				switch cmdutil.ErrCode(err) {
 				case planetscale.ErrNotFound:
 					return fmt.Errorf("branch %s does not exist in database %s (organization: %s)",
@@ -58,6 +59,30 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 					return cmdutil.HandleError(err)
 				}
 			}
+
+			listBackups := func(ctx context.Context, opts ...planetscale.ListOption) ([]*planetscale.Backup, error) {
+				return client.Backups.List(ctx, &planetscale.ListBackupsRequest{
+					Organization: ch.Config.Organization,
+					Database:     database,
+					Branch:       branch,
+				}, opts...)
+			}
+
+			if flags.all {
+				n, err := cmdutil.PrintPages(ch.Printer, planetscale.Pages(ctx, planetscale.MaxPerPage, listBackups), end, toBackups)
+				if err != nil {
+					return handleErr(err)
+				}
+				if n == 0 && ch.Printer.Format() == printer.Human {
+					ch.Printer.Printf("No backups exist in %s.\n", printer.BoldBlue(branch))
+				}
+				return nil
+			}
+
+			backups, err := listBackups(ctx)
+			if err != nil {
+				return handleErr(err)
+			}
 			end()
 
 			if len(backups) == 0 && ch.Printer.Format() == printer.Human {
@@ -70,5 +95,9 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolP("web", "w", false, "List backups in your web browser.")
+	cmdutil.AllPagesFlag(cmd, &flags.all)
+	cmdutil.SetJSONOutput(cmd, []*planetscale.Backup{})
+	cmdutil.SetReadOnly(cmd)
+
 	return cmd
 }
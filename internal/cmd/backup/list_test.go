@@ -32,7 +32,7 @@ func TestBackup_ListCmd(t *testing.T) {
 	}
 
 	svc := &mock.BackupsService{
-		ListFn: func(ctx context.Context, req *ps.ListBackupsRequest) ([]*ps.Backup, error) {
+		ListFn: func(ctx context.Context, req *ps.ListBackupsRequest, opts ...ps.ListOption) ([]*ps.Backup, error) {
 			c.Assert(req.Organization, qt.Equals, org)
 			c.Assert(req.Database, qt.Equals, db)
 			c.Assert(req.Branch, qt.Equals, branch)
@@ -84,6 +84,7 @@ func RestoreCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc("cluster-size", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return cmdutil.ClusterSizesCompletionFunc(ch, cmd, args, toComplete)
 	})
+	cmdutil.SetJSONOutput(cmd, &planetscale.DatabaseBranch{}, &planetscale.PostgresBranch{})
 
 	return cmd
 }
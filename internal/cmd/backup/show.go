@@ -67,5 +67,8 @@ func ShowCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolP("web", "w", false, "Show a branch backup in your web browser.")
+	cmdutil.SetJSONOutput(cmd, &planetscale.Backup{})
+	cmdutil.SetReadOnly(cmd)
+
 	return cmd
 }
@@ -87,6 +87,7 @@ the server reports available targets.`,
 	cmd.Flags().StringVar(&flags.role, "role", "", "Filter the live view to rows whose instance role is primary or replica.")
 	cmd.Flags().StringVar(&flags.keyspace, "keyspace", "", "Vitess keyspace to target.")
 	cmd.Flags().StringVar(&flags.shard, "shard", "", "Vitess shard to target.")
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
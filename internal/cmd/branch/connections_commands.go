@@ -50,6 +50,7 @@ query text and action IDs are not truncated.`,
 	cmd.Flags().StringVar(&flags.shard, "shard", "", "Vitess shard to target")
 	cmd.Flags().StringVar(&flags.instance, "instance", "", "Postgres instance to target")
 	cmd.Flags().StringVar(&flags.role, "role", "", "Postgres instance role to target: primary or replica")
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -267,6 +267,7 @@ func CreateCmd(ch *cmdutil.Helper) *cobra.Command {
 			cobra.CompletionWithDesc("17", "PostgreSQL 17"),
 		}, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmdutil.SetJSONOutput(cmd, &ps.DatabaseBranch{}, &ps.PostgresBranch{})
 
 	return cmd
 }
@@ -283,6 +284,7 @@ func waitUntilReady(ctx context.Context, client *ps.Client, printer *printer.Pri
 	ticker = time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
+	var state string
 	for {
 		select {
 		case <-ctx.Done():
@@ -297,8 +299,10 @@ func waitUntilReady(ctx context.Context, client *ps.Client, printer *printer.Pri
 			}
 
 			if resp.Ready {
+				printer.PrintState(state, "ready")
 				return nil
 			}
+			state = printer.PrintState(state, "pending")
 
 			elapsed := time.Since(startTime)
 			if elapsed > time.Minute {
@@ -321,6 +325,7 @@ func waitUntilPostgresReady(ctx context.Context, client *ps.Client, printer *pri
 	ticker = time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
+	var state string
 	for {
 		select {
 		case <-ctx.Done():
@@ -335,8 +340,10 @@ func waitUntilPostgresReady(ctx context.Context, client *ps.Client, printer *pri
 			}
 
 			if resp.Ready {
+				printer.PrintState(state, "ready")
 				return nil
 			}
+			state = printer.PrintState(state, "pending")
 
 			elapsed := time.Since(startTime)
 			if elapsed > time.Minute {
@@ -162,5 +162,7 @@ func DeleteCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.Flags().BoolVar(&force, "force", false, "Delete a branch without confirmation")
 	cmd.Flags().BoolVar(&deleteDescendants, "delete-descendants", false, "Delete the branch and all of its descendant branches")
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
+
 	return cmd
 }
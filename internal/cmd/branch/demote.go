@@ -11,7 +11,7 @@ import (
 )
 
 func DemoteCmd(ch *cmdutil.Helper) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "demote <database> <branch> [options]",
 		Short: "Demote a production branch to development",
 		Args:  cmdutil.RequiredArgs("database", "branch"),
@@ -54,4 +54,7 @@ func DemoteCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(ToDatabaseBranch(b))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.DatabaseBranch{})
+
+	return cmd
 }
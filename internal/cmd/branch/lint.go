@@ -70,6 +70,8 @@ func LintCmd(ch *cmdutil.Helper) *cobra.Command {
 			}
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*SchemaLintError{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -1,6 +1,7 @@
 package branch
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/planetscale/cli/internal/cmdutil"
@@ -16,6 +17,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 	var flags struct {
 		page    int
 		perPage int
+		all     bool
 	}
 
 	cmd := &cobra.Command{
@@ -53,11 +55,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			end := ch.Printer.PrintProgress(fmt.Sprintf("Fetching branches for %s", printer.BoldBlue(database)))
 			defer end()
 
-			db, err := client.Databases.Get(ctx, &planetscale.GetDatabaseRequest{
-				Organization: ch.Config.Organization,
-				Database:     database,
-			})
-			if err != nil {
+			handleErr := func(err error) error {
 				switch cmdutil.ErrCode(err) {
 				case planetscale.ErrNotFound:
 					return cmdutil.HandleNotFoundWithServiceTokenCheck(
@@ -69,61 +67,83 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 				}
 			}
 
+			printEmpty := func() {
+				if flags.page == 0 {
+					ch.Printer.Printf("No branches exist in %s.\n", printer.BoldBlue(database))
+				} else {
+					ch.Printer.Println("No branches found on this page.")
+				}
+			}
+
+			db, err := client.Databases.Get(ctx, &planetscale.GetDatabaseRequest{
+				Organization: ch.Config.Organization,
+				Database:     database,
+			})
+			if err != nil {
+				return handleErr(err)
+			}
+
 			if db.Kind == "mysql" {
-				branches, err := client.DatabaseBranches.List(ctx, &planetscale.ListDatabaseBranchesRequest{
-					Organization: ch.Config.Organization,
-					Database:     database,
-				}, planetscale.WithPage(flags.page), planetscale.WithPerPage(flags.perPage))
-				if err != nil {
-					switch cmdutil.ErrCode(err) {
-					case planetscale.ErrNotFound:
-						return cmdutil.HandleNotFoundWithServiceTokenCheck(
-							ctx, cmd, ch.Config, ch.Client, err, "read_branch",
-							"database %s does not exist in organization %s",
-							printer.BoldBlue(database), printer.BoldBlue(ch.Config.Organization))
-					default:
-						return cmdutil.HandleError(err)
+				listBranches := func(ctx context.Context, opts ...planetscale.ListOption) ([]*planetscale.DatabaseBranch, error) {
+					return client.DatabaseBranches.List(ctx, &planetscale.ListDatabaseBranchesRequest{
+						Organization: ch.Config.Organization,
+						Database:     database,
+					}, opts...)
+				}
+
+				if flags.all {
+					n, err := cmdutil.PrintPages(ch.Printer, planetscale.Pages(ctx, flags.perPage, listBranches), end, toDatabaseBranches)
+					if err != nil {
+						return handleErr(err)
 					}
+					if n == 0 && ch.Printer.Format() == printer.Human {
+						printEmpty()
+					}
+					return nil
+				}
+
+				branches, err := listBranches(ctx, planetscale.WithPage(flags.page), planetscale.WithPerPage(flags.perPage))
+				if err != nil {
+					return handleErr(err)
 				}
 
 				end()
 
 				if len(branches) == 0 && ch.Printer.Format() == printer.Human {
-					if flags.page == 0 {
-						ch.Printer.Printf("No branches exist in %s.\n", printer.BoldBlue(database))
-					} else {
-						ch.Printer.Println("No branches found on this page.")
-					}
+					printEmpty()
 					return nil
 				}
 
 				return ch.Printer.PrintResource(toDatabaseBranches(branches))
 			}
 
-			branches, err := client.PostgresBranches.List(ctx, &planetscale.ListPostgresBranchesRequest{
-				Organization: ch.Config.Organization,
-				Database:     database,
-			}, planetscale.WithPage(flags.page), planetscale.WithPerPage(flags.perPage))
-			if err != nil {
-				switch cmdutil.ErrCode(err) {
-				case planetscale.ErrNotFound:
-					return cmdutil.HandleNotFoundWithServiceTokenCheck(
-						ctx, cmd, ch.Config, ch.Client, err, "read_branch",
-						"database %s does not exist in organization %s",
-						printer.BoldBlue(database), printer.BoldBlue(ch.Config.Organization))
-				default:
-					return cmdutil.HandleError(err)
+			listBranches := func(ctx context.Context, opts ...planetscale.ListOption) ([]*planetscale.PostgresBranch, error) {
+				return client.PostgresBranches.List(ctx, &planetscale.ListPostgresBranchesRequest{
+					Organization: ch.Config.Organization,
+					Database:     database,
+				}, opts...)
+			}
+
+			if flags.all {
+				n, err := cmdutil.PrintPages(ch.Printer, planetscale.Pages(ctx, flags.perPage, listBranches), end, toPostgresBranches)
+				if err != nil {
+					return handleErr(err)
+				}
+				if n == 0 && ch.Printer.Format() == printer.Human {
+					printEmpty()
 				}
+				return nil
+			}
+
+			branches, err := listBranches(ctx, planetscale.WithPage(flags.page), planetscale.WithPerPage(flags.perPage))
+			if err != nil {
+				return handleErr(err)
 			}
 
 			end()
 
 			if len(branches) == 0 && ch.Printer.Format() == printer.Human {
-				if flags.page == 0 {
-					ch.Printer.Printf("No branches exist in %s.\n", printer.BoldBlue(database))
-				} else {
-					ch.Printer.Println("No branches found on this page.")
-				}
+				printEmpty()
 				return nil
 			}
 
@@ -134,5 +154,9 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().BoolP("web", "w", false, "List branches in your web browser.")
 	cmd.Flags().IntVar(&flags.page, "page", 0, "Page number to fetch")
 	cmd.Flags().IntVar(&flags.perPage, "per-page", 100, "Number of results per page")
+	cmdutil.AllPagesFlag(cmd, &flags.all)
+	cmdutil.SetJSONOutput(cmd, []*planetscale.DatabaseBranch{}, []*planetscale.PostgresBranch{})
+	cmdutil.SetReadOnly(cmd)
+
 	return cmd
 }
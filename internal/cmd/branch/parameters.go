@@ -90,6 +90,8 @@ To change parameters, use 'pscale branch resize <database> <branch> --parameters
 		RunE:    run,
 	}
 	registerFlags(cmd)
+	cmdutil.SetJSONOutput(cmd, []*ps.PostgresParameter{})
+	cmdutil.SetReadOnly(cmd)
 
 	listCmd := &cobra.Command{
 		Use:   "list <database> <branch>",
@@ -99,6 +101,8 @@ To change parameters, use 'pscale branch resize <database> <branch> --parameters
 		RunE:  run,
 	}
 	registerFlags(listCmd)
+	cmdutil.SetJSONOutput(listCmd, []*ps.PostgresParameter{})
+	cmdutil.SetReadOnly(listCmd)
 	cmd.AddCommand(listCmd)
 
 	return cmd
@@ -87,6 +87,7 @@ func PromoteCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(ToDatabaseBranch(dbBranch))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.DatabaseBranch{})
 
 	return cmd
 }
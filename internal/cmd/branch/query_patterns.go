@@ -152,6 +152,7 @@ func DownloadQueryPatternsCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.Flags().StringVar(&flags.output, "output", "",
 		"Output file name, or - to write to stdout. Defaults to query-patterns-<organization>-<database>-<branch>-<timestamp>.csv.")
+	cmdutil.SetJSONOutput(cmd, &QueryPatternsDownload{})
 
 	return cmd
 }
@@ -54,6 +54,7 @@ func RefreshSchemaCmd(ch *cmdutil.Helper) *cobra.Command {
 			)
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
 
 	return cmd
 }
@@ -151,6 +151,7 @@ and "pscale branch resize cancel" to cancel it while queued.`,
 
 	cmd.AddCommand(ResizeStatusCmd(ch))
 	cmd.AddCommand(ResizeCancelCmd(ch))
+	cmdutil.SetJSONOutput(cmd, map[string]string{}, &ps.PostgresBranchClusterResizeRequest{})
 
 	return cmd
 }
@@ -246,7 +247,9 @@ func waitForChange(ctx context.Context, ch *cmdutil.Helper, client *ps.Client, d
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
+	var state string
 	for {
+		state = ch.Printer.PrintState(state, change.State)
 		if change.Finished() {
 			end()
 			return change, nil
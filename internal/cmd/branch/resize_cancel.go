@@ -53,6 +53,7 @@ func ResizeCancelCmd(ch *cmdutil.Helper) *cobra.Command {
 			})
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
 
 	return cmd
 }
@@ -54,6 +54,8 @@ func ResizeStatusCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toPostgresBranchResize(changes[0]))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.PostgresBranchClusterResizeRequest{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -67,6 +67,8 @@ func GetRoutingRulesCmd(ch *cmdutil.Helper) *cobra.Command {
 			return nil
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &planetscale.RoutingRules{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -148,6 +150,7 @@ func UpdateRoutingRulesCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&flags.routingRules, "routing-rules", "", "The routing to set")
+	cmdutil.SetJSONOutput(cmd, &planetscale.RoutingRules{})
 
 	return cmd
 }
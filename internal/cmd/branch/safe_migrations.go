@@ -91,6 +91,7 @@ func EnableSafeMigrationsCmd(ch *cmdutil.Helper) *cobra.Command {
 			}
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.DatabaseBranch{}, []*SchemaLintError{})
 
 	return cmd
 }
@@ -131,6 +132,7 @@ func DisableSafeMigrationsCmd(ch *cmdutil.Helper) *cobra.Command {
 			}
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.DatabaseBranch{})
 
 	return cmd
 }
@@ -139,6 +139,8 @@ func SchemaCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&flags.web, "web", false, "Open in your web browser")
 	cmd.Flags().StringVar(&flags.keyspace, "keyspace", "", "The keyspace in the branch (MySQL only)")
 	cmd.Flags().StringVar(&flags.namespace, "namespace", "", "The namespace in the branch (PostgreSQL only)")
+	cmdutil.SetJSONOutput(cmd, []*planetscale.Diff{}, []*planetscale.PostgresBranchSchema{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -105,5 +105,8 @@ func ShowCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolP("web", "w", false, "Show a database branch in your web browser.")
+	cmdutil.SetJSONOutput(cmd, &planetscale.DatabaseBranch{}, &planetscale.PostgresBranch{})
+	cmdutil.SetReadOnly(cmd)
+
 	return cmd
 }
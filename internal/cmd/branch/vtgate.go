@@ -120,6 +120,8 @@ func VtgateShowCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toBranchVTGateConfig(b, resizes))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &branchVTGateConfig{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -232,6 +234,7 @@ queued.`,
 
 	cmd.AddCommand(VtgateResizeStatusCmd(ch))
 	cmd.AddCommand(VtgateResizeCancelCmd(ch))
+	cmdutil.SetJSONOutput(cmd, &ps.BranchResizeRequest{})
 
 	return cmd
 }
@@ -271,6 +274,8 @@ func VtgateResizeStatusCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toBranchVTGateResize(resize))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.BranchResizeRequest{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -318,6 +323,7 @@ func VtgateResizeCancelCmd(ch *cmdutil.Helper) *cobra.Command {
 			})
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
 
 	return cmd
 }
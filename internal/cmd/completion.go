@@ -8,7 +8,7 @@ import (
 )
 
 func CompletionCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "completion [bash|zsh|fish|powershell]",
 		Short: "Generate completion script for your shell",
 		Long: `To load completions:
@@ -66,4 +66,8 @@ PowerShell:
 			}
 		},
 	}
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
 }
@@ -0,0 +1,303 @@
+package configcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	ps "github.com/planetscale/cli/internal/planetscale"
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd encapsulates the commands for inspecting and editing the
+// configuration.
+func ConfigCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config <command>",
+		Short: "Inspect and edit the configuration",
+		Long: `Inspect and edit the configuration.
+
+Settings are taken from, in order of precedence:
+
+  1. flags given on the command line
+  2. PLANETSCALE_* environment variables, such as PLANETSCALE_ORG
+  3. the active profile, see "pscale profile"
+  4. the project file .pscale.yml (org, database and branch only)
+  5. the global file ~/.config/planetscale/pscale.yml
+  6. the keyring, for the access token stored by "pscale auth login"
+
+Use --show-origin to see where each value came from.`,
+	}
+
+	cmd.AddCommand(GetCmd(ch))
+	cmd.AddCommand(SetCmd(ch))
+	cmd.AddCommand(UnsetCmd(ch))
+	cmd.AddCommand(ListCmd(ch))
+	cmd.AddCommand(ValidateCmd(ch))
+
+	return cmd
+}
+
+// setting describes a setting pscale reads from the configuration.
+type setting struct {
+	key     string
+	values  []string
+	boolean bool
+	// credential settings are never written to config files.
+	credential bool
+	// secret values are redacted when printed.
+	secret bool
+}
+
+var settings = []*setting{
+	{key: "org"},
+	{key: "database"},
+	{key: "branch"},
+	{key: "api-url"},
+	{key: "api-token", credential: true, secret: true},
+	{key: "service-token-id", credential: true},
+	{key: "service-token", credential: true, secret: true},
+	{key: "format", values: printer.Formats},
+	{key: "debug", boolean: true},
+	{key: "no-color", boolean: true},
+	{key: "profile"},
+}
+
+// profileFields are the settings of a profile, see config.Profile.
+var profileFields = []string{"org", "api-url", "token-key", "service-token-id", "service-token-env"}
+
+func lookupSetting(key string) *setting {
+	for _, s := range settings {
+		if s.key == key {
+			return s
+		}
+	}
+	return nil
+}
+
+const redacted = "********"
+
+// display returns value as it's printed.
+func display(key, value string) string {
+	if s := lookupSetting(key); s != nil && s.secret && value != "" {
+		return redacted
+	}
+	return value
+}
+
+var envReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// envName is the environment variable viper reads key from.
+func envName(key string) string {
+	if key == "profile" {
+		return config.ProfileEnv
+	}
+	return "PLANETSCALE_" + strings.ToUpper(envReplacer.Replace(key))
+}
+
+// sources are the places the configuration is read from.
+type sources struct {
+	globalPath string
+	global     map[string]string
+	file       *config.FileConfig
+
+	// projectPath is empty when project files aren't read, which is the
+	// case when --config is given.
+	projectPath    string
+	project        map[string]string
+	projectIgnored []string
+
+	layers []*config.Layer
+}
+
+// loadSources reads the configuration the way pscale does at startup.
+func loadSources(cmd *cobra.Command, ch *cmdutil.Helper) (*sources, error) {
+	src := &sources{}
+
+	var err error
+	src.globalPath, err = globalPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+	src.global, err = config.ReadSettings(src.globalPath)
+	if err != nil {
+		return nil, err
+	}
+	src.file, err = ch.ConfigFS.NewFileConfig(src.globalPath)
+	if os.IsNotExist(err) {
+		src.file, err = &config.FileConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if configFlag(cmd) == "" {
+		if dir, err := config.ProjectDir(); err == nil {
+			src.projectPath = filepath.Join(dir, config.ProjectConfigFile())
+			raw, err := config.ReadSettings(src.projectPath)
+			if err != nil {
+				return nil, err
+			}
+			rawValues := make(map[string]interface{}, len(raw))
+			for k, v := range raw {
+				rawValues[k] = v
+			}
+			allowed, ignored := config.FilterProjectConfig(rawValues)
+			src.project = make(map[string]string, len(allowed))
+			for k, v := range allowed {
+				src.project[k] = fmt.Sprint(v)
+			}
+			src.projectIgnored = ignored
+		}
+	}
+
+	keys := map[string]bool{}
+	for _, s := range settings {
+		keys[s.key] = true
+	}
+	for k := range src.global {
+		keys[k] = true
+	}
+	for k := range src.project {
+		keys[k] = true
+	}
+
+	var flags map[string]string
+	if ch.Config != nil {
+		flags = ch.Config.CommandLineFlags
+	}
+	flagLayers := make([]*config.Layer, 0, len(flags))
+	for name, value := range flags {
+		if name == "config" {
+			continue
+		}
+		key := name
+		if name == "service-token-name" {
+			key = "service-token-id"
+		}
+		flagLayers = append(flagLayers, &config.Layer{
+			Origin: "flag --" + name,
+			Values: map[string]string{key: value},
+		})
+	}
+	sort.Slice(flagLayers, func(i, j int) bool { return flagLayers[i].Origin < flagLayers[j].Origin })
+	src.layers = append(src.layers, flagLayers...)
+
+	envKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		envKeys = append(envKeys, k)
+	}
+	sort.Strings(envKeys)
+	for _, key := range envKeys {
+		if value, ok := os.LookupEnv(envName(key)); ok && value != "" {
+			src.layers = append(src.layers, &config.Layer{
+				Origin: "env " + envName(key),
+				Values: map[string]string{key: value},
+			})
+		}
+	}
+
+	if name := activeProfile(ch); name != config.DefaultProfile {
+		if p, err := src.file.LookupProfile(name); err == nil {
+			origin := fmt.Sprintf("profile %s (%s)", name, src.globalPath)
+			values := map[string]string{}
+			if p.Organization != "" {
+				values["org"] = p.Organization
+			}
+			if p.BaseURL != "" {
+				values["api-url"] = p.BaseURL
+			}
+			if p.ServiceTokenID != "" {
+				values["service-token-id"] = p.ServiceTokenID
+			}
+			src.layers = append(src.layers, &config.Layer{Origin: origin, Values: values})
+
+			if p.ServiceTokenEnv != "" && os.Getenv(p.ServiceTokenEnv) != "" {
+				src.layers = append(src.layers, &config.Layer{
+					Origin: fmt.Sprintf("profile %s (env %s)", name, p.ServiceTokenEnv),
+					Values: map[string]string{"service-token": os.Getenv(p.ServiceTokenEnv)},
+				})
+			}
+		}
+	}
+
+	if src.projectPath != "" && len(src.project) > 0 {
+		src.layers = append(src.layers, &config.Layer{Origin: src.projectPath, Values: src.project})
+	}
+	if len(src.global) > 0 {
+		src.layers = append(src.layers, &config.Layer{Origin: src.globalPath, Values: src.global})
+	}
+
+	if ch.Config != nil && ch.Config.AccessToken != "" && !src.sets("api-token") {
+		src.layers = append(src.layers, &config.Layer{
+			Origin: "keyring entry " + ch.Config.AccessTokenKey(),
+			Values: map[string]string{"api-token": ch.Config.AccessToken},
+		})
+	}
+
+	src.layers = append(src.layers, &config.Layer{
+		Origin: "default",
+		Values: map[string]string{
+			"api-url":  ps.DefaultBaseURL,
+			"format":   "human",
+			"debug":    "false",
+			"no-color": "false",
+			"profile":  config.DefaultProfile,
+		},
+	})
+
+	return src, nil
+}
+
+// sets reports whether any layer read so far sets key.
+func (s *sources) sets(key string) bool {
+	for _, l := range s.layers {
+		if _, ok := l.Values[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the effective settings.
+func (s *sources) resolve() []*config.Setting {
+	return config.Resolve(s.layers)
+}
+
+// lookup returns the effective setting of key, or nil if it's not set.
+func (s *sources) lookup(key string) *config.Setting {
+	for _, setting := range s.resolve() {
+		if setting.Key == key {
+			return setting
+		}
+	}
+	return nil
+}
+
+func configFlag(cmd *cobra.Command) string {
+	if f := cmd.Flag("config"); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// globalPath is the global config file, which --config replaces.
+func globalPath(cmd *cobra.Command) (string, error) {
+	if path := configFlag(cmd); path != "" {
+		return path, nil
+	}
+	return config.DefaultConfigPath()
+}
+
+func activeProfile(ch *cmdutil.Helper) string {
+	if ch.Config == nil || ch.Config.Profile == "" {
+		return config.DefaultProfile
+	}
+	return ch.Config.Profile
+}
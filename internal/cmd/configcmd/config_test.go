@@ -0,0 +1,196 @@
+package configcmd
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/cobra"
+)
+
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+const testConfig = `org: acme
+format: json
+api-url: https://api.example.com
+profiles:
+  staging:
+    org: acme-staging
+    api-url: https://api.staging.example.com
+`
+
+// runConfig runs a config subcommand against the config file path, like
+// `pscale --config <path> config <args>`.
+func runConfig(c *qt.C, cfg *config.Config, path string, args ...string) (string, error) {
+	var buf bytes.Buffer
+	format := printer.JSON
+	p := printer.NewPrinter(&format)
+	p.SetResourceOutput(&buf)
+	p.SetHumanOutput(&buf)
+
+	ch := &cmdutil.Helper{
+		Printer:  p,
+		Config:   cfg,
+		ConfigFS: config.NewConfigFS(osFS{}),
+	}
+
+	root := &cobra.Command{Use: "pscale", SilenceUsage: true, SilenceErrors: true}
+	root.PersistentFlags().String("config", "", "")
+	root.AddCommand(ConfigCmd(ch))
+	root.SetArgs(append([]string{"--config", path, "config"}, args...))
+	err := root.Execute()
+	return buf.String(), err
+}
+
+func writeConfig(c *qt.C, data string) string {
+	path := filepath.Join(c.TempDir(), "pscale.yml")
+	c.Assert(os.WriteFile(path, []byte(data), 0o644), qt.IsNil)
+	return path
+}
+
+func TestListShowOrigin(t *testing.T) {
+	c := qt.New(t)
+	c.Setenv("PLANETSCALE_DATABASE", "from-env")
+
+	path := writeConfig(c, testConfig)
+	cfg := &config.Config{
+		Profile:          "staging",
+		AccessToken:      "pscale_oauth_secret",
+		CommandLineFlags: map[string]string{"format": "json", "profile": "staging"},
+	}
+	out, err := runConfig(c, cfg, path, "list", "--show-origin")
+	c.Assert(err, qt.IsNil)
+
+	profile := "profile staging (" + path + ")"
+	c.Assert(out, qt.JSONEquals, []*origin{
+		{Key: "api-token", Value: redacted, Origin: "keyring entry access-token"},
+		{Key: "api-url", Value: "https://api.staging.example.com", Origin: profile, Overridden: []*origin{
+			{Key: "api-url", Value: "https://api.example.com", Origin: path},
+			{Key: "api-url", Value: "https://api.planetscale.com/", Origin: "default"},
+		}},
+		{Key: "database", Value: "from-env", Origin: "env PLANETSCALE_DATABASE"},
+		{Key: "debug", Value: "false", Origin: "default"},
+		{Key: "format", Value: "json", Origin: "flag --format", Overridden: []*origin{
+			{Key: "format", Value: "json", Origin: path},
+			{Key: "format", Value: "human", Origin: "default"},
+		}},
+		{Key: "no-color", Value: "false", Origin: "default"},
+		{Key: "org", Value: "acme-staging", Origin: profile, Overridden: []*origin{
+			{Key: "org", Value: "acme", Origin: path},
+		}},
+		{Key: "profile", Value: "staging", Origin: "flag --profile", Overridden: []*origin{
+			{Key: "profile", Value: "default", Origin: "default"},
+		}},
+	})
+}
+
+func TestGet(t *testing.T) {
+	c := qt.New(t)
+
+	path := writeConfig(c, testConfig)
+	out, err := runConfig(c, &config.Config{}, path, "get", "org")
+	c.Assert(err, qt.IsNil)
+	c.Assert(out, qt.JSONEquals, &origin{Key: "org", Value: "acme", Origin: path})
+
+	out, err = runConfig(c, &config.Config{}, path, "get", "profiles.staging.org")
+	c.Assert(err, qt.IsNil)
+	c.Assert(out, qt.JSONEquals, &origin{Key: "profiles.staging.org", Value: "acme-staging", Origin: path})
+
+	_, err = runConfig(c, &config.Config{}, path, "get", "branch")
+	c.Assert(err, qt.ErrorMatches, "branch is not set")
+}
+
+func TestSetUnset(t *testing.T) {
+	c := qt.New(t)
+
+	path := writeConfig(c, testConfig)
+	_, err := runConfig(c, &config.Config{}, path, "set", "profiles.ci.org", "acme")
+	c.Assert(err, qt.IsNil)
+	_, err = runConfig(c, &config.Config{}, path, "set", "profile", "ci")
+	c.Assert(err, qt.IsNil)
+	_, err = runConfig(c, &config.Config{}, path, "set", "debug", "yes")
+	c.Assert(err, qt.ErrorMatches, "debug must be true or false")
+	_, err = runConfig(c, &config.Config{}, path, "set", "debug", "1")
+	c.Assert(err, qt.IsNil)
+	_, err = runConfig(c, &config.Config{}, path, "set", "format", "yaml")
+	c.Assert(err, qt.IsNil)
+	_, err = runConfig(c, &config.Config{}, path, "set", "format", "json")
+	c.Assert(err, qt.IsNil)
+	_, err = runConfig(c, &config.Config{}, path, "unset", "api-url")
+	c.Assert(err, qt.IsNil)
+
+	data, err := os.ReadFile(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, `org: acme
+format: json
+profiles:
+  staging:
+    org: acme-staging
+    api-url: https://api.staging.example.com
+  ci:
+    org: acme
+profile: ci
+debug: true
+`)
+
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"set", "format", "xml"}, "format must be one of: human, json, csv, template, yaml, markdown, ndjson"},
+		{[]string{"set", "api-url", "api.example.com"}, `api-url: "api.example.com" is not an http or https URL`},
+		{[]string{"set", "api-token", "pscale_tkn"}, "api-token is a credential.*"},
+		{[]string{"set", "profile", "prod"}, `profile "prod" doesn't exist.*`},
+		{[]string{"set", "profiles.default.org", "acme"}, "the default profile is made of the top level settings.*"},
+		{[]string{"set", "profiles.ci.password", "x"}, `unknown setting "profiles.ci.password".*`},
+		{[]string{"set", "colour", "red"}, `unknown setting "colour".*`},
+		{[]string{"set", "--project", "api-url", "https://api.example.com"}, "api-url can't be set in a project file.*"},
+	}
+	for _, tt := range tests {
+		_, err := runConfig(c, &config.Config{}, path, tt.args...)
+		c.Assert(err, qt.ErrorMatches, tt.err, qt.Commentf("%v", tt.args))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := qt.New(t)
+	c.Setenv(config.ProfileEnv, "")
+	c.Setenv("PLANETSCALE_SERVICE_TOKEN_ID", "abc123")
+
+	path := writeConfig(c, `org: acme
+format: xml
+colour: red
+profile: prod
+profiles:
+  default:
+    org: acme
+  ci:
+    service-token-id: abc123
+    service-token-env: ACME_SERVICE_TOKEN_UNSET
+`)
+	out, err := runConfig(c, &config.Config{}, path, "validate")
+	c.Assert(err, qt.IsNotNil)
+	c.Assert(out, qt.JSONEquals, []*issue{
+		{Level: levelWarning, Message: path + ": unknown setting colour"},
+		{Level: levelError, Message: "format: xml from " + path + " must be one of: human, json, csv, template, yaml, markdown, ndjson"},
+		{Level: levelError, Message: "service-token-id and service-token must be set together"},
+		{Level: levelError, Message: path + `: the saved profile "prod" doesn't exist, run 'pscale profile list' to see the available profiles`},
+		{Level: levelWarning, Message: "profile ci: environment variable ACME_SERVICE_TOKEN_UNSET is not set"},
+		{Level: levelWarning, Message: path + ": profiles.default is ignored, the default profile is made of the top level settings"},
+	})
+
+	path = writeConfig(c, "org: acme\n")
+	c.Setenv("PLANETSCALE_SERVICE_TOKEN_ID", "")
+	out, err = runConfig(c, &config.Config{}, path, "validate")
+	c.Assert(err, qt.IsNil)
+	c.Assert(out, qt.JSONEquals, []*issue{})
+}
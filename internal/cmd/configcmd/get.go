@@ -0,0 +1,83 @@
+package configcmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/spf13/cobra"
+)
+
+// GetCmd prints the effective value of a setting.
+func GetCmd(ch *cmdutil.Helper) *cobra.Command {
+	var showOrigin bool
+
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Long: `Print the effective value of a setting.
+
+Settings of profiles are read from the global file with keys such as
+profiles.staging.org.`,
+		Args:              cmdutil.RequiredArgs("key"),
+		ValidArgsFunction: completeKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+
+			src, err := loadSources(cmd, ch)
+			if err != nil {
+				return err
+			}
+
+			var s *config.Setting
+			if strings.Contains(key, ".") {
+				v, ok, err := config.GetFileValue(src.globalPath, key)
+				if err != nil {
+					return err
+				}
+				if ok {
+					s = &config.Setting{Key: key, Value: v, Origin: src.globalPath}
+				}
+			} else {
+				s = src.lookup(key)
+			}
+			if s == nil {
+				return &cmdutil.Error{
+					Msg:      fmt.Sprintf("%s is not set", key),
+					ExitCode: cmdutil.ActionRequestedExitCode,
+				}
+			}
+
+			if ch.Printer.Format() != printer.Human {
+				return ch.Printer.PrintResource(toOrigin(s))
+			}
+			if showOrigin {
+				ch.Printer.Printf("%s\t%s\n", display(s.Key, s.Value), s.Origin)
+				return nil
+			}
+			ch.Printer.Println(display(s.Key, s.Value))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&showOrigin, "show-origin", false, "Show the file, environment variable or flag the value came from.")
+	cmdutil.SetJSONOutput(cmd, &origin{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
+}
+
+func completeKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	keys := make([]string, 0, len(settings))
+	for _, s := range settings {
+		keys = append(keys, s.key)
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,74 @@
+package configcmd
+
+import (
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// value is a table-serializable setting.
+type value struct {
+	Key   string `header:"key" json:"key"`
+	Value string `header:"value" json:"value"`
+}
+
+// origin is a table-serializable setting with the place it came from.
+type origin struct {
+	Key        string    `header:"key" json:"key"`
+	Value      string    `header:"value" json:"value"`
+	Origin     string    `header:"origin" json:"origin"`
+	Overridden []*origin `json:"overridden,omitempty" csv:"-"`
+}
+
+func toValue(s *config.Setting) *value {
+	return &value{Key: s.Key, Value: display(s.Key, s.Value)}
+}
+
+func toOrigin(s *config.Setting) *origin {
+	o := &origin{Key: s.Key, Value: display(s.Key, s.Value), Origin: s.Origin}
+	for _, over := range s.Overridden {
+		o.Overridden = append(o.Overridden, toOrigin(over))
+	}
+	return o
+}
+
+// ListCmd lists the effective settings.
+func ListCmd(ch *cmdutil.Helper) *cobra.Command {
+	var showOrigin bool
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the effective settings",
+		Args:    cobra.NoArgs,
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, err := loadSources(cmd, ch)
+			if err != nil {
+				return err
+			}
+
+			settings := src.resolve()
+			if showOrigin {
+				origins := make([]*origin, 0, len(settings))
+				for _, s := range settings {
+					origins = append(origins, toOrigin(s))
+				}
+				return ch.Printer.PrintResource(origins)
+			}
+
+			values := make([]*value, 0, len(settings))
+			for _, s := range settings {
+				values = append(values, toValue(s))
+			}
+			return ch.Printer.PrintResource(values)
+		},
+	}
+
+	cmd.Flags().BoolVar(&showOrigin, "show-origin", false, "Show the file, environment variable or flag each value came from.")
+	cmdutil.SetJSONOutput(cmd, []*value{}, []*origin{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
+}
@@ -0,0 +1,217 @@
+package configcmd
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// SetCmd writes a setting to the global or the project config file.
+func SetCmd(ch *cmdutil.Helper) *cobra.Command {
+	var project bool
+
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Write a setting to a config file",
+		Long: `Write a setting to the global config file, or with --project to the
+.pscale.yml file of the project.
+
+Profiles are created and edited with keys such as profiles.staging.org.
+Credentials are never written to config files: use "pscale auth login" or a
+profile's service-token-env.`,
+		Example: `  pscale config set org my-org
+  pscale config set --project database my-db
+  pscale config set profiles.staging.api-url https://api.staging.example.com`,
+		Args:              cmdutil.RequiredArgs("key", "value"),
+		ValidArgsFunction: completeKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, raw := args[0], args[1]
+
+			path, err := targetPath(cmd, project)
+			if err != nil {
+				return err
+			}
+			if err := checkKey(cmd, key, project); err != nil {
+				return err
+			}
+			v, err := parseValue(key, raw)
+			if err != nil {
+				return err
+			}
+			if key == "profile" {
+				if err := checkProfile(ch, path, raw); err != nil {
+					return err
+				}
+			}
+
+			if err := config.SetFileValue(path, key, v); err != nil {
+				return err
+			}
+
+			ch.Printer.Printf("Set %s to %s in %s\n", printer.Bold(key), printer.Bold(raw), path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&project, "project", false, "Write to the .pscale.yml file of the project instead of the global config file.")
+
+	return cmd
+}
+
+// UnsetCmd removes a setting from the global or the project config file.
+func UnsetCmd(ch *cmdutil.Helper) *cobra.Command {
+	var project bool
+
+	cmd := &cobra.Command{
+		Use:               "unset <key>",
+		Short:             "Remove a setting from a config file",
+		Args:              cmdutil.RequiredArgs("key"),
+		ValidArgsFunction: completeKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+
+			path, err := targetPath(cmd, project)
+			if err != nil {
+				return err
+			}
+
+			removed, err := config.UnsetFileValue(path, key)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				ch.Printer.Printf("%s is not set in %s\n", printer.Bold(key), path)
+				return nil
+			}
+
+			ch.Printer.Printf("Removed %s from %s\n", printer.Bold(key), path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&project, "project", false, "Remove from the .pscale.yml file of the project instead of the global config file.")
+
+	return cmd
+}
+
+func targetPath(cmd *cobra.Command, project bool) (string, error) {
+	if project {
+		return config.ProjectConfigPath()
+	}
+	return globalPath(cmd)
+}
+
+// checkKey returns an error if key can't be written to a config file.
+func checkKey(cmd *cobra.Command, key string, project bool) error {
+	if project {
+		allowed, _ := config.FilterProjectConfig(map[string]interface{}{key: nil})
+		if len(allowed) == 0 {
+			return fmt.Errorf("%s can't be set in a project file, project files may only set org, database and branch", key)
+		}
+		return nil
+	}
+
+	if parts := strings.Split(key, "."); len(parts) > 1 {
+		if len(parts) != 3 || parts[0] != "profiles" || parts[1] == "" || !slices.Contains(profileFields, parts[2]) {
+			return fmt.Errorf("unknown setting %q, profile settings are profiles.<name>.<%s>", key, strings.Join(profileFields, "|"))
+		}
+		if parts[1] == config.DefaultProfile {
+			return fmt.Errorf("the %s profile is made of the top level settings, set %s instead", config.DefaultProfile, parts[2])
+		}
+		return nil
+	}
+
+	if s := lookupSetting(key); s != nil {
+		if s.credential {
+			return fmt.Errorf("%s is a credential and isn't stored in config files, use 'pscale auth login' or a profile's service-token-env", key)
+		}
+		return nil
+	}
+
+	// Any flag can be preset from the config file.
+	if isFlag(cmd.Root(), key) {
+		return nil
+	}
+	return fmt.Errorf("unknown setting %q, run 'pscale config list' to see the settings", key)
+}
+
+// parseValue checks raw and returns it as it's stored in the file.
+func parseValue(key, raw string) (interface{}, error) {
+	name := key
+	if parts := strings.Split(key, "."); len(parts) == 3 {
+		name = parts[2]
+	}
+
+	if s := lookupSetting(name); s != nil {
+		if s.boolean {
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be true or false", key)
+			}
+			return b, nil
+		}
+		if len(s.values) > 0 && !slices.Contains(s.values, raw) {
+			return nil, fmt.Errorf("%s must be one of: %s", key, strings.Join(s.values, ", "))
+		}
+	}
+	if name == "api-url" {
+		if err := checkURL(raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return raw, nil
+}
+
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", raw)
+	}
+	return nil
+}
+
+// checkProfile returns an error if the profile called name isn't defined in
+// the config file at path.
+func checkProfile(ch *cmdutil.Helper, path, name string) error {
+	cfg, err := ch.ConfigFS.NewFileConfig(path)
+	if err != nil {
+		cfg = &config.FileConfig{}
+	}
+	_, err = cfg.LookupProfile(name)
+	return err
+}
+
+func isFlag(cmd *cobra.Command, name string) bool {
+	found := false
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	cmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	if found {
+		return true
+	}
+	for _, child := range cmd.Commands() {
+		if isFlag(child, name) {
+			return true
+		}
+	}
+	return false
+}
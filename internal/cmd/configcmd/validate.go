@@ -0,0 +1,195 @@
+package configcmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/spf13/cobra"
+)
+
+// issue is a table-serializable configuration problem.
+type issue struct {
+	Level   string `header:"level" json:"level"`
+	Message string `header:"message" json:"message"`
+}
+
+const (
+	levelError   = "error"
+	levelWarning = "warning"
+)
+
+// ValidateCmd reports problems with the configuration.
+func ValidateCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Report problems with the configuration",
+		Long: `Report problems with the configuration: invalid values, keys ignored in
+project files, missing profiles and credentials, and values overridden by
+other sources. The command fails if any problem is an error.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, err := loadSources(cmd, ch)
+			if err != nil {
+				return err
+			}
+
+			issues := validate(cmd, src)
+			errors := 0
+			for _, i := range issues {
+				if i.Level == levelError {
+					errors++
+				}
+			}
+
+			if len(issues) == 0 && ch.Printer.Format() == printer.Human {
+				ch.Printer.Println("No problems found in the configuration.")
+				return nil
+			}
+			if err := ch.Printer.PrintResource(issues); err != nil {
+				return err
+			}
+
+			if errors == 0 {
+				return nil
+			}
+			if ch.Printer.Format() == printer.JSON {
+				return cmdutil.JSONReportedError(cmdutil.ActionRequestedExitCode)
+			}
+			return &cmdutil.Error{
+				Msg:      fmt.Sprintf("the configuration has %d error(s)", errors),
+				ExitCode: cmdutil.ActionRequestedExitCode,
+			}
+		},
+	}
+	cmdutil.SetJSONOutput(cmd, []*issue{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
+}
+
+func validate(cmd *cobra.Command, src *sources) []*issue {
+	issues := []*issue{}
+	add := func(level, format string, a ...interface{}) {
+		issues = append(issues, &issue{Level: level, Message: fmt.Sprintf(format, a...)})
+	}
+
+	for _, key := range src.projectIgnored {
+		add(levelWarning, "%s: %s is ignored, project files may only set org, database and branch", src.projectPath, key)
+	}
+
+	unknown := make([]string, 0)
+	for key := range src.global {
+		if lookupSetting(key) == nil && !isFlag(cmd.Root(), key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		add(levelWarning, "%s: unknown setting %s", src.globalPath, key)
+	}
+
+	resolved := map[string]*config.Setting{}
+	for _, s := range src.resolve() {
+		resolved[s.Key] = s
+		validateValue(s, add)
+
+		if strings.HasPrefix(s.Origin, "flag ") {
+			continue
+		}
+		for _, over := range s.Overridden {
+			if over.Value == s.Value || over.Origin == "default" {
+				continue
+			}
+			// Project files and profiles are meant to override the global
+			// file, surprises come from overriding those.
+			if over.Origin == src.globalPath || strings.HasPrefix(over.Origin, "keyring ") {
+				continue
+			}
+			add(levelWarning, "%s: %s from %s overrides %s from %s",
+				s.Key, display(s.Key, s.Value), s.Origin, display(s.Key, over.Value), over.Origin)
+		}
+	}
+
+	_, hasID := resolved["service-token-id"]
+	_, hasToken := resolved["service-token"]
+	if hasID != hasToken {
+		add(levelError, "service-token-id and service-token must be set together")
+	}
+	if _, ok := resolved["api-token"]; ok && hasID && hasToken {
+		add(levelWarning, "both an access token and a service token are set, the service token is used")
+	}
+
+	validateProfiles(src, add)
+
+	return issues
+}
+
+func validateValue(s *config.Setting, add func(level, format string, a ...interface{})) {
+	info := lookupSetting(s.Key)
+	switch {
+	case info == nil:
+	case info.boolean:
+		if _, err := strconv.ParseBool(s.Value); err != nil {
+			add(levelError, "%s: %s from %s must be true or false", s.Key, s.Value, s.Origin)
+		}
+	case len(info.values) > 0:
+		if !slices.Contains(info.values, s.Value) {
+			add(levelError, "%s: %s from %s must be one of: %s", s.Key, s.Value, s.Origin, strings.Join(info.values, ", "))
+		}
+	case s.Key == "api-url":
+		if err := checkURL(s.Value); err != nil {
+			add(levelError, "api-url from %s: %s", s.Origin, err)
+		}
+	}
+}
+
+func validateProfiles(src *sources, add func(level, format string, a ...interface{})) {
+	if src.file.Profile != "" {
+		if _, err := src.file.LookupProfile(src.file.Profile); err != nil {
+			add(levelError, "%s: the saved %s", src.globalPath, err)
+		}
+	}
+	if env := os.Getenv(config.ProfileEnv); env != "" {
+		if _, err := src.file.LookupProfile(env); err != nil {
+			add(levelError, "%s: %s", config.ProfileEnv, err)
+		}
+	}
+
+	for _, name := range src.file.ProfileNames() {
+		if name == config.DefaultProfile {
+			continue
+		}
+		p := src.file.Profiles[name]
+		if p == nil {
+			continue
+		}
+		if p.BaseURL != "" {
+			if err := checkURL(p.BaseURL); err != nil {
+				add(levelError, "profile %s: api-url: %s", name, err)
+			}
+		}
+		switch {
+		case p.ServiceTokenEnv != "" && p.ServiceTokenID == "":
+			add(levelWarning, "profile %s: service-token-env is set without service-token-id and is ignored", name)
+		case p.ServiceTokenID != "" && p.TokenKey != "":
+			add(levelWarning, "profile %s: token-key is ignored, the profile uses a service token", name)
+		case p.ServiceTokenID != "" && p.ServiceTokenEnv == "":
+			add(levelWarning, "profile %s: service-token-env is not set, pass the service token with --service-token", name)
+		case p.ServiceTokenID != "" && os.Getenv(p.ServiceTokenEnv) == "":
+			add(levelWarning, "profile %s: environment variable %s is not set", name, p.ServiceTokenEnv)
+		}
+	}
+	if _, ok := src.file.Profiles[config.DefaultProfile]; ok {
+		add(levelWarning, "%s: profiles.%s is ignored, the %s profile is made of the top level settings",
+			src.globalPath, config.DefaultProfile, config.DefaultProfile)
+	}
+}
@@ -0,0 +1,211 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/printer"
+	"github.com/planetscale/cli/internal/proxyutil"
+	"github.com/planetscale/cli/internal/querylog"
+)
+
+// connectConfig is the file passed to pscale connect --connect-file.
+type connectConfig struct {
+	Connections []*connectionEntry `yaml:"connections"`
+}
+
+// connectionEntry is one database branch of a connect config file.
+type connectionEntry struct {
+	Name            string `yaml:"name"`
+	Database        string `yaml:"database"`
+	Branch          string `yaml:"branch"`
+	Role            string `yaml:"role"`
+	Replica         bool   `yaml:"replica"`
+	Split           bool   `yaml:"split"`
+	Host            string `yaml:"host"`
+	Port            int    `yaml:"port"`
+	Socket          string `yaml:"socket"`
+	RemoteAddr      string `yaml:"remote_addr"`
+	PostgresDB      string `yaml:"dbname"`
+	MySQLAuthMethod string `yaml:"mysql_auth_method"`
+	Protocol        string `yaml:"protocol"`
+	Env             string `yaml:"env"`
+}
+
+// ConnectionSummary describes a proxy started from a config file.
+type ConnectionSummary struct {
+	Name     string `header:"name" json:"name"`
+	Database string `header:"database" json:"database"`
+	Branch   string `header:"branch" json:"branch"`
+	Kind     string `header:"kind" json:"kind"`
+	Address  string `header:"address" json:"address"`
+	Env      string `header:"env" json:"env"`
+}
+
+// loadConnectConfig reads and validates a connect config file, filling in
+// default names and environment variables.
+func loadConnectConfig(path string) (*connectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg connectConfig
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(cfg.Connections) == 0 {
+		return nil, fmt.Errorf("%s doesn't list any connections", path)
+	}
+
+	names := map[string]bool{}
+	envs := map[string]bool{}
+	for i, c := range cfg.Connections {
+		if c.Database == "" || c.Branch == "" {
+			return nil, fmt.Errorf("connection %d in %s needs a database and a branch", i+1, path)
+		}
+		if c.Name == "" {
+			c.Name = c.Database
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("connection name %q is used more than once in %s; give the connections distinct names", c.Name, path)
+		}
+		names[c.Name] = true
+
+		if c.Env == "" {
+			c.Env = envName(c.Name) + "_DATABASE_URL"
+		}
+		if envs[c.Env] {
+			return nil, fmt.Errorf("environment variable %s is used by more than one connection in %s", c.Env, path)
+		}
+		envs[c.Env] = true
+
+		if c.Socket != "" && c.Host != "" {
+			return nil, fmt.Errorf("connection %s sets both socket and host", c.Name)
+		}
+		if c.Port < 0 || c.Port > 65535 {
+			return nil, fmt.Errorf("connection %s has invalid port %d", c.Name, c.Port)
+		}
+		if c.PostgresDB == "" {
+			c.PostgresDB = "postgres"
+		}
+	}
+	return &cfg, nil
+}
+
+// envName turns a connection name into an environment variable prefix:
+// "my-app" becomes MY_APP.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// connectFromConfig starts a proxy for every connection in the config file
+// and serves them until ctx is canceled or one of them fails.
+func connectFromConfig(ctx context.Context, cancel context.CancelFunc, ch *cmdutil.Helper, flags *connectFlags, queryLog *querylog.Logger, metrics *proxyutil.Metrics) error {
+	cfg, err := loadConnectConfig(flags.connectFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := ch.Client()
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 2*len(cfg.Connections)+1)
+	summaries := make([]*ConnectionSummary, 0, len(cfg.Connections))
+	env := make([]string, 0, len(cfg.Connections))
+	states := make([]*connectionState, 0, len(cfg.Connections))
+
+	for _, c := range cfg.Connections {
+		opts := &proxyOptions{
+			database:   c.Database,
+			branch:     c.Branch,
+			role:       c.Role,
+			replica:    c.Replica,
+			split:      c.Split,
+			host:       c.Host,
+			socket:     c.Socket,
+			noRandom:   flags.noRandom,
+			remoteAddr: c.RemoteAddr,
+			authMethod: c.MySQLAuthMethod,
+			postgresDB: c.PostgresDB,
+			protocol:   c.Protocol,
+			queryLog:   queryLog,
+			metrics:    metrics,
+		}
+		if opts.host == "" {
+			opts.host = flags.host
+		}
+		if c.Port != 0 {
+			opts.port = strconv.Itoa(c.Port)
+		}
+
+		p, err := startProxy(ctx, ch, client, opts, errCh)
+		if err != nil {
+			return fmt.Errorf("connection %s: %w", c.Name, err)
+		}
+		defer p.Close()
+
+		summaries = append(summaries, &ConnectionSummary{
+			Name:     c.Name,
+			Database: c.Database,
+			Branch:   c.Branch,
+			Kind:     p.kind,
+			Address:  p.localAddr,
+			Env:      c.Env,
+		})
+		env = append(env, fmt.Sprintf("%s=%s", c.Env, p.url))
+
+		st := p.state()
+		st.Name, st.Env = c.Name, c.Env
+		states = append(states, st)
+	}
+
+	if flags.stateFile != "" {
+		remove, err := writeState(flags.stateFile, states)
+		if err != nil {
+			return err
+		}
+		defer remove()
+	}
+
+	if ch.Printer.Format() == printer.Human {
+		ch.Printer.Printf("Secure connections to %s branches are established! (press ctrl-c to quit)\n\n", printer.Number(uint64(len(summaries))))
+	}
+	if err := ch.Printer.PrintResource(summaries); err != nil {
+		return err
+	}
+
+	if flags.execCommand != "" {
+		go func() {
+			errCh <- runCommand(ctx, flags.execCommand, env)
+			cancel() // stop the proxies by cancelling all other child contexts
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errCh:
+		if err == nil {
+			return nil
+		}
+		return cmdutil.HandleError(err)
+	}
+}
@@ -0,0 +1,117 @@
+package connect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "connect.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConnectConfig(t *testing.T) {
+	c := qt.New(t)
+
+	path := writeConfig(t, `
+connections:
+  - name: my-app
+    database: app
+    branch: main
+    role: readwriter
+    port: 3306
+    split: true
+  - database: analytics
+    branch: dev
+    replica: true
+  - database: events
+    branch: main
+    env: EVENTS_URL
+    dbname: events
+`)
+
+	cfg, err := loadConnectConfig(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Connections, qt.HasLen, 3)
+
+	app := cfg.Connections[0]
+	c.Assert(app.Env, qt.Equals, "MY_APP_DATABASE_URL")
+	c.Assert(app.Port, qt.Equals, 3306)
+	c.Assert(app.Role, qt.Equals, "readwriter")
+	c.Assert(app.PostgresDB, qt.Equals, "postgres")
+	c.Assert(app.Split, qt.IsTrue)
+
+	analytics := cfg.Connections[1]
+	c.Assert(analytics.Name, qt.Equals, "analytics")
+	c.Assert(analytics.Env, qt.Equals, "ANALYTICS_DATABASE_URL")
+	c.Assert(analytics.Replica, qt.IsTrue)
+
+	events := cfg.Connections[2]
+	c.Assert(events.Env, qt.Equals, "EVENTS_URL")
+	c.Assert(events.PostgresDB, qt.Equals, "events")
+}
+
+func TestLoadConnectConfigErrors(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{content: "connections: []", err: ".* doesn't list any connections"},
+		{content: "connections:\n  - database: app\n", err: "connection 1 in .* needs a database and a branch"},
+		{content: "connections:\n  - database: app\n    branch: main\n  - database: app\n    branch: dev\n", err: `connection name "app" is used more than once.*`},
+		{content: "connections:\n  - database: app\n    branch: main\n    env: URL\n  - database: b\n    branch: main\n    env: URL\n", err: "environment variable URL is used by more than one connection.*"},
+		{content: "connections:\n  - database: app\n    branch: main\n    prot: 3306\n", err: `(?s)parsing .*field prot not found.*`},
+		{content: "connections:\n  - database: app\n    branch: main\n    port: 70000\n", err: "connection app has invalid port 70000"},
+		{content: "connections:\n  - database: app\n    branch: main\n    socket: /tmp/app.sock\n    host: 0.0.0.0\n", err: "connection app sets both socket and host"},
+	}
+	for _, tt := range tests {
+		_, err := loadConnectConfig(writeConfig(t, tt.content))
+		c.Assert(err, qt.ErrorMatches, tt.err, qt.Commentf("%s", tt.content))
+	}
+}
+
+func TestDatabaseURL(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(databaseURL("mysql2", "127.0.0.1:3306", "app"), qt.Equals, "mysql2://root@127.0.0.1:3306/app")
+	c.Assert(databaseURL("postgresql", "127.0.0.1:5432", "postgres"), qt.Equals, "postgresql://postgres@127.0.0.1:5432/postgres?sslmode=disable")
+}
+
+func TestConnectFileArgs(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{"--connect-file", "connect.yml"}},
+		{args: []string{"--connect-file", "connect.yml", "--host", "0.0.0.0", "--execute", "true"}},
+		{args: []string{"--connect-file", "connect.yml", "app"}, err: "database and branch arguments can't be combined with --connect-file"},
+		{args: []string{"--connect-file", "connect.yml", "--role", "reader"}, err: "--role can't be combined with --connect-file, .*"},
+		{args: []string{"--connect-file", "connect.yml", "--port", "3307"}, err: "--port can't be combined with --connect-file, .*"},
+		{args: []string{"--connect-file", "connect.yml", "--replica"}, err: "--replica can't be combined with --connect-file, .*"},
+		{args: []string{"--connect-file", "connect.yml", "--execute-protocol", "mysql"}, err: "--execute-protocol can't be combined with --connect-file, .*"},
+		{args: []string{"--role", "reader", "app"}},
+	}
+	for _, tt := range tests {
+		cmd := ConnectCmd(&cmdutil.Helper{Config: &config.Config{}})
+		c.Assert(cmd.ParseFlags(tt.args), qt.IsNil)
+		err := cmd.ValidateArgs(cmd.Flags().Args())
+		if tt.err == "" {
+			c.Assert(err, qt.IsNil, qt.Commentf("%v", tt.args))
+		} else {
+			c.Assert(err, qt.ErrorMatches, tt.err, qt.Commentf("%v", tt.args))
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -18,33 +19,114 @@ import (
 	"github.com/planetscale/cli/internal/printer"
 	"github.com/planetscale/cli/internal/promptutil"
 	"github.com/planetscale/cli/internal/proxyutil"
+	"github.com/planetscale/cli/internal/querylog"
 
 	"github.com/mattn/go-shellwords"
+	"github.com/planetscale/psdbproxy"
 	"github.com/spf13/cobra"
 
 	"vitess.io/vitess/go/mysql"
 )
 
+// connectFlags are the command line flags of pscale connect.
+type connectFlags struct {
+	port                string
+	host                string
+	remoteAddr          string
+	execCommand         string
+	execCommandProtocol string
+	execCommandEnvURL   string
+	role                string
+	noRandom            bool
+	replica             bool
+	split               bool
+	authMethod          string
+	postgresDB          string
+	connectFile         string
+	queryLog            string
+	queryLogRedact      bool
+	metricsAddr         string
+	socket              string
+	stateFile           string
+}
+
 func ConnectCmd(ch *cmdutil.Helper) *cobra.Command {
-	var flags struct {
-		port                string
-		host                string
-		remoteAddr          string
-		execCommand         string
-		execCommandProtocol string
-		execCommandEnvURL   string
-		role                string
-		noRandom            bool
-		replica             bool
-		authMethod          string
-	}
+	var flags connectFlags
 
 	cmd := &cobra.Command{
 		Use: "connect [database] [branch]",
 		// we only require database, because we deduct branch automatically
-		Args:  cmdutil.RequiredArgs("database"),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if flags.connectFile != "" {
+				if len(args) > 0 {
+					return errors.New("database and branch arguments can't be combined with --connect-file")
+				}
+				for _, name := range connectionFlags {
+					if cmd.Flags().Changed(name) {
+						return fmt.Errorf("--%s can't be combined with --connect-file, set it for each connection in the file instead", name)
+					}
+				}
+				return nil
+			}
+			return cmdutil.RequiredArgs("database")(cmd, args)
+		},
 		Short: "Create a secure connection to a database and branch for a local client",
-		Long:  "Create a secure connection to a database and branch for a local client.\n\nThis command is only supported for Vitess databases.",
+		Long: `Create a secure connection to a database and branch for a local client.
+
+For Vitess databases the local address speaks the MySQL protocol and listens
+on port 3306 by default. For Postgres databases it speaks the Postgres
+protocol and listens on port 5432 by default. Connect to it without TLS and
+with any user name and password: the connection to PlanetScale is encrypted
+and authenticated with an ephemeral credential that is renewed while the
+command runs and deleted when it exits.
+
+To connect to several branches from one process, list them in a YAML file and
+pass it with --connect-file:
+
+  connections:
+    - name: app
+      database: app
+      branch: main
+      role: readwriter
+      port: 3306
+    - name: analytics
+      database: analytics
+      branch: main
+      replica: true
+      port: 3307
+
+Each entry accepts name, database, branch, role, replica, split, host, port,
+socket, remote_addr, dbname, mysql_auth_method, protocol and env, and the flags
+of the same names can't be combined with --connect-file. With --execute every
+connection is exposed in its own environment variable, named by env or
+<NAME>_DATABASE_URL by default (APP_DATABASE_URL above).
+
+With --split a Vitess connection holds both a primary and a replica
+credential. Autocommit SELECT statements outside transactions go to replicas
+and everything else, including prepared statements, goes to the primary. SET
+and USE statements run on both. Replicas may lag behind the primary, so a
+read right after a write outside a transaction may not see it. The number of
+statements sent each way is printed on exit and exported as metrics.
+
+With --socket the proxy listens on a unix socket instead of a TCP port. The
+socket is only accessible to the current user. Postgres clients look for a
+socket named .s.PGSQL.<port> in a directory, so for Postgres databases
+--socket names that directory. --state-file writes the chosen addresses, ports,
+sockets and URLs as JSON for other tools and removes the file on exit.
+
+With --query-log every statement passing through the proxy is appended to a
+file as newline-delimited JSON, with its time, connection, duration, rows and
+error. Pass --query-log-redact to replace literals with ?. Summarize a log
+with "pscale connect log summarize".
+
+With --metrics-addr an HTTP listener serves Prometheus metrics at /metrics:
+open, accepted and failed connections, bytes in and out, and credential
+renewals and renewal failures, labelled by database and branch. Failed
+connections are counted for Postgres branches and with --split; a Vitess
+proxy without --split relays all its clients over one upstream session and
+doesn't report them. /healthz answers 503 while the upstream address of any
+branch can't be reached or its last credential renewal failed, for use as a
+liveness probe when connect runs as a sidecar.`,
 		Example: `The connect subcommand establishes a secure connection between your host and PlanetScale.
 
 By default, if no branch names are given and there is only one branch, it
@@ -56,12 +138,64 @@ If there are multiple branches for the given database, you'll be prompted to
 choose one. To connect to a specific branch, pass the branch as a second
 argument:
 
-  pscale connect mydatabase mybranch`,
+  pscale connect mydatabase mybranch
+
+To run your application with the connection in DATABASE_URL (a postgresql://
+URL for Postgres databases), pass it to --execute:
+
+  pscale connect mydatabase mybranch --execute "npm run dev"
+
+To connect to every branch listed in connect.yml:
+
+  pscale connect --connect-file connect.yml --execute "npm run dev"
+
+To listen on a unix socket and record it for other tools:
+
+  pscale connect mydatabase mybranch --socket /tmp/mydatabase.sock --state-file connect.json
+
+To log every statement your application runs, without literals:
+
+  pscale connect mydatabase mybranch --query-log queries.ndjson --query-log-redact`,
 		PersistentPreRunE: cmdutil.CheckAuthentication(ch.Config),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, cancel := context.WithCancel(cmd.Context())
 			defer cancel()
 
+			if flags.socket != "" && cmd.Flags().Changed("host") {
+				return errors.New("--socket can't be combined with --host")
+			}
+			if flags.queryLog == "" && flags.queryLogRedact {
+				return errors.New("--query-log-redact requires --query-log")
+			}
+
+			var queryLog *querylog.Logger
+			if flags.queryLog != "" {
+				var err error
+				queryLog, err = querylog.Open(flags.queryLog, flags.queryLogRedact)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if err := queryLog.Close(); err != nil {
+						ch.Printer.Println("failed to write query log: ", err)
+					}
+				}()
+			}
+
+			var metrics *proxyutil.Metrics
+			if flags.metricsAddr != "" {
+				metrics = proxyutil.NewMetrics()
+				stop, err := serveMetrics(ch, flags.metricsAddr, metrics)
+				if err != nil {
+					return err
+				}
+				defer stop()
+			}
+
+			if flags.connectFile != "" {
+				return connectFromConfig(ctx, cancel, ch, &flags, queryLog, metrics)
+			}
+
 			database := args[0]
 
 			client, err := ch.Client()
@@ -87,117 +221,58 @@ argument:
 				}
 			}
 
-			replica := flags.replica
-
-			role := cmdutil.AdministratorRole
-			if flags.role != "" {
-				role, err = cmdutil.RoleFromString(flags.role)
-				if err != nil {
-					return err
-				}
-			} else if replica {
-				role = cmdutil.ReaderRole
+			opts := &proxyOptions{
+				database:   database,
+				branch:     branch,
+				role:       flags.role,
+				replica:    flags.replica,
+				split:      flags.split,
+				socket:     flags.socket,
+				host:       flags.host,
+				noRandom:   flags.noRandom,
+				remoteAddr: flags.remoteAddr,
+				authMethod: flags.authMethod,
+				postgresDB: flags.postgresDB,
+				queryLog:   queryLog,
+				metrics:    metrics,
 			}
-
-			authMethod := mysql.CachingSha2Password
-			if flags.authMethod != "" {
-				switch flags.authMethod {
-				case "caching_sha2_password":
-					authMethod = mysql.CachingSha2Password
-				case "mysql_native_password":
-					authMethod = mysql.MysqlNativePassword
-				default:
-					return fmt.Errorf("unsupported auth method: %s", flags.authMethod)
-				}
+			if cmd.Flags().Changed("port") {
+				opts.port = flags.port
 			}
-
-			// check whether database and branch exist
-			dbBranch, err := client.DatabaseBranches.Get(ctx, &planetscale.GetDatabaseBranchRequest{
-				Organization: ch.Config.Organization,
-				Database:     database,
-				Branch:       branch,
-			})
-			if err != nil {
-				switch cmdutil.ErrCode(err) {
-				case planetscale.ErrNotFound:
-					return fmt.Errorf("database %s and branch %s does not exist in organization %s",
-						printer.BoldBlue(database), printer.BoldBlue(branch), printer.BoldBlue(ch.Config.Organization))
-				default:
-					return cmdutil.HandleError(err)
-				}
+			if cmd.Flags().Changed("execute-protocol") {
+				opts.protocol = flags.execCommandProtocol
 			}
 
-			if !dbBranch.Ready {
-				return errors.New("database branch is not ready yet")
-			}
-			pw, err := passwordutil.New(ctx, client, passwordutil.Options{
-				Organization: ch.Config.Organization,
-				Database:     database,
-				Branch:       branch,
-				Role:         role,
-				Name:         passwordutil.GenerateName("pscale-cli-connect"),
-				TTL:          5 * time.Minute,
-				Replica:      replica,
-			})
+			errCh := make(chan error, 3)
+			p, err := startProxy(ctx, ch, client, opts, errCh)
 			if err != nil {
-				return cmdutil.HandleError(err)
+				return err
 			}
-			defer func() {
-				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-				defer cancel()
+			defer p.Close()
 
-				if err := pw.Cleanup(ctx); err != nil {
-					ch.Printer.Println("failed to delete credentials: ", err)
+			if flags.stateFile != "" {
+				remove, err := writeState(flags.stateFile, []*connectionState{p.state()})
+				if err != nil {
+					return err
 				}
-			}()
-
-			remoteAddr := flags.remoteAddr
-			if remoteAddr == "" {
-				remoteAddr = pw.Password.Hostname
-			}
-
-			proxy := proxyutil.New(proxyutil.Config{
-				Logger:       cmdutil.NewZapLogger(ch.Debug()),
-				UpstreamAddr: remoteAddr,
-				Username:     pw.Password.Username,
-				Password:     pw.Password.PlainText,
-			})
-			defer proxy.Close()
-
-			l, err := listenProxy(ch, flags.host, flags.port, !flags.noRandom)
-			if err != nil {
-				return cmdutil.HandleError(err)
+				defer remove()
 			}
-			defer l.Close()
-
-			errCh := make(chan error, 1)
-			go func() {
-				errCh <- proxy.Serve(l, authMethod)
-			}()
-
-			go func() {
-				errCh <- pw.Renew(ctx)
-			}()
-
-			localAddr := l.Addr().String()
 
 			ch.Printer.Printf("Secure connection to database %s and branch %s is established!.\n\nLocal address to connect your application: %s (press ctrl-c to quit)\n",
 				printer.BoldBlue(database),
 				printer.BoldBlue(branch),
-				printer.BoldBlue(localAddr),
+				printer.BoldBlue(p.localAddr),
 			)
 
 			if flags.execCommand != "" {
+				env := []string{
+					fmt.Sprintf("%s=%s", flags.execCommandEnvURL, p.url),
+					fmt.Sprintf("PLANETSCALE_DATABASE_HOST=%s", p.localAddr),
+					fmt.Sprintf("PLANETSCALE_DATABASE_NAME=%s", database),
+					fmt.Sprintf("PLANETSCALE_BRANCH_NAME=%s", branch),
+				}
 				go func() {
-					errCh <- runCommand(
-						ctx,
-						localAddr,
-						flags.execCommand,
-						flags.execCommandProtocol,
-						flags.execCommandEnvURL,
-						database,
-						branch,
-					)
+					errCh <- runCommand(ctx, flags.execCommand, env)
 
 					// TODO(fatih): is it worth to making cancellation configurable?
 					cancel() // stop the proxy by cancelling all other child contexts
@@ -218,25 +293,409 @@ argument:
 
 	cmd.PersistentFlags().StringVar(&ch.Config.Organization, "org", ch.Config.Organization, "The organization for the current user")
 	cmd.PersistentFlags().StringVar(&flags.host, "host", "127.0.0.1", "Local host to bind and listen for connections")
-	cmd.PersistentFlags().StringVar(&flags.port, "port", "3306", "Local port to bind and listen for connections")
+	cmd.PersistentFlags().StringVar(&flags.port, "port", "3306", "Local port to bind and listen for connections. Defaults to 5432 for Postgres databases.")
 	cmd.PersistentFlags().BoolVar(&flags.noRandom, "no-random", false, "Do not pick a random port if the default port is in use")
 	cmd.PersistentFlags().StringVar(&flags.remoteAddr, "remote-addr", "",
 		"PlanetScale Database remote network address. By default the remote address is populated automatically from the PlanetScale API.")
 	cmd.MarkPersistentFlagRequired("org") // nolint:errcheck
 	cmd.PersistentFlags().StringVar(&flags.execCommand, "execute", "", "Run this command after successfully connecting to the database.")
 	cmd.PersistentFlags().StringVar(&flags.execCommandProtocol, "execute-protocol",
-		"mysql2", "Protocol for the exposed URL (by default DATABASE_URL) value in execute")
+		"mysql2", "Protocol for the exposed URL (by default DATABASE_URL) value in execute. Defaults to 'postgresql' for Postgres databases.")
 	cmd.PersistentFlags().StringVar(&flags.execCommandEnvURL, "execute-env-url", "DATABASE_URL",
 		"Environment variable name that contains the exposed Database URL.")
 	cmd.PersistentFlags().StringVar(&flags.role, "role",
 		"", "Role defines the access level, allowed values are: reader, writer, readwriter, admin. Defaults to 'reader' for replica passwords, otherwise defaults to 'admin'.")
 	cmd.Flags().BoolVar(&flags.replica, "replica", false, "When enabled, the password will route all reads to the branch's primary replicas and all read-only regions.")
+	cmd.Flags().BoolVar(&flags.split, "split", false, "Send autocommit SELECT statements outside transactions to replicas and everything else to the primary. Vitess databases only.")
 	cmd.PersistentFlags().StringVar(&flags.authMethod, "mysql-auth-method",
 		"", "MySQL auth method defines the authentication method returned for the MySQL protocol. Allowed values are: caching_sha2_password, mysql_native_password. Defaults to 'caching_sha2_password'.")
+	cmd.PersistentFlags().StringVar(&flags.postgresDB, "dbname", "postgres", "Postgres database clients connect to when they don't name one, also used in the --execute URL")
+	cmd.Flags().StringVar(&flags.connectFile, "connect-file", "", "YAML file listing several database branches to connect to at once")
+	cmd.Flags().StringVar(&flags.queryLog, "query-log", "", "Append every statement passing through the proxy to this file as newline-delimited JSON")
+	cmd.Flags().BoolVar(&flags.queryLogRedact, "query-log-redact", false, "Replace string and numeric literals with ? in the query log")
+	cmd.Flags().StringVar(&flags.socket, "socket", "", "Listen on a unix socket at this path, readable only by the current user, instead of a TCP port. For Postgres databases this names the directory the socket is created in.")
+	cmd.Flags().StringVar(&flags.stateFile, "state-file", "", "Write the addresses and URLs of the running proxies to this file as JSON, and remove it on exit")
+	cmd.Flags().StringVar(&flags.metricsAddr, "metrics-addr", "", "Serve Prometheus metrics at /metrics and a health check at /healthz on this address, e.g. 127.0.0.1:9090")
+
+	cmd.AddCommand(LogCmd(ch))
 
 	return cmd
 }
 
+// connectionFlags are set for each connection in a --connect-file instead.
+var connectionFlags = []string{
+	"port", "role", "replica", "split", "socket", "remote-addr", "mysql-auth-method",
+	"dbname", "execute-protocol", "execute-env-url",
+}
+
+// proxyOptions describe one proxied database branch. Empty port and protocol
+// pick the defaults for the database kind.
+type proxyOptions struct {
+	database   string
+	branch     string
+	role       string
+	replica    bool
+	split      bool
+	socket     string
+	host       string
+	port       string
+	noRandom   bool
+	remoteAddr string
+	authMethod string
+	postgresDB string
+	protocol   string
+	// queryLog, if set, records the statements sent through the proxy.
+	queryLog *querylog.Logger
+	// metrics, if set, collects the proxy's metrics.
+	metrics *proxyutil.Metrics
+}
+
+// runningProxy is a local listener forwarding to a database branch.
+type runningProxy struct {
+	database string
+	branch   string
+	kind     string
+	// network is tcp, or unix for --socket.
+	network   string
+	localAddr string
+	// url is the connection URL exposed to --execute.
+	url     string
+	cleanup []func()
+}
+
+// Close stops the proxy and deletes its credentials.
+func (p *runningProxy) Close() {
+	for i := len(p.cleanup) - 1; i >= 0; i-- {
+		p.cleanup[i]()
+	}
+	p.cleanup = nil
+}
+
+// startProxy creates a credential for the branch in opts and starts serving
+// it on a local listener. Serving and renewal errors are sent to errCh. The
+// returned proxy must be closed.
+func startProxy(ctx context.Context, ch *cmdutil.Helper, client *planetscale.Client, opts *proxyOptions, errCh chan<- error) (_ *runningProxy, err error) {
+	database, branch := opts.database, opts.branch
+
+	if opts.split && opts.replica {
+		return nil, errors.New("--split already routes reads to replicas and can't be combined with --replica")
+	}
+
+	role, err := cmdutil.ResolveAccessRole(opts.role, opts.replica, cmdutil.AdministratorRole)
+	if err != nil {
+		return nil, err
+	}
+
+	authMethod := mysql.CachingSha2Password
+	if opts.authMethod != "" {
+		switch opts.authMethod {
+		case "caching_sha2_password":
+			authMethod = mysql.CachingSha2Password
+		case "mysql_native_password":
+			authMethod = mysql.MysqlNativePassword
+		default:
+			return nil, fmt.Errorf("unsupported auth method: %s", opts.authMethod)
+		}
+	}
+
+	// check whether database and branch exist
+	dbBranch, err := client.DatabaseBranches.Get(ctx, &planetscale.GetDatabaseBranchRequest{
+		Organization: ch.Config.Organization,
+		Database:     database,
+		Branch:       branch,
+	})
+	if err != nil {
+		switch cmdutil.ErrCode(err) {
+		case planetscale.ErrNotFound:
+			return nil, fmt.Errorf("database %s and branch %s does not exist in organization %s",
+				printer.BoldBlue(database), printer.BoldBlue(branch), printer.BoldBlue(ch.Config.Organization))
+		default:
+			return nil, cmdutil.HandleError(err)
+		}
+	}
+
+	if !dbBranch.Ready {
+		return nil, errors.New("database branch is not ready yet")
+	}
+
+	dbInfo, err := client.Databases.Get(ctx, &planetscale.GetDatabaseRequest{
+		Organization: ch.Config.Organization,
+		Database:     database,
+	})
+	if err != nil {
+		return nil, cmdutil.HandleError(err)
+	}
+
+	var pm *proxyutil.ProxyMetrics
+	if opts.metrics != nil {
+		pm = opts.metrics.Proxy(database, branch)
+	}
+
+	p := &runningProxy{database: database, branch: branch, kind: string(dbInfo.Kind), network: "tcp"}
+	defer func() {
+		if err != nil {
+			p.Close()
+		}
+	}()
+
+	port := opts.port
+	protocol := opts.protocol
+	urlDatabase := database
+	engine := "mysql"
+	// probeAddr is the upstream address checked for /healthz.
+	var probeAddr string
+	var serve func(net.Listener) error
+	var renew func(context.Context) error
+
+	switch dbInfo.Kind {
+	case "postgresql", "horizon":
+		if opts.authMethod != "" {
+			return nil, errors.New("--mysql-auth-method is not supported for Postgres databases")
+		}
+		if opts.split {
+			return nil, errors.New("--split is only supported for Vitess databases")
+		}
+
+		roles, err := newPostgresRoles(ctx, client, ch.Config.Organization, database, branch, role, opts.replica, opts.remoteAddr)
+		if err != nil {
+			return nil, cmdutil.HandleError(err)
+		}
+		if pm != nil {
+			roles.onRenew = pm.Renewed
+		}
+		p.cleanup = append(p.cleanup, func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := roles.Cleanup(ctx); err != nil {
+				ch.Printer.Println("failed to delete role: ", err)
+			}
+		})
+
+		pgConfig := proxyutil.PostgresConfig{
+			Logger:      cmdutil.NewZapLogger(ch.Debug()),
+			Credentials: roles.Credentials,
+			Database:    opts.postgresDB,
+		}
+		if pm != nil {
+			pgConfig.ConnectFailed = func(error) { pm.ConnectionFailed() }
+		}
+		proxy := proxyutil.NewPostgres(pgConfig)
+		p.cleanup = append(p.cleanup, func() { proxy.Close() })
+
+		engine = "postgresql"
+		probeAddr = withDefaultPort(roles.Credentials().Addr, "5432")
+		serve = proxy.Serve
+		renew = roles.Renew
+		if port == "" {
+			port = "5432"
+		}
+		if protocol == "" {
+			protocol = "postgresql"
+		}
+		urlDatabase = opts.postgresDB
+	default:
+		up, err := newMySQLUpstream(ctx, ch, client, p, opts, role, opts.replica, pm)
+		if err != nil {
+			return nil, err
+		}
+		probeAddr = withDefaultPort(up.remoteAddr, "443")
+		serve = func(l net.Listener) error { return up.proxy.Serve(l, authMethod) }
+		renew = up.password.Renew
+
+		if opts.split {
+			split, err := startSplit(ctx, ch, client, p, opts, up, authMethod, pm, errCh)
+			if err != nil {
+				return nil, err
+			}
+			serve = split.Serve
+			renew = func(ctx context.Context) error {
+				errc := make(chan error, 2)
+				go func() { errc <- up.password.Renew(ctx) }()
+				go func() { errc <- split.replica.password.Renew(ctx) }()
+				return <-errc
+			}
+		}
+		if port == "" {
+			port = "3306"
+		}
+		if protocol == "" {
+			protocol = "mysql2"
+		}
+	}
+
+	var l net.Listener
+	if opts.socket != "" {
+		path := opts.socket
+		if engine == "postgresql" {
+			if path, err = postgresSocketPath(path, port); err != nil {
+				return nil, err
+			}
+		}
+		p.network = "unix"
+		l, err = listenSocket(path)
+	} else {
+		l, err = listenProxy(ch, opts.host, port, !opts.noRandom)
+	}
+	if err != nil {
+		return nil, cmdutil.HandleError(err)
+	}
+	p.cleanup = append(p.cleanup, func() { l.Close() })
+
+	var served net.Listener = l
+	if pm != nil {
+		served = pm.Listener(served)
+		go pm.Probe(ctx, probeAddr, metricsProbeInterval)
+	}
+	if opts.queryLog != nil {
+		served = querylog.Listener(served, engine, opts.queryLog, querylog.Target{Database: database, Branch: branch})
+	}
+
+	go func() {
+		errCh <- serve(served)
+	}()
+
+	go func() {
+		errCh <- renew(ctx)
+	}()
+
+	p.localAddr = l.Addr().String()
+	if p.network == "unix" {
+		p.url = socketURL(protocol, p.localAddr, urlDatabase)
+	} else {
+		p.url = databaseURL(protocol, p.localAddr, urlDatabase)
+	}
+	return p, nil
+}
+
+// mysqlUpstream is a psdbproxy server logged in with a branch password.
+type mysqlUpstream struct {
+	password   *passwordutil.Password
+	proxy      *psdbproxy.Server
+	remoteAddr string
+}
+
+// newMySQLUpstream creates a password for the branch in opts and a proxy
+// server using it. Both are released by closing p.
+func newMySQLUpstream(ctx context.Context, ch *cmdutil.Helper, client *planetscale.Client, p *runningProxy, opts *proxyOptions, role cmdutil.PasswordRole, replica bool, pm *proxyutil.ProxyMetrics) (*mysqlUpstream, error) {
+	pw, err := passwordutil.New(ctx, client, passwordutil.Options{
+		Organization: ch.Config.Organization,
+		Database:     opts.database,
+		Branch:       opts.branch,
+		Role:         role,
+		Name:         passwordutil.GenerateName("pscale-cli-connect"),
+		TTL:          5 * time.Minute,
+		Replica:      replica,
+	})
+	if err != nil {
+		return nil, cmdutil.HandleError(err)
+	}
+	if pm != nil {
+		pw.OnRenew = pm.Renewed
+	}
+	p.cleanup = append(p.cleanup, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := pw.Cleanup(ctx); err != nil {
+			ch.Printer.Println("failed to delete credentials: ", err)
+		}
+	})
+
+	remoteAddr := opts.remoteAddr
+	if remoteAddr == "" {
+		remoteAddr = pw.Password.Hostname
+	}
+
+	proxy := proxyutil.New(proxyutil.Config{
+		Logger:       cmdutil.NewZapLogger(ch.Debug()),
+		UpstreamAddr: remoteAddr,
+		Username:     pw.Password.Username,
+		Password:     pw.Password.PlainText,
+	})
+	p.cleanup = append(p.cleanup, func() { proxy.Close() })
+
+	return &mysqlUpstream{password: pw, proxy: proxy, remoteAddr: remoteAddr}, nil
+}
+
+// splitProxy routes the statements of local clients between a primary and a
+// replica upstream.
+type splitProxy struct {
+	*proxyutil.SplitProxy
+	replica *mysqlUpstream
+}
+
+// startSplit adds a replica password to the primary upstream and serves both
+// on loopback listeners for the read/write splitting proxy.
+func startSplit(ctx context.Context, ch *cmdutil.Helper, client *planetscale.Client, p *runningProxy, opts *proxyOptions, primary *mysqlUpstream, authMethod mysql.AuthMethodDescription, pm *proxyutil.ProxyMetrics, errCh chan<- error) (*splitProxy, error) {
+	replica, err := newMySQLUpstream(ctx, ch, client, p, opts, cmdutil.ReaderRole, true, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, up := range []*mysqlUpstream{primary, replica} {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		p.cleanup = append(p.cleanup, func() { l.Close() })
+		addrs = append(addrs, l.Addr().String())
+
+		go func() {
+			errCh <- up.proxy.Serve(l, authMethod)
+		}()
+	}
+
+	splitConfig := proxyutil.SplitConfig{
+		Logger:  cmdutil.NewZapLogger(ch.Debug()),
+		Primary: addrs[0],
+		Replica: addrs[1],
+	}
+	if pm != nil {
+		splitConfig.Routed = pm.Routed
+		splitConfig.ConnectFailed = func(error) { pm.ConnectionFailed() }
+	}
+	split := proxyutil.NewSplit(splitConfig)
+	p.cleanup = append(p.cleanup, func() {
+		split.Close()
+		primary, replica := split.Stats()
+		ch.Printer.Printf("Read/write split: %d statements sent to the primary, %d to replicas\n", primary, replica)
+	})
+
+	return &splitProxy{SplitProxy: split, replica: replica}, nil
+}
+
+// metricsProbeInterval is how often the upstream address is dialed to
+// answer /healthz.
+const metricsProbeInterval = 15 * time.Second
+
+// serveMetrics serves the metrics of the proxies on addr until the returned
+// function is called.
+func serveMetrics(ch *cmdutil.Helper, addr string, metrics *proxyutil.Metrics) (func(), error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening for metrics: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:           metrics.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go srv.Serve(l) // nolint:errcheck
+
+	ch.Printer.Printf("Serving metrics at %s\n", printer.BoldBlue(fmt.Sprintf("http://%s/metrics", l.Addr())))
+	return func() { srv.Close() }, nil
+}
+
+// withDefaultPort adds port to addr unless it has one.
+func withDefaultPort(addr, port string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	return net.JoinHostPort(addr, port)
+}
+
 func listenProxy(ch *cmdutil.Helper, host, port string, random bool) (net.Listener, error) {
 	addr := net.JoinHostPort(host, port)
 	l, err := net.Listen("tcp", addr)
@@ -250,9 +709,21 @@ func listenProxy(ch *cmdutil.Helper, host, port string, random bool) (net.Listen
 	return nil, err
 }
 
-// runCommand runs the given command with several environment variables exposed
-// to the command.
-func runCommand(ctx context.Context, addr, command, protocol, databaseEnvURL, database, branch string) error {
+// databaseURL is the URL of the local proxy exposed to --execute. The proxy
+// authenticates upstream, so the user in the URL is only a placeholder. The
+// Postgres proxy is plaintext, so its URL turns TLS off.
+func databaseURL(protocol, addr, database string) string {
+	switch protocol {
+	case "postgres", "postgresql":
+		return fmt.Sprintf("%s://postgres@%s/%s?sslmode=disable", protocol, addr, database)
+	default:
+		return fmt.Sprintf("%s://root@%s/%s", protocol, addr, database)
+	}
+}
+
+// runCommand runs the given command with the connection details added to its
+// environment.
+func runCommand(ctx context.Context, command string, env []string) error {
 	args, err := shellwords.Parse(command)
 	if err != nil {
 		return fmt.Errorf("failed to parse command, not running: %s", err)
@@ -262,23 +733,11 @@ func runCommand(ctx context.Context, addr, command, protocol, databaseEnvURL, da
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
-	cmd.Env = os.Environ()
+	cmd.Env = append(os.Environ(), env...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	connStr := fmt.Sprintf("%s=%s://root@%s/%s", databaseEnvURL, protocol, addr, database)
-	cmd.Env = append(cmd.Env, connStr)
-
-	hostEnv := fmt.Sprintf("PLANETSCALE_DATABASE_HOST=%s", addr)
-	cmd.Env = append(cmd.Env, hostEnv)
-
-	dbName := fmt.Sprintf("PLANETSCALE_DATABASE_NAME=%s", database)
-	cmd.Env = append(cmd.Env, dbName)
-
-	branchName := fmt.Sprintf("PLANETSCALE_BRANCH_NAME=%s", branch)
-	cmd.Env = append(cmd.Env, branchName)
-
 	err = cmd.Run()
 	if err == nil {
 		return nil
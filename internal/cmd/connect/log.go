@@ -0,0 +1,100 @@
+package connect
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/querylog"
+
+	"github.com/spf13/cobra"
+)
+
+// LogCmd works with the query logs written by pscale connect --query-log.
+func LogCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log <command>",
+		Short: "Inspect query logs written by connect --query-log",
+		Long: `Inspect query logs written by pscale connect --query-log.
+
+A database named log is reached with pscale connect -- log.`,
+		// Logs are local files, so no authentication is needed.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+
+	// Shadow connect's required --org, which a local file doesn't need.
+	cmd.PersistentFlags().StringVar(&ch.Config.Organization, "org", ch.Config.Organization,
+		"The organization for the current user")
+	cmd.PersistentFlags().MarkHidden("org") // nolint:errcheck
+
+	cmd.AddCommand(LogSummarizeCmd(ch))
+	return cmd
+}
+
+// LogSummarizeCmd groups the statements of a query log by fingerprint.
+func LogSummarizeCmd(ch *cmdutil.Helper) *cobra.Command {
+	var flags struct {
+		sort  string
+		limit int
+	}
+
+	cmd := &cobra.Command{
+		Use:   "summarize <file>",
+		Short: "Group the statements of a query log by normalized fingerprint",
+		Long: `Group the statements of a query log by normalized fingerprint.
+
+Statements that only differ in their literals, comments, whitespace, letter
+case or the length of IN and VALUES lists share a fingerprint. For every
+fingerprint the count, errors, total, mean and maximum duration and the rows
+returned or affected are shown.`,
+		Example: `  pscale connect log summarize queries.ndjson --sort count --limit 10`,
+		Args:    cmdutil.RequiredArgs("file"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			less, ok := summarySorts[flags.sort]
+			if !ok {
+				return fmt.Errorf("unsupported --sort value %q, allowed values are: total, mean, max, count, errors, rows", flags.sort)
+			}
+			if flags.limit < 0 {
+				return fmt.Errorf("--limit must not be negative")
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			summaries, err := querylog.Summarize(f)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", args[0], err)
+			}
+
+			sort.SliceStable(summaries, func(i, j int) bool {
+				return less(summaries[i], summaries[j])
+			})
+			if flags.limit > 0 && len(summaries) > flags.limit {
+				summaries = summaries[:flags.limit]
+			}
+
+			return ch.Printer.PrintResource(summaries)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.sort, "sort", "total", "Order fingerprints by total, mean, max, count, errors or rows, largest first")
+	cmd.Flags().IntVar(&flags.limit, "limit", 0, "Only show this many fingerprints. 0 shows all of them")
+	cmdutil.SetJSONOutput(cmd, []*querylog.Summary{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
+}
+
+var summarySorts = map[string]func(a, b *querylog.Summary) bool{
+	"total":  func(a, b *querylog.Summary) bool { return a.TotalMS > b.TotalMS },
+	"mean":   func(a, b *querylog.Summary) bool { return a.MeanMS > b.MeanMS },
+	"max":    func(a, b *querylog.Summary) bool { return a.MaxMS > b.MaxMS },
+	"count":  func(a, b *querylog.Summary) bool { return a.Count > b.Count },
+	"errors": func(a, b *querylog.Summary) bool { return a.Errors > b.Errors },
+	"rows":   func(a, b *querylog.Summary) bool { return a.Rows > b.Rows },
+}
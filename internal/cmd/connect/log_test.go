@@ -0,0 +1,35 @@
+package connect
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestLogSummarizeNeedsNoOrg(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(t.TempDir(), "queries.ndjson")
+	log := `{"time":"2026-10-19T00:00:00Z","conn":1,"statement":"SELECT * FROM users WHERE id = 1","duration_ms":2,"rows":1}
+{"time":"2026-10-19T00:00:01Z","conn":1,"statement":"select * from users where id = 2","duration_ms":4,"rows":1}
+`
+	c.Assert(os.WriteFile(path, []byte(log), 0o600), qt.IsNil)
+
+	format := printer.JSON
+	var buf bytes.Buffer
+	p := printer.NewPrinter(&format)
+	p.SetResourceOutput(&buf)
+	ch := &cmdutil.Helper{Printer: p, Config: &config.Config{}}
+
+	cmd := ConnectCmd(ch)
+	cmd.SetArgs([]string{"log", "summarize", path})
+	c.Assert(cmd.Execute(), qt.IsNil)
+	c.Assert(buf.String(), qt.Contains, `"count": 2`)
+}
@@ -0,0 +1,158 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/passwordutil"
+	ps "github.com/planetscale/cli/internal/planetscale"
+	"github.com/planetscale/cli/internal/proxyutil"
+	"github.com/planetscale/cli/internal/roleutil"
+)
+
+// postgresRoleTTL is the lifetime of the roles created for PostgreSQL
+// branches. Roles can't be renewed, so a fresh role takes over new
+// connections at half the TTL.
+const postgresRoleTTL = time.Hour
+
+// postgresRoles hands out the current ephemeral role for new proxy
+// connections and replaces it before it expires. Replaced roles are kept
+// until their TTL has passed, so connections opened with them aren't cut
+// off, and then forgotten: the API has removed them by then.
+type postgresRoles struct {
+	client     *ps.Client
+	opts       roleutil.Options
+	successor  string
+	replica    bool
+	remoteAddr string
+	// onRenew, if set, is called with the outcome of every renewal attempt.
+	onRenew func(error)
+
+	mu      sync.Mutex
+	current *roleutil.Role
+	created time.Time
+	roles   []*issuedRole
+}
+
+// issuedRole is a role created for the connection and when it expires.
+type issuedRole struct {
+	role    *roleutil.Role
+	expires time.Time
+}
+
+func newPostgresRoles(ctx context.Context, client *ps.Client, org, database, branch string, role cmdutil.PasswordRole, replica bool, remoteAddr string) (*postgresRoles, error) {
+	inheritedRoles, successor := cmdutil.PostgresInheritedRoles(role)
+	r := &postgresRoles{
+		client: client,
+		opts: roleutil.Options{
+			Organization:   org,
+			Database:       database,
+			Branch:         branch,
+			TTL:            postgresRoleTTL,
+			InheritedRoles: inheritedRoles,
+		},
+		successor:  successor,
+		replica:    replica,
+		remoteAddr: remoteAddr,
+	}
+	if err := r.rotate(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// rotate creates a new role and makes it the current one.
+func (r *postgresRoles) rotate(ctx context.Context) error {
+	opts := r.opts
+	opts.Name = passwordutil.GenerateName("pscale-cli-connect")
+	role, err := roleutil.New(ctx, r.client, opts)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = role
+	r.created = time.Now()
+	r.roles = append(r.live(r.created), &issuedRole{role: role, expires: r.created.Add(r.opts.TTL)})
+	return nil
+}
+
+// live returns the roles that haven't expired at now. r.mu must be held.
+func (r *postgresRoles) live(now time.Time) []*issuedRole {
+	return slices.DeleteFunc(r.roles, func(ir *issuedRole) bool {
+		return !now.Before(ir.expires)
+	})
+}
+
+// Credentials returns the upstream credentials for a new connection.
+func (r *postgresRoles) Credentials() proxyutil.PostgresCredentials {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	username := r.current.Role.Username
+	if r.replica {
+		username += "|replica"
+	}
+	addr := r.remoteAddr
+	if addr == "" {
+		addr = r.current.Role.AccessHostURL
+	}
+	return proxyutil.PostgresCredentials{
+		Addr:     addr,
+		Username: username,
+		Password: r.current.Role.Password,
+	}
+}
+
+// Renew replaces the current role at half its TTL until ctx is done. It
+// fails once the current role expired without a replacement.
+func (r *postgresRoles) Renew(ctx context.Context) error {
+	timer := time.NewTimer(postgresRoleTTL / 2)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		err := r.rotate(ctx)
+		if r.onRenew != nil {
+			r.onRenew(err)
+		}
+		if err != nil {
+			r.mu.Lock()
+			expired := time.Since(r.created) >= postgresRoleTTL
+			r.mu.Unlock()
+			if expired {
+				return fmt.Errorf("role failed to renew: %w", err)
+			}
+			// on failure to renew, retry a bit more aggressively
+			timer.Reset(postgresRoleTTL / 8)
+		} else {
+			timer.Reset(postgresRoleTTL / 2)
+		}
+	}
+}
+
+// Cleanup deletes the roles created for this connection that haven't
+// expired yet.
+func (r *postgresRoles) Cleanup(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for _, ir := range r.live(time.Now()) {
+		if err := ir.role.Cleanup(ctx, r.successor); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	r.roles = nil
+	return firstErr
+}
@@ -0,0 +1,46 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/mock"
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+func TestPostgresRolesForgetExpiredRoles(t *testing.T) {
+	c := qt.New(t)
+
+	created := 0
+	var deleted []string
+	svc := &mock.PostgresRolesService{
+		CreateFn: func(ctx context.Context, req *ps.CreatePostgresRoleRequest) (*ps.PostgresRole, error) {
+			created++
+			return &ps.PostgresRole{ID: fmt.Sprintf("role%d", created), Username: req.Name}, nil
+		},
+		DeleteFn: func(ctx context.Context, req *ps.DeletePostgresRoleRequest) error {
+			deleted = append(deleted, req.RoleId)
+			return nil
+		},
+	}
+
+	ctx := context.Background()
+	r, err := newPostgresRoles(ctx, &ps.Client{PostgresRoles: svc}, "acme", "db", "main", cmdutil.ReaderRole, false, "")
+	c.Assert(err, qt.IsNil)
+	c.Assert(r.rotate(ctx), qt.IsNil)
+	c.Assert(r.roles, qt.HasLen, 2)
+
+	// The first role's TTL has passed by the next rotation.
+	r.roles[0].expires = time.Now().Add(-time.Second)
+	c.Assert(r.rotate(ctx), qt.IsNil)
+	c.Assert(r.roles, qt.HasLen, 2)
+
+	c.Assert(r.Cleanup(ctx), qt.IsNil)
+	c.Assert(deleted, qt.DeepEquals, []string{"role2", "role3"})
+	c.Assert(r.roles, qt.HasLen, 0)
+}
@@ -0,0 +1,57 @@
+//go:build !windows
+
+package connect
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// listenUnix listens on a unix socket at path with mode 0600. The socket is
+// bound and restricted inside a private directory next to path, and only then
+// renamed to path, so other users can never connect to it.
+func listenUnix(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".pscale")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// The socket is removed by socketListener.Close under its final name.
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmp, 0o600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return &socketListener{Listener: l, path: path}, nil
+}
+
+// socketListener is a unix socket listener that was bound under another
+// name and moved to path.
+type socketListener struct {
+	net.Listener
+	path string
+}
+
+func (l *socketListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: l.path, Net: "unix"}
+}
+
+func (l *socketListener) Close() error {
+	err := l.Listener.Close()
+	if rerr := os.Remove(l.path); rerr != nil && !os.IsNotExist(rerr) && err == nil {
+		err = rerr
+	}
+	return err
+}
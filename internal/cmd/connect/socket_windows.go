@@ -0,0 +1,11 @@
+//go:build windows
+
+package connect
+
+import "net"
+
+// listenUnix listens on a unix socket at path. Windows ignores file modes,
+// access to the socket follows the ACL of its directory.
+func listenUnix(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
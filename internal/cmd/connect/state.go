@@ -0,0 +1,133 @@
+package connect
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// postgresSocketPrefix starts the file name of a Postgres unix socket.
+// Postgres clients take the socket directory as host and append the prefix
+// and the port.
+const postgresSocketPrefix = ".s.PGSQL."
+
+// listenSocket listens on a unix socket at path that only the current user
+// may connect to. A stale socket left behind by an earlier run is replaced.
+func listenSocket(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket %s is already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return listenUnix(path)
+}
+
+// postgresSocketPath returns the socket a Postgres proxy listens on when
+// --socket names dir.
+func postgresSocketPath(dir, port string) (string, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return "", fmt.Errorf("--socket must name a directory for Postgres databases, the socket is created in it as %s<port>", postgresSocketPrefix)
+	}
+	return filepath.Join(dir, postgresSocketPrefix+port), nil
+}
+
+// socketURL is the URL of a local proxy listening on a unix socket, see
+// databaseURL.
+func socketURL(protocol, socket, database string) string {
+	switch protocol {
+	case "postgres", "postgresql":
+		port := strings.TrimPrefix(filepath.Base(socket), postgresSocketPrefix)
+		return fmt.Sprintf("%s://postgres@/%s?host=%s&port=%s&sslmode=disable",
+			protocol, database, url.QueryEscape(filepath.Dir(socket)), port)
+	default:
+		return fmt.Sprintf("%s://root@localhost/%s?socket=%s", protocol, database, url.QueryEscape(socket))
+	}
+}
+
+// connectState is written to --state-file so tooling can find the addresses
+// connect picked.
+type connectState struct {
+	PID         int                `json:"pid"`
+	Connections []*connectionState `json:"connections"`
+}
+
+// connectionState describes one running proxy.
+type connectionState struct {
+	Name     string `json:"name,omitempty"`
+	Database string `json:"database"`
+	Branch   string `json:"branch"`
+	Kind     string `json:"kind"`
+	// Network is tcp or unix.
+	Network string `json:"network"`
+	Address string `json:"address"`
+	Host    string `json:"host,omitempty"`
+	Port    int    `json:"port,omitempty"`
+	Socket  string `json:"socket,omitempty"`
+	URL     string `json:"url"`
+	Env     string `json:"env,omitempty"`
+}
+
+// state describes p for the state file.
+func (p *runningProxy) state() *connectionState {
+	s := &connectionState{
+		Database: p.database,
+		Branch:   p.branch,
+		Kind:     p.kind,
+		Network:  p.network,
+		Address:  p.localAddr,
+		URL:      p.url,
+	}
+	if p.network == "unix" {
+		s.Socket = p.localAddr
+		return s
+	}
+	if host, port, err := net.SplitHostPort(p.localAddr); err == nil {
+		s.Host = host
+		s.Port, _ = strconv.Atoi(port)
+	}
+	return s
+}
+
+// writeState writes the state file atomically and returns a function that
+// removes it.
+func writeState(path string, connections []*connectionState) (func(), error) {
+	data, err := json.MarshalIndent(&connectState{PID: os.Getpid(), Connections: connections}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("writing state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("writing state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("writing state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("writing state file: %w", err)
+	}
+	return func() { os.Remove(path) }, nil
+}
@@ -0,0 +1,106 @@
+package connect
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestListenSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket permissions are not supported on Windows")
+	}
+	c := qt.New(t)
+
+	// Socket paths are limited to about 100 bytes, which t.TempDir may exceed.
+	dir, err := os.MkdirTemp("", "pscale")
+	c.Assert(err, qt.IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "mysql.sock")
+
+	l, err := listenSocket(path)
+	c.Assert(err, qt.IsNil)
+	fi, err := os.Stat(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(fi.Mode().Perm(), qt.Equals, os.FileMode(0o600))
+
+	_, err = listenSocket(path)
+	c.Assert(err, qt.ErrorMatches, "socket .* is already in use")
+
+	c.Assert(l.Addr().String(), qt.Equals, path)
+	l.Close()
+	_, err = os.Stat(path)
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+
+	// A socket left behind by a crashed process is replaced.
+	stale, err := net.Listen("unix", path)
+	c.Assert(err, qt.IsNil)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	l, err = listenSocket(path)
+	c.Assert(err, qt.IsNil)
+	l.Close()
+
+	// Neither the socket nor its private directory are left behind.
+	entries, err := os.ReadDir(dir)
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 0)
+
+	regular := filepath.Join(dir, "file")
+	c.Assert(os.WriteFile(regular, nil, 0o600), qt.IsNil)
+	_, err = listenSocket(regular)
+	c.Assert(err, qt.ErrorMatches, ".* exists and is not a socket")
+}
+
+func TestPostgresSocketPath(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	path, err := postgresSocketPath(dir, "5432")
+	c.Assert(err, qt.IsNil)
+	c.Assert(path, qt.Equals, filepath.Join(dir, ".s.PGSQL.5432"))
+
+	_, err = postgresSocketPath(path, "5432")
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestSocketURL(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(socketURL("mysql2", "/tmp/app/mysql.sock", "app"), qt.Equals, "mysql2://root@localhost/app?socket=%2Ftmp%2Fapp%2Fmysql.sock")
+	c.Assert(socketURL("postgresql", "/tmp/app/.s.PGSQL.6543", "postgres"), qt.Equals, "postgresql://postgres@/postgres?host=%2Ftmp%2Fapp&port=6543&sslmode=disable")
+}
+
+func TestWriteState(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(t.TempDir(), "connect.json")
+	tcp := &runningProxy{database: "app", branch: "main", kind: "mysql", network: "tcp", localAddr: "127.0.0.1:3306", url: "mysql2://root@127.0.0.1:3306/app"}
+	unix := &runningProxy{database: "pg", branch: "dev", kind: "postgresql", network: "unix", localAddr: "/tmp/.s.PGSQL.5432", url: "postgresql://postgres@/postgres?host=%2Ftmp&port=5432&sslmode=disable"}
+
+	remove, err := writeState(path, []*connectionState{tcp.state(), unix.state()})
+	c.Assert(err, qt.IsNil)
+
+	data, err := os.ReadFile(path)
+	c.Assert(err, qt.IsNil)
+	var state connectState
+	c.Assert(json.Unmarshal(data, &state), qt.IsNil)
+	c.Assert(state.PID, qt.Equals, os.Getpid())
+	c.Assert(state.Connections, qt.DeepEquals, []*connectionState{
+		{Database: "app", Branch: "main", Kind: "mysql", Network: "tcp", Address: "127.0.0.1:3306", Host: "127.0.0.1", Port: 3306, URL: tcp.url},
+		{Database: "pg", Branch: "dev", Kind: "postgresql", Network: "unix", Address: "/tmp/.s.PGSQL.5432", Socket: "/tmp/.s.PGSQL.5432", URL: unix.url},
+	})
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 1)
+
+	remove()
+	_, err = os.Stat(path)
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+}
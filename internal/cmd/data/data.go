@@ -0,0 +1,25 @@
+package data
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+)
+
+// DataCmd moves data between database branches and local files.
+func DataCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "data <command>",
+		Short:             "Export query results to files and load files into tables",
+		PersistentPreRunE: cmdutil.CheckAuthentication(ch.Config),
+	}
+
+	cmd.PersistentFlags().StringVar(&ch.Config.Organization, "org", ch.Config.Organization,
+		"The organization for the current user")
+	cmd.MarkPersistentFlagRequired("org") // nolint:errcheck
+
+	cmd.AddCommand(ExportCmd(ch))
+	cmd.AddCommand(ImportCmd(ch))
+
+	return cmd
+}
@@ -0,0 +1,263 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+	"vitess.io/vitess/go/mysql"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/dumper"
+	"github.com/planetscale/cli/internal/printer"
+	"github.com/planetscale/cli/internal/proxyutil"
+	"github.com/planetscale/cli/internal/sqlquery"
+)
+
+// exportCredentialTTL outlives the five minute credential of pscale sql, so
+// exports of large tables don't lose their credential partway.
+const exportCredentialTTL = 12 * time.Hour
+
+type exportFlags struct {
+	query      string
+	output     string
+	fileType   string
+	table      string
+	chunkSize  int
+	keyspace   string
+	postgresDB string
+	role       string
+	replica    bool
+	overwrite  bool
+}
+
+// ExportResult summarizes a finished export.
+type ExportResult struct {
+	Rows      uint64   `header:"rows" json:"rows"`
+	Bytes     uint64   `header:"bytes" json:"bytes"`
+	Files     []string `header:"files" json:"files"`
+	ZeroDates uint64   `header:"zero_dates" json:"zero_dates,omitempty"`
+}
+
+// ExportCmd runs a query and streams its result set into local files.
+func ExportCmd(ch *cmdutil.Helper) *cobra.Command {
+	f := &exportFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "export <database> <branch>",
+		Short: "Export the result of a query to CSV, NDJSON, Parquet, or SQL files",
+		Long: `Export the result of a query to CSV, NDJSON, Parquet, or SQL files.
+
+The query is streamed from the branch (through an in-process proxy on MySQL,
+directly on PostgreSQL) and written with the same writers as pscale database
+dump, so the result never has to fit in memory. Parquet files keep the column
+types; SQL files contain INSERT statements into --table.
+
+The format is inferred from the extension of --output (.csv, .ndjson/.jsonl/.json,
+.parquet, .sql) unless --type is passed. Results larger than --chunk-size are
+split into numbered files next to --output (out.00001.parquet, out.00002.parquet, ...).
+
+Only read queries are allowed. Like pscale sql, the default role is reader and
+a cached pscale sql session is reused when one matches.`,
+		Args: cmdutil.RequiredArgs("database", "branch"),
+		Example: `  # Export a join to Parquet
+  pscale data export <database> <branch> --org <org> \
+    --query "SELECT o.id, o.total, c.email FROM orders o JOIN customers c ON c.id = o.customer_id" \
+    --output orders.parquet
+
+  # Export from a replica to newline-delimited JSON
+  pscale data export <database> <branch> --org <org> --replica --query "SELECT * FROM events" --output events.ndjson`,
+		RunE: func(cmd *cobra.Command, args []string) error { return export(ch, cmd, f, args) },
+	}
+
+	cmd.Flags().StringVar(&f.query, "query", "", "Read query whose result is exported")
+	cmd.Flags().StringVar(&f.output, "output", "", "File to write the result to")
+	cmd.Flags().StringVar(&f.fileType, "type", "",
+		"Output file type: csv, json (newline-delimited), parquet, or sql. Inferred from the --output extension by default.")
+	cmd.Flags().StringVar(&f.table, "table", "", "Table name used in the INSERT statements of --type sql")
+	cmd.Flags().IntVar(&f.chunkSize, "chunk-size", 128, "Start a new numbered file after this many MB of data")
+	cmd.Flags().StringVar(&f.keyspace, "keyspace", "", "Vitess keyspace, optionally with a shard and tablet type. Defaults to @primary, same as pscale sql.")
+	cmd.Flags().StringVar(&f.postgresDB, "dbname", "postgres", "PostgreSQL database name")
+	cmd.Flags().StringVar(&f.role, "role",
+		"", "Role defines the access level, allowed values are: reader, writer, readwriter, admin. Defaults to reader.")
+	cmd.Flags().BoolVar(&f.replica, "replica", false,
+		"When enabled, the query is routed to the branch's primary replicas and read-only regions.")
+	cmd.Flags().BoolVar(&f.overwrite, "overwrite", false, "Replace --output and its numbered chunks if they already exist")
+	cmd.MarkFlagRequired("query")  // nolint:errcheck
+	cmd.MarkFlagRequired("output") // nolint:errcheck
+	cmdutil.SetJSONOutput(cmd, &ExportResult{})
+
+	return cmd
+}
+
+func export(ch *cmdutil.Helper, cmd *cobra.Command, flags *exportFlags, args []string) error {
+	ctx := cmd.Context()
+	database, branch := args[0], args[1]
+
+	if !sqlquery.IsReadQuery(flags.query) {
+		return errors.New("--query must be a read query (SELECT, SHOW, EXPLAIN, ...)")
+	}
+	if flags.chunkSize <= 0 {
+		return errors.New("--chunk-size must be greater than 0")
+	}
+
+	format := flags.fileType
+	if format == "" {
+		var err error
+		if format, err = dumper.ExportFormat(flags.output); err != nil {
+			return err
+		}
+	}
+	if format == "sql" && flags.table == "" {
+		return errors.New("--table is required for sql exports")
+	}
+
+	existing, err := dumper.ExportFiles(flags.output)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && !flags.overwrite {
+		return fmt.Errorf("output file already exists: %s (pass --overwrite to replace it)", existing[0])
+	}
+
+	ep, err := sqlquery.OpenEndpoint(ctx, ch, sqlquery.Options{
+		Organization:  ch.Config.Organization,
+		Database:      database,
+		Branch:        branch,
+		Keyspace:      flags.keyspace,
+		PostgresDB:    flags.postgresDB,
+		Role:          flags.role,
+		Replica:       flags.replica,
+		CredentialTTL: exportCredentialTTL,
+	})
+	if err != nil {
+		return cmdutil.HandleError(err)
+	}
+	defer ep.Close()
+
+	cfg := &dumper.ExportConfig{
+		Path:          flags.output,
+		Format:        format,
+		Table:         flags.table,
+		ChunksizeInMB: flags.chunkSize,
+		StmtSize:      1000000,
+		// Chunks of an earlier export would be mistaken for part of this one.
+		Replace: existing,
+	}
+
+	progress := ch.Printer.StartProgress(fmt.Sprintf("Exporting from %s/%s...",
+		printer.BoldBlue(database), printer.BoldBlue(branch)))
+	defer progress.Stop()
+
+	onChunk := func(s *dumper.ExportStats) {
+		progress.Update(fmt.Sprintf("Exported %s rows (%s MB)...",
+			printer.Number(s.Rows), printer.Number(s.Bytes/1024/1024)))
+	}
+
+	start := time.Now()
+	var stats *dumper.ExportStats
+	if ep.Engine() == "mysql" {
+		stats, err = exportMySQL(ctx, ch, ep, cfg, flags.query, onChunk)
+	} else {
+		stats, err = exportPostgres(ctx, ep, cfg, flags.query, onChunk)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export query result: %w", err)
+	}
+	progress.Stop()
+
+	if stats.ZeroDates > 0 {
+		ch.Printer.Printf("Warning: %s zero dates (0000-00-00) were exported as NULL\n", printer.Number(stats.ZeroDates))
+	}
+	if ch.Printer.Format() == printer.Human {
+		ch.Printer.Printf("Exported %s rows to %s (elapsed time: %s)\n",
+			printer.Number(stats.Rows), printer.BoldBlue(describeFiles(stats.Files)), time.Since(start).Round(time.Millisecond))
+		return nil
+	}
+
+	return ch.Printer.PrintResource(&ExportResult{Rows: stats.Rows, Bytes: stats.Bytes, Files: stats.Files, ZeroDates: stats.ZeroDates})
+}
+
+// exportMySQL streams the query through an in-process proxy with the
+// dumper's connection, which keeps the MySQL column types of the result.
+func exportMySQL(ctx context.Context, ch *cmdutil.Helper, ep *sqlquery.Endpoint, cfg *dumper.ExportConfig, query string, progress func(*dumper.ExportStats)) (*dumper.ExportStats, error) {
+	logger := cmdutil.NewZapLogger(ch.Debug())
+
+	proxy := proxyutil.New(proxyutil.Config{
+		Logger:       logger,
+		UpstreamAddr: ep.Credential.Host,
+		Username:     ep.Credential.Username,
+		Password:     ep.Credential.Password,
+	})
+	defer proxy.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	go func() {
+		// The dumper's driver (go-mysqlstack) doesn't support
+		// caching_sha2_password, same as pscale database dump.
+		if err := proxy.Serve(l, mysql.MysqlNativePassword); err != nil {
+			ch.Printer.Println("proxy error: ", err)
+		}
+	}()
+
+	// NOTE: credentials are needed even though the proxy ignores them.
+	pool, err := dumper.NewPool(logger, 1, l.Addr().String(), "nobody", "nobody",
+		[]string{"set workload=olap;"}, ep.MySQLDatabase())
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Close()
+
+	conn := pool.Get()
+	defer pool.Put(conn)
+
+	rows, err := conn.StreamFetch(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return dumper.Export(ctx, cfg, rows, progress)
+}
+
+// exportPostgres streams the query with the simple protocol, so every value
+// arrives as text that the dumper's writers understand.
+func exportPostgres(ctx context.Context, ep *sqlquery.Endpoint, cfg *dumper.ExportConfig, query string, progress func(*dumper.ExportStats)) (*dumper.ExportStats, error) {
+	connCfg, err := pgx.ParseConfig(ep.PostgresConnString())
+	if err != nil {
+		return nil, err
+	}
+	connCfg.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	connCfg.RuntimeParams["TimeZone"] = "UTC"
+	connCfg.RuntimeParams["bytea_output"] = "hex"
+
+	conn, err := pgx.ConnectConfig(ctx, connCfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return dumper.Export(ctx, cfg, dumper.NewPostgresRows(rows), progress)
+}
+
+func describeFiles(files []string) string {
+	if len(files) == 1 {
+		return files[0]
+	}
+	return fmt.Sprintf("%d files (%s ... %s)", len(files), files[0], files[len(files)-1])
+}
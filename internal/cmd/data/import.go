@@ -0,0 +1,287 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/printer"
+	"github.com/planetscale/cli/internal/sqlquery"
+)
+
+// importCredentialTTL outlives the five minute credential of pscale sql, so
+// long imports can keep reconnecting.
+const importCredentialTTL = 2 * time.Hour
+
+type importFlags struct {
+	table      string
+	file       string
+	fileType   string
+	mappings   []string
+	null       string
+	upsert     bool
+	batchSize  int
+	maxStmt    int
+	rejects    string
+	keyspace   string
+	postgresDB string
+	role       string
+}
+
+// ImportResult summarizes a finished import.
+type ImportResult struct {
+	Table       string `header:"table" json:"table"`
+	Imported    uint64 `header:"imported" json:"imported"`
+	Rejected    uint64 `header:"rejected" json:"rejected"`
+	RejectsFile string `header:"rejects_file" json:"rejects_file,omitempty"`
+}
+
+// ImportCmd loads a CSV or NDJSON file into an existing table.
+func ImportCmd(ch *cmdutil.Helper) *cobra.Command {
+	f := &importFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "import <database> <branch>",
+		Short: "Load a CSV or NDJSON file into an existing table",
+		Long: `Load a CSV or NDJSON file into an existing table.
+
+CSV files must start with a header row. NDJSON files contain one JSON object
+per line; the keys of the first object are the columns, and later objects may
+leave keys out (NULL). File columns are matched to table columns by name,
+ignoring case. Rename a column with --map <file column>=<table column>, or
+skip it with --map <file column>=.
+
+Values are converted using the table's column types: integers, numbers,
+booleans (true/false, t/f, 1/0, yes/no), dates, timestamps (RFC 3339 or
+"YYYY-MM-DD HH:MM:SS"), and JSON are checked before they are sent, and empty
+CSV fields are NULL in columns that aren't text. Timestamps with an offset are
+stored in UTC unless the column keeps the time zone.
+
+Rows are loaded in batches of --batch-size rows. MySQL tables are loaded with
+multi-row INSERT statements whose values are sent as bound parameters, and no
+statement grows beyond --max-statement-size. PostgreSQL tables are loaded with
+COPY; with --upsert they are loaded with INSERT ... ON CONFLICT statements
+like MySQL tables instead, since COPY can't update existing rows. With
+--upsert, rows whose primary key already exists are updated instead of
+rejected.
+
+Rows that can't be converted or that the database refuses (duplicate keys,
+constraint violations, values out of range, ...) are written to a rejects file
+next to --file, with an _error column explaining why. Fix the file and load it
+again with --map _error=. The command exits with status 1 when rows were
+rejected.`,
+		Args: cmdutil.RequiredArgs("database", "branch"),
+		Example: `  # Load a CSV file into the customers table
+  pscale data import <database> <branch> --org <org> --table customers --file customers.csv
+
+  # Update existing rows by primary key and rename a column
+  pscale data import <database> <branch> --org <org> --table customers --file customers.ndjson \
+    --upsert --map "E-Mail=email"`,
+		RunE: func(cmd *cobra.Command, args []string) error { return importFile(ch, cmd, f, args) },
+	}
+
+	cmd.Flags().StringVar(&f.table, "table", "", "Table to load the rows into. PostgreSQL tables may be schema-qualified (schema.table).")
+	cmd.Flags().StringVar(&f.file, "file", "", "CSV or NDJSON file to load, or - for standard input")
+	cmd.Flags().StringVar(&f.fileType, "type", "", "Input file type: csv or json (newline-delimited). Inferred from the --file extension by default.")
+	cmd.Flags().StringArrayVar(&f.mappings, "map", nil,
+		"Map a file column to a table column (format: 'file_column=table_column'), or skip it with 'file_column='. Can be specified multiple times.")
+	cmd.Flags().StringVar(&f.null, "null", "", `CSV field value that stands for NULL in any column, e.g. "\N" or "NULL"`)
+	cmd.Flags().BoolVar(&f.upsert, "upsert", false, "Update rows whose primary key already exists instead of rejecting them")
+	cmd.Flags().IntVar(&f.batchSize, "batch-size", 500, "Maximum number of rows per INSERT statement or COPY")
+	cmd.Flags().IntVar(&f.maxStmt, "max-statement-size", 1000000, "Maximum size of a single INSERT statement in bytes")
+	cmd.Flags().StringVar(&f.rejects, "rejects", "", "File for rejected rows. Defaults to <file>.rejected.<ext> next to --file.")
+	cmd.Flags().StringVar(&f.keyspace, "keyspace", "", "Vitess keyspace of the table. Defaults to @primary, same as pscale sql.")
+	cmd.Flags().StringVar(&f.postgresDB, "dbname", "postgres", "PostgreSQL database name")
+	cmd.Flags().StringVar(&f.role, "role",
+		"readwriter", "Role defines the access level, allowed values are: writer, readwriter, admin.")
+	cmd.MarkFlagRequired("table") // nolint:errcheck
+	cmd.MarkFlagRequired("file")  // nolint:errcheck
+	cmdutil.SetJSONOutput(cmd, &ImportResult{})
+
+	return cmd
+}
+
+func importFile(ch *cmdutil.Helper, cmd *cobra.Command, flags *importFlags, args []string) error {
+	ctx := cmd.Context()
+	database, branch := args[0], args[1]
+
+	if flags.batchSize <= 0 {
+		return errors.New("--batch-size must be greater than 0")
+	}
+
+	renames := map[string]string{}
+	for _, m := range flags.mappings {
+		from, to, ok := strings.Cut(m, "=")
+		if !ok || strings.TrimSpace(from) == "" {
+			return fmt.Errorf("invalid --map %q: expected 'file_column=table_column'", m)
+		}
+		renames[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+
+	format := flags.fileType
+	if format == "" {
+		if flags.file == "-" {
+			return errors.New("--type is required when reading from standard input")
+		}
+		switch strings.ToLower(filepath.Ext(flags.file)) {
+		case ".csv":
+			format = "csv"
+		case ".ndjson", ".jsonl", ".json":
+			format = "json"
+		default:
+			return fmt.Errorf("can't infer the type of %q; use a .csv or .ndjson file or pass --type", flags.file)
+		}
+	}
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("invalid --type %q: must be csv or json", format)
+	}
+
+	var in io.Reader = os.Stdin
+	if flags.file != "-" {
+		file, err := os.Open(flags.file)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var src recordSource
+	var err error
+	if format == "csv" {
+		src, err = newCSVSource(in, flags.null)
+	} else {
+		src, err = newNDJSONSource(in)
+	}
+	if err != nil {
+		return err
+	}
+
+	rejectsPath := flags.rejects
+	if rejectsPath == "" {
+		rejectsPath = defaultRejectsPath(flags.file, format)
+	}
+
+	session, err := sqlquery.NewSession(ctx, ch, sqlquery.Options{
+		Organization:  ch.Config.Organization,
+		Database:      database,
+		Branch:        branch,
+		Keyspace:      flags.keyspace,
+		PostgresDB:    flags.postgresDB,
+		Role:          flags.role,
+		CredentialTTL: importCredentialTTL,
+	})
+	if err != nil {
+		return cmdutil.HandleError(err)
+	}
+	defer session.Close()
+
+	db := session.DB()
+	var table *tableSchema
+	if session.Engine() == "mysql" {
+		table, err = loadMySQLTable(ctx, db, flags.table)
+	} else {
+		table, err = loadPostgresTable(ctx, db, flags.table)
+	}
+	if err != nil {
+		return err
+	}
+
+	mappings, err := mapColumns(src.Columns(), table, renames)
+	if err != nil {
+		return err
+	}
+	columns := make([]*tableColumn, len(mappings))
+	for i, m := range mappings {
+		columns[i] = m.column
+	}
+
+	var target batchTarget
+	maxBytes := flags.maxStmt
+	if table.engine == "postgresql" && !flags.upsert {
+		target = newCopyTarget(db, table, columns)
+	} else {
+		insert, err := newInsertTarget(db, table, columns, flags.upsert)
+		if err != nil {
+			return err
+		}
+		target = insert
+		maxBytes -= len(insert.prefix) + len(insert.suffix)
+	}
+
+	rejects := &rejectWriter{path: rejectsPath, format: format, columns: src.Columns()}
+
+	progress := ch.Printer.StartProgress(fmt.Sprintf("Importing %s into %s/%s...",
+		printer.BoldBlue(flags.file), printer.BoldBlue(database), printer.BoldBlue(table.name)))
+	defer progress.Stop()
+
+	im := &importer{
+		mappings:  mappings,
+		target:    target,
+		rejects:   rejects,
+		batchSize: flags.batchSize,
+		maxBytes:  maxBytes,
+		progress: func(imported, rejected uint64) {
+			progress.Update(fmt.Sprintf("Imported %s rows (%s rejected)...",
+				printer.Number(imported), printer.Number(rejected)))
+		},
+	}
+
+	start := time.Now()
+	runErr := im.run(ctx, src)
+	if err := rejects.Close(); err != nil && runErr == nil {
+		runErr = err
+	}
+	progress.Stop()
+
+	result := &ImportResult{Table: flags.table, Imported: im.imported, Rejected: im.rejected}
+	if im.rejected > 0 {
+		result.RejectsFile = rejectsPath
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("import stopped after %d rows: %w", im.imported, runErr)
+	}
+
+	if ch.Printer.Format() == printer.Human {
+		ch.Printer.Printf("Imported %s rows into %s (elapsed time: %s)\n",
+			printer.Number(im.imported), printer.BoldBlue(flags.table), time.Since(start).Round(time.Millisecond))
+		if im.rejected > 0 {
+			ch.Printer.Printf("%s rows were rejected and written to %s\n",
+				printer.Number(im.rejected), printer.BoldRed(rejectsPath))
+		}
+	} else if err := ch.Printer.PrintResource(result); err != nil {
+		return err
+	}
+
+	if im.rejected > 0 {
+		if ch.Printer.Format() == printer.JSON {
+			return cmdutil.JSONReportedError(cmdutil.ActionRequestedExitCode)
+		}
+		return &cmdutil.Error{
+			Msg:      fmt.Sprintf("%d rows were rejected", im.rejected),
+			ExitCode: cmdutil.ActionRequestedExitCode,
+		}
+	}
+	return nil
+}
+
+// defaultRejectsPath puts rejected rows next to the input file:
+// customers.csv becomes customers.rejected.csv.
+func defaultRejectsPath(file, format string) string {
+	if file == "-" {
+		if format == "csv" {
+			return "rejected.csv"
+		}
+		return "rejected.ndjson"
+	}
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + ".rejected" + ext
+}
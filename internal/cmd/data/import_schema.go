@@ -0,0 +1,404 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// valueKind is how an input value is converted for a column.
+type valueKind int
+
+const (
+	kindText valueKind = iota
+	kindInt
+	kindUint
+	kindFloat
+	kindDecimal
+	kindBool
+	kindDate
+	kindDateTime
+	kindTimestampTZ
+	kindJSON
+	kindBinary
+)
+
+// tableColumn is a column of the import's target table.
+type tableColumn struct {
+	name     string
+	dataType string
+	kind     valueKind
+	nullable bool
+	// required columns are NOT NULL without a default and must be imported.
+	required bool
+	key      bool
+}
+
+// tableSchema is the target table as read from the branch.
+type tableSchema struct {
+	engine  string
+	schema  string // PostgreSQL only
+	name    string
+	columns []*tableColumn
+}
+
+func (t *tableSchema) column(name string) *tableColumn {
+	for _, c := range t.columns {
+		if strings.EqualFold(c.name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (t *tableSchema) qualifiedName() string {
+	if t.schema == "" {
+		return quoteIdent(t.engine, t.name)
+	}
+	return quoteIdent(t.engine, t.schema) + "." + quoteIdent(t.engine, t.name)
+}
+
+// loadMySQLTable reads the columns of a MySQL table with SHOW COLUMNS, which
+// Vitess routes to the right keyspace.
+func loadMySQLTable(ctx context.Context, db *sql.DB, table string) (*tableSchema, error) {
+	rows, err := db.QueryContext(ctx, "SHOW COLUMNS FROM "+quoteIdent("mysql", table))
+	if err != nil {
+		return nil, fmt.Errorf("reading columns of table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	t := &tableSchema{engine: "mysql", name: table}
+	for rows.Next() {
+		var field, typ, null, key, extra string
+		var def sql.NullString
+		if err := rows.Scan(&field, &typ, &null, &key, &def, &extra); err != nil {
+			return nil, err
+		}
+
+		extra = strings.ToLower(extra)
+		t.columns = append(t.columns, &tableColumn{
+			name:     field,
+			dataType: strings.ToLower(typ),
+			kind:     mysqlKind(strings.ToLower(typ)),
+			nullable: null == "YES",
+			required: null != "YES" && !def.Valid &&
+				!strings.Contains(extra, "auto_increment") && !strings.Contains(extra, "generated"),
+			key: key == "PRI",
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.columns) == 0 {
+		return nil, fmt.Errorf("table %s has no columns", table)
+	}
+	return t, nil
+}
+
+func mysqlKind(typ string) valueKind {
+	if strings.HasPrefix(typ, "tinyint(1)") || typ == "bool" || typ == "boolean" {
+		return kindBool
+	}
+
+	base, _, _ := strings.Cut(typ, "(")
+	base, _, _ = strings.Cut(base, " ")
+	switch base {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		if strings.Contains(typ, "unsigned") {
+			return kindUint
+		}
+		return kindInt
+	case "year":
+		return kindInt
+	case "float", "double", "real":
+		return kindFloat
+	case "decimal", "numeric":
+		return kindDecimal
+	case "date":
+		return kindDate
+	case "datetime", "timestamp":
+		return kindDateTime
+	case "json":
+		return kindJSON
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return kindBinary
+	default:
+		return kindText
+	}
+}
+
+// loadPostgresTable reads the columns and primary key of a PostgreSQL
+// table. table may be schema-qualified; otherwise the current schema is
+// used.
+func loadPostgresTable(ctx context.Context, db *sql.DB, table string) (*tableSchema, error) {
+	t := &tableSchema{engine: "postgresql", name: table}
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		t.schema, t.name = schema, name
+	} else if err := db.QueryRowContext(ctx, "SELECT current_schema()").Scan(&t.schema); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT column_name, data_type, is_nullable = 'YES',
+       column_default IS NULL AND is_identity = 'NO' AND is_generated = 'NEVER'
+  FROM information_schema.columns
+ WHERE table_schema = $1 AND table_name = $2
+ ORDER BY ordinal_position`, t.schema, t.name)
+	if err != nil {
+		return nil, fmt.Errorf("reading columns of table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, typ string
+		var nullable, noDefault bool
+		if err := rows.Scan(&name, &typ, &nullable, &noDefault); err != nil {
+			return nil, err
+		}
+		t.columns = append(t.columns, &tableColumn{
+			name:     name,
+			dataType: typ,
+			kind:     postgresKind(typ),
+			nullable: nullable,
+			required: !nullable && noDefault,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist or has no columns", table)
+	}
+
+	keys, err := db.QueryContext(ctx, `SELECT a.attname
+  FROM pg_index i
+  JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+ WHERE i.indrelid = $1::regclass AND i.indisprimary`, t.qualifiedName())
+	if err != nil {
+		return nil, fmt.Errorf("reading primary key of table %s: %w", table, err)
+	}
+	defer keys.Close()
+
+	for keys.Next() {
+		var name string
+		if err := keys.Scan(&name); err != nil {
+			return nil, err
+		}
+		if c := t.column(name); c != nil {
+			c.key = true
+		}
+	}
+	return t, keys.Err()
+}
+
+func postgresKind(typ string) valueKind {
+	switch typ {
+	case "smallint", "integer", "bigint":
+		return kindInt
+	case "real", "double precision":
+		return kindFloat
+	case "numeric":
+		return kindDecimal
+	case "boolean":
+		return kindBool
+	case "date":
+		return kindDate
+	case "timestamp without time zone":
+		return kindDateTime
+	case "timestamp with time zone":
+		return kindTimestampTZ
+	case "json", "jsonb":
+		return kindJSON
+	case "bytea":
+		return kindBinary
+	default:
+		return kindText
+	}
+}
+
+// columnMapping pairs a source column with the table column it loads.
+type columnMapping struct {
+	source int
+	column *tableColumn
+}
+
+// mapColumns matches source columns to table columns by name, ignoring
+// case. renames maps source names to table columns; an empty target skips
+// the source column.
+func mapColumns(source []string, table *tableSchema, renames map[string]string) ([]columnMapping, error) {
+	for from := range renames {
+		if !slices.ContainsFunc(source, func(s string) bool { return strings.EqualFold(s, from) }) {
+			return nil, fmt.Errorf("--map %s: the file has no column %q", from, from)
+		}
+	}
+
+	var mappings []columnMapping
+	var unknown []string
+	used := map[*tableColumn]string{}
+	for i, name := range source {
+		target := name
+		for from, to := range renames {
+			if strings.EqualFold(from, name) {
+				target = to
+			}
+		}
+		if target == "" {
+			continue
+		}
+
+		col := table.column(target)
+		if col == nil {
+			unknown = append(unknown, name)
+			continue
+		}
+		if prev, ok := used[col]; ok {
+			return nil, fmt.Errorf("file columns %q and %q both map to column %q", prev, name, col.name)
+		}
+		used[col] = name
+		mappings = append(mappings, columnMapping{source: i, column: col})
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("table %s has no column(s) %s; rename them with --map <column>=<table column> or skip them with --map <column>=",
+			table.name, strings.Join(unknown, ", "))
+	}
+	if len(mappings) == 0 {
+		return nil, errors.New("no file columns match the table")
+	}
+
+	var missing []string
+	for _, c := range table.columns {
+		if _, ok := used[c]; !ok && c.required {
+			missing = append(missing, c.name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("the file has no value for required column(s) %s", strings.Join(missing, ", "))
+	}
+	return mappings, nil
+}
+
+var importTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	time.DateOnly,
+}
+
+// convertValue converts an input field to the value loaded into col:
+// int64, uint64, float64, bool, []byte, string, or nil for NULL. Empty
+// fields are NULL for columns that aren't text.
+func convertValue(col *tableColumn, field *string) (any, error) {
+	if field == nil || (*field == "" && col.kind != kindText) {
+		if !col.nullable {
+			return nil, fmt.Errorf("column %s can't be NULL", col.name)
+		}
+		return nil, nil
+	}
+	s := *field
+	t := strings.TrimSpace(s)
+
+	switch col.kind {
+	case kindInt:
+		i, err := strconv.ParseInt(t, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %q is not an integer", col.name, s)
+		}
+		return i, nil
+	case kindUint:
+		u, err := strconv.ParseUint(t, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %q is not an unsigned integer", col.name, s)
+		}
+		return u, nil
+	case kindFloat:
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %q is not a number", col.name, s)
+		}
+		return f, nil
+	case kindDecimal:
+		if _, ok := new(big.Float).SetString(t); !ok {
+			return nil, fmt.Errorf("column %s: %q is not a number", col.name, s)
+		}
+		return t, nil
+	case kindBool:
+		switch strings.ToLower(t) {
+		case "1", "t", "true", "y", "yes":
+			return true, nil
+		case "0", "f", "false", "n", "no":
+			return false, nil
+		}
+		return nil, fmt.Errorf("column %s: %q is not a boolean", col.name, s)
+	case kindDate:
+		d, err := time.Parse(time.DateOnly, t)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %q is not a date (YYYY-MM-DD)", col.name, s)
+		}
+		return d.Format(time.DateOnly), nil
+	case kindDateTime, kindTimestampTZ:
+		ts, err := parseImportTime(t)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %q is not a timestamp", col.name, s)
+		}
+		if col.kind == kindTimestampTZ {
+			return ts.Format("2006-01-02 15:04:05.999999Z07:00"), nil
+		}
+		// Values with an offset are stored in UTC.
+		return ts.UTC().Format("2006-01-02 15:04:05.999999"), nil
+	case kindJSON:
+		if !json.Valid([]byte(s)) {
+			return nil, fmt.Errorf("column %s: invalid JSON", col.name)
+		}
+		return s, nil
+	case kindBinary:
+		return []byte(s), nil
+	default:
+		return s, nil
+	}
+}
+
+func parseImportTime(s string) (time.Time, error) {
+	for _, layout := range importTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognized time format")
+}
+
+// quoteIdent quotes a table or column name for engine.
+func quoteIdent(engine, name string) string {
+	if engine == "mysql" {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return pq.QuoteIdentifier(name)
+}
+
+// valueSize returns the number of bytes a converted value adds to an INSERT
+// statement, for --max-statement-size. Values of a type convertValue doesn't
+// return are an error, which rejects the row.
+func valueSize(v any) (int, error) {
+	switch v := v.(type) {
+	case nil, bool:
+		return 1, nil
+	case int64, uint64, float64:
+		return 8, nil
+	case []byte:
+		return len(v), nil
+	case string:
+		return len(v), nil
+	default:
+		return 0, fmt.Errorf("unsupported value of type %T", v)
+	}
+}
@@ -0,0 +1,215 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sourceRecord is one row of the input file. Fields line up with the
+// source's columns; a nil field is NULL.
+type sourceRecord struct {
+	line   int
+	fields []*string
+	// raw is the record as read, for the rejects file: []string for CSV,
+	// the original line for NDJSON.
+	raw any
+	// err is set when the record couldn't be parsed.
+	err error
+}
+
+// recordSource reads an input file record by record. Next returns io.EOF
+// after the last record.
+type recordSource interface {
+	Columns() []string
+	Next() (*sourceRecord, error)
+}
+
+// csvSource reads a CSV file whose first row names the columns.
+type csvSource struct {
+	r       *csv.Reader
+	columns []string
+	null    string
+}
+
+func newCSVSource(r io.Reader, null string) (*csvSource, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty; CSV files need a header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	if len(header) > 0 {
+		// Spreadsheet exports often start with a byte order mark.
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	return &csvSource{r: cr, columns: header, null: null}, nil
+}
+
+func (s *csvSource) Columns() []string { return s.columns }
+
+func (s *csvSource) Next() (*sourceRecord, error) {
+	record, err := s.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	rec := &sourceRecord{raw: record}
+	if err != nil {
+		var perr *csv.ParseError
+		if !errors.As(err, &perr) {
+			return nil, err
+		}
+		// The reader recovers at the next line, so a malformed record is
+		// rejected rather than ending the import.
+		rec.line = perr.Line
+		rec.err = perr.Err
+		return rec, nil
+	}
+
+	rec.line, _ = s.r.FieldPos(0)
+	rec.fields = make([]*string, len(record))
+	for i := range record {
+		if s.null != "" && record[i] == s.null {
+			continue
+		}
+		rec.fields[i] = &record[i]
+	}
+	return rec, nil
+}
+
+// ndjsonSource reads newline-delimited JSON objects. The columns are the
+// keys of the first object; later objects may leave keys out (NULL) but
+// can't add new ones.
+type ndjsonSource struct {
+	scanner *bufio.Scanner
+	line    int
+	columns []string
+	index   map[string]int
+	first   *sourceRecord
+}
+
+// maxNDJSONLine bounds a single input line.
+const maxNDJSONLine = 64 * 1024 * 1024
+
+func newNDJSONSource(r io.Reader) (*ndjsonSource, error) {
+	s := &ndjsonSource{scanner: bufio.NewScanner(r), index: map[string]int{}}
+	s.scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+
+	line, err := s.nextLine()
+	if err == io.EOF {
+		return nil, errors.New("the file has no JSON objects")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("line %d: expected a JSON object", s.line)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", s.line, err)
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, fmt.Errorf("line %d: %w", s.line, err)
+		}
+	}
+
+	for _, k := range keys {
+		if _, ok := s.index[k]; !ok {
+			s.index[k] = len(s.columns)
+			s.columns = append(s.columns, k)
+		}
+	}
+	s.first = s.parse(line)
+	return s, nil
+}
+
+// nextLine returns the next non-blank line.
+func (s *ndjsonSource) nextLine() ([]byte, error) {
+	for s.scanner.Scan() {
+		s.line++
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) > 0 {
+			return line, nil
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (s *ndjsonSource) Columns() []string { return s.columns }
+
+func (s *ndjsonSource) Next() (*sourceRecord, error) {
+	if s.first != nil {
+		rec := s.first
+		s.first = nil
+		return rec, nil
+	}
+
+	line, err := s.nextLine()
+	if err != nil {
+		return nil, err
+	}
+	return s.parse(line), nil
+}
+
+func (s *ndjsonSource) parse(line []byte) *sourceRecord {
+	rec := &sourceRecord{line: s.line, raw: string(line)}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(line, &obj); err != nil {
+		rec.err = fmt.Errorf("invalid JSON: %w", err)
+		return rec
+	}
+
+	rec.fields = make([]*string, len(s.columns))
+	for k, v := range obj {
+		i, ok := s.index[k]
+		if !ok {
+			rec.err = fmt.Errorf("unexpected key %q; every key must appear in the first object", k)
+			return rec
+		}
+		rec.fields[i] = jsonFieldValue(v)
+	}
+	return rec
+}
+
+// jsonFieldValue flattens a JSON value to the text the column conversion
+// expects: strings unquoted, numbers and booleans as written, objects and
+// arrays as JSON.
+func jsonFieldValue(v json.RawMessage) *string {
+	v = bytes.TrimSpace(v)
+	if string(v) == "null" {
+		return nil
+	}
+
+	var s string
+	if len(v) > 0 && v[0] == '"' {
+		if err := json.Unmarshal(v, &s); err == nil {
+			return &s
+		}
+	}
+	s = string(v)
+	return &s
+}
@@ -0,0 +1,397 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// pendingRow is a converted row waiting for its batch.
+type pendingRow struct {
+	record *sourceRecord
+	values []any
+	// size is the number of bytes the row adds to an INSERT statement.
+	size int
+}
+
+// maxBatchParams is the most parameters MySQL and PostgreSQL accept in one
+// statement.
+const maxBatchParams = 65535
+
+// batchTarget loads a batch of rows. A batch either succeeds or fails as a
+// whole.
+type batchTarget interface {
+	write(ctx context.Context, rows []*pendingRow) error
+}
+
+// insertTarget loads rows with multi-row INSERT statements, optionally
+// updating rows whose primary key already exists. The values are sent as
+// bound parameters, never inlined in the statement.
+type insertTarget struct {
+	db     *sql.DB
+	engine string
+	prefix string
+	suffix string
+}
+
+func newInsertTarget(db *sql.DB, table *tableSchema, columns []*tableColumn, upsert bool) (*insertTarget, error) {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = quoteIdent(table.engine, c.name)
+	}
+
+	t := &insertTarget{
+		db:     db,
+		engine: table.engine,
+		prefix: fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table.qualifiedName(), strings.Join(names, ", ")),
+	}
+	if !upsert {
+		return t, nil
+	}
+	if !slices.ContainsFunc(table.columns, func(c *tableColumn) bool { return c.key }) {
+		return nil, fmt.Errorf("--upsert needs a primary key, and table %s has none", table.name)
+	}
+
+	var keys, updates []string
+	for i, c := range columns {
+		if c.key {
+			keys = append(keys, names[i])
+			continue
+		}
+		if table.engine == "mysql" {
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", names[i], names[i]))
+		} else {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", names[i], names[i]))
+		}
+	}
+
+	if table.engine == "mysql" {
+		if len(updates) == 0 {
+			// Only key columns: keep the existing row.
+			updates = append(updates, fmt.Sprintf("%s = %s", names[0], names[0]))
+		}
+		t.suffix = " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+		return t, nil
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("--upsert needs the primary key columns of table %s in the file", table.name)
+	}
+	if len(updates) == 0 {
+		t.suffix = fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(keys, ", "))
+	} else {
+		t.suffix = fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keys, ", "), strings.Join(updates, ", "))
+	}
+	return t, nil
+}
+
+// statement returns the INSERT statement for rows, with a placeholder for
+// every value, and the values to bind to them.
+func (t *insertTarget) statement(rows []*pendingRow) (string, []any) {
+	var (
+		b    strings.Builder
+		args []any
+	)
+	b.WriteString(t.prefix)
+	for i, r := range rows {
+		if i > 0 {
+			b.WriteString(",\n")
+		}
+		b.WriteByte('(')
+		for j, v := range r.values {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			args = append(args, v)
+			if t.engine == "mysql" {
+				b.WriteByte('?')
+			} else {
+				b.WriteString("$" + strconv.Itoa(len(args)))
+			}
+		}
+		b.WriteByte(')')
+	}
+	b.WriteString(t.suffix)
+	return b.String(), args
+}
+
+func (t *insertTarget) write(ctx context.Context, rows []*pendingRow) error {
+	stmt, args := t.statement(rows)
+	_, err := t.db.ExecContext(ctx, stmt, args...)
+	return err
+}
+
+// copyTarget loads rows into PostgreSQL with COPY FROM STDIN, one
+// transaction per batch.
+type copyTarget struct {
+	db   *sql.DB
+	stmt string
+}
+
+func newCopyTarget(db *sql.DB, table *tableSchema, columns []*tableColumn) *copyTarget {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = quoteIdent(table.engine, c.name)
+	}
+	return &copyTarget{
+		db:   db,
+		stmt: fmt.Sprintf("COPY %s (%s) FROM STDIN", table.qualifiedName(), strings.Join(names, ", ")),
+	}
+}
+
+func (t *copyTarget) write(ctx context.Context, rows []*pendingRow) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, t.stmt)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if _, err := stmt.ExecContext(ctx, r.values...); err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return err
+	}
+	if err := stmt.Close(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// mysqlRowErrors are the MySQL errors caused by the data of a row (bad
+// values, constraint violations), as opposed to the connection or schema.
+var mysqlRowErrors = map[uint16]bool{
+	1048: true, // column cannot be null
+	1062: true, // duplicate entry
+	1264: true, // out of range value
+	1265: true, // data truncated
+	1292: true, // incorrect date/time value
+	1366: true, // incorrect integer/string value
+	1367: true, // illegal value
+	1406: true, // data too long
+	1411: true, // incorrect value for function
+	1451: true, // foreign key (parent row)
+	1452: true, // foreign key (child row)
+	1690: true, // value out of range
+	3140: true, // invalid JSON text
+	3819: true, // check constraint violated
+}
+
+// isRowError reports whether err was caused by the rows of a batch, so
+// retrying smaller batches can isolate the bad rows.
+func isRowError(err error) bool {
+	var myErr *gomysql.MySQLError
+	if errors.As(err, &myErr) {
+		return mysqlRowErrors[myErr.Number]
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Class 22 is data exceptions, 23 integrity constraint violations.
+		class := pqErr.SQLState()[:2]
+		return class == "22" || class == "23"
+	}
+	return false
+}
+
+// rejectWriter records rows that couldn't be imported, in the format of the
+// input file plus an _error column (CSV) or key (NDJSON). The file is only
+// created once the first row is rejected.
+type rejectWriter struct {
+	path    string
+	format  string
+	columns []string
+
+	f   *os.File
+	csv *csv.Writer
+}
+
+func (w *rejectWriter) reject(rec *sourceRecord, reason error) error {
+	if w.f == nil {
+		f, err := os.Create(w.path)
+		if err != nil {
+			return fmt.Errorf("creating rejects file: %w", err)
+		}
+		w.f = f
+		if w.format == "csv" {
+			w.csv = csv.NewWriter(f)
+			if err := w.csv.Write(append(slices.Clone(w.columns), "_error")); err != nil {
+				return err
+			}
+		}
+	}
+
+	msg := fmt.Sprintf("line %d: %s", rec.line, reason)
+	if w.format == "csv" {
+		fields, _ := rec.raw.([]string)
+		row := make([]string, len(w.columns)+1)
+		copy(row, fields)
+		row[len(w.columns)] = msg
+		return w.csv.Write(row)
+	}
+
+	obj := map[string]json.RawMessage{}
+	line, _ := rec.raw.(string)
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		obj = map[string]json.RawMessage{"_line": json.RawMessage(strconv.Quote(line))}
+	}
+	obj["_error"], _ = json.Marshal(msg)
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w.f, "%s\n", out)
+	return err
+}
+
+// Close flushes the rejects file, if one was created.
+func (w *rejectWriter) Close() error {
+	if w.f == nil {
+		return nil
+	}
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			w.f.Close()
+			return err
+		}
+	}
+	return w.f.Close()
+}
+
+// importer converts source records and loads them in batches bounded by a
+// row count and a statement size. Batches that fail because of their data
+// are split until the bad rows are isolated and rejected.
+type importer struct {
+	mappings  []columnMapping
+	target    batchTarget
+	rejects   *rejectWriter
+	batchSize int
+	maxBytes  int
+	progress  func(imported, rejected uint64)
+
+	batch      []*pendingRow
+	batchBytes int
+	imported   uint64
+	rejected   uint64
+}
+
+func (im *importer) add(ctx context.Context, rec *sourceRecord) error {
+	if rec.err != nil {
+		return im.reject(rec, rec.err)
+	}
+
+	row := &pendingRow{record: rec, values: make([]any, len(im.mappings))}
+	for i, m := range im.mappings {
+		var field *string
+		if m.source < len(rec.fields) {
+			field = rec.fields[m.source]
+		}
+		v, err := convertValue(m.column, field)
+		if err != nil {
+			return im.reject(rec, err)
+		}
+		size, err := valueSize(v)
+		if err != nil {
+			return im.reject(rec, err)
+		}
+		row.values[i] = v
+		row.size += size
+	}
+
+	full := len(im.batch) >= im.batchSize ||
+		im.batchBytes+row.size > im.maxBytes ||
+		(len(im.batch)+1)*len(row.values) > maxBatchParams
+	if len(im.batch) > 0 && full {
+		if err := im.flush(ctx); err != nil {
+			return err
+		}
+	}
+	im.batch = append(im.batch, row)
+	im.batchBytes += row.size
+	return nil
+}
+
+func (im *importer) flush(ctx context.Context) error {
+	if len(im.batch) == 0 {
+		return nil
+	}
+	if err := im.load(ctx, im.batch); err != nil {
+		return err
+	}
+	im.batch = im.batch[:0]
+	im.batchBytes = 0
+	if im.progress != nil {
+		im.progress(im.imported, im.rejected)
+	}
+	return nil
+}
+
+// load writes rows, bisecting batches that fail because of their data.
+func (im *importer) load(ctx context.Context, rows []*pendingRow) error {
+	err := im.target.write(ctx, rows)
+	if err == nil {
+		im.imported += uint64(len(rows))
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if !isRowError(err) {
+		return err
+	}
+	if len(rows) == 1 {
+		return im.reject(rows[0].record, err)
+	}
+
+	mid := len(rows) / 2
+	if err := im.load(ctx, rows[:mid]); err != nil {
+		return err
+	}
+	return im.load(ctx, rows[mid:])
+}
+
+func (im *importer) reject(rec *sourceRecord, reason error) error {
+	im.rejected++
+	return im.rejects.reject(rec, reason)
+}
+
+// run imports every record of src.
+func (im *importer) run(ctx context.Context, src recordSource) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		rec, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := im.add(ctx, rec); err != nil {
+			return err
+		}
+	}
+	return im.flush(ctx)
+}
@@ -0,0 +1,324 @@
+package data
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	gomysql "github.com/go-sql-driver/mysql"
+)
+
+func testTable() *tableSchema {
+	return &tableSchema{
+		engine: "mysql",
+		name:   "customers",
+		columns: []*tableColumn{
+			{name: "id", kind: kindInt, key: true, required: true},
+			{name: "email", kind: kindText, required: true},
+			{name: "active", kind: kindBool, nullable: true},
+			{name: "signed_up_at", kind: kindDateTime, nullable: true},
+			{name: "notes", kind: kindText, nullable: true},
+		},
+	}
+}
+
+func readAll(t *testing.T, src recordSource) []*sourceRecord {
+	t.Helper()
+	var recs []*sourceRecord
+	for {
+		rec, err := src.Next()
+		if err == io.EOF {
+			return recs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, rec)
+	}
+}
+
+func TestCSVSource(t *testing.T) {
+	c := qt.New(t)
+
+	src, err := newCSVSource(strings.NewReader("\ufeffid, Email ,notes\n1,a@example.com,\\N\n2,b@example.com\n3,c@example.com,hi\n"), `\N`)
+	c.Assert(err, qt.IsNil)
+	c.Assert(src.Columns(), qt.DeepEquals, []string{"id", "Email", "notes"})
+
+	recs := readAll(t, src)
+	c.Assert(recs, qt.HasLen, 3)
+
+	c.Assert(recs[0].err, qt.IsNil)
+	c.Assert(recs[0].line, qt.Equals, 2)
+	c.Assert(*recs[0].fields[1], qt.Equals, "a@example.com")
+	c.Assert(recs[0].fields[2], qt.IsNil)
+
+	c.Assert(recs[1].err, qt.ErrorIs, csv.ErrFieldCount)
+	c.Assert(recs[1].line, qt.Equals, 3)
+
+	c.Assert(*recs[2].fields[2], qt.Equals, "hi")
+}
+
+func TestNDJSONSource(t *testing.T) {
+	c := qt.New(t)
+
+	input := `{"id": 1, "email": "a@example.com", "notes": {"vip": true}}
+
+{"id": 2, "email": null}
+{"id": 3, "email": "c@example.com", "phone": "555"}
+not json
+`
+	src, err := newNDJSONSource(strings.NewReader(input))
+	c.Assert(err, qt.IsNil)
+	c.Assert(src.Columns(), qt.DeepEquals, []string{"id", "email", "notes"})
+
+	recs := readAll(t, src)
+	c.Assert(recs, qt.HasLen, 4)
+
+	c.Assert(*recs[0].fields[0], qt.Equals, "1")
+	c.Assert(*recs[0].fields[2], qt.Equals, `{"vip": true}`)
+
+	c.Assert(recs[1].line, qt.Equals, 3)
+	c.Assert(recs[1].fields[1], qt.IsNil)
+	c.Assert(recs[1].fields[2], qt.IsNil)
+
+	c.Assert(recs[2].err, qt.ErrorMatches, `unexpected key "phone".*`)
+	c.Assert(recs[3].err, qt.ErrorMatches, `invalid JSON.*`)
+}
+
+func TestMapColumns(t *testing.T) {
+	c := qt.New(t)
+
+	mappings, err := mapColumns([]string{"ID", "E-Mail", "internal", "Notes"}, testTable(),
+		map[string]string{"e-mail": "email", "internal": ""})
+	c.Assert(err, qt.IsNil)
+	c.Assert(mappings, qt.HasLen, 3)
+	c.Assert(mappings[0].column.name, qt.Equals, "id")
+	c.Assert(mappings[1].source, qt.Equals, 1)
+	c.Assert(mappings[1].column.name, qt.Equals, "email")
+	c.Assert(mappings[2].source, qt.Equals, 3)
+
+	_, err = mapColumns([]string{"id", "email", "phone"}, testTable(), nil)
+	c.Assert(err, qt.ErrorMatches, `table customers has no column\(s\) phone; .*`)
+
+	_, err = mapColumns([]string{"id"}, testTable(), nil)
+	c.Assert(err, qt.ErrorMatches, `the file has no value for required column\(s\) email`)
+
+	_, err = mapColumns([]string{"id", "email", "mail"}, testTable(), map[string]string{"mail": "email"})
+	c.Assert(err, qt.ErrorMatches, `file columns "email" and "mail" both map to column "email"`)
+
+	_, err = mapColumns([]string{"id", "email"}, testTable(), map[string]string{"_error": ""})
+	c.Assert(err, qt.ErrorMatches, `--map _error: the file has no column "_error"`)
+}
+
+func TestConvertValue(t *testing.T) {
+	c := qt.New(t)
+
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		col     *tableColumn
+		in      *string
+		want    any
+		wantErr string
+	}{
+		{col: &tableColumn{name: "n", kind: kindInt}, in: str(" 42 "), want: int64(42)},
+		{col: &tableColumn{name: "n", kind: kindInt}, in: str("4.2"), wantErr: `column n: "4.2" is not an integer`},
+		{col: &tableColumn{name: "n", kind: kindUint}, in: str("18446744073709551615"), want: uint64(18446744073709551615)},
+		{col: &tableColumn{name: "n", kind: kindInt, nullable: true}, in: str(""), want: nil},
+		{col: &tableColumn{name: "n", kind: kindInt}, in: nil, wantErr: "column n can't be NULL"},
+		{col: &tableColumn{name: "s", kind: kindText}, in: str(""), want: ""},
+		{col: &tableColumn{name: "d", kind: kindDecimal}, in: str("10.50"), want: "10.50"},
+		{col: &tableColumn{name: "d", kind: kindDecimal}, in: str("ten"), wantErr: `column d: "ten" is not a number`},
+		{col: &tableColumn{name: "b", kind: kindBool}, in: str("Yes"), want: true},
+		{col: &tableColumn{name: "b", kind: kindBool}, in: str("0"), want: false},
+		{col: &tableColumn{name: "dt", kind: kindDate}, in: str("2024-03-01"), want: "2024-03-01"},
+		{col: &tableColumn{name: "ts", kind: kindDateTime}, in: str("2024-03-01T14:30:00+02:00"), want: "2024-03-01 12:30:00"},
+		{col: &tableColumn{name: "ts", kind: kindDateTime}, in: str("2024-03-01 12:30:00.25"), want: "2024-03-01 12:30:00.25"},
+		{col: &tableColumn{name: "ts", kind: kindTimestampTZ}, in: str("2024-03-01T14:30:00+02:00"), want: "2024-03-01 14:30:00+02:00"},
+		{col: &tableColumn{name: "ts", kind: kindDateTime}, in: str("yesterday"), wantErr: `column ts: "yesterday" is not a timestamp`},
+		{col: &tableColumn{name: "j", kind: kindJSON}, in: str(`{"a":1}`), want: `{"a":1}`},
+		{col: &tableColumn{name: "j", kind: kindJSON}, in: str(`{"a":`), wantErr: "column j: invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		got, err := convertValue(tt.col, tt.in)
+		if tt.wantErr != "" {
+			c.Assert(err, qt.ErrorMatches, tt.wantErr)
+			continue
+		}
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.Equals, tt.want)
+	}
+}
+
+func TestValueSize(t *testing.T) {
+	c := qt.New(t)
+
+	size := func(v any) int {
+		n, err := valueSize(v)
+		c.Assert(err, qt.IsNil)
+		return n
+	}
+	c.Assert(size(nil), qt.Equals, 1)
+	c.Assert(size(int64(-3)), qt.Equals, 8)
+	c.Assert(size("it's"), qt.Equals, 4)
+	c.Assert(size([]byte("hi")), qt.Equals, 2)
+
+	_, err := valueSize(struct{}{})
+	c.Assert(err, qt.ErrorMatches, `unsupported value of type struct \{\}`)
+}
+
+func TestInsertTargetUpsert(t *testing.T) {
+	c := qt.New(t)
+
+	table := testTable()
+	cols := []*tableColumn{table.columns[0], table.columns[1]}
+
+	mysql, err := newInsertTarget(nil, table, cols, true)
+	c.Assert(err, qt.IsNil)
+	rows := []*pendingRow{{values: []any{int64(1), "a'b"}}, {values: []any{int64(2), `\'`}}}
+	stmt, args := mysql.statement(rows)
+	c.Assert(stmt, qt.Equals,
+		"INSERT INTO `customers` (`id`, `email`) VALUES (?, ?),\n(?, ?) ON DUPLICATE KEY UPDATE `email` = VALUES(`email`)")
+	c.Assert(args, qt.DeepEquals, []any{int64(1), "a'b", int64(2), `\'`})
+
+	table.engine = "postgresql"
+	table.schema = "public"
+	pg, err := newInsertTarget(nil, table, cols, true)
+	c.Assert(err, qt.IsNil)
+	stmt, args = pg.statement(rows)
+	c.Assert(stmt, qt.Equals,
+		`INSERT INTO "public"."customers" ("id", "email") VALUES ($1, $2),
+($3, $4) ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email"`)
+	c.Assert(args, qt.HasLen, 4)
+
+	_, err = newInsertTarget(nil, table, []*tableColumn{table.columns[1]}, true)
+	c.Assert(err, qt.ErrorMatches, "--upsert needs the primary key columns of table customers in the file")
+
+	table.columns[0].key = false
+	_, err = newInsertTarget(nil, table, cols, true)
+	c.Assert(err, qt.ErrorMatches, "--upsert needs a primary key, and table customers has none")
+}
+
+// fakeTarget records batches and fails those containing a row whose first
+// value is in bad.
+type fakeTarget struct {
+	batches [][]any
+	bad     map[int64]bool
+	err     error
+}
+
+func (f *fakeTarget) write(_ context.Context, rows []*pendingRow) error {
+	if f.err != nil {
+		return f.err
+	}
+	for _, r := range rows {
+		if f.bad[r.values[0].(int64)] {
+			return &gomysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+		}
+	}
+	ids := make([]any, len(rows))
+	for i, r := range rows {
+		ids[i] = r.values[0]
+	}
+	f.batches = append(f.batches, ids)
+	return nil
+}
+
+func TestImporter(t *testing.T) {
+	c := qt.New(t)
+
+	input := "id,email\n1,a@x.com\n2,b@x.com\nthree,c@x.com\n4,d@x.com\n5,e@x.com\n6,f@x.com\n7,g@x.com\n"
+	src, err := newCSVSource(strings.NewReader(input), "")
+	c.Assert(err, qt.IsNil)
+
+	table := testTable()
+	mappings, err := mapColumns(src.Columns(), table, nil)
+	c.Assert(err, qt.IsNil)
+
+	rejectsPath := filepath.Join(t.TempDir(), "customers.rejected.csv")
+	target := &fakeTarget{bad: map[int64]bool{5: true}}
+	var updates int
+	im := &importer{
+		mappings:  mappings,
+		target:    target,
+		rejects:   &rejectWriter{path: rejectsPath, format: "csv", columns: src.Columns()},
+		batchSize: 4,
+		maxBytes:  1000,
+		progress:  func(uint64, uint64) { updates++ },
+	}
+
+	c.Assert(im.run(context.Background(), src), qt.IsNil)
+	c.Assert(im.rejects.Close(), qt.IsNil)
+
+	c.Assert(im.imported, qt.Equals, uint64(5))
+	c.Assert(im.rejected, qt.Equals, uint64(2))
+	c.Assert(updates, qt.Equals, 2)
+	// The first batch (1, 2, 4, 5) failed as a whole and was split until
+	// row 5 was isolated.
+	c.Assert(target.batches, qt.DeepEquals, [][]any{
+		{int64(1), int64(2)},
+		{int64(4)},
+		{int64(6), int64(7)},
+	})
+
+	data, err := os.ReadFile(rejectsPath)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, `id,email,_error
+three,c@x.com,"line 4: column id: ""three"" is not an integer"
+5,e@x.com,line 6: Error 1062: Duplicate entry
+`)
+}
+
+func TestImporterStatementSize(t *testing.T) {
+	c := qt.New(t)
+
+	src, err := newCSVSource(strings.NewReader("id,email\n1,a@x.com\n2,b@x.com\n3,c@x.com\n"), "")
+	c.Assert(err, qt.IsNil)
+	mappings, err := mapColumns(src.Columns(), testTable(), nil)
+	c.Assert(err, qt.IsNil)
+
+	target := &fakeTarget{}
+	im := &importer{
+		mappings:  mappings,
+		target:    target,
+		rejects:   &rejectWriter{path: filepath.Join(t.TempDir(), "rejects.csv"), format: "csv"},
+		batchSize: 100,
+		// Each row, 1 and a@x.com, is 8+7 bytes.
+		maxBytes: 30,
+	}
+
+	c.Assert(im.run(context.Background(), src), qt.IsNil)
+	c.Assert(target.batches, qt.DeepEquals, [][]any{{int64(1), int64(2)}, {int64(3)}})
+}
+
+func TestImporterStopsOnConnectionErrors(t *testing.T) {
+	c := qt.New(t)
+
+	src, err := newCSVSource(strings.NewReader("id,email\n1,a@x.com\n"), "")
+	c.Assert(err, qt.IsNil)
+	mappings, err := mapColumns(src.Columns(), testTable(), nil)
+	c.Assert(err, qt.IsNil)
+
+	im := &importer{
+		mappings:  mappings,
+		target:    &fakeTarget{err: gomysql.ErrInvalidConn},
+		rejects:   &rejectWriter{path: filepath.Join(t.TempDir(), "rejects.csv"), format: "csv"},
+		batchSize: 100,
+		maxBytes:  1000,
+	}
+
+	c.Assert(im.run(context.Background(), src), qt.ErrorIs, gomysql.ErrInvalidConn)
+	c.Assert(im.rejected, qt.Equals, uint64(0))
+}
+
+func TestDefaultRejectsPath(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(defaultRejectsPath("data/customers.csv", "csv"), qt.Equals, "data/customers.rejected.csv")
+	c.Assert(defaultRejectsPath("-", "json"), qt.Equals, "rejected.ndjson")
+}
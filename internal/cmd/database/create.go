@@ -162,6 +162,7 @@ func CreateCmd(ch *cmdutil.Helper) *cobra.Command {
 	_ = cmd.Flags().MarkHidden("cloudflare-signature")
 
 	cmd.Flags().BoolVar(&flags.wait, "wait", false, "Wait until the database is ready")
+	cmdutil.SetJSONOutput(cmd, &ps.Database{})
 
 	return cmd
 }
@@ -188,6 +189,7 @@ func waitUntilReady(ctx context.Context, client *ps.Client, printer *printer.Pri
 	ticker = time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
+	var state string
 	for {
 		select {
 		case <-ctx.Done():
@@ -201,6 +203,7 @@ func waitUntilReady(ctx context.Context, client *ps.Client, printer *printer.Pri
 				continue
 			}
 
+			state = printer.PrintState(state, string(resp.State))
 			if resp.State == "ready" {
 				return nil
 			}
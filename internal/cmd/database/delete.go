@@ -119,5 +119,7 @@ func DeleteCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "Delete a database without confirmation")
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
+
 	return cmd
 }
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/planetscale/cli/internal/cmdutil"
@@ -42,6 +43,15 @@ type dumpFlags struct {
 	outputFormat   string
 }
 
+// DumpResult summarizes a finished dump.
+type DumpResult struct {
+	Directory string   `header:"directory" json:"directory"`
+	Tables    int      `header:"tables" json:"tables"`
+	Rows      uint64   `header:"rows" json:"rows"`
+	Files     []string `header:"files" json:"files"`
+	ElapsedMs int64    `header:"elapsed_ms" json:"elapsed_ms"`
+}
+
 // DumpCmd encapsulates the commands for dumping a database
 func DumpCmd(ch *cmdutil.Helper) *cobra.Command {
 	f := &dumpFlags{}
@@ -76,6 +86,7 @@ func DumpCmd(ch *cmdutil.Helper) *cobra.Command {
 		"Output format for data: sql (for MySQL, default), json, or csv.")
 	cmd.PersistentFlags().StringArrayVar(&f.columns, "columns", nil,
 		"Columns to include for specific tables (format: 'table:col1,col2'). Can be specified multiple times for different tables.")
+	cmdutil.SetJSONOutput(cmd, &DumpResult{})
 
 	return cmd
 }
@@ -319,6 +330,18 @@ func dump(ch *cmdutil.Helper, cmd *cobra.Command, flags *dumpFlags, args []strin
 		cfg.ColumnIncludes = includes
 	}
 
+	var tables atomic.Int64
+	cfg.TableDumped = func(p dumper.TableProgress) {
+		tables.Add(1)
+		ch.Printer.PrintEvent(&printer.Event{ // nolint:errcheck
+			Type:    printer.EventProgress,
+			State:   "table",
+			Message: fmt.Sprintf("Dumped %s.%s (%d rows)", p.Database, p.Table, p.Rows),
+			Current: int64(p.Done),
+			Total:   int64(p.Total),
+		})
+	}
+
 	d, err := dumper.NewDumper(cfg)
 	if err != nil {
 		return err
@@ -342,8 +365,28 @@ func dump(ch *cmdutil.Helper, cmd *cobra.Command, flags *dumpFlags, args []strin
 	}
 
 	end()
-	ch.Printer.Printf("Dumping is finished! (elapsed time: %s)\n", time.Since(start))
-	return nil
+	elapsed := time.Since(start)
+
+	if ch.Printer.Format() == printer.Human {
+		ch.Printer.Printf("Dumping is finished! (elapsed time: %s)\n", elapsed)
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	result := &DumpResult{
+		Directory: dir,
+		Tables:    int(tables.Load()),
+		Rows:      atomic.LoadUint64(&cfg.Allrows),
+		Files:     make([]string, 0, len(entries)),
+		ElapsedMs: elapsed.Milliseconds(),
+	}
+	for _, e := range entries {
+		result.Files = append(result.Files, filepath.Join(dir, e.Name()))
+	}
+	return ch.Printer.PrintResource(result)
 }
 
 func getDatabaseName(name, addr string) (string, error) {
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/planetscale/cli/internal/cmdutil"
@@ -17,6 +18,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 	var flags struct {
 		page    int
 		perPage int
+		all     bool
 	}
 
 	cmd := &cobra.Command{
@@ -44,12 +46,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 				return err
 			}
 
-			end := ch.Printer.PrintProgress("Fetching databases...")
-			defer end()
-			databases, err := client.Databases.List(ctx, &planetscale.ListDatabasesRequest{
-				Organization: ch.Config.Organization,
-			}, planetscale.WithPage(flags.page), planetscale.WithPerPage(flags.perPage))
-			if err != nil {
+			handleErr := func(err error) error {
 				switch cmdutil.ErrCode(err) {
 				case planetscale.ErrNotFound:
 					return fmt.Errorf("organization %s does not exist or your account is not authorized to access it", printer.BoldBlue(ch.Config.Organization))
@@ -58,6 +55,31 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 				}
 			}
 
+			listDatabases := func(ctx context.Context, opts ...planetscale.ListOption) ([]*planetscale.Database, error) {
+				return client.Databases.List(ctx, &planetscale.ListDatabasesRequest{
+					Organization: ch.Config.Organization,
+				}, opts...)
+			}
+
+			end := ch.Printer.PrintProgress("Fetching databases...")
+			defer end()
+
+			if flags.all {
+				n, err := cmdutil.PrintPages(ch.Printer, planetscale.Pages(ctx, flags.perPage, listDatabases), end, toDatabases)
+				if err != nil {
+					return handleErr(err)
+				}
+				if n == 0 && ch.Printer.Format() == printer.Human {
+					ch.Printer.Println("No databases have been created yet.")
+				}
+				return nil
+			}
+
+			databases, err := listDatabases(ctx, planetscale.WithPage(flags.page), planetscale.WithPerPage(flags.perPage))
+			if err != nil {
+				return handleErr(err)
+			}
+
 			end()
 
 			if len(databases) == 0 && ch.Printer.Format() == printer.Human {
@@ -78,6 +100,9 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().BoolP("web", "w", false, "Open in your web browser")
 	cmd.Flags().IntVar(&flags.page, "page", 0, "Page number to fetch")
 	cmd.Flags().IntVar(&flags.perPage, "per-page", 100, "Number of results per page")
+	cmdutil.AllPagesFlag(cmd, &flags.all)
+	cmdutil.SetJSONOutput(cmd, []*planetscale.Database{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -3,6 +3,8 @@ package database
 import (
 	"bytes"
 	"context"
+	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/planetscale/cli/internal/cmdutil"
@@ -55,3 +57,59 @@ func TestDatabase_ListCmd(t *testing.T) {
 	c.Assert(svc.ListFnInvoked, qt.IsTrue)
 	c.Assert(buf.String(), qt.JSONEquals, dbs)
 }
+
+func TestDatabase_ListCmdAll(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	format := printer.JSON
+	p := printer.NewPrinter(&format)
+	p.SetResourceOutput(&buf)
+
+	org := "planetscale"
+
+	pages := [][]*ps.Database{
+		{{Name: "foo"}, {Name: "bar"}},
+		{{Name: "baz"}},
+	}
+
+	var fetched []string
+	svc := &mock.DatabaseService{
+		ListFn: func(ctx context.Context, req *ps.ListDatabasesRequest, opts ...ps.ListOption) ([]*ps.Database, error) {
+			listOpts := &ps.ListOptions{URLValues: &url.Values{}}
+			for _, opt := range opts {
+				c.Assert(opt(listOpts), qt.IsNil)
+			}
+			c.Assert(listOpts.URLValues.Get("per_page"), qt.Equals, "2")
+
+			page, err := strconv.Atoi(listOpts.URLValues.Get("page"))
+			c.Assert(err, qt.IsNil)
+			fetched = append(fetched, listOpts.URLValues.Get("page"))
+			return pages[page-1], nil
+		},
+	}
+
+	ch := &cmdutil.Helper{
+		Printer: p,
+		Config: &config.Config{
+			Organization: org,
+		},
+		Client: func() (*ps.Client, error) {
+			return &ps.Client{
+				Databases: svc,
+			}, nil
+		},
+	}
+
+	cmd := ListCmd(ch)
+	cmd.SetArgs([]string{"--all", "--per-page", "2"})
+	err := cmd.Execute()
+
+	c.Assert(err, qt.IsNil)
+	c.Assert(fetched, qt.DeepEquals, []string{"1", "2"})
+	c.Assert(buf.String(), qt.JSONEquals, append(pages[0], pages[1]...))
+
+	cmd = ListCmd(ch)
+	cmd.SetArgs([]string{"--all", "--page", "2"})
+	c.Assert(cmd.Execute(), qt.ErrorMatches, ".*none of the others can be.*")
+}
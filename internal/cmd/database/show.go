@@ -66,6 +66,8 @@ func ShowCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolP("web", "w", false, "Open in your web browser")
+	cmdutil.SetJSONOutput(cmd, &planetscale.Database{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
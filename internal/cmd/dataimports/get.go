@@ -61,6 +61,7 @@ func GetDataImportCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.PersistentFlags().StringVar(&flags.name, "name", "", "PlanetScale database importing data")
 	cmd.MarkPersistentFlagRequired("name")
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -108,6 +108,7 @@ func LintExternalDataSourceCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.MarkPersistentFlagRequired("username")
 	cmd.MarkPersistentFlagRequired("password")
 	cmd.MarkPersistentFlagRequired("ssl-mode")
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -120,6 +120,7 @@ func DeployCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.Flags().StringVar(&flags.strategy, "strategy", "", "Deployment strategy: \"serial\" (default) or \"parallel\". Parallel deployments must be enabled for the database.")
 	_ = cmd.Flags().MarkHidden("strategy")
+	cmdutil.SetJSONOutput(cmd, &planetscale.DeployRequest{})
 
 	return cmd
 }
@@ -131,6 +132,7 @@ func waitUntilReady(ctx context.Context, client *planetscale.Client, printer *pr
 
 	ticker := time.NewTicker(time.Second)
 
+	var state string
 	for {
 		select {
 		case <-ctx.Done():
@@ -144,6 +146,7 @@ func waitUntilReady(ctx context.Context, client *planetscale.Client, printer *pr
 				continue
 			}
 
+			state = printer.PrintState(state, resp.Deployment.State)
 			if resp.Deployment.State == "complete" || resp.Deployment.State == "complete_pending_revert" || resp.Deployment.State == "pending_cutover" {
 				return resp.Deployment.State, nil
 			}
@@ -88,6 +88,8 @@ func DiffCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.PersistentFlags().BoolVar(&flags.web, "web", false, "Open in your web browser")
+	cmdutil.SetJSONOutput(cmd, []*planetscale.Diff{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
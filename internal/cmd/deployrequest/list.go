@@ -1,6 +1,7 @@
 package deployrequest
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/planetscale/cli/internal/cmdutil"
@@ -13,6 +14,10 @@ import (
 
 // ListCmd is the command for listing deploy requests.
 func ListCmd(ch *cmdutil.Helper) *cobra.Command {
+	var flags struct {
+		all bool
+	}
+
 	cmd := &cobra.Command{
 		Use:     "list <database>",
 		Short:   "List all deploy requests for a database",
@@ -44,11 +49,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			end := ch.Printer.PrintProgress(fmt.Sprintf("Fetching deploy requests for %s", printer.BoldBlue(database)))
 			defer end()
 
-			deployRequests, err := client.DeployRequests.List(ctx, &planetscale.ListDeployRequestsRequest{
-				Organization: ch.Config.Organization,
-				Database:     database,
-			})
-			if err != nil {
+			handleErr := func(err error) error {
 				switch cmdutil.ErrCode(err) {
 				case planetscale.ErrNotFound:
 					return fmt.Errorf("database %s does not exist in organization %s",
@@ -57,6 +58,29 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 					return cmdutil.HandleError(err)
 				}
 			}
+
+			listDeployRequests := func(ctx context.Context, opts ...planetscale.ListOption) ([]*planetscale.DeployRequest, error) {
+				return client.DeployRequests.List(ctx, &planetscale.ListDeployRequestsRequest{
+					Organization: ch.Config.Organization,
+					Database:     database,
+				}, opts...)
+			}
+
+			if flags.all {
+				n, err := cmdutil.PrintPages(ch.Printer, planetscale.Pages(ctx, planetscale.MaxPerPage, listDeployRequests), end, toDeployRequests)
+				if err != nil {
+					return handleErr(err)
+				}
+				if n == 0 && ch.Printer.Format() == printer.Human {
+					ch.Printer.Printf("No deploy requests exist for %s.\n", printer.BoldBlue(database))
+				}
+				return nil
+			}
+
+			deployRequests, err := listDeployRequests(ctx)
+			if err != nil {
+				return handleErr(err)
+			}
 			end()
 
 			if len(deployRequests) == 0 && ch.Printer.Format() == printer.Human {
@@ -70,6 +94,9 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolP("web", "w", false, "Open in your web browser")
+	cmdutil.AllPagesFlag(cmd, &flags.all)
+	cmdutil.SetJSONOutput(cmd, []*planetscale.DeployRequest{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
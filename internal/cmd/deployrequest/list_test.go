@@ -26,7 +26,7 @@ func TestDeployRequest_ListCmd(t *testing.T) {
 	db := "planetscale"
 
 	svc := &mock.DeployRequestsService{
-		ListFn: func(ctx context.Context, req *ps.ListDeployRequestsRequest) ([]*ps.DeployRequest, error) {
+		ListFn: func(ctx context.Context, req *ps.ListDeployRequestsRequest, opts ...ps.ListOption) ([]*ps.DeployRequest, error) {
 			c.Assert(req.Organization, qt.Equals, org)
 			c.Assert(req.Database, qt.Equals, db)
 
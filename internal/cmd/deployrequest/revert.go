@@ -57,6 +57,7 @@ func RevertCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toDeployRequest(dr))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &planetscale.DeployRequest{})
 
 	return cmd
 }
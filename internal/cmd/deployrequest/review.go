@@ -84,6 +84,7 @@ func ReviewCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.PersistentFlags().BoolVar(&flags.approve, "approve", false, "Approve a deploy request")
 	cmd.PersistentFlags().StringVar(&flags.comment, "comment", "", "Comment on a deploy request")
+	cmdutil.SetJSONOutput(cmd, &planetscale.DeployRequestReview{})
 
 	return cmd
 }
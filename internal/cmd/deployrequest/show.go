@@ -68,6 +68,8 @@ func ShowCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.PersistentFlags().BoolVar(&flags.web, "web", false, "Open in your web browser")
+	cmdutil.SetJSONOutput(cmd, &planetscale.DeployRequest{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -83,7 +83,7 @@ func TestDeployRequest_ShowBranchName(t *testing.T) {
 
 			return &ps.DeployRequest{Number: number}, nil
 		},
-		ListFn: func(ctx context.Context, req *ps.ListDeployRequestsRequest) ([]*ps.DeployRequest, error) {
+		ListFn: func(ctx context.Context, req *ps.ListDeployRequestsRequest, opts ...ps.ListOption) ([]*ps.DeployRequest, error) {
 			c.Assert(req.Organization, qt.Equals, org)
 			c.Assert(req.Database, qt.Equals, db)
 			c.Assert(req.Branch, qt.Equals, branchName)
@@ -0,0 +1,92 @@
+package dev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/devserver"
+	"github.com/planetscale/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+// APIServerCmd starts an in-memory emulator of the PlanetScale API.
+func APIServerCmd(ch *cmdutil.Helper) *cobra.Command {
+	var flags struct {
+		host string
+		port string
+	}
+
+	cmd := &cobra.Command{
+		Use:   "api-server",
+		Short: "Run an in-memory emulator of the PlanetScale API",
+		Long: `Run an in-memory emulator of the PlanetScale API, for testing scripts and
+tools that use pscale without a PlanetScale organization.
+
+The emulator supports MySQL databases, branches, deploy requests, passwords,
+backups and webhooks. Organizations exist as soon as they're used and any API
+token is accepted. Branches, backups and deployments are ready immediately,
+and deploy requests go through the same states as on PlanetScale. Passwords
+don't give access to a database. The state is lost when the server stops.
+
+Point pscale at the emulator with --api-url, or with the PLANETSCALE_API_URL
+environment variable.`,
+		Example: `  pscale dev api-server --port 8080 &
+  export PLANETSCALE_API_URL=http://127.0.0.1:8080 PLANETSCALE_API_TOKEN=dev
+  pscale database create my-db --org acme
+  pscale branch create my-db feature --org acme`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			ln, err := net.Listen("tcp", net.JoinHostPort(flags.host, flags.port))
+			if err != nil {
+				return err
+			}
+
+			srv := &http.Server{
+				Handler:           devserver.New(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			errc := make(chan error, 1)
+			go func() {
+				errc <- srv.Serve(ln)
+			}()
+
+			url := "http://" + ln.Addr().String()
+			if ch.Printer.Format() == printer.Human {
+				ch.Printer.Printf("Serving the PlanetScale API emulator at %s\n", printer.BoldBlue(url))
+				ch.Printer.Printf("Use it with %s, press Ctrl-C to stop.\n", printer.Bold(fmt.Sprintf("--api-url %s --api-token dev", url)))
+			} else if err := ch.Printer.PrintResource(&apiServer{URL: url}); err != nil {
+				return err
+			}
+
+			select {
+			case err := <-errc:
+				return err
+			case <-ctx.Done():
+			}
+
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.host, "host", "127.0.0.1", "Local host to bind and listen for requests")
+	cmd.Flags().StringVar(&flags.port, "port", "8080", "Local port to bind and listen for requests, 0 for a random one")
+	cmdutil.SetJSONOutput(cmd, &apiServer{})
+
+	return cmd
+}
+
+type apiServer struct {
+	URL string `header:"url" json:"url"`
+}
@@ -0,0 +1,19 @@
+package dev
+
+import (
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+// DevCmd encapsulates the commands for developing against PlanetScale
+// locally.
+func DevCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev <command>",
+		Short: "Tools for developing and testing against PlanetScale locally",
+	}
+
+	cmd.AddCommand(APIServerCmd(ch))
+
+	return cmd
+}
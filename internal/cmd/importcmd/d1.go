@@ -36,10 +36,14 @@ func writeD1(ch *cmdutil.Helper, resp d1.Response) error {
 			if err := ch.Printer.PrintJSON(resp); err != nil {
 				return err
 			}
+		case printer.NDJSON:
+			if err := ch.Printer.PrintResource(resp); err != nil {
+				return err
+			}
 		case printer.Human:
 			d1.PrintHumanResponse(ch.Printer, resp)
 		default:
-			return fmt.Errorf(`import d1 does not support output format %q (use human or json)`, ch.Printer.Format())
+			return fmt.Errorf(`import d1 does not support output format %q (use human, json or ndjson)`, ch.Printer.Format())
 		}
 		return d1CommandError(resp)
 	}
@@ -47,11 +51,13 @@ func writeD1(ch *cmdutil.Helper, resp d1.Response) error {
 	switch ch.Printer.Format() {
 	case printer.JSON:
 		return ch.Printer.PrintJSON(resp)
+	case printer.NDJSON:
+		return ch.Printer.PrintResource(resp)
 	case printer.Human:
 		d1.PrintHumanResponse(ch.Printer, resp)
 		return nil
 	default:
-		return fmt.Errorf(`import d1 does not support output format %q (use human or json)`, ch.Printer.Format())
+		return fmt.Errorf(`import d1 does not support output format %q (use human, json or ndjson)`, ch.Printer.Format())
 	}
 }
 
@@ -19,6 +19,8 @@ func d1DoctorCmd(ch *cmdutil.Helper) *cobra.Command {
 			return writeD1(ch, d1.DoctorResponse(result))
 		},
 	}
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
 
 	return cmd
 }
@@ -28,5 +28,7 @@ func d1LintCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.Flags().StringVar(&flags.input, "input", "", "Path to D1 SQL export")
 	cmd.MarkFlagRequired("input")
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
 	return cmd
 }
@@ -19,16 +19,19 @@ type progressReporter struct {
 	printer  *printer.Printer
 	handle   *printer.ProgressHandle
 	jsonMode bool
-	phase    string
+	// events reports progress as progress events of the ndjson format.
+	events bool
+	phase  string
 }
 
 func newImportProgressReporter(ch *cmdutil.Helper, tableCount int, sizeBytes int64) *progressReporter {
 	r := &progressReporter{
 		printer:  ch.Printer,
 		jsonMode: ch.Printer.Format() == printer.JSON,
+		events:   ch.Printer.Format() == printer.NDJSON,
 		phase:    progressPhaseImport,
 	}
-	if r.jsonMode {
+	if r.jsonMode || r.events {
 		return r
 	}
 	msg := "Importing D1 export"
@@ -49,9 +52,10 @@ func newVerifyProgressReporter(ch *cmdutil.Helper, tableCount int) *progressRepo
 	r := &progressReporter{
 		printer:  ch.Printer,
 		jsonMode: ch.Printer.Format() == printer.JSON,
+		events:   ch.Printer.Format() == printer.NDJSON,
 		phase:    progressPhaseVerify,
 	}
-	if r.jsonMode {
+	if r.jsonMode || r.events {
 		return r
 	}
 	msg := "Verifying D1 import..."
@@ -74,6 +78,16 @@ func (r *progressReporter) Report(p d1.ImportProgress) {
 		r.writeJSON(p, msg)
 		return
 	}
+	if r.events {
+		r.printer.PrintEvent(&printer.Event{ // nolint:errcheck
+			Type:    printer.EventProgress,
+			State:   r.phase + ":" + p.Stage,
+			Message: msg,
+			Current: int64(p.Current),
+			Total:   int64(p.Total),
+		})
+		return
+	}
 	if r.handle != nil {
 		r.handle.Update(msg)
 		return
@@ -29,5 +29,6 @@ func d1StatusCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.Flags().StringVar(&flags.migrationID, "migration-id", "", "Migration ID")
 	cmd.MarkFlagRequired("migration-id")
+	cmdutil.SetReadOnly(cmd)
 	return cmd
 }
@@ -54,8 +54,11 @@ func AnomaliesCmd(ch *cmdutil.Helper) *cobra.Command {
 				return nil
 			}
 
-			if ch.Printer.Format() == printer.JSON {
+			switch ch.Printer.Format() {
+			case printer.JSON:
 				return ch.Printer.PrintJSON(anomalies)
+			case printer.YAML:
+				return ch.Printer.PrintYAML(anomalies)
 			}
 
 			rows := make([]*AnomalyRow, 0, len(anomalies))
@@ -75,6 +78,8 @@ func AnomaliesCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(rows)
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*ps.Anomaly{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -58,8 +58,11 @@ func ErrorsCmd(ch *cmdutil.Helper) *cobra.Command {
 				return nil
 			}
 
-			if ch.Printer.Format() == printer.JSON {
+			switch ch.Printer.Format() {
+			case printer.JSON:
 				return ch.Printer.PrintJSON(errs)
+			case printer.YAML:
+				return ch.Printer.PrintYAML(errs)
 			}
 
 			rows := make([]*ErrorRow, 0, len(errs))
@@ -77,6 +80,8 @@ func ErrorsCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.Flags().IntVar(&flags.limit, "limit", 15, "Number of errors to return")
 	cmd.Flags().StringVar(&flags.period, "period", "", "Time period to aggregate over (e.g. 1h, 24h)")
+	cmdutil.SetJSONOutput(cmd, []*ps.QueryInsightError{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -101,8 +101,11 @@ func QueriesCmd(ch *cmdutil.Helper) *cobra.Command {
 				return nil
 			}
 
-			if ch.Printer.Format() == printer.JSON {
+			switch ch.Printer.Format() {
+			case printer.JSON:
 				return ch.Printer.PrintJSON(insights)
+			case printer.YAML:
+				return ch.Printer.PrintYAML(insights)
 			}
 			return ch.Printer.PrintResource(toQueryRows(insights))
 		},
@@ -113,6 +116,8 @@ func QueriesCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().StringVar(&flags.dir, "dir", "desc", "Sort direction: asc or desc")
 	cmd.Flags().IntVar(&flags.limit, "limit", 15, "Number of queries to return")
 	cmd.Flags().StringVar(&flags.period, "period", "", "Time period to aggregate over (e.g. 1h, 24h)")
+	cmdutil.SetJSONOutput(cmd, []*ps.QueryInsight{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
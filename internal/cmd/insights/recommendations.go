@@ -59,8 +59,11 @@ recommendation includes ready-to-apply DDL (shown with --format json).`,
 				return nil
 			}
 
-			if ch.Printer.Format() == printer.JSON {
+			switch ch.Printer.Format() {
+			case printer.JSON:
 				return ch.Printer.PrintJSON(recommendations)
+			case printer.YAML:
+				return ch.Printer.PrintYAML(recommendations)
 			}
 
 			rows := make([]*RecommendationRow, 0, len(recommendations))
@@ -77,6 +80,8 @@ recommendation includes ready-to-apply DDL (shown with --format json).`,
 			return ch.Printer.PrintResource(rows)
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*ps.SchemaRecommendation{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
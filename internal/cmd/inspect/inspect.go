@@ -126,7 +126,7 @@ func substituteResourceNames(s, database, branch string) string {
 }
 
 func checkCmd(ch *cmdutil.Helper, c check, flags *inspectFlags) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   c.Name + " <database> <branch>",
 		Short: c.Short,
 		Args:  cmdutil.RequiredArgs("database", "branch"),
@@ -165,10 +165,14 @@ func checkCmd(ch *cmdutil.Helper, c check, flags *inspectFlags) *cobra.Command {
 			return nil
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &CheckResult{})
+	cmdutil.SetReadOnly(cmd)
+
+	return cmd
 }
 
 func allCmd(ch *cmdutil.Helper, flags *inspectFlags) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "all <database> <branch>",
 		Short: "Run every applicable check and print a combined report",
 		Args:  cmdutil.RequiredArgs("database", "branch"),
@@ -237,11 +241,19 @@ func allCmd(ch *cmdutil.Helper, flags *inspectFlags) *cobra.Command {
 				return nil
 			case printer.JSON:
 				return ch.Printer.PrintJSON(report)
+			case printer.YAML:
+				return ch.Printer.PrintYAML(report)
+			case printer.Markdown:
+				return printMarkdownReport(ch.Printer.ResourceOutput(), report)
 			default:
-				return fmt.Errorf("csv output is not supported for inspect all; use a single check or --format json")
+				return fmt.Errorf("%s output is not supported for inspect all; use a single check or --format json", ch.Printer.Format())
 			}
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &Report{})
+	cmdutil.SetReadOnly(cmd)
+
+	return cmd
 }
 
 // printNextSteps renders a check's follow-up commands in human output. The
@@ -343,6 +355,14 @@ func printCheck(ch *cmdutil.Helper, c check, result *CheckResult) error {
 		return ch.Printer.PrintJSON(result)
 	case printer.CSV:
 		return printCSV(ch.Printer.ResourceOutput(), result)
+	case printer.YAML:
+		return ch.Printer.PrintYAML(result)
+	case printer.Markdown:
+		out := ch.Printer.ResourceOutput()
+		if err := printMarkdown(out, c, result); err != nil {
+			return err
+		}
+		return printMarkdownNextSteps(out, "For server-side analysis of production traffic, also see:", result.NextSteps)
 	default:
 		printHumanTable(ch, c, result)
 		return nil
@@ -402,6 +422,66 @@ func printCSV(out io.Writer, result *CheckResult) error {
 	return w.Error()
 }
 
+// printMarkdown prints the rows of a check as a Markdown table, or why there
+// are none.
+func printMarkdown(out io.Writer, c check, result *CheckResult) error {
+	switch {
+	case result.Skipped != "":
+		_, err := fmt.Fprintln(out, result.Skipped)
+		return err
+	case len(result.Rows) == 0:
+		_, err := fmt.Fprintln(out, c.EmptyMessage)
+		return err
+	}
+
+	rows := make([][]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		values := make([]string, 0, len(result.Columns))
+		for _, col := range result.Columns {
+			values = append(values, formatValue(row[col]))
+		}
+		rows = append(rows, values)
+	}
+	return printer.MarkdownTable(out, result.Columns, rows)
+}
+
+// printMarkdownReport prints the combined report of inspect all as a section
+// per check, for pasting into incident docs and pull requests.
+func printMarkdownReport(out io.Writer, report *Report) error {
+	// inspect all runs every check, in order.
+	for i, result := range report.Results {
+		c := checks[i]
+		if _, err := fmt.Fprintf(out, "### %s — %s\n\n", c.Name, c.Short); err != nil {
+			return err
+		}
+		if err := printMarkdown(out, c, result); err != nil {
+			return err
+		}
+		if result.Skipped != "" || result.RowCount > 0 {
+			if err := printMarkdownNextSteps(out, "Also see:", result.NextSteps); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(out); err != nil {
+			return err
+		}
+	}
+	return printMarkdownNextSteps(out, "These checks are point-in-time. For server-side analysis of production traffic, also run:", report.NextSteps)
+}
+
+func printMarkdownNextSteps(out io.Writer, title string, steps []string) error {
+	if len(steps) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n%s\n\n", title)
+	for _, step := range steps {
+		fmt.Fprintf(&b, "- `%s`\n", step)
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
 func formatValue(v any) string {
 	if v == nil {
 		return ""
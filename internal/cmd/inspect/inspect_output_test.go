@@ -37,3 +37,32 @@ func TestPrintCSVExplainsSkippedCheck(t *testing.T) {
 		},
 	})
 }
+
+func TestPrintMarkdownReport(t *testing.T) {
+	c := qt.New(t)
+
+	results := make([]*CheckResult, len(checks))
+	for i, check := range checks {
+		results[i] = &CheckResult{Check: check.Name, Database: "mydb", Branch: "main"}
+	}
+	results[0].Columns = []string{"table_name", "rows"}
+	results[0].Rows = []map[string]any{{"table_name": "users", "rows": int64(12)}}
+	results[0].RowCount = 1
+	results[0].NextSteps = []string{"pscale insights queries mydb main --org myorg --format json"}
+
+	var out bytes.Buffer
+	err := printMarkdownReport(&out, &Report{
+		Database:  "mydb",
+		Branch:    "main",
+		Results:   results,
+		NextSteps: []string{"pscale insights errors mydb main --org myorg --format json"},
+	})
+	c.Assert(err, qt.IsNil)
+
+	got := out.String()
+	c.Assert(got, qt.Contains, "### "+checks[0].Name+" — "+checks[0].Short+"\n\n"+
+		"| table_name | rows |\n| --- | --- |\n| users | 12 |\n\n"+
+		"Also see:\n\n- `pscale insights queries mydb main --org myorg --format json`\n")
+	c.Assert(got, qt.Contains, "### "+checks[1].Name+" — "+checks[1].Short+"\n\n"+checks[1].EmptyMessage+"\n\n###")
+	c.Assert(strings.HasSuffix(got, "- `pscale insights errors mydb main --org myorg --format json`\n"), qt.IsTrue)
+}
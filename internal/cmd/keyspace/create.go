@@ -92,6 +92,7 @@ func CreateCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc("cluster-size", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
 		return cmdutil.BranchClusterSizesCompletionFunc(ch, cmd, args, toComplete)
 	})
+	cmdutil.SetJSONOutput(cmd, &planetscale.Keyspace{})
 
 	return cmd
 }
@@ -102,6 +103,7 @@ func waitUntilReady(ctx context.Context, client *planetscale.Client, printer *pr
 
 	ticker := time.NewTicker(time.Second)
 
+	var state string
 	for {
 		select {
 		case <-ctx.Done():
@@ -116,8 +118,10 @@ func waitUntilReady(ctx context.Context, client *planetscale.Client, printer *pr
 			}
 
 			if resp.Ready {
+				printer.PrintState(state, "ready")
 				return nil
 			}
+			state = printer.PrintState(state, "pending")
 		}
 	}
 }
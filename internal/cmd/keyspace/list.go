@@ -45,6 +45,8 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toKeyspaces(keyspaces))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*planetscale.Keyspace{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -59,6 +59,8 @@ func ReadOnlyRegionsCmd(ch *cmdutil.Helper) *cobra.Command {
 		ReadOnlyRegionsUpdateCmd(ch),
 		ReadOnlyRegionsRemoveCmd(ch),
 	)
+	cmdutil.SetJSONOutput(cmd, []*ps.ReadOnlyRegionKeyspace{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -59,6 +59,7 @@ func ReadOnlyRegionsAddCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc("cluster-size", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
 		return cmdutil.BranchClusterSizesCompletionFunc(ch, cmd, args, toComplete)
 	})
+	cmdutil.SetJSONOutput(cmd, []*ps.ReadOnlyRegionKeyspace{})
 
 	return cmd
 }
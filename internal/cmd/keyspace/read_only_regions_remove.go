@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/planetscale/cli/internal/cmdutil"
+	ps "github.com/planetscale/cli/internal/planetscale"
 	"github.com/planetscale/cli/internal/printer"
 	"github.com/spf13/cobra"
 )
@@ -42,6 +43,7 @@ func ReadOnlyRegionsRemoveCmd(ch *cmdutil.Helper) *cobra.Command {
 			return printReadOnlyRegionMutation(ch, fmt.Sprintf("Removed read-only region %s from keyspace %s.", printer.BoldBlue(region), printer.BoldBlue(keyspace)), regions)
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*ps.ReadOnlyRegionKeyspace{})
 
 	return cmd
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/planetscale/cli/internal/cmdutil"
+	ps "github.com/planetscale/cli/internal/planetscale"
 	"github.com/planetscale/cli/internal/printer"
 	"github.com/spf13/cobra"
 )
@@ -63,6 +64,7 @@ func ReadOnlyRegionsUpdateCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc("cluster-size", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
 		return cmdutil.BranchClusterSizesCompletionFunc(ch, cmd, args, toComplete)
 	})
+	cmdutil.SetJSONOutput(cmd, []*ps.ReadOnlyRegionKeyspace{})
 
 	return cmd
 }
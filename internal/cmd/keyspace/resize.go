@@ -97,6 +97,7 @@ func ResizeCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.AddCommand(ResizeStatusCmd(ch))
 	cmd.AddCommand(ResizeCancelCmd(ch))
+	cmdutil.SetJSONOutput(cmd, &ps.KeyspaceResizeRequest{})
 
 	return cmd
 }
@@ -47,6 +47,8 @@ func ResizeStatusCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toKeyspaceResizeRequest(krr))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.KeyspaceResizeRequest{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -47,6 +47,8 @@ func RolloutStatusCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toShardRollouts(k))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*ps.ShardRollout{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
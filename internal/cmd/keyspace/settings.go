@@ -46,6 +46,8 @@ func SettingsCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toKeyspaceSettings(ks))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.Keyspace{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
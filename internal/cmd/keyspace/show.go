@@ -45,6 +45,8 @@ func ShowCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toKeyspace(k))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &planetscale.Keyspace{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
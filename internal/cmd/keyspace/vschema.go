@@ -26,7 +26,7 @@ func VSchemaCmd(ch *cmdutil.Helper) *cobra.Command {
 
 // ShowVSchemaCmd is the command for showing a keyspace's VSchema.
 func ShowVSchemaCmd(ch *cmdutil.Helper) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "show <database> <branch> <keyspace>",
 		Short: "Show the VSchema of a keyspace in a branch",
 		Args:  cmdutil.RequiredArgs("database", "branch", "keyspace"),
@@ -67,6 +67,10 @@ func ShowVSchemaCmd(ch *cmdutil.Helper) *cobra.Command {
 			return nil
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &planetscale.VSchema{})
+	cmdutil.SetReadOnly(cmd)
+
+	return cmd
 }
 
 // UpdateVSchemaCmd is the command for updating a keyspace's VSchema.
@@ -145,6 +149,7 @@ func UpdateVSchemaCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&flags.vschema, "vschema", "", "The path to the VSchema file")
+	cmdutil.SetJSONOutput(cmd, &planetscale.VSchema{})
 
 	return cmd
 }
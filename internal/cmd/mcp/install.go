@@ -103,8 +103,8 @@ MCP setup instructions instead of installing the deprecated local stdio server.`
 }
 
 func printInstallResponse(ch *cmdutil.Helper, resp installResponse) error {
-	if ch.Printer.Format() == printer.JSON {
-		if err := ch.Printer.PrintJSON(resp); err != nil {
+	if ch.Printer.Format() != printer.Human {
+		if err := ch.Printer.PrintResource(resp); err != nil {
 			return err
 		}
 		if resp.Status == "action_required" {
@@ -35,6 +35,8 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toOrgs(orgs, currentOrgName, ch.Printer.Format()))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*organization{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
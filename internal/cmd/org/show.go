@@ -63,6 +63,8 @@ func ShowCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(map[string]string{"org": cfg.Organization})
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
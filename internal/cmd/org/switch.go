@@ -136,6 +136,10 @@ func SwitchCmd(ch *cmdutil.Helper) *cobra.Command {
 				fileCfg = &config.FileConfig{
 					Organization: organization,
 				}
+			} else if p := profileToSwitch(ch, fileCfg, filePath); p != nil {
+				// Under a named profile the global config holds the
+				// profile's organization.
+				p.Organization = organization
 			} else {
 				fileCfg.Organization = organization
 				// TODO(fatih): check whether the branch/database exists for
@@ -162,3 +166,19 @@ func SwitchCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	return cmd
 }
+
+// profileToSwitch returns the active named profile when path is the global
+// config file it's defined in.
+func profileToSwitch(ch *cmdutil.Helper, fileCfg *config.FileConfig, path string) *config.Profile {
+	if ch.Config == nil || ch.Config.Profile == "" || ch.Config.Profile == config.DefaultProfile {
+		return nil
+	}
+	if defaultPath, err := config.DefaultConfigPath(); err != nil || path != defaultPath {
+		return nil
+	}
+	p, err := fileCfg.LookupProfile(ch.Config.Profile)
+	if err != nil {
+		return nil
+	}
+	return p
+}
@@ -121,6 +121,7 @@ func CreateCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().BoolVar(&flags.replica, "replica", false, "When enabled, the password will route all reads to the branch's primary replicas and all read-only regions.")
 	cmd.Flags().StringVar(&flags.readOnlyRegion, "read-only-region", "",
 		"Create a password scoped to a Vitess read-only region (region slug, display name, or id). List regions with: pscale keyspace read-only-regions <database> <branch> <keyspace>.")
+	cmdutil.SetJSONOutput(cmd, &ps.DatabaseBranchPassword{})
 
 	return cmd
 }
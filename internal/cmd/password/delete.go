@@ -183,5 +183,7 @@ func DeleteCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.Flags().BoolVar(&force, "force", false, "Delete a password without confirmation")
 	cmd.Flags().StringVar(&name, "name", "", "Delete password by name instead of ID")
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
+
 	return cmd
 }
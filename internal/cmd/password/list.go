@@ -1,6 +1,7 @@
 package password
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/planetscale/cli/internal/cmdutil"
@@ -18,6 +19,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 		perPage int
 		name    string
 		status  string
+		all     bool
 	}
 
 	cmd := &cobra.Command{
@@ -59,17 +61,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			end := ch.Printer.PrintProgress(fmt.Sprintf("Fetching passwords for %s", forMsg))
 			defer end()
 
-			passwords, err := client.Passwords.List(ctx, &planetscale.ListDatabaseBranchPasswordRequest{
-				Organization: ch.Config.Organization,
-				Database:     database,
-				Branch:       branch,
-			},
-				planetscale.WithSearch(flags.name),
-				planetscale.WithStatus(flags.status),
-				planetscale.WithPage(flags.page),
-				planetscale.WithPerPage(flags.perPage),
-			)
-			if err != nil {
+			handleErr := func(err error) error {
 				switch cmdutil.ErrCode(err) {
 				case planetscale.ErrNotFound:
 					return fmt.Errorf("branch %s does not exist in database %s (organization: %s)",
@@ -79,9 +71,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 				}
 			}
 
-			end()
-
-			if len(passwords) == 0 && ch.Printer.Format() == printer.Human {
+			printEmpty := func() {
 				if flags.page > 0 {
 					ch.Printer.Println("No passwords found on this page.")
 				} else if flags.name != "" || flags.status != "" {
@@ -89,16 +79,53 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 				} else {
 					ch.Printer.Printf("No passwords exist in %s.\n", forMsg)
 				}
-				return nil
 			}
 
 			// if we're doing human display and none of our passwords are ephemeral
 			// we can hide a few of the columns for a more compact view.
-			if ch.Printer.Format() == printer.Human && !hasEphemeral(passwords) {
-				return ch.Printer.PrintResource(toPasswordsWithoutTTL(passwords))
+			toResource := func(passwords []*planetscale.DatabaseBranchPassword) any {
+				if ch.Printer.Format() == printer.Human && !hasEphemeral(passwords) {
+					return toPasswordsWithoutTTL(passwords)
+				}
+				return toPasswords(passwords)
+			}
+
+			listPasswords := func(ctx context.Context, opts ...planetscale.ListOption) ([]*planetscale.DatabaseBranchPassword, error) {
+				opts = append([]planetscale.ListOption{
+					planetscale.WithSearch(flags.name),
+					planetscale.WithStatus(flags.status),
+				}, opts...)
+				return client.Passwords.List(ctx, &planetscale.ListDatabaseBranchPasswordRequest{
+					Organization: ch.Config.Organization,
+					Database:     database,
+					Branch:       branch,
+				}, opts...)
+			}
+
+			if flags.all {
+				n, err := cmdutil.PrintPages(ch.Printer, planetscale.Pages(ctx, flags.perPage, listPasswords), end, toResource)
+				if err != nil {
+					return handleErr(err)
+				}
+				if n == 0 && ch.Printer.Format() == printer.Human {
+					printEmpty()
+				}
+				return nil
+			}
+
+			passwords, err := listPasswords(ctx, planetscale.WithPage(flags.page), planetscale.WithPerPage(flags.perPage))
+			if err != nil {
+				return handleErr(err)
+			}
+
+			end()
+
+			if len(passwords) == 0 && ch.Printer.Format() == printer.Human {
+				printEmpty()
+				return nil
 			}
 
-			return ch.Printer.PrintResource(toPasswords(passwords))
+			return ch.Printer.PrintResource(toResource(passwords))
 		},
 	}
 
@@ -107,8 +134,12 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().StringVar(&flags.status, "status", "", "Filter passwords by status (active, renewable, or expired)")
 	cmd.Flags().IntVar(&flags.page, "page", 0, "Page number to fetch")
 	cmd.Flags().IntVar(&flags.perPage, "per-page", 100, "Number of results per page")
+	cmdutil.AllPagesFlag(cmd, &flags.all)
 	cmd.RegisterFlagCompletionFunc("status", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return []string{"active", "renewable", "expired"}, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmdutil.SetJSONOutput(cmd, []*planetscale.DatabaseBranchPassword{})
+	cmdutil.SetReadOnly(cmd)
+
 	return cmd
 }
@@ -65,5 +65,7 @@ func RenewCmd(ch *cmdutil.Helper) *cobra.Command {
 			)
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
+
 	return cmd
 }
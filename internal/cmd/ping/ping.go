@@ -130,6 +130,8 @@ func PingCmd(ch *cmdutil.Helper) *cobra.Command {
 		// change. Hardcode to enable easier completion.
 		return []string{"aws", "gcp"}, cobra.ShellCompDirectiveDefault
 	})
+	cmdutil.SetJSONOutput(cmd, []*Result{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
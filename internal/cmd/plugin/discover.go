@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Prefix starts the file name of every plugin.
+const Prefix = "pscale-"
+
+// plugin is an executable called pscale-<name> found on PATH.
+type plugin struct {
+	Name string
+	Path string
+}
+
+// discover returns the plugins in the directories of path, a list like
+// $PATH. Like the shell, the first plugin with a given name wins and the
+// others are returned as shadowed.
+func discover(path string) (found, shadowed []*plugin) {
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "."
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, e := range entries {
+			name, ok := pluginName(e.Name())
+			if !ok {
+				continue
+			}
+			p := filepath.Join(dir, e.Name())
+			if !isExecutable(p) {
+				continue
+			}
+
+			if seen[name] {
+				shadowed = append(shadowed, &plugin{Name: name, Path: p})
+				continue
+			}
+			seen[name] = true
+			found = append(found, &plugin{Name: name, Path: p})
+		}
+	}
+	return found, shadowed
+}
+
+// pluginName returns the plugin name of the file called base.
+func pluginName(base string) (string, bool) {
+	name, ok := strings.CutPrefix(base, Prefix)
+	if !ok {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(name)
+		if !windowsExecutable(ext) {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name, name != ""
+}
+
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil || fi.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return fi.Mode()&0o111 != 0
+}
+
+func windowsExecutable(ext string) bool {
+	pathext := os.Getenv("PATHEXT")
+	if pathext == "" {
+		pathext = ".com;.exe;.bat;.cmd"
+	}
+	for _, e := range filepath.SplitList(pathext) {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
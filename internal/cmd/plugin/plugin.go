@@ -0,0 +1,350 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	ps "github.com/planetscale/cli/internal/planetscale"
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// defaultTokenTTL is the lifetime of scoped tokens in seconds.
+const defaultTokenTTL = 3600
+
+// PluginCmd encapsulates the commands for working with plugins.
+func PluginCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin <command>",
+		Short: "List external pscale-<name> commands",
+		Long: `List external pscale-<name> commands.
+
+Running "pscale <name>" for a name that's not a pscale command runs the
+executable pscale-<name> found on PATH with the remaining arguments. The
+plugin gets the resolved settings as environment variables, so it can call
+the API or pscale itself:
+
+  PLANETSCALE_ORG               the organization
+  PLANETSCALE_API_URL           the API URL
+  PLANETSCALE_FORMAT            the output format
+  PLANETSCALE_API_TOKEN         the access token, or else
+  PLANETSCALE_SERVICE_TOKEN_ID  the service token
+  PLANETSCALE_SERVICE_TOKEN
+  PSCALE_EXECUTABLE             the path of pscale
+
+Instead of the user's credentials a plugin can get a short-lived service
+token limited to some accesses, created before it runs and deleted after:
+
+  plugins:
+    deploy-bot:
+      scoped-token:
+        ttl: 900
+        database: shop
+        accesses: [read_branch, create_deploy_request]`,
+	}
+
+	cmd.AddCommand(ListCmd(ch))
+
+	return cmd
+}
+
+// pluginInfo returns a table-serializable plugin model.
+type pluginInfo struct {
+	Name   string `header:"name" json:"name"`
+	Path   string `header:"path" json:"path"`
+	Status string `header:"status" json:"status"`
+}
+
+// ListCmd lists the plugins found on PATH.
+func ListCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the plugins found on PATH",
+		Args:    cobra.NoArgs,
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			found, shadowed := discover(os.Getenv("PATH"))
+
+			infos := make([]*pluginInfo, 0, len(found)+len(shadowed))
+			winner := map[string]string{}
+			for _, p := range found {
+				status := "ok"
+				if cmdutil.IsBuiltinCommand(cmd.Root(), p.Name) {
+					status = "ignored, shadowed by the built-in command"
+				}
+				winner[p.Name] = p.Path
+				infos = append(infos, &pluginInfo{Name: p.Name, Path: p.Path, Status: status})
+			}
+			for _, p := range shadowed {
+				infos = append(infos, &pluginInfo{
+					Name:   p.Name,
+					Path:   p.Path,
+					Status: "ignored, shadowed by " + winner[p.Name],
+				})
+			}
+
+			if len(infos) == 0 && ch.Printer.Format() == printer.Human {
+				ch.Printer.Printf("No plugins found, plugins are executables called %s<name> on PATH\n", Prefix)
+				return nil
+			}
+
+			return ch.Printer.PrintResource(infos)
+		},
+	}
+	cmdutil.SetJSONOutput(cmd, []*pluginInfo{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
+}
+
+// Register adds a command running the plugin args invoke to root and returns
+// the args to execute root with. It does nothing when args invoke a built-in
+// command or no plugin exists. The plugin command passes its arguments to the
+// plugin unparsed, so the pscale flags before the plugin name are parsed here
+// and dropped from the returned args.
+func Register(root *cobra.Command, ch *cmdutil.Helper, args []string) []string {
+	i := cmdutil.ArgIndex(pluginFlags(root, pluginOrgFlags(new(string))), args)
+	if i < 0 {
+		return args
+	}
+	name := args[i]
+	if strings.ContainsAny(name, `/\`) || cmdutil.IsBuiltinCommand(root, name) {
+		return args
+	}
+
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		return args
+	}
+
+	cmd := newPluginCmd(ch, name, path)
+	flags := pluginFlags(root, cmd.Flags())
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args[:i]); err != nil {
+		// Leave the error to cobra.
+		return args
+	}
+	root.AddCommand(cmd)
+	return args[i:]
+}
+
+// pluginFlags returns the flags accepted before a plugin name: the global
+// flags of root and the plugin command's own.
+func pluginFlags(root *cobra.Command, own *pflag.FlagSet) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(Prefix+"plugin", pflag.ContinueOnError)
+	flags.AddFlagSet(root.PersistentFlags())
+	flags.AddFlagSet(own)
+	return flags
+}
+
+// pluginOrgFlags returns the flags of a plugin command, with --org set in
+// org.
+func pluginOrgFlags(org *string) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(Prefix+"plugin", pflag.ContinueOnError)
+	flags.StringVar(org, "org", *org, "The organization for the current user")
+	return flags
+}
+
+func newPluginCmd(ch *cmdutil.Helper, name, path string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                name,
+		Short:              fmt.Sprintf("Run the %s%s plugin", Prefix, name),
+		Hidden:             true,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd, ch, name, path, args)
+		},
+	}
+
+	// Filled in from the configuration or given before the plugin name, the
+	// plugin parses the flags after its name itself.
+	cmd.Flags().AddFlagSet(pluginOrgFlags(&ch.Config.Organization))
+
+	return cmd
+}
+
+// run runs the plugin at path and returns its exit code as error.
+func run(cmd *cobra.Command, ch *cmdutil.Helper, name, path string, args []string) error {
+	ctx := cmd.Context()
+	cfg := ch.Config
+
+	vars := map[string]string{
+		"PLANETSCALE_ORG":     cfg.Organization,
+		"PLANETSCALE_API_URL": cfg.BaseURL,
+		"PLANETSCALE_FORMAT":  ch.Printer.Format().String(),
+	}
+	if exe, err := os.Executable(); err == nil {
+		vars["PSCALE_EXECUTABLE"] = exe
+	}
+
+	scoped, err := scopedToken(cmd, ch, name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case scoped != nil:
+		token, cleanup, err := createScopedToken(ctx, ch, name, scoped)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		vars["PLANETSCALE_SERVICE_TOKEN_ID"] = token.ID
+		vars["PLANETSCALE_SERVICE_TOKEN"] = token.Token
+	case cfg.ServiceTokenIsSet():
+		vars["PLANETSCALE_SERVICE_TOKEN_ID"] = cfg.ServiceTokenID
+		vars["PLANETSCALE_SERVICE_TOKEN"] = cfg.ServiceToken
+	default:
+		vars["PLANETSCALE_API_TOKEN"] = cfg.AccessToken
+	}
+
+	c := exec.Command(path, args...)
+	c.Stdin = cmd.InOrStdin()
+	c.Stdout = cmd.OutOrStdout()
+	c.Stderr = cmd.ErrOrStderr()
+	c.Env = pluginEnv(os.Environ(), vars)
+
+	err = c.Run()
+	if exitErr, ok := errors.AsType[*exec.ExitError](err); ok {
+		code := exitErr.ExitCode()
+		if code <= 0 {
+			code = cmdutil.FatalErrExitCode
+		}
+		return &cmdutil.Error{ExitCode: code, Handled: true}
+	}
+	if err != nil {
+		return fmt.Errorf("running plugin %s: %w", path, err)
+	}
+	return nil
+}
+
+// pluginEnv returns environ with the settings in vars. Credentials of the
+// environment are dropped so the plugin can't mix them up with vars.
+func pluginEnv(environ []string, vars map[string]string) []string {
+	drop := map[string]bool{
+		"PLANETSCALE_API_TOKEN":        true,
+		"PLANETSCALE_SERVICE_TOKEN_ID": true,
+		"PLANETSCALE_SERVICE_TOKEN":    true,
+	}
+	for k := range vars {
+		drop[k] = true
+	}
+
+	env := make([]string, 0, len(environ)+len(vars))
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		if !drop[k] {
+			env = append(env, kv)
+		}
+	}
+	for k, v := range vars {
+		if v != "" {
+			env = append(env, k+"="+v)
+		}
+	}
+	return env
+}
+
+// scopedToken returns the scoped token configuration of the plugin, or nil.
+func scopedToken(cmd *cobra.Command, ch *cmdutil.Helper, name string) (*config.ScopedToken, error) {
+	path, err := config.DefaultConfigPath()
+	if f := cmd.Flag("config"); f != nil && f.Value.String() != "" {
+		path, err = f.Value.String(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fileCfg, err := ch.ConfigFS.NewFileConfig(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p := fileCfg.Plugins[name]; p != nil {
+		return p.ScopedToken, nil
+	}
+	return nil, nil
+}
+
+// createScopedToken creates the service token for a run of the plugin and
+// returns a function deleting it.
+func createScopedToken(ctx context.Context, ch *cmdutil.Helper, name string, scoped *config.ScopedToken) (*ps.ServiceToken, func(), error) {
+	if err := ch.Config.IsAuthenticated(); err != nil {
+		return nil, nil, err
+	}
+	if ch.Config.ServiceTokenIsSet() {
+		return nil, nil, fmt.Errorf("plugin %s needs a scoped token, which can't be created when authenticated with a service token", name)
+	}
+	org := ch.Config.Organization
+	if org == "" {
+		return nil, nil, fmt.Errorf("plugin %s needs a scoped token, set an organization with 'pscale org switch'", name)
+	}
+
+	client, err := ch.Client()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ttl := scoped.TTL
+	if ttl <= 0 {
+		ttl = defaultTokenTTL
+	}
+	tokenName := Prefix + name
+	token, err := client.ServiceTokens.Create(ctx, &ps.CreateServiceTokenRequest{
+		Organization: org,
+		Name:         &tokenName,
+		TTL:          &ttl,
+	})
+	if err != nil {
+		return nil, nil, cmdutil.HandleError(err)
+	}
+
+	cleanup := func() {
+		// The plugin may have been interrupted, delete the token anyway.
+		err := client.ServiceTokens.Delete(context.WithoutCancel(ctx), &ps.DeleteServiceTokenRequest{
+			Organization: org,
+			ID:           token.ID,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: deleting the service token %s of plugin %s: %s\n", token.ID, name, err)
+		}
+	}
+
+	if len(scoped.Accesses) > 0 {
+		_, err = client.ServiceTokens.AddAccess(ctx, &ps.AddServiceTokenAccessRequest{
+			Organization: org,
+			ID:           token.ID,
+			Database:     scoped.Database,
+			Accesses:     scoped.Accesses,
+		})
+		if err != nil {
+			cleanup()
+			return nil, nil, cmdutil.HandleError(err)
+		}
+	}
+
+	return token, cleanup, nil
+}
+
+// CompletePlugins completes the names of the plugins found on PATH.
+func CompletePlugins(cmd *cobra.Command, toComplete string) []string {
+	found, _ := discover(os.Getenv("PATH"))
+	var completions []string
+	for _, p := range found {
+		if strings.HasPrefix(p.Name, toComplete) && !cmdutil.IsBuiltinCommand(cmd.Root(), p.Name) {
+			completions = append(completions, cobra.CompletionWithDesc(p.Name, "Plugin "+p.Path))
+		}
+	}
+	return completions
+}
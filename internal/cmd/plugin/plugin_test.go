@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/cobra"
+)
+
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+func writePlugin(c *qt.C, dir, name, script string) string {
+	path := filepath.Join(dir, name)
+	c.Assert(os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755), qt.IsNil)
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	c := qt.New(t)
+
+	a, b := c.TempDir(), c.TempDir()
+	helloA := writePlugin(c, a, "pscale-hello", "")
+	helloB := writePlugin(c, b, "pscale-hello", "")
+	deploy := writePlugin(c, b, "pscale-deploy", "")
+	c.Assert(os.WriteFile(filepath.Join(a, "pscale-notes"), nil, 0o644), qt.IsNil)
+	c.Assert(os.Mkdir(filepath.Join(a, "pscale-dir"), 0o755), qt.IsNil)
+	writePlugin(c, a, "other", "")
+
+	found, shadowed := discover(a + string(os.PathListSeparator) + b)
+	c.Assert(found, qt.DeepEquals, []*plugin{
+		{Name: "hello", Path: helloA},
+		{Name: "deploy", Path: deploy},
+	})
+	c.Assert(shadowed, qt.DeepEquals, []*plugin{{Name: "hello", Path: helloB}})
+}
+
+func TestPluginEnv(t *testing.T) {
+	c := qt.New(t)
+
+	env := pluginEnv([]string{
+		"HOME=/home/me",
+		"PLANETSCALE_ORG=old",
+		"PLANETSCALE_SERVICE_TOKEN=secret",
+	}, map[string]string{
+		"PLANETSCALE_ORG":       "acme",
+		"PLANETSCALE_API_TOKEN": "token",
+		"PLANETSCALE_FORMAT":    "",
+	})
+	c.Assert(env, qt.ContentEquals, []string{
+		"HOME=/home/me",
+		"PLANETSCALE_ORG=acme",
+		"PLANETSCALE_API_TOKEN=token",
+	})
+}
+
+func TestRegister(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	c := qt.New(t)
+
+	dir := c.TempDir()
+	writePlugin(c, dir, "pscale-hello", `echo "$PLANETSCALE_ORG $PLANETSCALE_FORMAT $PLANETSCALE_API_TOKEN $*"
+exit 3
+`)
+	writePlugin(c, dir, "pscale-list", "echo plugin")
+	c.Setenv("PATH", dir)
+
+	var buf bytes.Buffer
+	format := printer.JSON
+	p := printer.NewPrinter(&format)
+	p.SetResourceOutput(&buf)
+	p.SetHumanOutput(&buf)
+	ch := &cmdutil.Helper{
+		Printer:  p,
+		ConfigFS: config.NewConfigFS(osFS{}),
+		Config: &config.Config{
+			Organization: "acme",
+			AccessToken:  "token",
+			BaseURL:      "https://api.planetscale.com",
+		},
+	}
+
+	run := func(args ...string) error {
+		buf.Reset()
+		ch.Config.Organization = "acme"
+		root := &cobra.Command{Use: "pscale", SilenceErrors: true, SilenceUsage: true}
+		root.PersistentFlags().String("config", filepath.Join(dir, "pscale.yml"), "")
+		root.PersistentFlags().VarP(printer.NewFormatValue(printer.JSON, &format), "format", "f", "")
+		root.AddCommand(&cobra.Command{Use: "list", Run: func(cmd *cobra.Command, args []string) {
+			buf.WriteString("builtin\n")
+		}})
+		root.SetOut(&buf)
+		root.SetArgs(Register(root, ch, args))
+		return root.Execute()
+	}
+
+	err := run("hello", "--org", "other", "x")
+	c.Assert(buf.String(), qt.Equals, "acme json token --org other x\n")
+	cmdErr, ok := errors.AsType[*cmdutil.Error](err)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(cmdErr.ExitCode, qt.Equals, 3)
+
+	// Flags before the plugin name are pscale's.
+	_ = run("--org", "other", "--format", "csv", "hello", "x", "--format", "y")
+	c.Assert(buf.String(), qt.Equals, "other csv token x --format y\n")
+	_ = run("-f=csv", "--org=other", "hello")
+	c.Assert(buf.String(), qt.Equals, "other csv token \n")
+
+	c.Assert(run("--org", "other", "list"), qt.ErrorMatches, "unknown flag: --org")
+
+	c.Assert(run("list"), qt.IsNil)
+	c.Assert(buf.String(), qt.Equals, "builtin\n")
+
+	c.Assert(run("missing"), qt.ErrorMatches, `unknown command "missing" for "pscale"`)
+}
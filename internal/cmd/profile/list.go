@@ -0,0 +1,41 @@
+package profile
+
+import (
+	"github.com/planetscale/cli/internal/cmdutil"
+
+	"github.com/spf13/cobra"
+)
+
+// ListCmd lists the configuration profiles.
+func ListCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the configuration profiles",
+		Args:    cobra.NoArgs,
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(ch)
+			if err != nil {
+				return err
+			}
+
+			active := activeProfile(ch)
+			names := cfg.ProfileNames()
+			profiles := make([]*profile, 0, len(names))
+			for _, name := range names {
+				p, err := cfg.LookupProfile(name)
+				if err != nil {
+					return err
+				}
+				profiles = append(profiles, toProfile(name, p, active, ch.Printer.Format()))
+			}
+
+			return ch.Printer.PrintResource(profiles)
+		},
+	}
+	cmdutil.SetJSONOutput(cmd, []*profile{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+package profile
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+	"github.com/planetscale/cli/internal/testutil"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const testConfig = `org: acme
+profiles:
+  staging:
+    org: acme-staging
+    api-url: https://api.staging.example.com
+  ci:
+    org: acme
+    service-token-id: abc123
+    service-token-env: ACME_SERVICE_TOKEN
+`
+
+func testHelper(c *qt.C, active string, format printer.Format) (*cmdutil.Helper, *bytes.Buffer) {
+	var buf bytes.Buffer
+	p := printer.NewPrinter(&format)
+	p.SetResourceOutput(&buf)
+	p.SetHumanOutput(&buf)
+
+	configPath, err := config.DefaultConfigPath()
+	c.Assert(err, qt.IsNil)
+
+	return &cmdutil.Helper{
+		Printer: p,
+		Config:  &config.Config{Profile: active},
+		ConfigFS: config.NewConfigFS(testutil.MemFS{
+			configPath: &fstest.MapFile{Data: []byte(testConfig)},
+		}),
+	}, &buf
+}
+
+func TestProfile_ListCmd(t *testing.T) {
+	c := qt.New(t)
+
+	ch, buf := testHelper(c, "staging", printer.JSON)
+	err := ListCmd(ch).Execute()
+	c.Assert(err, qt.IsNil)
+
+	res := []*profile{
+		{Name: "default", Org: "acme", APIURL: "https://api.planetscale.com/", Credential: "keyring entry access-token"},
+		{Name: "ci", Org: "acme", APIURL: "https://api.planetscale.com/", Credential: "service token abc123 ($ACME_SERVICE_TOKEN)"},
+		{Name: "staging", Org: "acme-staging", APIURL: "https://api.staging.example.com", Credential: "keyring entry access-token@staging", Active: true},
+	}
+	c.Assert(buf.String(), qt.JSONEquals, res)
+}
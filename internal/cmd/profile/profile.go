@@ -0,0 +1,106 @@
+package profile
+
+import (
+	"os"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	ps "github.com/planetscale/cli/internal/planetscale"
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/spf13/cobra"
+)
+
+// ProfileCmd encapsulates the commands for managing configuration profiles.
+func ProfileCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile <command>",
+		Short: "List, show, and switch configuration profiles",
+		Long: `List, show, and switch configuration profiles.
+
+Profiles are defined in pscale.yml and each has its own organization, API URL
+and credentials:
+
+  profiles:
+    staging:
+      org: acme-staging
+      api-url: https://api.staging.example.com
+    ci:
+      org: acme
+      service-token-id: <id>
+      service-token-env: ACME_SERVICE_TOKEN
+
+Profiles without a service token use the access token stored by
+"pscale auth login --profile <name>". The top level settings of pscale.yml
+form the "default" profile.
+
+The profile is picked by the --profile flag, then the PSCALE_PROFILE
+environment variable, then the profile saved by "pscale profile use".`,
+	}
+
+	cmd.AddCommand(ListCmd(ch))
+	cmd.AddCommand(UseCmd(ch))
+	cmd.AddCommand(ShowCmd(ch))
+
+	return cmd
+}
+
+// profile returns a table-serializable profile model.
+type profile struct {
+	Name       string `header:"name" json:"name"`
+	Org        string `header:"org" json:"org"`
+	APIURL     string `header:"api url" json:"api_url"`
+	Credential string `header:"credential" json:"credential"`
+	Active     bool   `header:"active" json:"active"`
+}
+
+func toProfile(name string, p *config.Profile, activeName string, format printer.Format) *profile {
+	isActive := name == activeName
+	displayName := name
+	if isActive && format == printer.Human {
+		displayName = "* " + name
+	}
+
+	apiURL := p.BaseURL
+	if apiURL == "" {
+		apiURL = ps.DefaultBaseURL
+	}
+
+	return &profile{
+		Name:       displayName,
+		Org:        p.Organization,
+		APIURL:     apiURL,
+		Credential: p.Credential(name),
+		Active:     isActive,
+	}
+}
+
+// readConfig reads the global config file, which is empty if it doesn't
+// exist yet.
+func readConfig(ch *cmdutil.Helper) (*config.FileConfig, error) {
+	cfg, err := ch.ConfigFS.DefaultConfig()
+	if os.IsNotExist(err) {
+		return &config.FileConfig{}, nil
+	}
+	return cfg, err
+}
+
+// activeProfile returns the name of the profile the command runs with.
+func activeProfile(ch *cmdutil.Helper) string {
+	if ch.Config == nil || ch.Config.Profile == "" {
+		return config.DefaultProfile
+	}
+	return ch.Config.Profile
+}
+
+// CompleteProfiles completes the names of the profiles in the global config
+// file.
+func CompleteProfiles(ch *cmdutil.Helper) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		cfg, err := readConfig(ch)
+		if err != nil {
+			return []string{config.DefaultProfile}, cobra.ShellCompDirectiveNoFileComp
+		}
+		return cfg.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,55 @@
+package profile
+
+import (
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/spf13/cobra"
+)
+
+// ShowCmd shows the active profile or the one given as argument.
+func ShowCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "show [profile]",
+		Short:             "Display the active profile",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: CompleteProfiles(ch),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(ch)
+			if err != nil {
+				return err
+			}
+
+			active := activeProfile(ch)
+			name := active
+			if len(args) == 1 {
+				name = args[0]
+			}
+			p, err := cfg.LookupProfile(name)
+			if err != nil {
+				return err
+			}
+
+			res := toProfile(name, p, active, printer.JSON)
+			if ch.Printer.Format() != printer.Human {
+				return ch.Printer.PrintResource(res)
+			}
+
+			ch.Printer.Printf("Profile:    %s\n", printer.Bold(res.Name))
+			if res.Org != "" {
+				ch.Printer.Printf("Org:        %s\n", res.Org)
+			}
+			ch.Printer.Printf("API URL:    %s\n", res.APIURL)
+			ch.Printer.Printf("Credential: %s\n", res.Credential)
+			if !res.Active {
+				ch.Printer.Println("(not active)")
+			}
+			return nil
+		},
+	}
+	cmdutil.SetJSONOutput(cmd, &profile{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
+}
@@ -0,0 +1,32 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/planetscale/cli/internal/printer"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestProfile_ShowCmd(t *testing.T) {
+	c := qt.New(t)
+
+	ch, buf := testHelper(c, "staging", printer.JSON)
+	err := ShowCmd(ch).Execute()
+	c.Assert(err, qt.IsNil)
+
+	res := &profile{Name: "staging", Org: "acme-staging", APIURL: "https://api.staging.example.com", Credential: "keyring entry access-token@staging", Active: true}
+	c.Assert(buf.String(), qt.JSONEquals, res)
+}
+
+func TestProfile_ShowCmdUnknown(t *testing.T) {
+	c := qt.New(t)
+
+	ch, _ := testHelper(c, "default", printer.JSON)
+	cmd := ShowCmd(ch)
+	cmd.SetArgs([]string{"prod"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	err := cmd.Execute()
+	c.Assert(err, qt.ErrorMatches, `profile "prod" doesn't exist.*`)
+}
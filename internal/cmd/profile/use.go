@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"os"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/spf13/cobra"
+)
+
+// UseCmd saves the profile later commands use by default.
+func UseCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use <profile>",
+		Short: "Switch the profile used by default",
+		Long: `Switch the profile used by default.
+
+The profile is saved to pscale.yml. Use "default" to go back to the top level
+settings. The --profile flag and the PSCALE_PROFILE environment variable take
+precedence over the saved profile.`,
+		Args:              cmdutil.RequiredArgs("profile"),
+		ValidArgsFunction: CompleteProfiles(ch),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			cfg, err := readConfig(ch)
+			if err != nil {
+				return err
+			}
+			if _, err := cfg.LookupProfile(name); err != nil {
+				return err
+			}
+
+			saved := name
+			if name == config.DefaultProfile {
+				saved = ""
+			}
+			if cfg.Profile != saved {
+				cfg.Profile = saved
+				if err := cfg.WriteDefault(); err != nil {
+					return err
+				}
+			}
+
+			ch.Printer.Printf("Successfully switched to profile %s\n", printer.Bold(name))
+			if env := os.Getenv(config.ProfileEnv); env != "" && env != name {
+				ch.Printer.Printf("Note: %s=%s takes precedence over the saved profile.\n", config.ProfileEnv, env)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
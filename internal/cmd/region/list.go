@@ -47,6 +47,8 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 		},
 		TraverseChildren: true,
 	}
+	cmdutil.SetJSONOutput(cmd, []*planetscale.Region{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -52,6 +52,8 @@ func GetCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toPostgresRole(role))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &PostgresRole{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -1,6 +1,7 @@
 package role
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/planetscale/cli/internal/cmdutil"
@@ -18,6 +19,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 		perPage int
 		name    string
 		status  string
+		all     bool
 	}
 
 	cmd := &cobra.Command{
@@ -54,17 +56,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			end := ch.Printer.PrintProgress(fmt.Sprintf("Fetching roles for %s", forMsg))
 			defer end()
 
-			roles, err := client.PostgresRoles.List(ctx, &ps.ListPostgresRolesRequest{
-				Organization: ch.Config.Organization,
-				Database:     database,
-				Branch:       branch,
-			},
-				ps.WithSearch(flags.name),
-				ps.WithStatus(flags.status),
-				ps.WithPage(flags.page),
-				ps.WithPerPage(flags.perPage),
-			)
-			if err != nil {
+			handleErr := func(err error) error {
 				switch cmdutil.ErrCode(err) {
 				case ps.ErrNotFound:
 					return cmdutil.HandleNotFoundWithServiceTokenCheck(
@@ -77,9 +69,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 				}
 			}
 
-			end()
-
-			if len(roles) == 0 && ch.Printer.Format() == printer.Human {
+			printEmpty := func() {
 				if flags.page > 0 {
 					ch.Printer.Println("No roles found on this page.")
 				} else if flags.name != "" || flags.status != "" {
@@ -87,6 +77,40 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 				} else {
 					ch.Printer.Printf("No roles exist in %s.\n", forMsg)
 				}
+			}
+
+			listRoles := func(ctx context.Context, opts ...ps.ListOption) ([]*ps.PostgresRole, error) {
+				opts = append([]ps.ListOption{
+					ps.WithSearch(flags.name),
+					ps.WithStatus(flags.status),
+				}, opts...)
+				return client.PostgresRoles.List(ctx, &ps.ListPostgresRolesRequest{
+					Organization: ch.Config.Organization,
+					Database:     database,
+					Branch:       branch,
+				}, opts...)
+			}
+
+			if flags.all {
+				n, err := cmdutil.PrintPages(ch.Printer, ps.Pages(ctx, flags.perPage, listRoles), end, toPostgresRoles)
+				if err != nil {
+					return handleErr(err)
+				}
+				if n == 0 && ch.Printer.Format() == printer.Human {
+					printEmpty()
+				}
+				return nil
+			}
+
+			roles, err := listRoles(ctx, ps.WithPage(flags.page), ps.WithPerPage(flags.perPage))
+			if err != nil {
+				return handleErr(err)
+			}
+
+			end()
+
+			if len(roles) == 0 && ch.Printer.Format() == printer.Human {
+				printEmpty()
 				return nil
 			}
 
@@ -99,9 +123,13 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().StringVar(&flags.status, "status", "", "Filter roles by status (active, renewable, disabled, or expired)")
 	cmd.Flags().IntVar(&flags.page, "page", 0, "Page number to fetch")
 	cmd.Flags().IntVar(&flags.perPage, "per-page", 100, "Number of results per page")
+	cmdutil.AllPagesFlag(cmd, &flags.all)
 	cmd.RegisterFlagCompletionFunc("status", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return []string{"active", "renewable", "disabled", "expired"}, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmdutil.SetJSONOutput(cmd, []*PostgresRoleList{})
+	cmdutil.SetReadOnly(cmd)
+
 	return cmd
 }
 
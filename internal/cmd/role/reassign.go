@@ -123,6 +123,7 @@ func ReassignCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().BoolVar(&flags.force, "force", false, "Reassign objects without confirmation")
 	cmd.Flags().StringVar(&flags.successor, "successor", "", "Successor role to transfer ownership to (required)")
 	cmd.MarkFlagRequired("successor") // nolint:errcheck
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
 
 	return cmd
 }
@@ -59,6 +59,7 @@ func RenewCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toPostgresRole(role))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &PostgresRole{})
 
 	return cmd
 }
@@ -104,6 +104,7 @@ func ResetCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&flags.force, "force", false, "Reset password without confirmation")
+	cmdutil.SetJSONOutput(cmd, &PostgresRole{})
 
 	return cmd
 }
@@ -105,6 +105,7 @@ func ResetDefaultCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&flags.force, "force", false, "Force reset without confirmation")
+	cmdutil.SetJSONOutput(cmd, &PostgresRole{})
 
 	return cmd
 }
@@ -70,6 +70,7 @@ func UpdateCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.Flags().StringVar(&flags.name, "name", "", "New name for the role")
 	cmd.MarkFlagRequired("name")
+	cmdutil.SetJSONOutput(cmd, &PostgresRole{})
 
 	return cmd
 }
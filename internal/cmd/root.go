@@ -21,13 +21,16 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	clicontent "github.com/planetscale/cli"
 	"github.com/planetscale/cli/internal/cmd/agentguide"
+	"github.com/planetscale/cli/internal/cmd/alias"
 	"github.com/planetscale/cli/internal/cmd/mcp"
 	"github.com/planetscale/cli/internal/cmd/role"
 	"github.com/planetscale/cli/internal/cmd/size"
@@ -40,10 +43,13 @@ import (
 	"github.com/planetscale/cli/internal/cmd/auth"
 	"github.com/planetscale/cli/internal/cmd/backup"
 	"github.com/planetscale/cli/internal/cmd/branch"
+	"github.com/planetscale/cli/internal/cmd/configcmd"
 	"github.com/planetscale/cli/internal/cmd/connect"
+	"github.com/planetscale/cli/internal/cmd/data"
 	"github.com/planetscale/cli/internal/cmd/database"
 	"github.com/planetscale/cli/internal/cmd/dataimports"
 	"github.com/planetscale/cli/internal/cmd/deployrequest"
+	"github.com/planetscale/cli/internal/cmd/dev"
 	"github.com/planetscale/cli/internal/cmd/importcmd"
 	"github.com/planetscale/cli/internal/cmd/insights"
 	"github.com/planetscale/cli/internal/cmd/inspect"
@@ -51,12 +57,15 @@ import (
 	"github.com/planetscale/cli/internal/cmd/org"
 	"github.com/planetscale/cli/internal/cmd/password"
 	"github.com/planetscale/cli/internal/cmd/ping"
+	"github.com/planetscale/cli/internal/cmd/plugin"
+	"github.com/planetscale/cli/internal/cmd/profile"
 	"github.com/planetscale/cli/internal/cmd/region"
 	"github.com/planetscale/cli/internal/cmd/shell"
 	"github.com/planetscale/cli/internal/cmd/signup"
 	"github.com/planetscale/cli/internal/cmd/sql"
 	"github.com/planetscale/cli/internal/cmd/token"
 	"github.com/planetscale/cli/internal/cmd/version"
+	"github.com/planetscale/cli/internal/cmd/watch"
 	"github.com/planetscale/cli/internal/cmd/webhook"
 	"github.com/planetscale/cli/internal/cmdutil"
 	"github.com/planetscale/cli/internal/config"
@@ -71,8 +80,9 @@ import (
 )
 
 var (
-	cfgFile  string
-	replacer = strings.NewReplacer("-", "_", ".", "_")
+	cfgFile     string
+	profileName string
+	replacer    = strings.NewReplacer("-", "_", ".", "_")
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -137,8 +147,11 @@ func Execute(ctx context.Context, sigc chan os.Signal, signals []os.Signal, ver,
 		return cmdutil.ActionRequestedExitCode
 	}
 
-	if format == printer.JSON {
+	switch format {
+	case printer.JSON:
 		return cmdutil.ReportGlobalJSONError(os.Stdout, os.Stderr, err)
+	case printer.NDJSON:
+		return cmdutil.ReportGlobalNDJSONError(os.Stdout, os.Stderr, err)
 	}
 
 	if isRootOrgFlagError(err) {
@@ -166,10 +179,10 @@ func isRootOrgFlagError(err error) bool {
 // runCmd adds all child commands to the root command, sets flags
 // appropriately, and runs the root command.
 func runCmd(ctx context.Context, ver, commit, buildDate string, format *printer.Format, debug *bool, sigc chan os.Signal, signals []os.Signal) error {
-	cobra.OnInitialize(initConfig)
-
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config",
 		"", "Config file (default is $HOME/.config/planetscale/pscale.yml)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile",
+		"", "Profile from the config file to use (default is $"+config.ProfileEnv+" or the profile saved by 'pscale profile use')")
 	rootCmd.SilenceUsage = true
 	rootCmd.SilenceErrors = true
 
@@ -182,6 +195,7 @@ func runCmd(ctx context.Context, ver, commit, buildDate string, format *printer.
 	if err != nil {
 		return err
 	}
+	cobra.OnInitialize(func() { configErr = initConfig(cfg) })
 
 	rootCmd.PersistentFlags().StringVar(&cfg.BaseURL,
 		"api-url", ps.DefaultBaseURL, "The base URL for the PlanetScale API.")
@@ -189,14 +203,23 @@ func runCmd(ctx context.Context, ver, commit, buildDate string, format *printer.
 		"api-token", cfg.AccessToken, "The API token to use for authenticating against the PlanetScale API.")
 
 	rootCmd.PersistentFlags().VarP(printer.NewFormatValue(printer.Human, format), "format", "f",
-		"Show output in a specific format. Possible values: [human, json, csv]")
+		"Show output in a specific format. Possible values: ["+strings.Join(printer.Formats, ", ")+"]")
 	if err := viper.BindPFlag("format", rootCmd.PersistentFlags().Lookup("format")); err != nil {
 		return err
 	}
 	rootCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"human", "json", "csv"}, cobra.ShellCompDirectiveDefault
+		return printer.Formats, cobra.ShellCompDirectiveDefault
 	})
 
+	var (
+		outputTemplate string
+		outputFields   []string
+	)
+	rootCmd.PersistentFlags().StringVar(&outputTemplate, "template", "",
+		"Print resources with a Go `template`, implies --format template. Functions: json, join, timeago, color")
+	rootCmd.PersistentFlags().StringSliceVar(&outputFields, "fields", nil,
+		"Comma separated `fields` to print in human, json and csv output, named after the table columns")
+
 	rootCmd.PersistentFlags().BoolVar(debug, "debug", false, "Enable debug mode")
 	if err := viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug")); err != nil {
 		return err
@@ -207,15 +230,29 @@ func runCmd(ctx context.Context, ver, commit, buildDate string, format *printer.
 		"pscale-cli-version": ver,
 	}
 
+	var profileNotice sync.Once
 	ch := &cmdutil.Helper{
 		Printer:  printer.NewPrinter(format),
 		Config:   cfg,
 		ConfigFS: config.NewConfigFS(osFS{}),
 		Client: func() (*ps.Client, error) {
-			return cfg.NewClientFromConfig(ps.WithUserAgent(userAgent), ps.WithRequestHeaders(headers))
+			if cfg.Profile != config.DefaultProfile && *format == printer.Human {
+				profileNotice.Do(func() {
+					fmt.Fprintf(os.Stderr, "Using profile %s\n", printer.Bold(cfg.Profile))
+				})
+			}
+			return cfg.NewClientFromConfig(ps.WithUserAgent(userAgent), ps.WithRequestHeaders(headers), retryPolicy(*debug))
 		},
 	}
 	ch.SetDebug(debug)
+	cobra.OnInitialize(func() {
+		if outputTemplate != "" && !rootCmd.PersistentFlags().Changed("format") {
+			*format = printer.Template
+		}
+		ch.Printer.SetTemplate(outputTemplate)
+		ch.Printer.SetFields(outputFields)
+	})
+	rootCmd.RegisterFlagCompletionFunc("profile", profile.CompleteProfiles(ch))
 
 	// service token flags. they are hidden for now.
 	rootCmd.PersistentFlags().StringVar(&cfg.ServiceTokenID,
@@ -264,6 +301,10 @@ func runCmd(ctx context.Context, ver, commit, buildDate string, format *printer.
 	rootCmd.AddCommand(logoutCmd)
 
 	// Platform & Account Management commands
+	aliasCmd := alias.AliasCmd(ch)
+	aliasCmd.GroupID = "platform"
+	rootCmd.AddCommand(aliasCmd)
+
 	agentGuideCmd := agentguide.AgentGuideCmd(ch)
 	agentGuideCmd.GroupID = "platform"
 	rootCmd.AddCommand(agentGuideCmd)
@@ -280,10 +321,18 @@ func runCmd(ctx context.Context, ver, commit, buildDate string, format *printer.
 	authCmd.GroupID = "platform"
 	rootCmd.AddCommand(authCmd)
 
+	configCmd := configcmd.ConfigCmd(ch)
+	configCmd.GroupID = "platform"
+	rootCmd.AddCommand(configCmd)
+
 	completionCmd := CompletionCmd()
 	completionCmd.GroupID = "platform"
 	rootCmd.AddCommand(completionCmd)
 
+	devCmd := dev.DevCmd(ch)
+	devCmd.GroupID = "platform"
+	rootCmd.AddCommand(devCmd)
+
 	mcpCmd := mcp.McpCmd(ch)
 	mcpCmd.GroupID = "database"
 	rootCmd.AddCommand(mcpCmd)
@@ -292,10 +341,18 @@ func runCmd(ctx context.Context, ver, commit, buildDate string, format *printer.
 	orgCmd.GroupID = "platform"
 	rootCmd.AddCommand(orgCmd)
 
+	profileCmd := profile.ProfileCmd(ch)
+	profileCmd.GroupID = "platform"
+	rootCmd.AddCommand(profileCmd)
+
 	pingCmd := ping.PingCmd(ch)
 	pingCmd.GroupID = "platform"
 	rootCmd.AddCommand(pingCmd)
 
+	pluginCmd := plugin.PluginCmd(ch)
+	pluginCmd.GroupID = "platform"
+	rootCmd.AddCommand(pluginCmd)
+
 	regionCmd := region.RegionCmd(ch)
 	regionCmd.GroupID = "platform"
 	rootCmd.AddCommand(regionCmd)
@@ -325,6 +382,10 @@ func runCmd(ctx context.Context, ver, commit, buildDate string, format *printer.
 	branchCmd.GroupID = "database"
 	rootCmd.AddCommand(branchCmd)
 
+	dataCmd := data.DataCmd(ch)
+	dataCmd.GroupID = "database"
+	rootCmd.AddCommand(dataCmd)
+
 	databaseCmd := database.DatabaseCmd(ch)
 	databaseCmd.GroupID = "database"
 	rootCmd.AddCommand(databaseCmd)
@@ -386,11 +447,69 @@ func runCmd(ctx context.Context, ver, commit, buildDate string, format *printer.
 	trafficCmd.GroupID = "postgres"
 	rootCmd.AddCommand(trafficCmd)
 
+	completeAliases := alias.CompleteAliases(ch)
+	rootCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		completions, directive := completeAliases(cmd, args, toComplete)
+		if len(args) == 0 {
+			completions = append(completions, plugin.CompletePlugins(cmd, toComplete)...)
+		}
+		return completions, directive
+	}
+
+	watch.Register(rootCmd, ch)
 	annotateRequiredFlags(rootCmd)
 
+	args, err := alias.Resolve(rootCmd, ch.ConfigFS, os.Args[1:])
+	if err != nil {
+		return err
+	}
+	rootCmd.SetArgs(plugin.Register(rootCmd, ch, args))
+	checkConfigBeforeRun(rootCmd)
+
 	return rootCmd.ExecuteContext(ctx)
 }
 
+// configErr is the error initConfig returned. Cobra's initializers can't
+// return errors, so the commands return it before they run instead.
+var configErr error
+
+// checkConfigBeforeRun makes cmd and the commands below it fail with
+// configErr before they run. Cobra only runs the closest persistent pre-run
+// hook of a command, so every one of them is wrapped.
+func checkConfigBeforeRun(cmd *cobra.Command) {
+	if pre, preE := cmd.PersistentPreRun, cmd.PersistentPreRunE; pre != nil || preE != nil || !cmd.HasParent() {
+		cmd.PersistentPreRun = nil
+		cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+			if configErr != nil {
+				return configErr
+			}
+			if preE != nil {
+				return preE(cmd, args)
+			}
+			if pre != nil {
+				pre(cmd, args)
+			}
+			return nil
+		}
+	}
+	for _, child := range cmd.Commands() {
+		checkConfigBeforeRun(child)
+	}
+}
+
+// retryPolicy returns the retry policy of the API client, which reports the
+// retries on stderr in debug mode.
+func retryPolicy(debug bool) ps.ClientOption {
+	policy := ps.DefaultRetryPolicy
+	if debug {
+		policy.OnRetry = func(req *http.Request, attempt int, wait time.Duration, reason string) {
+			fmt.Fprintf(os.Stderr, "Retrying %s %s in %s after %s (attempt %d of %d)\n",
+				req.Method, req.URL.Path, wait.Round(time.Millisecond), reason, attempt+1, policy.MaxAttempts)
+		}
+	}
+	return ps.WithRetryPolicy(policy)
+}
+
 // annotateRequiredFlags walks the command tree and appends "(required)" to the
 // usage text of every flag marked as required, so it's visible in --help.
 func annotateRequiredFlags(cmd *cobra.Command) {
@@ -405,15 +524,14 @@ func annotateRequiredFlags(cmd *cobra.Command) {
 }
 
 // initConfig reads in config file and ENV variables if set.
-func initConfig() {
+func initConfig(cfg *config.Config) error {
 	if cfgFile != "" {
 		// Use config file from the flag.
 		viper.SetConfigFile(cfgFile)
 	} else {
 		defaultConfigDir, err := config.ConfigDir()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(cmdutil.FatalErrExitCode)
+			return err
 		}
 
 		// Order of preference for configuration files:
@@ -432,8 +550,7 @@ func initConfig() {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			// Only handle errors when it's something unrelated to the config file not
 			// existing.
-			fmt.Println(err)
-			os.Exit(cmdutil.FatalErrExitCode)
+			return err
 		}
 	}
 
@@ -443,17 +560,10 @@ func initConfig() {
 	// Only org/database/branch are accepted from project-scoped files — never
 	// api-url, tokens, or other security-sensitive settings.
 	if cfgFile == "" {
-		var projectDir string
-		if rootDir, err := config.RootGitRepoDir(); err == nil {
-			projectDir = rootDir
-		} else if localDir, err := config.LocalDir(); err == nil {
-			projectDir = localDir
-		}
-		if projectDir != "" {
+		if projectDir, err := config.ProjectDir(); err == nil {
 			ignored, err := config.MergeProjectConfig(viper.GetViper(), projectDir)
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(cmdutil.FatalErrExitCode)
+				return err
 			}
 			config.WarnIgnoredProjectConfigKeys(
 				filepath.Join(projectDir, config.ProjectConfigFile()),
@@ -462,7 +572,77 @@ func initConfig() {
 		}
 	}
 
+	cfg.CommandLineFlags = make(map[string]string)
+	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			cfg.CommandLineFlags[f.Name] = f.Value.String()
+		}
+	})
+
+	if err := applyProfile(cfg); err != nil {
+		return err
+	}
+
 	postInitCommands(rootCmd.Commands())
+	return nil
+}
+
+// applyProfile switches cfg to the selected profile. The profile's org and
+// API URL are merged below flags and environment variables, and credentials
+// given as flags win over the profile's.
+func applyProfile(cfg *config.Config) error {
+	fileCfg := &config.FileConfig{}
+	if path := viper.ConfigFileUsed(); path != "" {
+		var err error
+		fileCfg, err = config.NewConfigFS(osFS{}).NewFileConfig(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if fileCfg == nil {
+			fileCfg = &config.FileConfig{}
+		}
+	}
+
+	name, source := fileCfg.SelectedProfile(profileName)
+	if name == config.DefaultProfile {
+		return nil
+	}
+	p, err := fileCfg.LookupProfile(name)
+	if err != nil {
+		if source != "config file" {
+			return err
+		}
+		// A broken saved selection must not lock users out of `pscale
+		// profile use`.
+		fmt.Fprintf(os.Stderr, "Warning: %s, using the default profile.\n", err)
+		return nil
+	}
+
+	settings := map[string]interface{}{}
+	if p.Organization != "" {
+		settings["org"] = p.Organization
+	}
+	if p.BaseURL != "" {
+		settings["api-url"] = p.BaseURL
+	}
+	if err := viper.MergeConfigMap(settings); err != nil {
+		return err
+	}
+
+	flags := rootCmd.PersistentFlags()
+	credentialFlags := flags.Changed("api-token") || flags.Changed("service-token") ||
+		flags.Changed("service-token-id") || flags.Changed("service-token-name")
+	given := *cfg
+	if err := cfg.ApplyProfile(name, p); err != nil {
+		return err
+	}
+	if flags.Changed("api-url") {
+		cfg.BaseURL = given.BaseURL
+	}
+	if credentialFlags {
+		cfg.AccessToken, cfg.ServiceTokenID, cfg.ServiceToken = given.AccessToken, given.ServiceTokenID, given.ServiceToken
+	}
+	return nil
 }
 
 // Hacky fix for getting Cobra required flags and Viper playing well together.
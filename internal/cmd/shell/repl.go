@@ -0,0 +1,539 @@
+package shell
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/peterh/liner"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	ps "github.com/planetscale/cli/internal/planetscale"
+	"github.com/planetscale/cli/internal/sqlquery"
+)
+
+// nativeCredentialTTL bounds how long the credential of a native shell stays
+// valid. It is deleted when the shell exits; the TTL only matters if the
+// process dies before it can clean up.
+const nativeCredentialTTL = 12 * time.Hour
+
+const nativeShellHelp = `Enter SQL statements ending with ; or \G. Statements can span several lines.
+
+  \G          end a statement and show each row vertically
+  \c          clear the statement being typed
+  help, \h    show this help
+  exit, \q    leave the shell (or press Ctrl-D)
+
+Press Tab to complete table and column names.
+`
+
+// nativeShell is a built-in interactive SQL prompt for machines without the
+// mysql or psql clients. All statements run over one connection, so session
+// state such as USE or SET carries over between statements.
+type nativeShell struct {
+	engine string
+	prompt string
+	db     *sql.DB
+	conn   *sql.Conn
+	line   *liner.State
+	intc   chan os.Signal
+	schema *schemaNames
+	out    io.Writer
+	errOut io.Writer
+}
+
+func startNativeShell(ctx context.Context, ch *cmdutil.Helper, database, branch string, dbBranch *ps.DatabaseBranch, role cmdutil.PasswordRole, flags shellFlags, sigc chan os.Signal, signals []os.Signal) error {
+	if flags.localAddr != "" || flags.remoteAddr != "" {
+		return errors.New("--local-addr and --remote-addr are not supported by the built-in shell")
+	}
+
+	session, err := sqlquery.NewSession(ctx, ch, sqlquery.Options{
+		Organization:  ch.Config.Organization,
+		Database:      database,
+		Branch:        branch,
+		Role:          role.ToString(),
+		Replica:       flags.replica,
+		CredentialTTL: nativeCredentialTTL,
+	})
+	if err != nil {
+		return cmdutil.HandleError(err)
+	}
+	defer session.Close()
+
+	// Ctrl-C cancels the running statement instead of the whole command while
+	// the shell is open.
+	intc := make(chan os.Signal, 1)
+	signal.Notify(intc, os.Interrupt)
+	defer signal.Stop(intc)
+	signal.Stop(sigc)
+	defer signal.Notify(sigc, signals...)
+
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetMultiLineMode(true)
+
+	historyFile := historyFilePath(ch.Config.Organization, database, branch)
+	if historyFile != "" {
+		historyFile += ".native"
+		if f, err := os.Open(historyFile); err == nil {
+			line.ReadHistory(f) // nolint:errcheck
+			f.Close()
+		}
+	}
+
+	sh := &nativeShell{
+		engine: session.Engine(),
+		prompt: formatBranch(database, dbBranch),
+		db:     session.DB(),
+		line:   line,
+		intc:   intc,
+		out:    os.Stdout,
+		errOut: os.Stderr,
+	}
+	defer func() {
+		if sh.conn != nil {
+			sh.conn.Close()
+		}
+	}()
+
+	sh.loadSchema(ctx)
+	line.SetWordCompleter(sh.complete)
+
+	fmt.Fprintf(sh.out, "Connected to %s/%s with the built-in shell. Type help for help.\n\n", database, branch)
+	err = sh.run(ctx)
+
+	if historyFile != "" {
+		if f, err := os.Create(historyFile); err == nil {
+			line.WriteHistory(f) // nolint:errcheck
+			f.Close()
+		}
+	}
+	return err
+}
+
+// run reads statements until the user exits or input ends.
+func (s *nativeShell) run(ctx context.Context) error {
+	var buf strings.Builder
+	for {
+		prompt := s.prompt
+		if buf.Len() > 0 {
+			prompt = strings.Repeat(" ", max(runewidth.StringWidth(s.prompt)-3, 0)) + "-> "
+		}
+
+		input, err := s.line.Prompt(prompt)
+		if errors.Is(err, liner.ErrPromptAborted) {
+			buf.Reset()
+			continue
+		}
+		if err == io.EOF {
+			fmt.Fprintln(s.out)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if buf.Len() == 0 {
+			switch strings.TrimSuffix(strings.TrimSpace(input), ";") {
+			case "":
+				continue
+			case "exit", "quit", `\q`:
+				return nil
+			case "help", `\h`, `\?`:
+				fmt.Fprint(s.out, nativeShellHelp)
+				continue
+			}
+		}
+		if strings.HasSuffix(strings.TrimSpace(input), `\c`) {
+			buf.Reset()
+			continue
+		}
+
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(input)
+
+		script, vertical, ok := completeInput(buf.String())
+		if !ok {
+			continue
+		}
+		s.line.AppendHistory(strings.Join(strings.Fields(buf.String()), " "))
+		buf.Reset()
+
+		stmts := sqlquery.SplitStatements(script)
+		for i, stmt := range stmts {
+			if !s.execute(ctx, stmt, vertical && i == len(stmts)-1) {
+				break
+			}
+		}
+	}
+}
+
+// completeInput reports whether buf holds one or more finished statements,
+// ended by a semicolon or by \G (vertical output) or \g. It returns the
+// statements without the trailing \G or \g.
+func completeInput(buf string) (string, bool, bool) {
+	trimmed := strings.TrimRightFunc(buf, unicode.IsSpace)
+	for _, term := range []string{`\G`, `\g`} {
+		body, found := strings.CutSuffix(trimmed, term)
+		// The terminator only counts outside quotes and comments.
+		if found && sqlquery.IsStatementComplete(body+";") {
+			return body, term == `\G`, true
+		}
+	}
+	if sqlquery.IsStatementComplete(buf) {
+		return buf, false, true
+	}
+	return "", false, false
+}
+
+// execute runs one statement and prints its result. It returns false when
+// the remaining statements of the input should be skipped.
+func (s *nativeShell) execute(ctx context.Context, stmt string, vertical bool) bool {
+	if sqlquery.IsDestructiveQuery(stmt) {
+		answer, err := s.line.Prompt("This statement deletes or drops data. Type yes to run it: ")
+		if err != nil || !strings.EqualFold(strings.TrimSpace(answer), "yes") {
+			fmt.Fprintln(s.out, "Statement skipped.")
+			return false
+		}
+	}
+
+	queryCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	select {
+	case <-s.intc: // drop interrupts from before the statement started
+	default:
+	}
+	go func() {
+		select {
+		case <-s.intc:
+			cancel()
+		case <-queryCtx.Done():
+		}
+	}()
+
+	start := time.Now()
+	err := s.runStatement(queryCtx, stmt, vertical)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		if queryCtx.Err() != nil && ctx.Err() == nil {
+			fmt.Fprintln(s.errOut, "Statement canceled.")
+		} else {
+			fmt.Fprintf(s.errOut, "ERROR: %s\n", err)
+		}
+		// A canceled or broken connection loses its session state; the
+		// next statement gets a new one.
+		if queryCtx.Err() != nil || errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+			s.resetConn()
+		}
+		return false
+	}
+
+	fmt.Fprintf(s.out, "(%.2f sec)\n\n", elapsed.Seconds())
+	if isSchemaChange(stmt) {
+		s.loadSchema(ctx)
+	}
+	return true
+}
+
+func (s *nativeShell) runStatement(ctx context.Context, stmt string, vertical bool) error {
+	conn, err := s.connection(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !sqlquery.ReturnsRows(stmt) {
+		res, err := conn.ExecContext(ctx, stmt)
+		if err != nil {
+			return err
+		}
+		affected, _ := res.RowsAffected()
+		fmt.Fprintf(s.out, "Query OK, %s affected ", pluralRows(affected))
+		return nil
+	}
+
+	rows, err := conn.QueryContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	rs, err := readResult(rows)
+	if err != nil {
+		return err
+	}
+	if len(rs.rows) == 0 {
+		fmt.Fprint(s.out, "Empty set ")
+		return nil
+	}
+	if vertical {
+		renderVertical(s.out, rs)
+	} else {
+		renderTable(s.out, rs)
+	}
+	fmt.Fprintf(s.out, "%s in set ", pluralRows(int64(len(rs.rows))))
+	return nil
+}
+
+func (s *nativeShell) connection(ctx context.Context) (*sql.Conn, error) {
+	if s.conn == nil {
+		conn, err := s.db.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.conn = conn
+	}
+	return s.conn, nil
+}
+
+func (s *nativeShell) resetConn() {
+	if s.conn != nil {
+		s.conn.Raw(func(any) error { return driver.ErrBadConn }) // nolint:errcheck
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func pluralRows(n int64) string {
+	if n == 1 {
+		return "1 row"
+	}
+	return fmt.Sprintf("%d rows", n)
+}
+
+// isSchemaChange reports whether stmt may add or remove tables or columns,
+// or switch to another database, so completions need to be reloaded.
+func isSchemaChange(stmt string) bool {
+	fields := strings.Fields(strings.ToUpper(stmt))
+	return len(fields) > 0 && slices.Contains([]string{"CREATE", "ALTER", "DROP", "RENAME", "USE"}, fields[0])
+}
+
+// resultSet is a query result with every value rendered as text.
+type resultSet struct {
+	columns []string
+	rows    [][]string
+}
+
+func readResult(rows *sql.Rows) (*resultSet, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &resultSet{columns: columns}
+	values := make([]any, len(columns))
+	scanArgs := make([]any, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(columns))
+		for i, v := range values {
+			row[i] = formatValue(v)
+		}
+		rs.rows = append(rs.rows, row)
+	}
+	return rs, rows.Err()
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999Z07:00")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// renderTable prints rs as a bordered table, like the mysql client.
+func renderTable(w io.Writer, rs *resultSet) {
+	widths := make([]int, len(rs.columns))
+	for i, col := range rs.columns {
+		widths[i] = runewidth.StringWidth(col)
+	}
+	for _, row := range rs.rows {
+		for i, v := range row {
+			widths[i] = max(widths[i], runewidth.StringWidth(v))
+		}
+	}
+
+	var border strings.Builder
+	border.WriteString("+")
+	for _, width := range widths {
+		border.WriteString(strings.Repeat("-", width+2))
+		border.WriteString("+")
+	}
+	border.WriteString("\n")
+
+	writeRow := func(values []string) {
+		io.WriteString(w, "|") // nolint:errcheck
+		for i, v := range values {
+			fmt.Fprintf(w, " %s%s |", v, strings.Repeat(" ", widths[i]-runewidth.StringWidth(v)))
+		}
+		io.WriteString(w, "\n") // nolint:errcheck
+	}
+
+	io.WriteString(w, border.String()) // nolint:errcheck
+	writeRow(rs.columns)
+	io.WriteString(w, border.String()) // nolint:errcheck
+	for _, row := range rs.rows {
+		writeRow(row)
+	}
+	io.WriteString(w, border.String()) // nolint:errcheck
+}
+
+// renderVertical prints each row of rs as a block of "column: value" lines,
+// like \G in the mysql client.
+func renderVertical(w io.Writer, rs *resultSet) {
+	width := 0
+	for _, col := range rs.columns {
+		width = max(width, runewidth.StringWidth(col))
+	}
+	for n, row := range rs.rows {
+		fmt.Fprintf(w, "%s %d. row %s\n", strings.Repeat("*", 27), n+1, strings.Repeat("*", 27))
+		for i, col := range rs.columns {
+			fmt.Fprintf(w, "%s%s: %s\n", strings.Repeat(" ", width-runewidth.StringWidth(col)), col, row[i])
+		}
+	}
+}
+
+// schemaNames holds the table and column names offered by tab completion.
+type schemaNames struct {
+	tables []string
+	// columns maps lower-cased table names to their columns.
+	columns map[string][]string
+}
+
+var completionKeywords = []string{
+	"SELECT", "FROM", "WHERE", "AND", "OR", "NOT", "NULL", "IS", "IN", "LIKE",
+	"BETWEEN", "JOIN", "LEFT", "RIGHT", "INNER", "OUTER", "ON", "USING", "AS",
+	"GROUP", "BY", "ORDER", "HAVING", "LIMIT", "OFFSET", "DISTINCT", "COUNT",
+	"INSERT", "INTO", "VALUES", "UPDATE", "SET", "DELETE", "CREATE", "ALTER",
+	"DROP", "TABLE", "INDEX", "VIEW", "SHOW", "TABLES", "COLUMNS", "DESCRIBE",
+	"EXPLAIN", "WITH", "UNION", "ALL", "CASE", "WHEN", "THEN", "ELSE", "END",
+	"ASC", "DESC", "BEGIN", "COMMIT", "ROLLBACK", "RETURNING",
+}
+
+// loadSchema refreshes the completion names. Failures only cost completions,
+// so they are ignored.
+func (s *nativeShell) loadSchema(ctx context.Context) {
+	query := `SELECT table_name, column_name FROM information_schema.columns
+WHERE table_schema = DATABASE() ORDER BY table_name, ordinal_position`
+	if s.engine == "postgresql" {
+		query = `SELECT table_name, column_name FROM information_schema.columns
+WHERE table_schema NOT IN ('pg_catalog', 'information_schema') ORDER BY table_name, ordinal_position`
+	}
+
+	conn, err := s.connection(ctx)
+	if err != nil {
+		return
+	}
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	names := &schemaNames{columns: map[string][]string{}}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return
+		}
+		key := strings.ToLower(table)
+		if _, ok := names.columns[key]; !ok {
+			names.tables = append(names.tables, table)
+		}
+		names.columns[key] = append(names.columns[key], column)
+	}
+	if rows.Err() == nil {
+		s.schema = names
+	}
+}
+
+func (s *nativeShell) complete(line string, pos int) (string, []string, string) {
+	return s.schema.complete(line, pos)
+}
+
+// complete completes the word before pos with keywords, table names and
+// column names. A word of the form table.prefix completes the columns of
+// that table.
+func (n *schemaNames) complete(line string, pos int) (string, []string, string) {
+	runes := []rune(line)
+	pos = min(pos, len(runes))
+	start := pos
+	for start > 0 && (isCompletionChar(runes[start-1])) {
+		start--
+	}
+	head, word, tail := string(runes[:start]), string(runes[start:pos]), string(runes[pos:])
+
+	var candidates []string
+	if table, prefix, ok := strings.Cut(word, "."); ok {
+		if n != nil {
+			for _, col := range n.columns[strings.ToLower(table)] {
+				if hasPrefixFold(col, prefix) {
+					candidates = append(candidates, table+"."+col)
+				}
+			}
+		}
+		return head, candidates, tail
+	}
+	if word == "" {
+		return head, nil, tail
+	}
+
+	lower := strings.ToLower(word) == word
+	for _, kw := range completionKeywords {
+		if hasPrefixFold(kw, word) {
+			if lower {
+				kw = strings.ToLower(kw)
+			}
+			candidates = append(candidates, kw)
+		}
+	}
+	if n != nil {
+		var names []string
+		for _, table := range n.tables {
+			if hasPrefixFold(table, word) {
+				names = append(names, table)
+			}
+			for _, col := range n.columns[strings.ToLower(table)] {
+				if hasPrefixFold(col, word) && !slices.Contains(names, col) {
+					names = append(names, col)
+				}
+			}
+		}
+		sort.Strings(names)
+		candidates = append(candidates, names...)
+	}
+	return head, candidates, tail
+}
+
+func isCompletionChar(r rune) bool {
+	return r == '_' || r == '.' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
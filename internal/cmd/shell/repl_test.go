@@ -0,0 +1,98 @@
+package shell
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestCompleteInput(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		buf      string
+		script   string
+		vertical bool
+		ok       bool
+	}{
+		{buf: "SELECT 1;", script: "SELECT 1;", ok: true},
+		{buf: "SELECT 1", ok: false},
+		{buf: "SELECT *\nFROM t\nWHERE s = ';", ok: false},
+		{buf: "SELECT *\nFROM t\nWHERE s = ';';", script: "SELECT *\nFROM t\nWHERE s = ';';", ok: true},
+		{buf: `SELECT * FROM t\G`, script: "SELECT * FROM t", vertical: true, ok: true},
+		{buf: `SELECT * FROM t\g  `, script: "SELECT * FROM t", ok: true},
+		{buf: `SELECT '\G`, ok: false},
+		{buf: `SELECT 1 -- \G`, ok: false},
+	}
+	for _, tt := range tests {
+		script, vertical, ok := completeInput(tt.buf)
+		c.Assert(ok, qt.Equals, tt.ok, qt.Commentf("%q", tt.buf))
+		c.Assert(script, qt.Equals, tt.script, qt.Commentf("%q", tt.buf))
+		c.Assert(vertical, qt.Equals, tt.vertical, qt.Commentf("%q", tt.buf))
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	renderTable(&buf, &resultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]string{{"1", "Zoë"}, {"10", "NULL"}},
+	})
+	c.Assert(buf.String(), qt.Equals, `+----+------+
+| id | name |
++----+------+
+| 1  | Zoë  |
+| 10 | NULL |
++----+------+
+`)
+}
+
+func TestRenderVertical(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	renderVertical(&buf, &resultSet{
+		columns: []string{"id", "email"},
+		rows:    [][]string{{"1", "a@example.com"}},
+	})
+	c.Assert(buf.String(), qt.Equals, `*************************** 1. row ***************************
+   id: 1
+email: a@example.com
+`)
+}
+
+func TestComplete(t *testing.T) {
+	c := qt.New(t)
+
+	names := &schemaNames{
+		tables: []string{"customers", "orders"},
+		columns: map[string][]string{
+			"customers": {"id", "email", "created_at"},
+			"orders":    {"id", "customer_id", "total"},
+		},
+	}
+
+	head, got, tail := names.complete("select * from cu", 16)
+	c.Assert(head, qt.Equals, "select * from ")
+	c.Assert(got, qt.DeepEquals, []string{"customer_id", "customers"})
+	c.Assert(tail, qt.Equals, "")
+
+	_, got, _ = names.complete("SEL", 3)
+	c.Assert(got, qt.DeepEquals, []string{"SELECT"})
+
+	_, got, _ = names.complete("sel", 3)
+	c.Assert(got, qt.DeepEquals, []string{"select"})
+
+	head, got, tail = names.complete("select orders.t from orders", 15)
+	c.Assert(head, qt.Equals, "select ")
+	c.Assert(got, qt.DeepEquals, []string{"orders.total"})
+	c.Assert(tail, qt.Equals, " from orders")
+
+	// Without a loaded schema only keywords complete.
+	var empty *schemaNames
+	_, got, _ = empty.complete("wher", 4)
+	c.Assert(got, qt.DeepEquals, []string{"where"})
+}
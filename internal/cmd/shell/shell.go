@@ -7,6 +7,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/adrg/xdg"
@@ -29,6 +30,7 @@ type shellFlags struct {
 	remoteAddr string
 	role       string
 	replica    bool
+	native     bool
 }
 
 func ShellCmd(ch *cmdutil.Helper, sigc chan os.Signal, signals ...os.Signal) *cobra.Command {
@@ -43,6 +45,9 @@ func ShellCmd(ch *cmdutil.Helper, sigc chan os.Signal, signals ...os.Signal) *co
 
 For MySQL databases, it uses the MySQL command-line client ("mysql").
 For Postgres databases, it uses the Postgres command-line client ("psql").
+If the client isn't installed, or --native is passed, it opens a built-in
+SQL shell instead, with multi-line input, history, and tab completion of table
+and column names.
 
 By default, if no branch names are given and there is only one branch, it
 automatically opens a shell to that branch:
@@ -93,22 +98,27 @@ second argument:
 			var clientPath string
 			var authMethod mysql.AuthMethodDescription
 			var isPostgreSQL bool
+			native := flags.native
 
 			switch dbInfo.Kind {
 			case "mysql":
-				clientPath, authMethod, err = cmdutil.MySQLClientPath()
-				if err != nil {
-					return err
+				if !native {
+					clientPath, authMethod, err = cmdutil.MySQLClientPath()
 				}
 			case "postgresql", "horizon":
-				clientPath, err = cmdutil.PostgreSQLClientPath()
-				if err != nil {
-					return err
+				if !native {
+					clientPath, err = cmdutil.PostgreSQLClientPath()
 				}
 				isPostgreSQL = true
 			default:
 				return fmt.Errorf("unsupported database kind: %s. Only 'mysql' and 'postgresql' are supported", dbInfo.Kind)
 			}
+			if err != nil {
+				// Without a usable client binary, fall back to the built-in shell.
+				reason, _, _ := strings.Cut(err.Error(), "\n")
+				ch.Printer.Printf("%s\nOpening the built-in shell instead (pass --native to skip this check).\n\n", reason)
+				native = true
+			}
 
 			var branch string
 			if len(args) == 2 {
@@ -162,6 +172,9 @@ second argument:
 				return errors.New("database branch is not ready yet")
 			}
 
+			if native {
+				return startNativeShell(ctx, ch, database, branch, dbBranch, role, flags, sigc, signals)
+			}
 			if isPostgreSQL {
 				return startShellForPostgres(ctx, ch, client, database, branch, dbBranch, clientPath, role, flags, sigc, signals, runForeground)
 			} else {
@@ -178,6 +191,7 @@ second argument:
 	cmd.PersistentFlags().StringVar(&flags.role, "role",
 		"", "Role defines the access level, allowed values are: reader, writer, readwriter, admin. Defaults to 'reader' for replica passwords, otherwise defaults to 'admin'.")
 	cmd.Flags().BoolVar(&flags.replica, "replica", false, "When enabled, the password will route all reads to the branch's primary replicas and all read-only regions.")
+	cmd.Flags().BoolVar(&flags.native, "native", false, "Use the built-in SQL shell instead of the mysql or psql client.")
 
 	cmd.MarkPersistentFlagRequired("org") // nolint:errcheck
 
@@ -131,6 +131,8 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			cobra.CompletionWithDesc("postgresql", "The fastest cloud Postgres"),
 		}, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmdutil.SetJSONOutput(cmd, []*clusterSKUJSON{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -0,0 +1,580 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/printer"
+	"github.com/planetscale/cli/internal/sqlquery"
+)
+
+// maxSampleRows bounds how many differing rows are reported per query so a
+// large mismatch doesn't flood the terminal or the JSON payload.
+const maxSampleRows = 10
+
+// planEstimateColumns are MySQL EXPLAIN columns holding optimizer estimates.
+// They change with data volume rather than with the plan itself, so they are
+// left out when comparing plans.
+var planEstimateColumns = map[string]bool{
+	"rows":     true,
+	"filtered": true,
+}
+
+// CompareReport is the output of pscale sql compare.
+type CompareReport struct {
+	Status      string             `json:"status"`
+	Database    string             `json:"database"`
+	BranchA     string             `json:"branch_a"`
+	BranchB     string             `json:"branch_b"`
+	Differences int                `json:"differences"`
+	Queries     []*QueryComparison `json:"queries"`
+}
+
+// QueryComparison is the outcome of running one query on both branches.
+type QueryComparison struct {
+	Index   int        `json:"index"`
+	Query   string     `json:"query"`
+	Same    bool       `json:"same"`
+	Issues  []string   `json:"issues,omitempty"`
+	Results ResultDiff `json:"results"`
+	Plans   *PlanDiff  `json:"plans,omitempty"`
+	Timing  TimingDiff `json:"timing"`
+}
+
+// ResultDiff compares the result sets. Columns and sample rows are only set
+// when they differ.
+type ResultDiff struct {
+	Same      bool       `json:"same"`
+	RowCountA int        `json:"row_count_a"`
+	RowCountB int        `json:"row_count_b"`
+	ErrorA    string     `json:"error_a,omitempty"`
+	ErrorB    string     `json:"error_b,omitempty"`
+	ColumnsA  []string   `json:"columns_a,omitempty"`
+	ColumnsB  []string   `json:"columns_b,omitempty"`
+	OnlyInA   [][]string `json:"only_in_a,omitempty"`
+	OnlyInB   [][]string `json:"only_in_b,omitempty"`
+}
+
+// PlanDiff compares the EXPLAIN output. Plans are only set when they differ.
+type PlanDiff struct {
+	Same  bool     `json:"same"`
+	PlanA []string `json:"plan_a,omitempty"`
+	PlanB []string `json:"plan_b,omitempty"`
+}
+
+// TimingDiff holds the wall-clock time of the query on each branch.
+type TimingDiff struct {
+	DurationAMs float64 `json:"duration_a_ms"`
+	DurationBMs float64 `json:"duration_b_ms"`
+	Slowdown    float64 `json:"slowdown"`
+}
+
+type compareRow struct {
+	Index     int     `csv:"index" header:"#"`
+	Query     string  `csv:"query" header:"query"`
+	Same      bool    `csv:"same" header:"same"`
+	Issues    string  `csv:"issues" header:"issues"`
+	DurationA float64 `csv:"duration_a_ms" header:"a ms"`
+	DurationB float64 `csv:"duration_b_ms" header:"b ms"`
+}
+
+type compareFlags struct {
+	file        string
+	ignoreOrder bool
+	skipPlans   bool
+	maxSlowdown float64
+	timeout     time.Duration
+	keyspace    string
+	postgresDB  string
+	role        string
+	replica     bool
+}
+
+// branchRun is one query's raw outcome on a single branch.
+type branchRun struct {
+	columns  []string
+	rows     []map[string]any
+	err      error
+	duration time.Duration
+	plan     []string
+	planErr  error
+}
+
+// CompareCmd runs the same queries on two branches and diffs the results.
+func CompareCmd(ch *cmdutil.Helper) *cobra.Command {
+	flags := &compareFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "compare <database> <branch-a> <branch-b>",
+		Short: "Compare query results and plans between two branches",
+		Long: `Run every query in a file against two branches of the same database and
+compare the result sets, EXPLAIN plans, and timings.
+
+Statements are separated by semicolons. Only read queries (SELECT, SHOW,
+EXPLAIN, and SELECTs behind CTEs) are allowed, since each query runs on both
+branches.
+
+Rows are compared in order unless --ignore-order is passed. Plan estimates
+(MySQL rows and filtered, PostgreSQL costs) are ignored so that branches with
+different amounts of data can still have matching plans. Timings are reported
+but only count as a difference when --max-slowdown is set.
+
+The command exits with a non-zero status when any query differs, so it can
+gate CI before a deploy request is merged.
+
+A database named compare is reached with pscale sql -- compare, since
+pscale sql compare always runs this command.`,
+		Args: cmdutil.RequiredArgs("database", "branch-a", "branch-b"),
+		Example: `  # Compare a query suite between main and a development branch
+  pscale sql compare <database> main dev --org <org> --file queries.sql
+
+  # Ignore row order and fail when dev is more than twice as slow
+  pscale sql compare <database> main dev --org <org> --file queries.sql --ignore-order --max-slowdown 2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			database, branchA, branchB := args[0], args[1], args[2]
+
+			queries, err := readCompareQueries(flags.file)
+			if err != nil {
+				return err
+			}
+
+			end := ch.Printer.PrintProgress(fmt.Sprintf("Connecting to %s and %s in %s...",
+				printer.BoldBlue(branchA), printer.BoldBlue(branchB), printer.BoldBlue(database)))
+			defer end()
+
+			sessA, err := newCompareSession(ctx, ch, database, branchA, flags)
+			if err != nil {
+				return cmdutil.HandleError(err)
+			}
+			defer sessA.Close()
+
+			sessB, err := newCompareSession(ctx, ch, database, branchB, flags)
+			if err != nil {
+				return cmdutil.HandleError(err)
+			}
+			defer sessB.Close()
+			end()
+
+			report := &CompareReport{
+				Database: database,
+				BranchA:  branchA,
+				BranchB:  branchB,
+			}
+			for i, query := range queries {
+				runA := runOnBranch(ctx, sessA, query, flags)
+				runB := runOnBranch(ctx, sessB, query, flags)
+
+				comparison := compareRuns(i+1, query, runA, runB, flags)
+				if !comparison.Same {
+					report.Differences++
+				}
+				report.Queries = append(report.Queries, comparison)
+			}
+
+			report.Status = "same"
+			if report.Differences > 0 {
+				report.Status = "different"
+			}
+
+			if err := printCompareReport(ch, report); err != nil {
+				return err
+			}
+
+			if report.Differences == 0 {
+				return nil
+			}
+			if f := ch.Printer.Format(); f == printer.JSON || f == printer.YAML {
+				return cmdutil.JSONReportedError(cmdutil.ActionRequestedExitCode)
+			}
+			return &cmdutil.Error{
+				Msg:      fmt.Sprintf("%d of %d queries differ between %s and %s", report.Differences, len(report.Queries), branchA, branchB),
+				ExitCode: cmdutil.ActionRequestedExitCode,
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.file, "file", "", "Path to a file with the SQL queries to compare, separated by semicolons")
+	cmd.Flags().BoolVar(&flags.ignoreOrder, "ignore-order", false, "Compare result rows regardless of their order")
+	cmd.Flags().BoolVar(&flags.skipPlans, "skip-plans", false, "Don't compare EXPLAIN plans")
+	cmd.Flags().Float64Var(&flags.maxSlowdown, "max-slowdown", 0,
+		"Treat a query as different when it is more than this many times slower on <branch-b> than on <branch-a>. 0 disables the timing check.")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 30*time.Second, "Maximum time a single query may run on one branch")
+	cmd.Flags().StringVar(&flags.keyspace, "keyspace", "", "Vitess keyspace, optionally with a shard and tablet type (e.g. mykeyspace, mykeyspace/-80). Defaults to @primary, same as pscale sql.")
+	cmd.Flags().StringVar(&flags.postgresDB, "dbname", "postgres", "PostgreSQL database name")
+	cmd.Flags().StringVar(&flags.role, "role", "",
+		"Role defines the access level, allowed values are: reader, writer, readwriter, admin. Defaults to reader.")
+	cmd.Flags().BoolVar(&flags.replica, "replica", false,
+		"When enabled, queries run against the branches' replicas.")
+	cmd.MarkFlagRequired("file") // nolint:errcheck
+	cmdutil.SetJSONOutput(cmd, &CompareReport{})
+	cmdutil.SetReadOnly(cmd)
+
+	return cmd
+}
+
+func readCompareQueries(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading queries: %w", err)
+	}
+
+	queries := sqlquery.SplitStatements(string(data))
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no queries found in %s", path)
+	}
+	for i, query := range queries {
+		if !sqlquery.IsReadQuery(query) {
+			return nil, fmt.Errorf("query %d is not a read query; compare runs every query on both branches, so only SELECT, SHOW, and EXPLAIN are allowed:\n%s", i+1, query)
+		}
+	}
+	return queries, nil
+}
+
+func newCompareSession(ctx context.Context, ch *cmdutil.Helper, database, branch string, flags *compareFlags) (*sqlquery.Session, error) {
+	sess, err := sqlquery.NewSession(ctx, ch, sqlquery.Options{
+		Organization: ch.Config.Organization,
+		Database:     database,
+		Branch:       branch,
+		Keyspace:     flags.keyspace,
+		PostgresDB:   flags.postgresDB,
+		Role:         flags.role,
+		Replica:      flags.replica,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("branch %s: %w", branch, err)
+	}
+	return sess, nil
+}
+
+func runOnBranch(ctx context.Context, sess *sqlquery.Session, query string, flags *compareFlags) *branchRun {
+	run := &branchRun{}
+
+	queryCtx, cancel := context.WithTimeout(ctx, flags.timeout)
+	start := time.Now()
+	run.columns, run.rows, run.err = sess.Query(queryCtx, query)
+	run.duration = time.Since(start)
+	cancel()
+
+	if flags.skipPlans {
+		return run
+	}
+
+	planCtx, cancel := context.WithTimeout(ctx, flags.timeout)
+	defer cancel()
+	columns, rows, err := sess.Explain(planCtx, query)
+	if err != nil {
+		run.planErr = err
+		return run
+	}
+	run.plan = planLines(columns, rows)
+	return run
+}
+
+// compareRuns diffs one query's outcome on both branches.
+func compareRuns(index int, query string, a, b *branchRun, flags *compareFlags) *QueryComparison {
+	c := &QueryComparison{
+		Index:   index,
+		Query:   query,
+		Results: diffResults(a, b, flags.ignoreOrder),
+		Timing: TimingDiff{
+			DurationAMs: milliseconds(a.duration),
+			DurationBMs: milliseconds(b.duration),
+		},
+	}
+	if a.duration > 0 {
+		c.Timing.Slowdown = float64(b.duration) / float64(a.duration)
+	}
+
+	if !c.Results.Same {
+		c.Issues = append(c.Issues, describeResultDiff(c.Results))
+	}
+
+	if !flags.skipPlans {
+		c.Plans = diffPlans(a, b)
+		if !c.Plans.Same {
+			c.Issues = append(c.Issues, "query plans differ")
+		}
+	}
+
+	if flags.maxSlowdown > 0 && c.Timing.Slowdown > flags.maxSlowdown {
+		c.Issues = append(c.Issues, fmt.Sprintf("%.1fx slower on branch b (limit %.1fx)", c.Timing.Slowdown, flags.maxSlowdown))
+	}
+
+	c.Same = len(c.Issues) == 0
+	return c
+}
+
+func diffResults(a, b *branchRun, ignoreOrder bool) ResultDiff {
+	d := ResultDiff{
+		RowCountA: len(a.rows),
+		RowCountB: len(b.rows),
+	}
+
+	if a.err != nil || b.err != nil {
+		d.ErrorA = errString(a.err)
+		d.ErrorB = errString(b.err)
+		d.Same = d.ErrorA == d.ErrorB
+		return d
+	}
+
+	if !slices.Equal(a.columns, b.columns) {
+		d.ColumnsA = a.columns
+		d.ColumnsB = b.columns
+		return d
+	}
+
+	rowsA := rowValues(a.columns, a.rows)
+	rowsB := rowValues(b.columns, b.rows)
+	if ignoreOrder {
+		d.OnlyInA, d.OnlyInB = unorderedRowDiff(rowsA, rowsB)
+	} else {
+		d.OnlyInA, d.OnlyInB = orderedRowDiff(rowsA, rowsB)
+	}
+	d.Same = len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0
+	return d
+}
+
+// orderedRowDiff compares rows position by position and returns the rows of
+// each side that don't match the other side at the same position.
+func orderedRowDiff(a, b [][]string) (onlyA, onlyB [][]string) {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		switch {
+		case i >= len(a):
+			onlyB = appendSample(onlyB, b[i])
+		case i >= len(b):
+			onlyA = appendSample(onlyA, a[i])
+		case !slices.Equal(a[i], b[i]):
+			onlyA = appendSample(onlyA, a[i])
+			onlyB = appendSample(onlyB, b[i])
+		}
+	}
+	return onlyA, onlyB
+}
+
+// unorderedRowDiff compares rows as multisets, so duplicates must appear the
+// same number of times on both sides.
+func unorderedRowDiff(a, b [][]string) (onlyA, onlyB [][]string) {
+	counts := make(map[string]int, len(a))
+	for _, row := range a {
+		counts[rowKey(row)]++
+	}
+	for _, row := range b {
+		key := rowKey(row)
+		if counts[key] > 0 {
+			counts[key]--
+			continue
+		}
+		onlyB = appendSample(onlyB, row)
+	}
+	for _, row := range a {
+		key := rowKey(row)
+		if counts[key] > 0 {
+			counts[key]--
+			onlyA = appendSample(onlyA, row)
+		}
+	}
+	return onlyA, onlyB
+}
+
+func appendSample(rows [][]string, row []string) [][]string {
+	if len(rows) >= maxSampleRows {
+		return rows
+	}
+	return append(rows, row)
+}
+
+func rowValues(columns []string, rows []map[string]any) [][]string {
+	out := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		values := make([]string, 0, len(columns))
+		for _, col := range columns {
+			values = append(values, formatCompareValue(row[col]))
+		}
+		out = append(out, values)
+	}
+	return out
+}
+
+func formatCompareValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// rowKey joins values with a separator that can't appear in formatted values
+// so that distinct rows never share a key.
+func rowKey(row []string) string {
+	return strings.Join(row, "\x00")
+}
+
+func diffPlans(a, b *branchRun) *PlanDiff {
+	// A statement that can't be explained (e.g. SHOW) fails the same way on
+	// both branches, which isn't a difference.
+	if a.planErr != nil || b.planErr != nil {
+		return &PlanDiff{Same: errString(a.planErr) == errString(b.planErr)}
+	}
+	if slices.Equal(a.plan, b.plan) {
+		return &PlanDiff{Same: true}
+	}
+	return &PlanDiff{PlanA: a.plan, PlanB: b.plan}
+}
+
+// planLines renders EXPLAIN output as one line per plan row, leaving out
+// optimizer estimates.
+func planLines(columns []string, rows []map[string]any) []string {
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if len(columns) == 1 {
+			lines = append(lines, formatCompareValue(row[columns[0]]))
+			continue
+		}
+
+		parts := make([]string, 0, len(columns))
+		for _, col := range columns {
+			if planEstimateColumns[strings.ToLower(col)] {
+				continue
+			}
+			parts = append(parts, col+"="+formatCompareValue(row[col]))
+		}
+		lines = append(lines, strings.Join(parts, " "))
+	}
+	return lines
+}
+
+func describeResultDiff(d ResultDiff) string {
+	switch {
+	case d.ErrorA != "" || d.ErrorB != "":
+		return "queries failed differently"
+	case d.ColumnsA != nil || d.ColumnsB != nil:
+		return "result columns differ"
+	case d.RowCountA != d.RowCountB:
+		return fmt.Sprintf("row counts differ (%d vs %d)", d.RowCountA, d.RowCountB)
+	default:
+		return "result rows differ"
+	}
+}
+
+func printCompareReport(ch *cmdutil.Helper, report *CompareReport) error {
+	switch ch.Printer.Format() {
+	case printer.JSON:
+		return ch.Printer.PrintJSON(report)
+	case printer.YAML:
+		return ch.Printer.PrintYAML(report)
+	case printer.Human:
+		printHumanCompareReport(ch, report)
+		return nil
+	default:
+		rows := make([]*compareRow, 0, len(report.Queries))
+		for _, q := range report.Queries {
+			rows = append(rows, &compareRow{
+				Index:     q.Index,
+				Query:     q.Query,
+				Same:      q.Same,
+				Issues:    strings.Join(q.Issues, "; "),
+				DurationA: q.Timing.DurationAMs,
+				DurationB: q.Timing.DurationBMs,
+			})
+		}
+		return ch.Printer.PrintResource(rows)
+	}
+}
+
+func printHumanCompareReport(ch *cmdutil.Helper, report *CompareReport) {
+	for _, q := range report.Queries {
+		ch.Printer.Printf("%s %s\n", printer.Bold(fmt.Sprintf("[%d]", q.Index)), summarizeQuery(q.Query))
+
+		timing := fmt.Sprintf("%s %.1fms, %s %.1fms", report.BranchA, q.Timing.DurationAMs, report.BranchB, q.Timing.DurationBMs)
+		if q.Same {
+			ch.Printer.Printf("    %s (%d rows; %s)\n", printer.BoldGreen("same"), q.Results.RowCountA, timing)
+			continue
+		}
+
+		ch.Printer.Printf("    %s: %s (%s)\n", printer.BoldRed("different"), strings.Join(q.Issues, ", "), timing)
+		printResultDiff(ch, report, q.Results)
+		if q.Plans != nil && !q.Plans.Same {
+			printPlanDiff(ch, report, q.Plans)
+		}
+	}
+
+	if report.Differences == 0 {
+		ch.Printer.Printf("\nAll %d queries match on %s and %s.\n", len(report.Queries),
+			printer.BoldBlue(report.BranchA), printer.BoldBlue(report.BranchB))
+		return
+	}
+	ch.Printer.Printf("\n%d of %d queries differ between %s and %s.\n", report.Differences, len(report.Queries),
+		printer.BoldBlue(report.BranchA), printer.BoldBlue(report.BranchB))
+}
+
+func printResultDiff(ch *cmdutil.Helper, report *CompareReport, d ResultDiff) {
+	if d.Same {
+		return
+	}
+	if d.ErrorA != "" || d.ErrorB != "" {
+		ch.Printer.Printf("      %s: %s\n", report.BranchA, orNone(d.ErrorA))
+		ch.Printer.Printf("      %s: %s\n", report.BranchB, orNone(d.ErrorB))
+		return
+	}
+	if d.ColumnsA != nil || d.ColumnsB != nil {
+		ch.Printer.Printf("      %s columns: %s\n", report.BranchA, strings.Join(d.ColumnsA, ", "))
+		ch.Printer.Printf("      %s columns: %s\n", report.BranchB, strings.Join(d.ColumnsB, ", "))
+		return
+	}
+	for _, row := range d.OnlyInA {
+		ch.Printer.Printf("      - %s: %s\n", report.BranchA, strings.Join(row, " | "))
+	}
+	for _, row := range d.OnlyInB {
+		ch.Printer.Printf("      + %s: %s\n", report.BranchB, strings.Join(row, " | "))
+	}
+}
+
+func printPlanDiff(ch *cmdutil.Helper, report *CompareReport, d *PlanDiff) {
+	ch.Printer.Printf("      %s plan:\n", report.BranchA)
+	for _, line := range d.PlanA {
+		ch.Printer.Printf("        %s\n", line)
+	}
+	ch.Printer.Printf("      %s plan:\n", report.BranchB)
+	for _, line := range d.PlanB {
+		ch.Printer.Printf("        %s\n", line)
+	}
+}
+
+// summarizeQuery collapses whitespace and truncates long queries for the
+// human report header.
+func summarizeQuery(query string) string {
+	q := strings.Join(strings.Fields(query), " ")
+	if len(q) > 80 {
+		return q[:77] + "..."
+	}
+	return q
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "no error"
+	}
+	return s
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,123 @@
+package sql
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestReadCompareQueriesRejectsWrites(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(t.TempDir(), "queries.sql")
+	err := os.WriteFile(path, []byte("SELECT 1;\nUPDATE users SET name = 'x';\n"), 0o644)
+	c.Assert(err, qt.IsNil)
+
+	_, err = readCompareQueries(path)
+	c.Assert(err, qt.ErrorMatches, `query 2 is not a read query(?s).*`)
+}
+
+func TestReadCompareQueries(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(t.TempDir(), "queries.sql")
+	err := os.WriteFile(path, []byte("-- users\nSELECT * FROM users;\nSHOW TABLES;\n"), 0o644)
+	c.Assert(err, qt.IsNil)
+
+	queries, err := readCompareQueries(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(queries, qt.DeepEquals, []string{"-- users\nSELECT * FROM users", "SHOW TABLES"})
+}
+
+func TestDiffResultsOrdered(t *testing.T) {
+	c := qt.New(t)
+
+	a := &branchRun{
+		columns: []string{"id", "name"},
+		rows: []map[string]any{
+			{"id": int64(1), "name": "a"},
+			{"id": int64(2), "name": "b"},
+		},
+	}
+	b := &branchRun{
+		columns: []string{"id", "name"},
+		rows: []map[string]any{
+			{"id": int64(2), "name": "b"},
+			{"id": int64(1), "name": "a"},
+		},
+	}
+
+	d := diffResults(a, b, false)
+	c.Assert(d.Same, qt.IsFalse)
+	c.Assert(d.OnlyInA, qt.DeepEquals, [][]string{{"1", "a"}, {"2", "b"}})
+	c.Assert(d.OnlyInB, qt.DeepEquals, [][]string{{"2", "b"}, {"1", "a"}})
+
+	d = diffResults(a, b, true)
+	c.Assert(d.Same, qt.IsTrue)
+}
+
+func TestDiffResultsUnorderedCountsDuplicates(t *testing.T) {
+	c := qt.New(t)
+
+	a := &branchRun{
+		columns: []string{"v"},
+		rows:    []map[string]any{{"v": "x"}, {"v": "x"}, {"v": nil}},
+	}
+	b := &branchRun{
+		columns: []string{"v"},
+		rows:    []map[string]any{{"v": "x"}, {"v": nil}, {"v": "y"}},
+	}
+
+	d := diffResults(a, b, true)
+	c.Assert(d.Same, qt.IsFalse)
+	c.Assert(d.OnlyInA, qt.DeepEquals, [][]string{{"x"}})
+	c.Assert(d.OnlyInB, qt.DeepEquals, [][]string{{"y"}})
+}
+
+func TestDiffResultsColumnsAndErrors(t *testing.T) {
+	c := qt.New(t)
+
+	d := diffResults(&branchRun{columns: []string{"a"}}, &branchRun{columns: []string{"b"}}, false)
+	c.Assert(d.Same, qt.IsFalse)
+	c.Assert(d.ColumnsA, qt.DeepEquals, []string{"a"})
+	c.Assert(d.ColumnsB, qt.DeepEquals, []string{"b"})
+
+	d = diffResults(&branchRun{err: errors.New("no such table")}, &branchRun{err: errors.New("no such table")}, false)
+	c.Assert(d.Same, qt.IsTrue)
+
+	d = diffResults(&branchRun{}, &branchRun{err: errors.New("no such table")}, false)
+	c.Assert(d.Same, qt.IsFalse)
+	c.Assert(d.ErrorB, qt.Equals, "no such table")
+}
+
+func TestPlanLinesDropsEstimates(t *testing.T) {
+	c := qt.New(t)
+
+	lines := planLines(
+		[]string{"id", "table", "type", "key", "rows", "filtered"},
+		[]map[string]any{{"id": "1", "table": "users", "type": "ref", "key": "idx_email", "rows": "12", "filtered": "100.00"}},
+	)
+	c.Assert(lines, qt.DeepEquals, []string{"id=1 table=users type=ref key=idx_email"})
+
+	lines = planLines([]string{"QUERY PLAN"}, []map[string]any{{"QUERY PLAN": "Seq Scan on users"}})
+	c.Assert(lines, qt.DeepEquals, []string{"Seq Scan on users"})
+}
+
+func TestCompareRunsMaxSlowdown(t *testing.T) {
+	c := qt.New(t)
+
+	a := &branchRun{columns: []string{"v"}, duration: 10 * time.Millisecond}
+	b := &branchRun{columns: []string{"v"}, duration: 50 * time.Millisecond}
+
+	got := compareRuns(1, "SELECT 1", a, b, &compareFlags{skipPlans: true})
+	c.Assert(got.Same, qt.IsTrue)
+	c.Assert(got.Timing.Slowdown, qt.Equals, 5.0)
+
+	got = compareRuns(1, "SELECT 1", a, b, &compareFlags{skipPlans: true, maxSlowdown: 2})
+	c.Assert(got.Same, qt.IsFalse)
+	c.Assert(got.Issues, qt.HasLen, 1)
+}
@@ -0,0 +1,231 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/printer"
+	"github.com/planetscale/cli/internal/sqlquery"
+)
+
+// defaultSessionTTL keeps session credentials short-lived unless the user
+// asks for more.
+const defaultSessionTTL = 30 * time.Minute
+
+// SQLSession is a cached credential as shown by the session commands. The
+// password itself is never printed.
+type SQLSession struct {
+	Organization string `header:"org" json:"organization"`
+	Database     string `header:"database" json:"database"`
+	Branch       string `header:"branch" json:"branch"`
+	Engine       string `header:"engine" json:"engine"`
+	Role         string `header:"role" json:"role"`
+	Replica      bool   `header:"replica" json:"replica"`
+	Name         string `header:"credential" json:"credential"`
+	ExpiresAt    int64  `header:"expires_at,timestamp(ms|utc|human)" json:"expires_at"`
+}
+
+func toSQLSession(c *sqlquery.Credential) *SQLSession {
+	return &SQLSession{
+		Organization: c.Organization,
+		Database:     c.Database,
+		Branch:       c.Branch,
+		Engine:       c.Engine,
+		Role:         c.Role,
+		Replica:      c.Replica,
+		Name:         c.Name,
+		ExpiresAt:    printer.GetMilliseconds(c.ExpiresAt),
+	}
+}
+
+func toSQLSessions(creds []*sqlquery.Credential) []*SQLSession {
+	sessions := make([]*SQLSession, 0, len(creds))
+	for _, c := range creds {
+		sessions = append(sessions, toSQLSession(c))
+	}
+	return sessions
+}
+
+// SessionCmd manages credentials that are reused across pscale sql
+// invocations.
+func SessionCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session <command>",
+		Short: "Reuse one credential across pscale sql invocations",
+		Long: `By default every pscale sql invocation creates an ephemeral password (MySQL) or
+role (PostgreSQL) and deletes it afterwards. A session keeps one short-lived
+credential per organization, database, branch, role, and replica setting, and
+every pscale sql, sql compare, and inspect invocation with the same settings
+reuses it until it expires.
+
+Session credentials are stored in the system keyring (the macOS Keychain, the
+Windows Credential Manager, or the Secret Service or KWallet on Linux).
+They hold database passwords, so they are never written to a plaintext file.
+Headless Linux machines and CI runners usually have no keyring: sessions
+can't be started there, and every invocation uses its own ephemeral
+credential instead. Sessions are deleted server-side by pscale sql session
+end, or expire on their own after --ttl.
+
+A database named session is reached with pscale sql -- session.`,
+	}
+
+	cmd.AddCommand(SessionStartCmd(ch))
+	cmd.AddCommand(SessionListCmd(ch))
+	cmd.AddCommand(SessionEndCmd(ch))
+
+	return cmd
+}
+
+// SessionStartCmd creates and caches a session credential for a branch.
+func SessionStartCmd(ch *cmdutil.Helper) *cobra.Command {
+	var flags struct {
+		role    string
+		replica bool
+		ttl     cmdutil.TTLFlag
+	}
+	flags.ttl.Value = defaultSessionTTL
+
+	cmd := &cobra.Command{
+		Use:   "start <database> <branch>",
+		Short: "Create a credential that later pscale sql invocations reuse",
+		Args:  cmdutil.RequiredArgs("database", "branch"),
+		Example: `  # Reuse one reader credential for the next 30 minutes
+  pscale sql session start <database> <branch> --org <org>
+
+  # Reuse an admin credential for an hour
+  pscale sql session start <database> <branch> --org <org> --role admin --ttl 1h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			database, branch := args[0], args[1]
+
+			if flags.ttl.Value == 0 {
+				return errors.New("--ttl must be greater than 0; session credentials always expire")
+			}
+
+			end := ch.Printer.PrintProgress(fmt.Sprintf("Starting a session for %s/%s...",
+				printer.BoldBlue(database), printer.BoldBlue(branch)))
+			defer end()
+
+			cred, err := sqlquery.StartSession(ctx, ch, sqlquery.SessionOptions{
+				Organization: ch.Config.Organization,
+				Database:     database,
+				Branch:       branch,
+				Role:         flags.role,
+				Replica:      flags.replica,
+				TTL:          flags.ttl.Value,
+			})
+			if err != nil {
+				return cmdutil.HandleError(err)
+			}
+			end()
+
+			if ch.Printer.Format() == printer.Human {
+				ch.Printer.Printf("Session for %s/%s (%s) started; it expires in %s.\n",
+					printer.BoldBlue(database), printer.BoldBlue(branch), cred.Role, flags.ttl.Value)
+				ch.Printer.Printf("pscale sql invocations with the same role reuse it. End it with: %s\n",
+					printer.BoldBlue(fmt.Sprintf("pscale sql session end %s %s", database, branch)))
+				return nil
+			}
+
+			return ch.Printer.PrintResource(toSQLSession(cred))
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.role, "role", "",
+		"Role defines the access level, allowed values are: reader, writer, readwriter, admin. Defaults to reader, same as pscale sql.")
+	cmd.Flags().BoolVar(&flags.replica, "replica", false,
+		"Create the credential for pscale sql --replica invocations.")
+	cmd.Flags().Var(&flags.ttl, "ttl", `How long the session credential lives. Durations such as "30m" or "2h" are accepted.`)
+	cmdutil.SetJSONOutput(cmd, &SQLSession{})
+
+	return cmd
+}
+
+// SessionListCmd lists the cached session credentials.
+func SessionListCmd(ch *cmdutil.Helper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List active pscale sql sessions",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			creds, err := sqlquery.ListSessions()
+			if err != nil {
+				return err
+			}
+
+			if len(creds) == 0 && ch.Printer.Format() == printer.Human {
+				ch.Printer.Println("No active sessions.")
+				return nil
+			}
+
+			return ch.Printer.PrintResource(toSQLSessions(creds))
+		},
+	}
+	cmdutil.SetJSONOutput(cmd, []*SQLSession{})
+	cmdutil.SetReadOnly(cmd)
+	cmdutil.SetLocal(cmd)
+
+	return cmd
+}
+
+// SessionEndCmd revokes session credentials.
+func SessionEndCmd(ch *cmdutil.Helper) *cobra.Command {
+	var flags struct {
+		all bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "end [database] [branch]",
+		Short: "Delete session credentials and stop reusing them",
+		Long: `Delete session credentials and stop reusing them.
+
+Pass a database to end its sessions in the current organization, a database
+and branch to end that branch's sessions, or --all to end every session.`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if len(args) == 0 && !flags.all {
+				return errors.New("specify a database (and optionally a branch), or pass --all")
+			}
+
+			ended, err := sqlquery.EndSessions(ctx, ch, func(c *sqlquery.Credential) bool {
+				if flags.all {
+					return true
+				}
+				if c.Organization != ch.Config.Organization || c.Database != args[0] {
+					return false
+				}
+				return len(args) < 2 || c.Branch == args[1]
+			})
+			if err != nil {
+				return cmdutil.HandleError(err)
+			}
+
+			if ch.Printer.Format() == printer.Human {
+				switch len(ended) {
+				case 0:
+					ch.Printer.Println("No matching sessions.")
+				case 1:
+					ch.Printer.Printf("Ended session for %s/%s (%s).\n",
+						printer.BoldBlue(ended[0].Database), printer.BoldBlue(ended[0].Branch), ended[0].Role)
+				default:
+					ch.Printer.Printf("Ended %d sessions.\n", len(ended))
+				}
+				return nil
+			}
+
+			return ch.Printer.PrintResource(toSQLSessions(ended))
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.all, "all", false, "End every session, in all organizations")
+	cmdutil.SetJSONOutput(cmd, []*SQLSession{})
+
+	return cmd
+}
@@ -40,9 +40,17 @@ one shard; enumerate shards with SHOW VITESS_SHARDS.
 
 PostgreSQL databases use --dbname (default postgres).
 
+Each invocation creates and deletes an ephemeral credential. To reuse one
+credential across many invocations, start a session with pscale sql session start.
+
 Place flags after positional arguments (see Usage). --org is required:
 
-  pscale sql <database> <branch> --org <org> --format json --query "SELECT 1"`,
+  pscale sql <database> <branch> --org <org> --format json --query "SELECT 1"
+
+A database named compare or session would run that subcommand instead. Pass
+the flags first and separate the positional arguments with --:
+
+  pscale sql --org <org> --query "SELECT 1" -- compare <branch>`,
 		Args: cmdutil.RequiredArgs("database", "branch"),
 		Example: `  # Read query (default reader role)
   pscale sql <database> <branch> --org <org> --format json --query "SELECT 1"
@@ -72,6 +80,8 @@ Place flags after positional arguments (see Usage). --org is required:
 			switch ch.Printer.Format() {
 			case printer.JSON:
 				return ch.Printer.PrintJSON(result)
+			case printer.YAML:
+				return ch.Printer.PrintYAML(result)
 			case printer.Human:
 				if result.RowsAffected > 0 && result.RowCount == 0 {
 					ch.Printer.Printf("Rows affected: %d\n", result.RowsAffected)
@@ -102,5 +112,9 @@ Place flags after positional arguments (see Usage). --org is required:
 	cmd.MarkFlagRequired("query")         // nolint:errcheck
 	cmd.MarkPersistentFlagRequired("org") // nolint:errcheck
 
+	cmd.AddCommand(CompareCmd(ch))
+	cmd.AddCommand(SessionCmd(ch))
+	cmdutil.SetJSONOutput(cmd, &sqlquery.Result{})
+
 	return cmd
 }
@@ -50,6 +50,32 @@ func TestSQLCmdParsesPositionalsBeforeOrgFlag(t *testing.T) {
 	}
 }
 
+func TestSQLCmdDatabaseNamedLikeSubcommand(t *testing.T) {
+	format := printer.JSON
+	ch := &cmdutil.Helper{
+		Printer: printer.NewPrinter(&format),
+		Config:  &config.Config{AccessToken: "token"},
+	}
+	cmd := SQLCmd(ch)
+	ran := false
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		ran = true
+		if len(args) != 2 || args[0] != "compare" || args[1] != "main" {
+			t.Fatalf("args = %v, want [compare main]", args)
+		}
+		return nil
+	}
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--org", "acme", "--query", "SELECT 1", "--", "compare", "main"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !ran {
+		t.Fatal("expected pscale sql -- compare to run sql, not sql compare")
+	}
+}
+
 func TestSQLCmdDestructiveQueryReturnsActionRequiredJSON(t *testing.T) {
 	format := printer.JSON
 	var out bytes.Buffer
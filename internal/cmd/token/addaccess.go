@@ -71,6 +71,7 @@ For a complete list of the access permissions that can be granted to a token, se
 	}
 
 	cmd.PersistentFlags().StringVar(&ch.Config.Database, "database", ch.Config.Database, "The database to add access to")
+	cmdutil.SetJSONOutput(cmd, []*ServiceTokenAccess{})
 
 	return cmd
 }
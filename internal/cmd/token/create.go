@@ -50,6 +50,7 @@ func CreateCmd(ch *cmdutil.Helper) *cobra.Command {
 
 	cmd.Flags().StringVar(&name, "name", "", "optional name for the service token")
 	cmd.Flags().IntVar(&ttl, "ttl", 0, "Time to live (in seconds) for the service token. The token will be invalid when TTL has passed")
+	cmdutil.SetJSONOutput(cmd, &planetscale.ServiceToken{})
 
 	return cmd
 }
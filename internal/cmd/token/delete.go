@@ -58,6 +58,7 @@ func DeleteCmd(ch *cmdutil.Helper) *cobra.Command {
 			)
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
 
 	return cmd
 }
@@ -84,6 +84,7 @@ To remove an organization level grant:
 	}
 
 	cmd.PersistentFlags().StringVar(&ch.Config.Database, "database", ch.Config.Database, "The database to remove access to")
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
 
 	return cmd
 }
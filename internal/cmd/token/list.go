@@ -1,6 +1,7 @@
 package token
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/planetscale/cli/internal/cmdutil"
@@ -10,6 +11,10 @@ import (
 )
 
 func ListCmd(ch *cmdutil.Helper) *cobra.Command {
+	var flags struct {
+		all bool
+	}
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "list service tokens for the organization",
@@ -27,8 +32,7 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			end := ch.Printer.PrintProgress(fmt.Sprintf("Fetching service tokens from org %s", printer.BoldBlue(ch.Config.Organization)))
 			defer end()
 
-			tokens, err := client.ServiceTokens.List(ctx, req)
-			if err != nil {
+			handleErr := func(err error) error {
 				switch cmdutil.ErrCode(err) {
 				case planetscale.ErrNotFound:
 					return fmt.Errorf("organization %s does not exist", printer.BoldBlue(ch.Config.Organization))
@@ -37,6 +41,25 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 				}
 			}
 
+			if flags.all {
+				listTokens := func(ctx context.Context, opts ...planetscale.ListOption) ([]*planetscale.ServiceToken, error) {
+					return client.ServiceTokens.List(ctx, req, opts...)
+				}
+				n, err := cmdutil.PrintPages(ch.Printer, planetscale.Pages(ctx, planetscale.MaxPerPage, listTokens), end, toServiceTokens)
+				if err != nil {
+					return handleErr(err)
+				}
+				if n == 0 && ch.Printer.Format() == printer.Human {
+					ch.Printer.Println("No service tokens have been created yet.")
+				}
+				return nil
+			}
+
+			tokens, err := client.ServiceTokens.List(ctx, req)
+			if err != nil {
+				return handleErr(err)
+			}
+
 			end()
 
 			if len(tokens) == 0 && ch.Printer.Format() == printer.Human {
@@ -48,5 +71,9 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 		},
 	}
 
+	cmdutil.AllPagesFlag(cmd, &flags.all)
+	cmdutil.SetJSONOutput(cmd, []*planetscale.ServiceToken{})
+	cmdutil.SetReadOnly(cmd)
+
 	return cmd
 }
@@ -34,7 +34,7 @@ func TestServiceToken_ListCmd(t *testing.T) {
 	}
 
 	svc := &mock.ServiceTokenService{
-		ListFn: func(ctx context.Context, req *ps.ListServiceTokensRequest) ([]*ps.ServiceToken, error) {
+		ListFn: func(ctx context.Context, req *ps.ListServiceTokensRequest, opts ...ps.ListOption) ([]*ps.ServiceToken, error) {
 			c.Assert(req.Organization, qt.Equals, org)
 			return orig, nil
 		},
@@ -87,7 +87,7 @@ func TestServiceToken_ListCmdWithExpiresAt(t *testing.T) {
 	}
 
 	svc := &mock.ServiceTokenService{
-		ListFn: func(ctx context.Context, req *ps.ListServiceTokensRequest) ([]*ps.ServiceToken, error) {
+		ListFn: func(ctx context.Context, req *ps.ListServiceTokensRequest, opts ...ps.ListOption) ([]*ps.ServiceToken, error) {
 			c.Assert(req.Organization, qt.Equals, org)
 			return orig, nil
 		},
@@ -50,6 +50,8 @@ func ShowAccessCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toServiceTokenGrants(grants))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*ServiceTokenGrant{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -93,6 +93,7 @@ func BudgetCreateCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().IntVar(&flags.warningThreshold, "warning-threshold", 0, "Percentage (0-100) of capacity, burst, or concurrency at which to emit warnings for enforced budgets.")
 
 	cmd.MarkFlagRequired("name") // nolint:errcheck
+	cmdutil.SetJSONOutput(cmd, &ps.TrafficBudget{})
 
 	return cmd
 }
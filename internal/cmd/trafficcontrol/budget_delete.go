@@ -74,5 +74,7 @@ func BudgetDeleteCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "Delete a budget without confirmation")
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
+
 	return cmd
 }
@@ -62,6 +62,8 @@ func BudgetShowCmd(ch *cmdutil.Helper) *cobra.Command {
 			return nil
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.TrafficBudget{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -85,6 +85,7 @@ func RuleCreateCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().StringVar(&flags.fingerprint, "fingerprint", "", "SQL fingerprint to match")
 	cmd.Flags().StringVar(&flags.keyspace, "keyspace", "", "Keyspace to match")
 	cmd.Flags().StringArrayVar(&flags.tags, "tag", nil, "Tag in the format key=<key>,value=<value>,source=<source> (repeatable)")
+	cmdutil.SetJSONOutput(cmd, &ps.TrafficRule{})
 
 	return cmd
 }
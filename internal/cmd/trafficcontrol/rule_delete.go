@@ -77,5 +77,7 @@ func RuleDeleteCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "Delete a rule without confirmation")
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
+
 	return cmd
 }
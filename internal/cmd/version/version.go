@@ -31,6 +31,7 @@ func VersionCmd(ch *cmdutil.Helper, ver, commit, buildDate string) *cobra.Comman
 			return ch.Printer.PrintResource(v)
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
 
 	return cmd
 }
@@ -0,0 +1,118 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/planetscale/cli/internal/printer"
+)
+
+// condition is a condition of --until, such as "state=ready" or
+// "ready!=true".
+type condition struct {
+	field string
+	value string
+	not   bool
+}
+
+func parseConditions(conditions []string) ([]*condition, error) {
+	var out []*condition
+	for _, s := range conditions {
+		c := &condition{}
+		field, value, ok := strings.Cut(s, "!=")
+		if ok {
+			c.not = true
+		} else {
+			field, value, ok = strings.Cut(s, "=")
+		}
+		c.field, c.value = strings.TrimSpace(field), strings.TrimSpace(value)
+		if !ok || c.field == "" {
+			return nil, fmt.Errorf("invalid --until condition %q, it must be field=value or field!=value", s)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (c *condition) String() string {
+	if c.not {
+		return c.field + "!=" + c.value
+	}
+	return c.field + "=" + c.value
+}
+
+// matches reports whether the resource of snap, or every resource of a non
+// empty list, meets c. The field is a dotted path of the JSON output, such
+// as "state" or "region.slug", or a column of the table, and the value is
+// compared with both, ignoring case: "ready=true" and "ready=yes" are the
+// same.
+func (c *condition) matches(snap *printer.Snapshot) (bool, error) {
+	var v interface{}
+	if err := json.Unmarshal(snap.JSON, &v); err != nil {
+		return false, err
+	}
+
+	items, list := v.([]interface{})
+	if !list {
+		items = []interface{}{v}
+	}
+	if len(items) == 0 {
+		return false, nil
+	}
+
+	column := snap.Column(c.field)
+	for i, item := range items {
+		var values []string
+		if value, ok := lookup(item, c.field); ok {
+			values = append(values, value)
+		}
+		if column >= 0 && i < len(snap.Rows) {
+			values = append(values, snap.Rows[i][column])
+		}
+		if len(values) == 0 {
+			return false, fmt.Errorf("unknown --until field %q", c.field)
+		}
+
+		equal := slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, c.value) })
+		if equal == c.not {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// lookup returns the value at the dotted path of v, decoded JSON, as text.
+func lookup(v interface{}, path string) (string, bool) {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if v, ok = obj[key]; !ok {
+			return "", false
+		}
+	}
+
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+func conditionStrings(conditions []*condition) []string {
+	out := make([]string, len(conditions))
+	for i, c := range conditions {
+		out[i] = c.String()
+	}
+	return out
+}
+
+func conditionsString(conditions []*condition) string {
+	return strings.Join(conditionStrings(conditions), " and ")
+}
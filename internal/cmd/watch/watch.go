@@ -0,0 +1,407 @@
+// Package watch implements the global --watch flag, which re-runs a command
+// that shows resources and highlights what changed, until a condition given
+// with --until holds.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/lensesio/tableprinter"
+	"github.com/spf13/cobra"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/printer"
+)
+
+const (
+	// DefaultInterval is the interval of --watch without a value.
+	DefaultInterval = 2 * time.Second
+
+	// minInterval keeps --watch from flooding the API.
+	minInterval = time.Second
+)
+
+type options struct {
+	interval time.Duration
+	until    []string
+}
+
+// Register adds the --watch and --until flags to root, and makes every
+// command of root that only reads resources from the API watchable.
+func Register(root *cobra.Command, ch *cmdutil.Helper) {
+	opts := &options{}
+	root.PersistentFlags().DurationVar(&opts.interval, "watch", 0,
+		"Re-run the command every `interval` and highlight what changed. JSON output is a stream of change events")
+	root.PersistentFlags().Lookup("watch").NoOptDefVal = DefaultInterval.String()
+	root.PersistentFlags().StringArrayVar(&opts.until, "until", nil,
+		"With --watch, exit once a `field=value` or field!=value condition holds for the resource, or every listed resource. Implies --watch")
+
+	wrap(root, ch, opts)
+}
+
+func wrap(cmd *cobra.Command, ch *cmdutil.Helper, opts *options) {
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if opts.interval == 0 && len(opts.until) == 0 {
+				return run(cmd, args)
+			}
+			w, err := newWatcher(cmd, ch, opts, args)
+			if err != nil {
+				return err
+			}
+			return w.run(cmd.Context(), func() error { return run(cmd, args) })
+		}
+	}
+	for _, child := range cmd.Commands() {
+		wrap(child, ch, opts)
+	}
+}
+
+// watcher re-runs a command and prints its resources.
+type watcher struct {
+	printer  *printer.Printer
+	out      io.Writer
+	format   printer.Format
+	interval time.Duration
+	until    []*condition
+
+	// title is shown above the resources, with the time.
+	title string
+	// redraw reports whether the resources are printed over the previous
+	// ones, rather than after them.
+	redraw bool
+	now    func() time.Time
+	// errOut is where the errors of ticks after the first one are logged.
+	errOut io.Writer
+}
+
+// watchable reports whether cmd can be re-run by --watch: it only reads
+// resources, and from the API rather than from local state.
+func watchable(cmd *cobra.Command) bool {
+	return cmdutil.IsReadOnly(cmd) && !cmdutil.IsLocal(cmd)
+}
+
+func newWatcher(cmd *cobra.Command, ch *cmdutil.Helper, opts *options, args []string) (*watcher, error) {
+	if !watchable(cmd) {
+		return nil, fmt.Errorf("--watch can't be used with %s, only with commands that read resources from the API, such as list and show commands",
+			cmd.CommandPath())
+	}
+
+	format := ch.Printer.Format()
+	if format != printer.Human && format != printer.JSON && format != printer.NDJSON {
+		return nil, fmt.Errorf("--watch supports the human, json and ndjson formats, not %s", format)
+	}
+
+	interval := opts.interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	if interval < minInterval {
+		return nil, fmt.Errorf("--watch interval must be at least %s", minInterval)
+	}
+
+	until, err := parseConditions(opts.until)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spinners and messages would garble the resources.
+	ch.Printer.SetHumanOutput(io.Discard)
+
+	return &watcher{
+		printer:  ch.Printer,
+		out:      ch.Printer.ResourceOutput(),
+		format:   format,
+		interval: interval,
+		until:    until,
+		title:    strings.Join(append([]string{cmd.CommandPath()}, args...), " "),
+		redraw:   printer.IsTTY,
+		now:      time.Now,
+		errOut:   os.Stderr,
+	}, nil
+}
+
+// run calls tick, which prints resources, every interval until ctx is done
+// or the resources meet the conditions. An error of the first tick ends the
+// watch; later ones, such as a transient API error, are logged and the tick
+// is retried at the next interval.
+func (w *watcher) run(ctx context.Context, tick func() error) error {
+	var (
+		prev  *printer.Snapshot
+		timer *time.Timer
+		first = true
+	)
+	for {
+		snap, err := w.capture(tick)
+		switch {
+		case err != nil && first:
+			return err
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := w.printError(err); err != nil {
+				return err
+			}
+		default:
+			done, err := w.matches(snap)
+			if err != nil {
+				return err
+			}
+			if err := w.print(snap, prev, done); err != nil {
+				return err
+			}
+			if done {
+				return nil
+			}
+			prev = snap
+		}
+		first = false
+
+		if timer == nil {
+			timer = time.NewTimer(w.interval)
+			defer timer.Stop()
+		} else {
+			timer.Reset(w.interval)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// capture runs tick and returns the snapshot of the last resource it
+// printed, or nil if it printed none.
+func (w *watcher) capture(tick func() error) (*printer.Snapshot, error) {
+	var v interface{}
+	w.printer.SetCapture(func(r interface{}) error {
+		v = r
+		return nil
+	})
+	defer w.printer.SetCapture(nil)
+
+	if err := tick(); err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return w.printer.Snapshot(v)
+}
+
+func (w *watcher) matches(snap *printer.Snapshot) (bool, error) {
+	if len(w.until) == 0 || snap == nil {
+		return false, nil
+	}
+	for _, c := range w.until {
+		ok, err := c.matches(snap)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (w *watcher) print(snap, prev *printer.Snapshot, done bool) error {
+	if w.format == printer.JSON || w.format == printer.NDJSON {
+		return w.printEvents(snap, prev, done)
+	}
+	return w.printTable(snap, prev, done)
+}
+
+var highlight = color.New(color.FgYellow, color.Bold)
+
+// printTable prints the table of snap, with the cells that changed since
+// prev highlighted. Unless it redraws, it prints only when something
+// changed.
+func (w *watcher) printTable(snap, prev *printer.Snapshot, done bool) error {
+	changed := snapshotChanged(snap, prev)
+	if !w.redraw && !changed && !done {
+		return nil
+	}
+
+	var b strings.Builder
+	if w.redraw {
+		// Move to the top left corner and clear the screen.
+		b.WriteString("\033[H\033[2J")
+	}
+	fmt.Fprintf(&b, "%s %s  %s\n\n", printer.Bold(fmt.Sprintf("Every %s:", w.interval)), w.title, w.now().Format(time.TimeOnly))
+
+	if snap == nil || len(snap.Headers) == 0 {
+		b.WriteString("Nothing to show.\n")
+	} else {
+		cells := snap.Changed(prev)
+		rows := make([][]string, len(snap.Rows))
+		for i, row := range snap.Rows {
+			rows[i] = slices.Clone(row)
+			for j := range row {
+				if cells[i][j] {
+					rows[i][j] = highlight.Sprint(row[j])
+				}
+			}
+		}
+		tableprinter.New(&b).Render(slices.Clone(snap.Headers), rows, snap.Numbers, true)
+	}
+
+	if len(w.until) > 0 {
+		if done {
+			fmt.Fprintf(&b, "\n%s %s\n", printer.BoldGreen("Done:"), conditionsString(w.until))
+		} else {
+			fmt.Fprintf(&b, "\nWaiting until %s\n", conditionsString(w.until))
+		}
+	}
+
+	_, err := io.WriteString(w.out, b.String())
+	return err
+}
+
+// printError logs the error of a tick, as an event with JSON output.
+func (w *watcher) printError(err error) error {
+	if w.format == printer.JSON || w.format == printer.NDJSON {
+		return w.encode([]event{{Type: "error", Error: err.Error()}})
+	}
+	_, werr := fmt.Fprintf(w.errOut, "%s %s: %v (retrying in %s)\n",
+		w.now().Format(time.TimeOnly), printer.Red("Error"), err, w.interval)
+	return werr
+}
+
+// event is a line of the JSON output of --watch.
+type event struct {
+	Time time.Time `json:"time"`
+	// Type is "snapshot" for the first resources, "change" when they
+	// changed, "error" when re-running the command failed, and "done" once
+	// they meet the --until conditions.
+	Type     string          `json:"type"`
+	Changes  []change        `json:"changes,omitempty"`
+	Until    []string        `json:"until,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Resource json.RawMessage `json:"resource,omitempty"`
+}
+
+// change is a JSON value of the resources that changed. Path is the dotted
+// path of the value, with indexes for lists: "0.state".
+type change struct {
+	Path string          `json:"path"`
+	Old  json.RawMessage `json:"old"`
+	New  json.RawMessage `json:"new"`
+}
+
+// printEvents prints the events for snap as JSON lines.
+func (w *watcher) printEvents(snap, prev *printer.Snapshot, done bool) error {
+	var events []event
+	switch {
+	case snap == nil:
+	case prev == nil:
+		events = append(events, event{Type: "snapshot", Resource: snap.JSON})
+	default:
+		changes, err := diffJSON(prev.JSON, snap.JSON)
+		if err != nil {
+			return err
+		}
+		if len(changes) > 0 {
+			events = append(events, event{Type: "change", Changes: changes, Resource: snap.JSON})
+		}
+	}
+	if done {
+		events = append(events, event{Type: "done", Until: conditionStrings(w.until)})
+	}
+	return w.encode(events)
+}
+
+// encode prints events as JSON lines.
+func (w *watcher) encode(events []event) error {
+	enc := json.NewEncoder(w.out)
+	enc.SetEscapeHTML(false)
+	for _, e := range events {
+		e.Time = w.now().UTC()
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func snapshotChanged(snap, prev *printer.Snapshot) bool {
+	switch {
+	case snap == nil || prev == nil:
+		return snap != prev
+	default:
+		changes, err := diffJSON(prev.JSON, snap.JSON)
+		return err != nil || len(changes) > 0
+	}
+}
+
+// diffJSON returns the values that differ between the JSON documents a and
+// b.
+func diffJSON(a, b json.RawMessage) ([]change, error) {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return nil, err
+	}
+
+	var changes []change
+	diffValues("", va, vb, &changes)
+	return changes, nil
+}
+
+func diffValues(path string, a, b interface{}, changes *[]change) {
+	switch va := a.(type) {
+	case map[string]interface{}:
+		if vb, ok := b.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(va)+len(vb))
+			for k := range va {
+				keys = append(keys, k)
+			}
+			for k := range vb {
+				if _, ok := va[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				diffValues(joinPath(path, k), va[k], vb[k], changes)
+			}
+			return
+		}
+	case []interface{}:
+		if vb, ok := b.([]interface{}); ok {
+			for i := 0; i < max(len(va), len(vb)); i++ {
+				var ea, eb interface{}
+				if i < len(va) {
+					ea = va[i]
+				}
+				if i < len(vb) {
+					eb = vb[i]
+				}
+				diffValues(joinPath(path, fmt.Sprint(i)), ea, eb, changes)
+			}
+			return
+		}
+	}
+
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	if string(ja) != string(jb) {
+		*changes = append(*changes, change{Path: path, Old: ja, New: jb})
+	}
+}
+
+func joinPath(path, elem string) string {
+	if path == "" {
+		return elem
+	}
+	return path + "." + elem
+}
@@ -0,0 +1,203 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/cobra"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/printer"
+)
+
+type branch struct {
+	Name  string `header:"name" json:"name"`
+	State string `header:"state" json:"state"`
+	Ready bool   `header:"ready" json:"ready"`
+}
+
+// ticks returns a tick printing the resources of states, one per call, and
+// then the last one again.
+func ticks(p *printer.Printer, states ...string) func() error {
+	i := 0
+	return func() error {
+		state := states[min(i, len(states)-1)]
+		i++
+		return p.PrintResource(&branch{Name: "main", State: state, Ready: state == "ready"})
+	}
+}
+
+func newTestWatcher(format printer.Format, until ...string) (*watcher, *bytes.Buffer) {
+	var out bytes.Buffer
+	p := printer.NewPrinter(&format)
+	p.SetResourceOutput(&out)
+
+	conditions, err := parseConditions(until)
+	if err != nil {
+		panic(err)
+	}
+	return &watcher{
+		printer:  p,
+		out:      &out,
+		format:   format,
+		interval: time.Millisecond,
+		until:    conditions,
+		title:    "pscale branch show db main",
+		now:      func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+		errOut:   &out,
+	}, &out
+}
+
+func TestWatchJSONEvents(t *testing.T) {
+	c := qt.New(t)
+
+	w, out := newTestWatcher(printer.JSON, "state=ready")
+	err := w.run(context.Background(), ticks(w.printer, "pending", "pending", "ready"))
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(out.String(), qt.Equals, `{"time":"2026-01-02T03:04:05Z","type":"snapshot","resource":{"name":"main","state":"pending","ready":false}}
+{"time":"2026-01-02T03:04:05Z","type":"change","changes":[{"path":"ready","old":false,"new":true},{"path":"state","old":"pending","new":"ready"}],"resource":{"name":"main","state":"ready","ready":true}}
+{"time":"2026-01-02T03:04:05Z","type":"done","until":["state=ready"]}
+`)
+}
+
+func TestWatchHumanPrintsChanges(t *testing.T) {
+	c := qt.New(t)
+
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	w, out := newTestWatcher(printer.Human, "ready=yes")
+	err := w.run(context.Background(), ticks(w.printer, "pending", "pending", "resizing", "ready"))
+	c.Assert(err, qt.IsNil)
+
+	// Without redrawing, the table is printed when it changes.
+	got := out.String()
+	c.Assert(strings.Count(got, "Every 1ms: pscale branch show db main  03:04:05"), qt.Equals, 3)
+	c.Assert(strings.Count(got, "Waiting until ready=yes"), qt.Equals, 2)
+	c.Assert(got, qt.Contains, "resizing")
+	c.Assert(strings.HasSuffix(got, "Done: ready=yes\n"), qt.IsTrue)
+}
+
+func TestWatchStopsWhenCanceled(t *testing.T) {
+	c := qt.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w, out := newTestWatcher(printer.JSON)
+	calls := 0
+	err := w.run(ctx, func() error {
+		calls++
+		if calls == 3 {
+			cancel()
+		}
+		return w.printer.PrintResource(&branch{Name: "main", State: "pending"})
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(calls, qt.Equals, 3)
+	c.Assert(strings.Count(out.String(), "\n"), qt.Equals, 1)
+}
+
+func TestWatchRetriesAfterTickError(t *testing.T) {
+	c := qt.New(t)
+
+	w, out := newTestWatcher(printer.JSON, "state=ready")
+	next := ticks(w.printer, "pending", "ready")
+	calls := 0
+	err := w.run(context.Background(), func() error {
+		calls++
+		if calls == 2 {
+			return errors.New("502 bad gateway")
+		}
+		return next()
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(calls, qt.Equals, 3)
+	c.Assert(out.String(), qt.Contains, `{"time":"2026-01-02T03:04:05Z","type":"error","error":"502 bad gateway"}`)
+	c.Assert(out.String(), qt.Contains, `"type":"done"`)
+
+	// An error of the first tick, such as a missing resource, ends the watch.
+	w, _ = newTestWatcher(printer.Human)
+	err = w.run(context.Background(), func() error { return errors.New("not found") })
+	c.Assert(err, qt.ErrorMatches, "not found")
+}
+
+func TestWatchListCondition(t *testing.T) {
+	c := qt.New(t)
+
+	w, out := newTestWatcher(printer.JSON, "state!=pending")
+	i := 0
+	err := w.run(context.Background(), func() error {
+		i++
+		rows := []*branch{{Name: "main", State: "ready"}, {Name: "dev", State: "pending"}}
+		if i > 1 {
+			rows[1].State = "ready"
+		}
+		return w.printer.PrintResource(rows)
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(i, qt.Equals, 2)
+	c.Assert(out.String(), qt.Contains, `"changes":[{"path":"1.state","old":"pending","new":"ready"}]`)
+}
+
+func TestConditions(t *testing.T) {
+	c := qt.New(t)
+
+	_, err := parseConditions([]string{"state"})
+	c.Assert(err, qt.ErrorMatches, `invalid --until condition "state", it must be field=value or field!=value`)
+
+	w, _ := newTestWatcher(printer.JSON, "size=1")
+	err = w.run(context.Background(), ticks(w.printer, "ready"))
+	c.Assert(err, qt.ErrorMatches, `unknown --until field "size"`)
+}
+
+func TestRegister(t *testing.T) {
+	c := qt.New(t)
+
+	format := printer.Human
+	ch := &cmdutil.Helper{Printer: printer.NewPrinter(&format)}
+	var out bytes.Buffer
+	ch.Printer.SetResourceOutput(&out)
+
+	root := &cobra.Command{Use: "pscale", SilenceErrors: true, SilenceUsage: true}
+	calls := 0
+	root.AddCommand(&cobra.Command{
+		Use: "create",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			calls++
+			return nil
+		},
+	})
+	// Commands that only read local state can't be watched.
+	get := &cobra.Command{
+		Use: "get",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			calls++
+			return nil
+		},
+	}
+	cmdutil.SetReadOnly(get)
+	cmdutil.SetLocal(get)
+	config := &cobra.Command{Use: "config"}
+	config.AddCommand(get)
+	root.AddCommand(config)
+	Register(root, ch)
+
+	root.SetArgs([]string{"create"})
+	c.Assert(root.Execute(), qt.IsNil)
+	c.Assert(calls, qt.Equals, 1)
+
+	root.SetArgs([]string{"create", "--watch"})
+	c.Assert(root.Execute(), qt.ErrorMatches, `--watch can't be used with pscale create, .*`)
+	c.Assert(calls, qt.Equals, 1)
+
+	root.SetArgs([]string{"config", "get", "--watch"})
+	c.Assert(root.Execute(), qt.ErrorMatches, `--watch can't be used with pscale config get, .*`)
+	c.Assert(calls, qt.Equals, 1)
+}
@@ -65,6 +65,7 @@ func CreateCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().BoolVar(&flags.enabled, "enabled", true, "Whether the webhook is enabled")
 
 	cmd.MarkFlagRequired("url") // nolint:errcheck
+	cmdutil.SetJSONOutput(cmd, &planetscale.Webhook{})
 
 	return cmd
 }
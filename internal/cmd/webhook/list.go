@@ -50,6 +50,8 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toWebhooks(webhooks))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*planetscale.Webhook{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -47,6 +47,8 @@ func ShowCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toWebhookWithSecret(webhook))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &planetscale.Webhook{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -54,6 +54,7 @@ func TestCmd(ch *cmdutil.Helper) *cobra.Command {
 			})
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, map[string]string{})
 
 	return cmd
 }
@@ -80,6 +80,7 @@ func UpdateCmd(ch *cmdutil.Helper) *cobra.Command {
 	cmd.Flags().StringVar(&flags.url, "url", "", "The URL to send webhook events to")
 	cmd.Flags().StringSliceVar(&flags.events, "events", nil, "Comma-separated list of events to subscribe to")
 	cmd.Flags().BoolVar(&flags.enabled, "enabled", true, "Whether the webhook is enabled")
+	cmdutil.SetJSONOutput(cmd, &planetscale.Webhook{})
 
 	return cmd
 }
@@ -99,6 +99,7 @@ marks it as cancelled, allowing you to start a new workflow if needed.`,
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "Force cancel the workflow without confirmation")
+	cmdutil.SetJSONOutput(cmd, &ps.Workflow{})
 
 	return cmd
 }
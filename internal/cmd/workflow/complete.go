@@ -61,6 +61,7 @@ func CompleteCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toWorkflow(workflow))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.Workflow{})
 
 	return cmd
 }
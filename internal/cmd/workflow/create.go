@@ -82,6 +82,7 @@ func CreateCmd(ch *cmdutil.Helper) *cobra.Command {
 			cobra.CompletionWithDesc("IGNORE", "Ignore the DDL statement and continue."),
 		}, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmdutil.SetJSONOutput(cmd, &ps.Workflow{})
 
 	return cmd
 }
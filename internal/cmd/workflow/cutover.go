@@ -113,6 +113,7 @@ func CutoverCmd(ch *cmdutil.Helper) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "Force the cutover without prompting for confirmation.")
+	cmdutil.SetJSONOutput(cmd, &ps.Workflow{})
 
 	return cmd
 }
@@ -49,6 +49,8 @@ func ListCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toMinimalWorkflows(workflows))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, []*ps.Workflow{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -63,6 +63,7 @@ This is useful if your application has errors and you need to rollback after a c
 			return ch.Printer.PrintResource(toWorkflow(workflow))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.Workflow{})
 
 	return cmd
 }
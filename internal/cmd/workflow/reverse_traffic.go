@@ -62,6 +62,7 @@ This is useful when you need to rollback a traffic switch operation.`,
 			return ch.Printer.PrintResource(toWorkflow(workflow))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.Workflow{})
 
 	return cmd
 }
@@ -54,6 +54,8 @@ func ShowCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toWorkflow(workflow))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.Workflow{})
+	cmdutil.SetReadOnly(cmd)
 
 	return cmd
 }
@@ -126,6 +126,7 @@ By default, this command will route all queries for primary, replica, and read-o
 
 	cmd.Flags().BoolVar(&replicasOnly, "replicas-only", false, "Route read queries from the replica and read-only tablets to the target keyspace.")
 	cmd.Flags().BoolVar(&force, "force", false, "Force the switch traffic operation without prompting for confirmation.")
+	cmdutil.SetJSONOutput(cmd, &ps.Workflow{})
 
 	return cmd
 }
@@ -59,6 +59,7 @@ func VerifyDataCmd(ch *cmdutil.Helper) *cobra.Command {
 			return ch.Printer.PrintResource(toWorkflow(workflow))
 		},
 	}
+	cmdutil.SetJSONOutput(cmd, &ps.Workflow{})
 
 	return cmd
 }
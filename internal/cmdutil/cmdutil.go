@@ -474,3 +474,51 @@ func (f *TTLFlag) set(d time.Duration) error {
 		return nil
 	}
 }
+
+// CommandArgIndex returns the index of the first argument of root that's
+// not a global flag or its value, or -1. args don't include the program name.
+func CommandArgIndex(root *cobra.Command, args []string) int {
+	return ArgIndex(root.PersistentFlags(), args)
+}
+
+// ArgIndex returns the index of the first argument that's not one of flags
+// or its value, or -1.
+func ArgIndex(flags *pflag.FlagSet, args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+
+		var f *pflag.Flag
+		if strings.HasPrefix(arg, "--") {
+			f = flags.Lookup(arg[2:])
+		} else if len(arg) == 2 {
+			f = flags.ShorthandLookup(arg[1:])
+		}
+		if f != nil && f.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return -1
+}
+
+// IsBuiltinCommand reports whether name is a command or a command alias of
+// root.
+func IsBuiltinCommand(root *cobra.Command, name string) bool {
+	if strings.HasPrefix(name, "__") || name == "help" {
+		return true
+	}
+	for _, cmd := range root.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/planetscale/cli/internal/planetscale"
 )
@@ -196,8 +197,28 @@ func GlobalJSONError(err error) JSONErrorResponse {
 // returns the process exit code. On encoding failure it falls back to a
 // plain error line on errW.
 func ReportGlobalJSONError(w, errW io.Writer, err error) int {
+	return reportGlobalError(errW, err, func(resp JSONErrorResponse) error {
+		return WriteJSONError(w, resp)
+	})
+}
+
+// ReportGlobalNDJSONError writes the classified envelope for err to w as the
+// error event of the ndjson format, and returns the process exit code.
+func ReportGlobalNDJSONError(w, errW io.Writer, err error) int {
+	return reportGlobalError(errW, err, func(resp JSONErrorResponse) error {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(struct {
+			Time time.Time `json:"time"`
+			Type string    `json:"type"`
+			JSONErrorResponse
+		}{time.Now().UTC(), "error", resp})
+	})
+}
+
+func reportGlobalError(errW io.Writer, err error, write func(JSONErrorResponse) error) int {
 	resp := GlobalJSONError(err)
-	if writeErr := WriteJSONError(w, resp); writeErr != nil {
+	if writeErr := write(resp); writeErr != nil {
 		fmt.Fprintf(errW, "Error: %s\n", err)
 	}
 
@@ -273,3 +273,25 @@ func TestReportGlobalJSONErrorActionRequiredExitCode(t *testing.T) {
 		t.Fatalf("exit = %d", exit)
 	}
 }
+
+func TestReportGlobalNDJSONError(t *testing.T) {
+	var out, errOut bytes.Buffer
+	exit := ReportGlobalNDJSONError(&out, &errOut, errors.New("boom"))
+	if exit != FatalErrExitCode {
+		t.Fatalf("exit = %d", exit)
+	}
+	if bytes.Count(out.Bytes(), []byte("\n")) != 1 {
+		t.Fatalf("want a single line, got %q", out.String())
+	}
+
+	var event struct {
+		Type string `json:"type"`
+		JSONErrorResponse
+	}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if event.Type != "error" || event.Status != "error" || event.Error == "" {
+		t.Fatalf("event = %#v", event)
+	}
+}
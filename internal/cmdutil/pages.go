@@ -0,0 +1,61 @@
+package cmdutil
+
+import (
+	"errors"
+	"iter"
+
+	"github.com/planetscale/cli/internal/printer"
+
+	"github.com/spf13/cobra"
+)
+
+// AllPagesFlag adds the --all flag of list commands to cmd. If cmd has a
+// --page flag, the two can't be used together.
+func AllPagesFlag(cmd *cobra.Command, all *bool) {
+	cmd.Flags().BoolVar(all, "all", false, "Fetch every page of results")
+	if cmd.Flags().Lookup("page") != nil {
+		cmd.MarkFlagsMutuallyExclusive("all", "page")
+	}
+}
+
+// PrintPages prints the resources of every page, converted for printing with
+// toResource, and returns how many there were. In JSON and CSV format the
+// pages are printed as they arrive. Tables are printed once all pages
+// arrived, and toResource gets all of them at once. end stops the progress
+// indicator before anything is printed. Nothing is printed for an empty list
+// in human format, so the caller can print a message instead. When a later
+// page fails, the pages printed so far are ended as a complete document.
+func PrintPages[T, R any](p *printer.Printer, pages iter.Seq2[[]T, error], end func(), toResource func([]T) R) (int, error) {
+	if p.Format() == printer.Human {
+		var all []T
+		for page, err := range pages {
+			if err != nil {
+				return len(all), err
+			}
+			all = append(all, page...)
+		}
+		end()
+
+		if len(all) == 0 {
+			return 0, nil
+		}
+		return len(all), p.PrintResource(toResource(all))
+	}
+
+	stream := p.StreamResources()
+	for page, err := range pages {
+		if err != nil {
+			end()
+			if aerr := stream.Abort(); aerr != nil {
+				return stream.Len(), errors.Join(err, aerr)
+			}
+			return stream.Len(), err
+		}
+		end()
+		if err := stream.Print(toResource(page)); err != nil {
+			return stream.Len(), err
+		}
+	}
+	end()
+	return stream.Len(), stream.Close()
+}
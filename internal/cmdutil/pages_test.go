@@ -0,0 +1,63 @@
+package cmdutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/planetscale/cli/internal/printer"
+)
+
+type pageRow struct {
+	Name string `header:"name" json:"name"`
+}
+
+func failingPages(err error) iter.Seq2[[]*pageRow, error] {
+	return func(yield func([]*pageRow, error) bool) {
+		if !yield([]*pageRow{{Name: "a"}, {Name: "b"}}, nil) {
+			return
+		}
+		yield(nil, err)
+	}
+}
+
+func TestPrintPagesEndsJSONOnError(t *testing.T) {
+	format := printer.JSON
+	var out bytes.Buffer
+	p := printer.NewPrinter(&format)
+	p.SetResourceOutput(&out)
+
+	pageErr := errors.New("page 2 failed")
+	n, err := PrintPages(p, failingPages(pageErr), func() {}, func(rows []*pageRow) []*pageRow { return rows })
+	if !errors.Is(err, pageErr) {
+		t.Fatalf("got error %v, want %v", err, pageErr)
+	}
+	if n != 2 {
+		t.Errorf("got %d resources, want 2", n)
+	}
+
+	var rows []*pageRow
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("output isn't a complete JSON array: %v\n%s", err, out.String())
+	}
+	if len(rows) != 2 {
+		t.Errorf("got %d rows, want 2", len(rows))
+	}
+}
+
+func TestPrintPagesDropsBufferedOnError(t *testing.T) {
+	format := printer.NDJSON
+	var out bytes.Buffer
+	p := printer.NewPrinter(&format)
+	p.SetResourceOutput(&out)
+
+	pageErr := errors.New("page 2 failed")
+	if _, err := PrintPages(p, failingPages(pageErr), func() {}, func(rows []*pageRow) []*pageRow { return rows }); !errors.Is(err, pageErr) {
+		t.Fatalf("got error %v, want %v", err, pageErr)
+	}
+	if out.Len() != 0 {
+		t.Errorf("got %q, want no result for a failed list", out.String())
+	}
+}
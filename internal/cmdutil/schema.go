@@ -0,0 +1,62 @@
+package cmdutil
+
+import (
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// jsonOutputs maps commands to the values they print with --format json.
+var jsonOutputs sync.Map
+
+// SetJSONOutput records that cmd prints a value like v with --format json,
+// for the command schema of "pscale agent-guide --schema". v has the type
+// that's encoded, which is the API model for resources that print their
+// model as JSON: []*ps.Database rather than the table rows of database list.
+// Pass several values when the output depends on the database engine.
+func SetJSONOutput(cmd *cobra.Command, v ...interface{}) {
+	jsonOutputs.Store(cmd, v)
+}
+
+// JSONOutput returns the values recorded for cmd with SetJSONOutput.
+func JSONOutput(cmd *cobra.Command) []interface{} {
+	v, ok := jsonOutputs.Load(cmd)
+	if !ok {
+		return nil
+	}
+	return v.([]interface{})
+}
+
+// readOnlyAnnotation is the annotation of commands marked with SetReadOnly.
+const readOnlyAnnotation = "pscale_read_only"
+
+// SetReadOnly records that cmd doesn't change any state, for the command
+// schema. Commands that aren't marked are assumed to change state.
+func SetReadOnly(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[readOnlyAnnotation] = "true"
+}
+
+// IsReadOnly reports whether cmd was marked with SetReadOnly.
+func IsReadOnly(cmd *cobra.Command) bool {
+	return cmd.Annotations[readOnlyAnnotation] == "true"
+}
+
+// localAnnotation is the annotation of commands marked with SetLocal.
+const localAnnotation = "pscale_local"
+
+// SetLocal records that cmd only reads local state, such as the config
+// files, the keyring or its input files, and never calls the API.
+func SetLocal(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[localAnnotation] = "true"
+}
+
+// IsLocal reports whether cmd was marked with SetLocal.
+func IsLocal(cmd *cobra.Command) bool {
+	return cmd.Annotations[localAnnotation] == "true"
+}
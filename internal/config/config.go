@@ -34,6 +34,14 @@ type Config struct {
 	ServiceTokenID string
 	ServiceToken   string
 
+	// Profile is the name of the active profile, see Profile.
+	Profile  string
+	tokenKey string
+
+	// CommandLineFlags are the global flags given on the command line,
+	// before settings from files and the environment were copied to flags.
+	CommandLineFlags map[string]string
+
 	// Project Configuration
 	Database string
 	Branch   string
@@ -48,6 +56,8 @@ func New() (*Config, error) {
 	return &Config{
 		AccessToken: accessToken,
 		BaseURL:     ps.DefaultBaseURL,
+		Profile:     DefaultProfile,
+		tokenKey:    keyringKey,
 	}, nil
 }
 
@@ -143,10 +153,16 @@ func ProjectConfigFile() string {
 }
 
 func readAccessToken() (string, error) {
+	return ReadAccessTokenKey(keyringKey)
+}
+
+// ReadAccessTokenKey reads the access token stored under key. Profiles keep
+// their tokens under their own key, see Profile.
+func ReadAccessTokenKey(key string) (string, error) {
 	ring, err := openKeyring()
 
 	if errors.Is(err, keyring.ErrNoAvailImpl) {
-		accessToken, tokenErr := readAccessTokenPath()
+		accessToken, tokenErr := readAccessTokenPath(key)
 		// Token not existing means we're not authenticated.
 		if os.IsNotExist(tokenErr) {
 			return "", nil
@@ -154,7 +170,7 @@ func readAccessToken() (string, error) {
 		return string(accessToken), tokenErr
 	}
 
-	item, err := ring.Get(keyringKey)
+	item, err := ring.Get(key)
 	if err == nil {
 		// We're shipping this first without removing the
 		// existing token file. Once we're confident that
@@ -174,9 +190,9 @@ func readAccessToken() (string, error) {
 
 	if errors.Is(err, keyring.ErrKeyNotFound) {
 		// Migrate to keychain
-		accessToken, tokenErr := readAccessTokenPath()
+		accessToken, tokenErr := readAccessTokenPath(key)
 		if len(accessToken) > 0 && tokenErr == nil {
-			return migrateAccessToken(ring, accessToken)
+			return migrateAccessToken(ring, key, accessToken)
 		}
 		// Might need to improve this, but today the empty
 		// token value represents no auth known, and we should
@@ -188,9 +204,9 @@ func readAccessToken() (string, error) {
 	return "", err
 }
 
-func migrateAccessToken(ring keyring.Keyring, accessToken []byte) (string, error) {
+func migrateAccessToken(ring keyring.Keyring, key string, accessToken []byte) (string, error) {
 	err := ring.Set(keyring.Item{
-		Key:   keyringKey,
+		Key:   key,
 		Data:  accessToken,
 		Label: keyringLabel,
 	})
@@ -198,7 +214,7 @@ func migrateAccessToken(ring keyring.Keyring, accessToken []byte) (string, error
 		return "", err
 	}
 
-	path, err := accessTokenPath()
+	path, err := accessTokenPath(key)
 	if err == nil {
 		fmt.Fprintf(os.Stderr, "Your access token has been migrated to your keyring.\n"+
 			"In a future version we will remove the existing token located at: \n\n%s\n\n"+
@@ -209,27 +225,37 @@ func migrateAccessToken(ring keyring.Keyring, accessToken []byte) (string, error
 }
 
 func WriteAccessToken(accessToken string) error {
+	return WriteAccessTokenKey(keyringKey, accessToken)
+}
+
+// WriteAccessTokenKey stores the access token under key.
+func WriteAccessTokenKey(key, accessToken string) error {
 	ring, err := openKeyring()
 
 	if errors.Is(err, keyring.ErrNoAvailImpl) {
-		return writeAccessTokenPath(accessToken)
+		return writeAccessTokenPath(key, accessToken)
 	}
 
 	return ring.Set(keyring.Item{
-		Key:   keyringKey,
+		Key:   key,
 		Data:  []byte(accessToken),
 		Label: keyringLabel,
 	})
 }
 
 func DeleteAccessToken() error {
+	return DeleteAccessTokenKey(keyringKey)
+}
+
+// DeleteAccessTokenKey removes the access token stored under key.
+func DeleteAccessTokenKey(key string) error {
 	ring, err := openKeyring()
 
 	if errors.Is(err, keyring.ErrNoAvailImpl) {
-		return deleteAccessTokenPath()
+		return deleteAccessTokenPath(key)
 	}
 
-	return ring.Remove(keyringKey)
+	return ring.Remove(key)
 }
 
 func openKeyring() (keyring.Keyring, error) {
@@ -246,18 +272,18 @@ func openKeyring() (keyring.Keyring, error) {
 	})
 }
 
-func accessTokenPath() (string, error) {
+func accessTokenPath(key string) (string, error) {
 	dir, err := ConfigDir()
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(dir, keyringKey), nil
+	return filepath.Join(dir, key), nil
 }
 
-func readAccessTokenPath() ([]byte, error) {
+func readAccessTokenPath(key string) ([]byte, error) {
 	var accessToken []byte
-	tokenPath, err := accessTokenPath()
+	tokenPath, err := accessTokenPath(key)
 	if err != nil {
 		return nil, err
 	}
@@ -284,8 +310,8 @@ func readAccessTokenPath() ([]byte, error) {
 	return accessToken, nil
 }
 
-func deleteAccessTokenPath() error {
-	tokenPath, err := accessTokenPath()
+func deleteAccessTokenPath(key string) error {
+	tokenPath, err := accessTokenPath(key)
 	if err != nil {
 		return err
 	}
@@ -297,6 +323,12 @@ func deleteAccessTokenPath() error {
 		}
 	}
 
+	// Profiles share the config file, only logging out of the default
+	// credentials removes it.
+	if key != keyringKey {
+		return nil
+	}
+
 	configFile, err := DefaultConfigPath()
 	if err != nil {
 		return err
@@ -311,8 +343,8 @@ func deleteAccessTokenPath() error {
 	return nil
 }
 
-func writeAccessTokenPath(accessToken string) error {
-	tokenPath, err := accessTokenPath()
+func writeAccessTokenPath(key, accessToken string) error {
+	tokenPath, err := accessTokenPath(key)
 	if err != nil {
 		return err
 	}
@@ -25,6 +25,33 @@ type FileConfig struct {
 	Organization string `yaml:"org" json:"org"`
 	Database     string `yaml:"database,omitempty" json:"database,omitempty"`
 	Branch       string `yaml:"branch,omitempty" json:"branch,omitempty"`
+
+	// Profile selects one of Profiles instead of the settings above.
+	Profile  string              `yaml:"profile,omitempty" json:"profile,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+
+	// Aliases maps the name of an alias to the command it runs, see
+	// `pscale alias`.
+	Aliases map[string]string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+
+	// Plugins configures the external `pscale-<name>` commands by name.
+	Plugins map[string]*Plugin `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+}
+
+// Plugin configures how an external `pscale-<name>` command is run.
+type Plugin struct {
+	// ScopedToken runs the plugin with a short-lived service token instead
+	// of the user's credentials.
+	ScopedToken *ScopedToken `yaml:"scoped-token,omitempty" json:"scoped_token,omitempty"`
+}
+
+// ScopedToken describes the service token created for each run of a
+// plugin. It's deleted once the plugin exits.
+type ScopedToken struct {
+	// TTL is the lifetime of the token in seconds, an hour by default.
+	TTL      int      `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	Database string   `yaml:"database,omitempty" json:"database,omitempty"`
+	Accesses []string `yaml:"accesses" json:"accesses"`
 }
 
 // NewFileConfig reads the file config from the designated path and returns a
@@ -68,7 +95,7 @@ func (f *FileConfig) Write(path string) error {
 		return errors.New("path is empty")
 	}
 
-	if f.Organization == "" {
+	if f.Organization == "" && len(f.Profiles) == 0 {
 		return errors.New("fileconfig.Organization must be set")
 	}
 
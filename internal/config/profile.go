@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+const (
+	// DefaultProfile names the settings at the top level of pscale.yml and
+	// the access token stored by a plain `pscale auth login`.
+	DefaultProfile = "default"
+
+	// ProfileEnv selects a profile when --profile isn't given.
+	ProfileEnv = "PSCALE_PROFILE"
+)
+
+// Profile is a named set of settings in pscale.yml for switching between
+// organizations, accounts and API URLs:
+//
+//	profile: staging
+//	profiles:
+//	  staging:
+//	    org: acme-staging
+//	    api-url: https://api.staging.example.com
+//	  ci:
+//	    org: acme
+//	    service-token-id: 8a3kf0c2...
+//	    service-token-env: ACME_SERVICE_TOKEN
+//
+// A profile authenticates with a service token when ServiceTokenID is set
+// and with an access token stored by `pscale auth login --profile <name>`
+// otherwise.
+type Profile struct {
+	Organization string `yaml:"org,omitempty" json:"org,omitempty"`
+	BaseURL      string `yaml:"api-url,omitempty" json:"api_url,omitempty"`
+
+	// TokenKey is the keyring entry holding the access token. It defaults
+	// to access-token@<profile>; "access-token" shares the token of the
+	// default profile.
+	TokenKey string `yaml:"token-key,omitempty" json:"token_key,omitempty"`
+
+	// ServiceTokenEnv names the environment variable holding the secret of
+	// the service token, so it never has to be written to pscale.yml.
+	ServiceTokenID  string `yaml:"service-token-id,omitempty" json:"service_token_id,omitempty"`
+	ServiceTokenEnv string `yaml:"service-token-env,omitempty" json:"service_token_env,omitempty"`
+}
+
+// AccessTokenKey returns the keyring entry of the access token of the
+// profile called name.
+func (p *Profile) AccessTokenKey(name string) string {
+	if name == DefaultProfile {
+		return keyringKey
+	}
+	if p.TokenKey != "" {
+		return p.TokenKey
+	}
+	return keyringKey + "@" + name
+}
+
+// Credential describes how the profile called name authenticates.
+func (p *Profile) Credential(name string) string {
+	if p.ServiceTokenID != "" {
+		if p.ServiceTokenEnv == "" {
+			return fmt.Sprintf("service token %s", p.ServiceTokenID)
+		}
+		return fmt.Sprintf("service token %s ($%s)", p.ServiceTokenID, p.ServiceTokenEnv)
+	}
+	return fmt.Sprintf("keyring entry %s", p.AccessTokenKey(name))
+}
+
+// LookupProfile returns the profile called name. The default profile is
+// made of the top level settings.
+func (f *FileConfig) LookupProfile(name string) (*Profile, error) {
+	if name == DefaultProfile {
+		return &Profile{Organization: f.Organization}, nil
+	}
+	p, ok := f.Profiles[name]
+	if !ok || p == nil {
+		return nil, fmt.Errorf("profile %q doesn't exist, run 'pscale profile list' to see the available profiles", name)
+	}
+	return p, nil
+}
+
+// ProfileNames returns the names of all profiles, starting with the default
+// profile.
+func (f *FileConfig) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// SelectedProfile returns the profile chosen by the --profile flag, the
+// PSCALE_PROFILE environment variable or the profile saved by `pscale
+// profile use`, in that order, and where the choice came from.
+func (f *FileConfig) SelectedProfile(flag string) (name, source string) {
+	switch {
+	case flag != "":
+		return flag, "--profile"
+	case os.Getenv(ProfileEnv) != "":
+		return os.Getenv(ProfileEnv), ProfileEnv
+	case f.Profile != "":
+		return f.Profile, "config file"
+	default:
+		return DefaultProfile, ""
+	}
+}
+
+// ApplyProfile switches c to the API and credentials of the profile called
+// name.
+func (c *Config) ApplyProfile(name string, p *Profile) error {
+	c.Profile = name
+	c.tokenKey = p.AccessTokenKey(name)
+	if p.BaseURL != "" {
+		c.BaseURL = p.BaseURL
+	}
+
+	if p.ServiceTokenID != "" {
+		c.AccessToken = ""
+		c.ServiceTokenID = p.ServiceTokenID
+		c.ServiceToken = ""
+		if p.ServiceTokenEnv != "" {
+			c.ServiceToken = os.Getenv(p.ServiceTokenEnv)
+		}
+		return nil
+	}
+
+	accessToken, err := ReadAccessTokenKey(c.tokenKey)
+	if err != nil {
+		return err
+	}
+	c.AccessToken = accessToken
+	c.ServiceTokenID = ""
+	c.ServiceToken = ""
+	return nil
+}
+
+// AccessTokenKey returns the keyring entry of the access token of the
+// active profile.
+func (c *Config) AccessTokenKey() string {
+	if c.tokenKey == "" {
+		return keyringKey
+	}
+	return c.tokenKey
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"testing"
+	"testing/fstest"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const profilesYAML = `org: acme
+profile: staging
+profiles:
+  staging:
+    org: acme-staging
+    api-url: https://api.staging.example.com
+  shared:
+    org: acme
+    token-key: access-token
+  ci:
+    org: acme
+    service-token-id: abc123
+    service-token-env: ACME_SERVICE_TOKEN
+`
+
+func TestProfiles(t *testing.T) {
+	c := qt.New(t)
+
+	cfg, err := NewConfigFS(fstest.MapFS{
+		"pscale.yml": &fstest.MapFile{Data: []byte(profilesYAML)},
+	}).NewFileConfig("pscale.yml")
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(cfg.ProfileNames(), qt.DeepEquals, []string{"default", "ci", "shared", "staging"})
+
+	p, err := cfg.LookupProfile(DefaultProfile)
+	c.Assert(err, qt.IsNil)
+	c.Assert(p.Organization, qt.Equals, "acme")
+	c.Assert(p.AccessTokenKey(DefaultProfile), qt.Equals, "access-token")
+
+	p, err = cfg.LookupProfile("staging")
+	c.Assert(err, qt.IsNil)
+	c.Assert(p.BaseURL, qt.Equals, "https://api.staging.example.com")
+	c.Assert(p.AccessTokenKey("staging"), qt.Equals, "access-token@staging")
+	c.Assert(p.Credential("staging"), qt.Equals, "keyring entry access-token@staging")
+
+	p, err = cfg.LookupProfile("shared")
+	c.Assert(err, qt.IsNil)
+	c.Assert(p.AccessTokenKey("shared"), qt.Equals, "access-token")
+
+	p, err = cfg.LookupProfile("ci")
+	c.Assert(err, qt.IsNil)
+	c.Assert(p.Credential("ci"), qt.Equals, "service token abc123 ($ACME_SERVICE_TOKEN)")
+
+	_, err = cfg.LookupProfile("prod")
+	c.Assert(err, qt.ErrorMatches, `profile "prod" doesn't exist.*`)
+}
+
+func TestSelectedProfile(t *testing.T) {
+	c := qt.New(t)
+
+	cfg := &FileConfig{Profile: "staging"}
+
+	name, source := cfg.SelectedProfile("ci")
+	c.Assert(name, qt.Equals, "ci")
+	c.Assert(source, qt.Equals, "--profile")
+
+	c.Setenv(ProfileEnv, "shared")
+	name, source = cfg.SelectedProfile("")
+	c.Assert(name, qt.Equals, "shared")
+	c.Assert(source, qt.Equals, ProfileEnv)
+
+	c.Setenv(ProfileEnv, "")
+	name, source = cfg.SelectedProfile("")
+	c.Assert(name, qt.Equals, "staging")
+	c.Assert(source, qt.Equals, "config file")
+
+	name, _ = (&FileConfig{}).SelectedProfile("")
+	c.Assert(name, qt.Equals, DefaultProfile)
+}
+
+func TestApplyServiceTokenProfile(t *testing.T) {
+	c := qt.New(t)
+	c.Setenv("ACME_SERVICE_TOKEN", "secret")
+
+	cfg := &Config{AccessToken: "pscale_oauth_token", BaseURL: "https://api.planetscale.com"}
+	err := cfg.ApplyProfile("ci", &Profile{
+		BaseURL:         "https://api.staging.example.com",
+		ServiceTokenID:  "abc123",
+		ServiceTokenEnv: "ACME_SERVICE_TOKEN",
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Profile, qt.Equals, "ci")
+	c.Assert(cfg.BaseURL, qt.Equals, "https://api.staging.example.com")
+	c.Assert(cfg.AccessToken, qt.Equals, "")
+	c.Assert(cfg.ServiceTokenIsSet(), qt.IsTrue)
+	c.Assert(cfg.ServiceToken, qt.Equals, "secret")
+	c.Assert(cfg.AccessTokenKey(), qt.Equals, "access-token@ci")
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Layer is one source of settings, such as a config file, the environment or
+// the command line.
+type Layer struct {
+	Origin string
+	Values map[string]string
+}
+
+// Setting is the effective value of a setting and the layer it came from.
+type Setting struct {
+	Key    string
+	Value  string
+	Origin string
+
+	// Overridden are the values of lower layers that Value took precedence
+	// over.
+	Overridden []*Setting
+}
+
+// Resolve returns the effective settings of layers, which are ordered from
+// the highest precedence to the lowest, sorted by key.
+func Resolve(layers []*Layer) []*Setting {
+	byKey := make(map[string]*Setting)
+	var keys []string
+	for _, l := range layers {
+		for key, value := range l.Values {
+			s, ok := byKey[key]
+			if !ok {
+				byKey[key] = &Setting{Key: key, Value: value, Origin: l.Origin}
+				keys = append(keys, key)
+				continue
+			}
+			s.Overridden = append(s.Overridden, &Setting{Key: key, Value: value, Origin: l.Origin})
+		}
+	}
+
+	sort.Strings(keys)
+	settings := make([]*Setting, 0, len(keys))
+	for _, key := range keys {
+		settings = append(settings, byKey[key])
+	}
+	return settings
+}
+
+// ProjectDir returns the directory project config files are read from: the
+// root of the Git repository or else the working directory.
+func ProjectDir() (string, error) {
+	if dir, err := RootGitRepoDir(); err == nil {
+		return dir, nil
+	}
+	return LocalDir()
+}
+
+// ReadSettings returns the top level settings of the YAML file at path.
+// Nested settings such as profiles are left out. A missing file has no
+// settings.
+func ReadSettings(path string) (map[string]string, error) {
+	doc, err := readSettingsFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, item := range doc {
+		key := fmt.Sprint(item.Key)
+		switch item.Value.(type) {
+		case yaml.MapSlice, []interface{}, nil:
+			continue
+		}
+		values[key] = fmt.Sprint(item.Value)
+	}
+	return values, nil
+}
+
+// GetFileValue returns the value of key in the YAML file at path. Keys of
+// nested settings are joined with dots, such as profiles.staging.org.
+func GetFileValue(path, key string) (string, bool, error) {
+	doc, err := readSettingsFile(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		value, ok := lookupItem(doc, part)
+		if !ok {
+			return "", false, nil
+		}
+		if i == len(parts)-1 {
+			if _, nested := value.(yaml.MapSlice); nested || value == nil {
+				return "", false, nil
+			}
+			return fmt.Sprint(value), true, nil
+		}
+		if doc, ok = value.(yaml.MapSlice); !ok {
+			return "", false, nil
+		}
+	}
+	return "", false, nil
+}
+
+// SetFileValue sets key to value in the YAML file at path, creating the
+// file if needed. The other settings and their order are kept.
+func SetFileValue(path, key string, value interface{}) error {
+	doc, err := readSettingsFile(path)
+	if err != nil {
+		return err
+	}
+
+	doc, err = setItem(doc, strings.Split(key, "."), value)
+	if err != nil {
+		return fmt.Errorf("can't set %s: %w", key, err)
+	}
+	return writeSettingsFile(path, doc)
+}
+
+// UnsetFileValue removes key from the YAML file at path and reports whether
+// it was set.
+func UnsetFileValue(path, key string) (bool, error) {
+	doc, err := readSettingsFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	doc, removed := unsetItem(doc, strings.Split(key, "."))
+	if !removed {
+		return false, nil
+	}
+	return true, writeSettingsFile(path, doc)
+}
+
+func readSettingsFile(path string) (yaml.MapSlice, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("can't unmarshal file %q: %s", path, err)
+	}
+	return doc, nil
+}
+
+func writeSettingsFile(path string, doc yaml.MapSlice) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o771); err != nil {
+		return errors.New("error creating config directory")
+	}
+
+	var data []byte
+	if len(doc) > 0 {
+		var err error
+		data, err = yaml.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("can't marshal file config: %s", err)
+		}
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func lookupItem(doc yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range doc {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+func setItem(doc yaml.MapSlice, parts []string, value interface{}) (yaml.MapSlice, error) {
+	for i, item := range doc {
+		if fmt.Sprint(item.Key) != parts[0] {
+			continue
+		}
+		if len(parts) == 1 {
+			doc[i].Value = value
+			return doc, nil
+		}
+		nested, ok := item.Value.(yaml.MapSlice)
+		if !ok && item.Value != nil {
+			return nil, fmt.Errorf("%s is not a map", parts[0])
+		}
+		nested, err := setItem(nested, parts[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		doc[i].Value = nested
+		return doc, nil
+	}
+
+	if len(parts) == 1 {
+		return append(doc, yaml.MapItem{Key: parts[0], Value: value}), nil
+	}
+	nested, err := setItem(nil, parts[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	return append(doc, yaml.MapItem{Key: parts[0], Value: nested}), nil
+}
+
+func unsetItem(doc yaml.MapSlice, parts []string) (yaml.MapSlice, bool) {
+	for i, item := range doc {
+		if fmt.Sprint(item.Key) != parts[0] {
+			continue
+		}
+		if len(parts) == 1 {
+			return append(doc[:i:i], doc[i+1:]...), true
+		}
+		nested, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			return doc, false
+		}
+		nested, removed := unsetItem(nested, parts[1:])
+		if !removed {
+			return doc, false
+		}
+		if len(nested) == 0 {
+			return append(doc[:i:i], doc[i+1:]...), true
+		}
+		doc[i].Value = nested
+		return doc, true
+	}
+	return doc, false
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestResolve(t *testing.T) {
+	c := qt.New(t)
+
+	settings := Resolve([]*Layer{
+		{Origin: "flag --org", Values: map[string]string{"org": "from-flag"}},
+		{Origin: "env PLANETSCALE_DATABASE", Values: map[string]string{"database": "from-env"}},
+		{Origin: "pscale.yml", Values: map[string]string{"org": "from-file", "database": "from-file", "branch": "main"}},
+	})
+
+	c.Assert(settings, qt.DeepEquals, []*Setting{
+		{Key: "branch", Value: "main", Origin: "pscale.yml"},
+		{Key: "database", Value: "from-env", Origin: "env PLANETSCALE_DATABASE", Overridden: []*Setting{
+			{Key: "database", Value: "from-file", Origin: "pscale.yml"},
+		}},
+		{Key: "org", Value: "from-flag", Origin: "flag --org", Overridden: []*Setting{
+			{Key: "org", Value: "from-file", Origin: "pscale.yml"},
+		}},
+	})
+}
+
+func TestFileValues(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(c.TempDir(), "planetscale", "pscale.yml")
+
+	values, err := ReadSettings(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(values, qt.HasLen, 0)
+
+	c.Assert(SetFileValue(path, "org", "acme"), qt.IsNil)
+	c.Assert(SetFileValue(path, "profiles.staging.org", "acme-staging"), qt.IsNil)
+	c.Assert(SetFileValue(path, "profiles.staging.api-url", "https://api.staging.example.com"), qt.IsNil)
+	c.Assert(SetFileValue(path, "debug", true), qt.IsNil)
+	c.Assert(SetFileValue(path, "org", "acme-prod"), qt.IsNil)
+
+	data, err := os.ReadFile(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, `org: acme-prod
+profiles:
+  staging:
+    org: acme-staging
+    api-url: https://api.staging.example.com
+debug: true
+`)
+
+	values, err = ReadSettings(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(values, qt.DeepEquals, map[string]string{"org": "acme-prod", "debug": "true"})
+
+	v, ok, err := GetFileValue(path, "profiles.staging.api-url")
+	c.Assert(err, qt.IsNil)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(v, qt.Equals, "https://api.staging.example.com")
+
+	_, ok, err = GetFileValue(path, "profiles.staging")
+	c.Assert(err, qt.IsNil)
+	c.Assert(ok, qt.IsFalse)
+
+	c.Assert(SetFileValue(path, "org.name", "x"), qt.ErrorMatches, "can't set org.name: org is not a map")
+
+	removed, err := UnsetFileValue(path, "profiles.staging.org")
+	c.Assert(err, qt.IsNil)
+	c.Assert(removed, qt.IsTrue)
+	removed, err = UnsetFileValue(path, "profiles.staging.api-url")
+	c.Assert(err, qt.IsNil)
+	c.Assert(removed, qt.IsTrue)
+	removed, err = UnsetFileValue(path, "branch")
+	c.Assert(err, qt.IsNil)
+	c.Assert(removed, qt.IsFalse)
+
+	data, err = os.ReadFile(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, "org: acme-prod\ndebug: true\n")
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/99designs/keyring"
+)
+
+const (
+	sqlSessionsKey   = "sql-sessions"
+	sqlSessionsLabel = "PlanetScale CLI SQL Sessions"
+)
+
+// ErrNoSessionKeyring is returned when SQL session credentials can't be
+// cached because no keyring is available.
+var ErrNoSessionKeyring = errors.New("sql sessions need a system keyring to store their credentials and none is available; " +
+	"pscale sql creates a short-lived credential for every invocation instead")
+
+// ReadSQLSessions returns the encoded credentials cached by `pscale sql
+// session start`, or nil if there are none or no keyring is available. They
+// hold database passwords, so unlike the access token they are only kept in
+// the keyring, never in a plaintext file.
+func ReadSQLSessions() ([]byte, error) {
+	ring, err := openKeyring()
+	if errors.Is(err, keyring.ErrNoAvailImpl) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := ring.Get(sqlSessionsKey)
+	if errors.Is(err, keyring.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.Data, nil
+}
+
+// WriteSQLSessions replaces the cached SQL session credentials. It returns
+// ErrNoSessionKeyring when no keyring is available.
+func WriteSQLSessions(data []byte) error {
+	ring, err := openKeyring()
+	if errors.Is(err, keyring.ErrNoAvailImpl) {
+		return ErrNoSessionKeyring
+	}
+	if err != nil {
+		return err
+	}
+
+	return ring.Set(keyring.Item{
+		Key:   sqlSessionsKey,
+		Data:  data,
+		Label: sqlSessionsLabel,
+	})
+}
+
+// DeleteSQLSessions removes every cached SQL session credential.
+func DeleteSQLSessions() error {
+	ring, err := openKeyring()
+	if errors.Is(err, keyring.ErrNoAvailImpl) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := ring.Remove(sqlSessionsKey); err != nil && !errors.Is(err, keyring.ErrKeyNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package devserver
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+// retentionUnits are the units of the retention period of backups.
+var retentionUnits = map[string]time.Duration{
+	"hour":  time.Hour,
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+	"year":  365 * 24 * time.Hour,
+}
+
+// backup returns the branch and backup of the path of r.
+func (s *Server) backup(r *http.Request) (*branch, *ps.Backup, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id := r.PathValue("id")
+	for _, bkp := range b.backups {
+		if bkp.PublicID == id {
+			return b, bkp, nil
+		}
+	}
+	return nil, nil, notFound("backup %s does not exist in branch %s", id, b.branch.Name)
+}
+
+func (s *Server) listBackups(r *http.Request) (any, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(r, b.backups)
+}
+
+func (s *Server) createBackup(r *http.Request) (any, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req ps.CreateBackupRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	retention := 2 * retentionUnits["day"]
+	if req.RetentionUnit != "" || req.RetentionValue != 0 {
+		unit, ok := retentionUnits[req.RetentionUnit]
+		if !ok || req.RetentionValue < 1 {
+			return nil, invalid("invalid retention of %d %q", req.RetentionValue, req.RetentionUnit)
+		}
+		retention = time.Duration(req.RetentionValue) * unit
+	}
+
+	now := s.now()
+	name := req.Name
+	if name == "" {
+		name = now.Format("2006.01.02 15:04:05")
+	}
+
+	// Branches have no data, so backups are complete right away.
+	bkp := &ps.Backup{
+		PublicID:    s.newID(),
+		Name:        name,
+		State:       "success",
+		Actor:       &actor,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		StartedAt:   now,
+		ExpiresAt:   now.Add(retention),
+		CompletedAt: now,
+	}
+	b.backups = append(b.backups, bkp)
+	return bkp, nil
+}
+
+func (s *Server) getBackup(r *http.Request) (any, error) {
+	_, bkp, err := s.backup(r)
+	if err != nil {
+		return nil, err
+	}
+	return bkp, nil
+}
+
+func (s *Server) deleteBackup(r *http.Request) (any, error) {
+	b, bkp, err := s.backup(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b.backups = slices.DeleteFunc(b.backups, func(other *ps.Backup) bool { return other == bkp })
+	return nil, nil
+}
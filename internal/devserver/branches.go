@@ -0,0 +1,182 @@
+package devserver
+
+import (
+	"net/http"
+	"slices"
+
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+type branch struct {
+	branch    *ps.DatabaseBranch
+	passwords []*ps.DatabaseBranchPassword
+	backups   []*ps.Backup
+}
+
+func (db *database) branch(name string) (*branch, error) {
+	for _, b := range db.branches {
+		if b.branch.Name == name {
+			return b, nil
+		}
+	}
+	return nil, notFound("branch %s does not exist in database %s", name, db.db.Name)
+}
+
+// branch returns the database and branch of the path of r.
+func (s *Server) branch(r *http.Request) (*database, *branch, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := db.branch(r.PathValue("branch"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, b, nil
+}
+
+func (s *Server) listBranches(r *http.Request) (any, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make([]*ps.DatabaseBranch, len(db.branches))
+	for i, b := range db.branches {
+		branches[i] = b.branch
+	}
+	return paginate(r, branches)
+}
+
+func (s *Server) createBranch(r *http.Request) (any, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req ps.CreateDatabaseBranchRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	if req.Name == "" {
+		return nil, invalid("name is required")
+	}
+	if _, err := db.branch(req.Name); err == nil {
+		return nil, invalid("name %s has already been taken", req.Name)
+	}
+
+	var parent *branch
+	if req.ParentBranch == "" {
+		parent = db.branches[slices.IndexFunc(db.branches, func(b *branch) bool { return b.branch.Production })]
+	} else if parent, err = db.branch(req.ParentBranch); err != nil {
+		return nil, err
+	}
+
+	region := parent.branch.Region
+	if req.Region != "" {
+		region = ps.Region{Slug: req.Region, Provider: "AWS", Name: req.Region, Enabled: true}
+	}
+	size := req.ClusterSize
+	if size == "" {
+		size = "PS_DEV"
+	}
+
+	now := s.now()
+	b := &branch{
+		branch: &ps.DatabaseBranch{
+			ID:           s.newID(),
+			Name:         req.Name,
+			ParentBranch: parent.branch.Name,
+			Actor:        actor,
+			Region:       region,
+			Ready:        true,
+			HtmlURL:      htmlURL(r, r.PathValue("org"), db.db.Name, req.Name),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			VTGateSize:   size,
+			VTGateCount:  1,
+		},
+	}
+	db.branches = append(db.branches, b)
+	return b.branch, nil
+}
+
+func (s *Server) getBranch(r *http.Request) (any, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+	return b.branch, nil
+}
+
+func (s *Server) deleteBranch(r *http.Request) (any, error) {
+	db, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+	if b.branch.Production {
+		return nil, invalid("production branch %s can't be deleted, demote it first", b.branch.Name)
+	}
+
+	db.branches = slices.DeleteFunc(db.branches, func(other *branch) bool { return other == b })
+	return nil, nil
+}
+
+func (s *Server) promoteBranch(r *http.Request) (any, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b.branch.Production = true
+	b.branch.UpdatedAt = s.now()
+	return b.branch, nil
+}
+
+func (s *Server) demoteBranch(r *http.Request) (any, error) {
+	db, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+
+	production := 0
+	for _, other := range db.branches {
+		if other.branch.Production {
+			production++
+		}
+	}
+	if b.branch.Production && production == 1 {
+		return nil, invalid("branch %s is the only production branch of database %s", b.branch.Name, db.db.Name)
+	}
+
+	b.branch.Production = false
+	b.branch.SafeMigrations = false
+	b.branch.UpdatedAt = s.now()
+	return b.branch, nil
+}
+
+func (s *Server) enableSafeMigrations(r *http.Request) (any, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+	if !b.branch.Production {
+		return nil, invalid("safe migrations can only be enabled on production branches")
+	}
+
+	b.branch.SafeMigrations = true
+	b.branch.UpdatedAt = s.now()
+	return b.branch, nil
+}
+
+func (s *Server) disableSafeMigrations(r *http.Request) (any, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b.branch.SafeMigrations = false
+	b.branch.UpdatedAt = s.now()
+	return b.branch, nil
+}
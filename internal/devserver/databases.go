@@ -0,0 +1,143 @@
+package devserver
+
+import (
+	"net/http"
+	"slices"
+
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+const defaultRegion = "us-east"
+
+type organization struct {
+	org       *ps.Organization
+	databases []*database
+}
+
+type database struct {
+	db             *ps.Database
+	branches       []*branch
+	deployRequests []*deployRequest
+	webhooks       []*ps.Webhook
+}
+
+// organization returns the organization named name, creating it if it
+// doesn't exist yet.
+func (s *Server) organization(name string) *organization {
+	for _, o := range s.orgs {
+		if o.org.Name == name {
+			return o
+		}
+	}
+
+	now := s.now()
+	o := &organization{org: &ps.Organization{Name: name, CreatedAt: now, UpdatedAt: now}}
+	s.orgs = append(s.orgs, o)
+	return o
+}
+
+// database returns the database of the path of r.
+func (s *Server) database(r *http.Request) (*database, error) {
+	org, name := r.PathValue("org"), r.PathValue("db")
+	for _, db := range s.organization(org).databases {
+		if db.db.Name == name {
+			return db, nil
+		}
+	}
+	return nil, notFound("database %s does not exist in organization %s", name, org)
+}
+
+func (s *Server) listOrganizations(r *http.Request) (any, error) {
+	orgs := make([]*ps.Organization, len(s.orgs))
+	for i, o := range s.orgs {
+		orgs[i] = o.org
+	}
+	return paginate(r, orgs)
+}
+
+func (s *Server) getOrganization(r *http.Request) (any, error) {
+	return s.organization(r.PathValue("org")).org, nil
+}
+
+func (s *Server) listDatabases(r *http.Request) (any, error) {
+	org := s.organization(r.PathValue("org"))
+	dbs := make([]*ps.Database, len(org.databases))
+	for i, db := range org.databases {
+		dbs[i] = db.db
+	}
+	return paginate(r, dbs)
+}
+
+func (s *Server) createDatabase(r *http.Request) (any, error) {
+	var req ps.CreateDatabaseRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.Name == "":
+		return nil, invalid("name is required")
+	case req.Kind != "" && req.Kind != ps.DatabaseEngineMySQL:
+		return nil, invalid("the dev API server only emulates MySQL databases")
+	}
+
+	org := s.organization(r.PathValue("org"))
+	if slices.ContainsFunc(org.databases, func(db *database) bool { return db.db.Name == req.Name }) {
+		return nil, invalid("name %s has already been taken", req.Name)
+	}
+
+	region := req.Region
+	if region == "" {
+		region = defaultRegion
+	}
+
+	now := s.now()
+	db := &database{
+		db: &ps.Database{
+			Name:      req.Name,
+			Notes:     req.Notes,
+			Region:    ps.Region{Slug: region, Provider: "AWS", Name: region, Enabled: true},
+			State:     ps.DatabaseReady,
+			Kind:      ps.DatabaseEngineMySQL,
+			HtmlURL:   htmlURL(r, org.org.Name, req.Name),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	db.branches = []*branch{{
+		branch: &ps.DatabaseBranch{
+			ID:          s.newID(),
+			Name:        "main",
+			Actor:       actor,
+			Region:      db.db.Region,
+			Ready:       true,
+			Production:  true,
+			HtmlURL:     htmlURL(r, org.org.Name, req.Name, "main"),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			VTGateSize:  "PS_DEV",
+			VTGateCount: 1,
+		},
+	}}
+	org.databases = append(org.databases, db)
+	return db.db, nil
+}
+
+func (s *Server) getDatabase(r *http.Request) (any, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, err
+	}
+	return db.db, nil
+}
+
+func (s *Server) deleteDatabase(r *http.Request) (any, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, err
+	}
+
+	org := s.organization(r.PathValue("org"))
+	org.databases = slices.DeleteFunc(org.databases, func(d *database) bool { return d == db })
+	return &ps.DatabaseDeletionRequest{ID: s.newID(), Actor: actor}, nil
+}
@@ -0,0 +1,319 @@
+package devserver
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+// Deployment states of deploy requests. Deployments skip the queued and
+// in_progress states and go from ready to pending_cutover, or straight to
+// complete_pending_revert if auto-apply is enabled.
+const (
+	deploymentReady                 = "ready"
+	deploymentPendingCutover        = "pending_cutover"
+	deploymentCompletePendingRevert = "complete_pending_revert"
+	deploymentComplete              = "complete"
+	deploymentCompleteRevert        = "complete_revert"
+	deploymentCompleteCancel        = "complete_cancel"
+)
+
+type deployRequest struct {
+	dr               *ps.DeployRequest
+	autoCutover      bool
+	autoDeleteBranch bool
+}
+
+// deployRequest returns the database and deploy request of the path of r.
+func (s *Server) deployRequest(r *http.Request) (*database, *deployRequest, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	number, err := strconv.ParseUint(r.PathValue("number"), 10, 64)
+	if err != nil {
+		return nil, nil, badRequest("invalid deploy request number %q", r.PathValue("number"))
+	}
+	for _, dr := range db.deployRequests {
+		if dr.dr.Number == number {
+			return db, dr, nil
+		}
+	}
+	return nil, nil, notFound("deploy request %d does not exist in database %s", number, db.db.Name)
+}
+
+// setDeploymentState moves the deployment of dr to state.
+func (s *Server) setDeploymentState(db *database, dr *deployRequest, state string) {
+	now := s.now()
+	d := dr.dr.Deployment
+	d.State = state
+	d.UpdatedAt = now
+	dr.dr.DeploymentState = state
+	dr.dr.UpdatedAt = now
+
+	switch state {
+	case deploymentPendingCutover:
+		d.StartedAt = &now
+	case deploymentCompletePendingRevert:
+		if d.StartedAt == nil {
+			d.StartedAt = &now
+		}
+		d.FinishedAt = &now
+		d.CutoverActor = &actor
+		dr.dr.DeployedAt = &now
+		s.closeDeploy(dr)
+
+		if dr.autoDeleteBranch {
+			db.branches = slices.DeleteFunc(db.branches, func(b *branch) bool {
+				return b.branch.Name == dr.dr.Branch && !b.branch.Production
+			})
+			dr.dr.BranchDeletedBy = &actor
+		}
+	case deploymentCompleteCancel:
+		d.FinishedAt = &now
+		d.CancelledActor = &actor
+	}
+}
+
+func (s *Server) closeDeploy(dr *deployRequest) {
+	now := s.now()
+	dr.dr.State = "closed"
+	dr.dr.ClosedAt = &now
+	dr.dr.ClosedBy = &actor
+	dr.dr.UpdatedAt = now
+}
+
+// transition moves the deployment of the deploy request of the path of r
+// from one of the states in from to state to.
+func (s *Server) transition(r *http.Request, to string, from ...string) (any, error) {
+	db, dr, err := s.deployRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(from, dr.dr.DeploymentState) {
+		return nil, invalid("deploy request %d can't go from %s to %s", dr.dr.Number, dr.dr.DeploymentState, to)
+	}
+
+	s.setDeploymentState(db, dr, to)
+	return dr.dr, nil
+}
+
+func (s *Server) listDeployRequests(r *http.Request) (any, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, err
+	}
+
+	q := r.URL.Query()
+	var drs []*ps.DeployRequest
+	for _, dr := range db.deployRequests {
+		switch {
+		case q.Get("state") != "" && q.Get("state") != dr.dr.State:
+		case q.Get("branch") != "" && q.Get("branch") != dr.dr.Branch:
+		case q.Get("into_branch") != "" && q.Get("into_branch") != dr.dr.IntoBranch:
+		default:
+			drs = append(drs, dr.dr)
+		}
+	}
+	return paginate(r, drs)
+}
+
+func (s *Server) createDeployRequest(r *http.Request) (any, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req ps.CreateDeployRequestRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	b, err := db.branch(req.Branch)
+	if err != nil {
+		return nil, err
+	}
+	if b.branch.Production {
+		return nil, invalid("deploy requests can't be opened from production branch %s", b.branch.Name)
+	}
+	into := req.IntoBranch
+	if into == "" {
+		into = b.branch.ParentBranch
+	}
+	if _, err := db.branch(into); err != nil {
+		return nil, err
+	}
+	for _, dr := range db.deployRequests {
+		if dr.dr.Branch == req.Branch && dr.dr.State == "open" {
+			return nil, invalid("branch %s already has an open deploy request, #%d", req.Branch, dr.dr.Number)
+		}
+	}
+
+	now := s.now()
+	number := uint64(len(db.deployRequests) + 1)
+	dr := &deployRequest{
+		dr: &ps.DeployRequest{
+			ID:              s.newID(),
+			Branch:          req.Branch,
+			IntoBranch:      into,
+			Actor:           actor,
+			Number:          number,
+			State:           "open",
+			DeploymentState: deploymentReady,
+			Notes:           req.Notes,
+			HtmlURL:         htmlURL(r, r.PathValue("org"), db.db.Name, "deploy-requests", strconv.FormatUint(number, 10)),
+			Deployment: &ps.Deployment{
+				ID:                  s.newID(),
+				State:               deploymentReady,
+				Deployable:          true,
+				LintErrors:          []*ps.DeploymentLintError{},
+				DeployRequestNumber: number,
+				IntoBranch:          into,
+				Actor:               &actor,
+				CreatedAt:           now,
+				UpdatedAt:           now,
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		autoCutover:      req.AutoCutover,
+		autoDeleteBranch: req.AutoDeleteBranch,
+	}
+	db.deployRequests = append(db.deployRequests, dr)
+	return dr.dr, nil
+}
+
+func (s *Server) getDeployRequest(r *http.Request) (any, error) {
+	_, dr, err := s.deployRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return dr.dr, nil
+}
+
+func (s *Server) closeDeployRequest(r *http.Request) (any, error) {
+	_, dr, err := s.deployRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req ps.CloseRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	switch {
+	case req.State != "closed":
+		return nil, invalid("deploy requests can only be updated to the closed state")
+	case dr.dr.State != "open":
+		return nil, invalid("deploy request %d is already closed", dr.dr.Number)
+	case dr.dr.DeploymentState == deploymentPendingCutover:
+		return nil, invalid("deploy request %d is being deployed, cancel it first", dr.dr.Number)
+	}
+
+	s.closeDeploy(dr)
+	return dr.dr, nil
+}
+
+func (s *Server) deployDeployRequest(r *http.Request) (any, error) {
+	_, dr, err := s.deployRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if dr.dr.State != "open" {
+		return nil, invalid("deploy request %d is closed", dr.dr.Number)
+	}
+
+	var req ps.PerformDeployRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	to := deploymentPendingCutover
+	if dr.autoCutover {
+		to = deploymentCompletePendingRevert
+	}
+	res, err := s.transition(r, to, deploymentReady, deploymentCompleteCancel)
+	if err != nil {
+		return nil, err
+	}
+	dr.dr.Deployment.InstantDDL = req.InstantDDL
+	return res, nil
+}
+
+func (s *Server) applyDeployRequest(r *http.Request) (any, error) {
+	return s.transition(r, deploymentCompletePendingRevert, deploymentPendingCutover)
+}
+
+func (s *Server) autoApplyDeployRequest(r *http.Request) (any, error) {
+	_, dr, err := s.deployRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req struct {
+		Enable bool `json:"enable"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	dr.autoCutover = req.Enable
+	dr.dr.UpdatedAt = s.now()
+	return dr.dr, nil
+}
+
+func (s *Server) cancelDeployRequest(r *http.Request) (any, error) {
+	return s.transition(r, deploymentCompleteCancel, deploymentPendingCutover)
+}
+
+func (s *Server) skipRevertDeployRequest(r *http.Request) (any, error) {
+	return s.transition(r, deploymentComplete, deploymentCompletePendingRevert)
+}
+
+func (s *Server) revertDeployRequest(r *http.Request) (any, error) {
+	return s.transition(r, deploymentCompleteRevert, deploymentCompletePendingRevert)
+}
+
+func (s *Server) reviewDeployRequest(r *http.Request) (any, error) {
+	_, dr, err := s.deployRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req struct {
+		State string `json:"state"`
+		Body  string `json:"body"`
+	}
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	switch req.State {
+	case "approved":
+		dr.dr.Approved = true
+	case "commented":
+	default:
+		return nil, invalid("invalid review state %q", req.State)
+	}
+
+	now := s.now()
+	return &ps.DeployRequestReview{
+		ID:        s.newID(),
+		Body:      req.Body,
+		State:     req.State,
+		Actor:     actor,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// emptyDeployRequestList answers the diff and operations of a deploy
+// request. Branches have no schema, so there are never any.
+func (s *Server) emptyDeployRequestList(r *http.Request) (any, error) {
+	if _, _, err := s.deployRequest(r); err != nil {
+		return nil, err
+	}
+	return &listResponse{CurrentPage: 1, Data: []any{}}, nil
+}
@@ -0,0 +1,128 @@
+package devserver
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+var passwordRoles = []string{"reader", "writer", "readwriter", "admin"}
+
+// password returns the branch and password of the path of r.
+func (s *Server) password(r *http.Request) (*branch, *ps.DatabaseBranchPassword, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id := r.PathValue("id")
+	for _, p := range b.passwords {
+		if p.PublicID == id {
+			return b, p, nil
+		}
+	}
+	return nil, nil, notFound("password %s does not exist in branch %s", id, b.branch.Name)
+}
+
+func (s *Server) listDatabasePasswords(r *http.Request) (any, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var passwords []*ps.DatabaseBranchPassword
+	for _, b := range db.branches {
+		passwords = append(passwords, b.passwords...)
+	}
+	return paginate(r, passwords)
+}
+
+func (s *Server) listPasswords(r *http.Request) (any, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(r, b.passwords)
+}
+
+func (s *Server) createPassword(r *http.Request) (any, error) {
+	_, b, err := s.branch(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req ps.DatabaseBranchPasswordRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = "admin"
+	}
+	switch {
+	case !slices.Contains(passwordRoles, role):
+		return nil, invalid("invalid role %q, must be one of %v", role, passwordRoles)
+	case req.TTL < 0:
+		return nil, invalid("ttl must not be negative")
+	}
+
+	now := s.now()
+	id := s.newID()
+	p := &ps.DatabaseBranchPassword{
+		PublicID:  id,
+		Name:      req.Name,
+		Hostname:  "localhost",
+		Username:  id,
+		Role:      role,
+		Actor:     &actor,
+		Branch:    *b.branch,
+		Region:    b.branch.Region,
+		CreatedAt: now,
+		TTL:       req.TTL,
+		Renewable: req.TTL > 0,
+		Replica:   req.Replica,
+	}
+	if req.TTL > 0 {
+		p.ExpiresAt = now.Add(time.Duration(req.TTL) * time.Second)
+	}
+	b.passwords = append(b.passwords, p)
+
+	// The plain text password is only returned when it's created.
+	created := *p
+	created.PlainText = secret("pscale_pw_")
+	return &created, nil
+}
+
+func (s *Server) getPassword(r *http.Request) (any, error) {
+	_, p, err := s.password(r)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *Server) deletePassword(r *http.Request) (any, error) {
+	b, p, err := s.password(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b.passwords = slices.DeleteFunc(b.passwords, func(other *ps.DatabaseBranchPassword) bool { return other == p })
+	return nil, nil
+}
+
+func (s *Server) renewPassword(r *http.Request) (any, error) {
+	_, p, err := s.password(r)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Renewable {
+		return nil, invalid("password %s doesn't expire and can't be renewed", p.PublicID)
+	}
+
+	p.ExpiresAt = s.now().Add(time.Duration(p.TTL) * time.Second)
+	return p, nil
+}
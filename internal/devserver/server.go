@@ -0,0 +1,251 @@
+// Package devserver emulates the PlanetScale API in memory, for testing
+// pscale and the tools built on it without a PlanetScale organization.
+//
+// It implements the endpoints of the databases, branches, deploy requests,
+// passwords, backups and webhooks services of internal/planetscale, for MySQL
+// databases. Organizations exist as soon as they're used and any credentials
+// are accepted. Operations that take a while on PlanetScale, such as
+// creating a branch or deploying a deploy request, complete immediately.
+package devserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+const (
+	defaultPerPage = 25
+	maxPerPage     = 100
+)
+
+// actor is the user every change is attributed to.
+var actor = ps.Actor{Type: "User", ID: "dev", Name: "pscale dev"}
+
+// Server is an http.Handler serving the emulated API. Its state is lost when
+// the process exits.
+type Server struct {
+	// Now returns the current time. It's time.Now if nil.
+	Now func() time.Time
+
+	mux *http.ServeMux
+
+	mu     sync.Mutex
+	orgs   []*organization
+	lastID int
+}
+
+// New returns a server without any organizations.
+func New() *Server {
+	s := &Server{mux: http.NewServeMux()}
+
+	s.handle("GET /v1/organizations", s.listOrganizations)
+	s.handle("GET /v1/organizations/{org}", s.getOrganization)
+
+	s.handle("GET /v1/organizations/{org}/databases", s.listDatabases)
+	s.handle("POST /v1/organizations/{org}/databases", s.createDatabase)
+	s.handle("GET /v1/organizations/{org}/databases/{db}", s.getDatabase)
+	s.handle("DELETE /v1/organizations/{org}/databases/{db}", s.deleteDatabase)
+
+	s.handle("GET /v1/organizations/{org}/databases/{db}/branches", s.listBranches)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/branches", s.createBranch)
+	s.handle("GET /v1/organizations/{org}/databases/{db}/branches/{branch}", s.getBranch)
+	s.handle("DELETE /v1/organizations/{org}/databases/{db}/branches/{branch}", s.deleteBranch)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/branches/{branch}/promote", s.promoteBranch)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/branches/{branch}/demote", s.demoteBranch)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/branches/{branch}/safe-migrations", s.enableSafeMigrations)
+	s.handle("DELETE /v1/organizations/{org}/databases/{db}/branches/{branch}/safe-migrations", s.disableSafeMigrations)
+
+	s.handle("GET /v1/organizations/{org}/databases/{db}/deploy-requests", s.listDeployRequests)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/deploy-requests", s.createDeployRequest)
+	s.handle("GET /v1/organizations/{org}/databases/{db}/deploy-requests/{number}", s.getDeployRequest)
+	s.handle("PATCH /v1/organizations/{org}/databases/{db}/deploy-requests/{number}", s.closeDeployRequest)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/deploy-requests/{number}/deploy", s.deployDeployRequest)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/deploy-requests/{number}/apply-deploy", s.applyDeployRequest)
+	s.handle("PUT /v1/organizations/{org}/databases/{db}/deploy-requests/{number}/auto-apply", s.autoApplyDeployRequest)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/deploy-requests/{number}/cancel", s.cancelDeployRequest)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/deploy-requests/{number}/skip-revert", s.skipRevertDeployRequest)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/deploy-requests/{number}/revert", s.revertDeployRequest)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/deploy-requests/{number}/reviews", s.reviewDeployRequest)
+	s.handle("GET /v1/organizations/{org}/databases/{db}/deploy-requests/{number}/diff", s.emptyDeployRequestList)
+	s.handle("GET /v1/organizations/{org}/databases/{db}/deploy-requests/{number}/operations", s.emptyDeployRequestList)
+
+	s.handle("GET /v1/organizations/{org}/databases/{db}/passwords", s.listDatabasePasswords)
+	s.handle("GET /v1/organizations/{org}/databases/{db}/branches/{branch}/passwords", s.listPasswords)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/branches/{branch}/passwords", s.createPassword)
+	s.handle("GET /v1/organizations/{org}/databases/{db}/branches/{branch}/passwords/{id}", s.getPassword)
+	s.handle("DELETE /v1/organizations/{org}/databases/{db}/branches/{branch}/passwords/{id}", s.deletePassword)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/branches/{branch}/passwords/{id}/renew", s.renewPassword)
+
+	s.handle("GET /v1/organizations/{org}/databases/{db}/branches/{branch}/backups", s.listBackups)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/branches/{branch}/backups", s.createBackup)
+	s.handle("GET /v1/organizations/{org}/databases/{db}/branches/{branch}/backups/{id}", s.getBackup)
+	s.handle("DELETE /v1/organizations/{org}/databases/{db}/branches/{branch}/backups/{id}", s.deleteBackup)
+
+	s.handle("GET /v1/organizations/{org}/databases/{db}/webhooks", s.listWebhooks)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/webhooks", s.createWebhook)
+	s.handle("GET /v1/organizations/{org}/databases/{db}/webhooks/{id}", s.getWebhook)
+	s.handle("PATCH /v1/organizations/{org}/databases/{db}/webhooks/{id}", s.updateWebhook)
+	s.handle("DELETE /v1/organizations/{org}/databases/{db}/webhooks/{id}", s.deleteWebhook)
+	s.handle("POST /v1/organizations/{org}/databases/{db}/webhooks/{id}/test", s.testWebhook)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := s.mux.Handler(r); pattern == "" {
+		writeError(w, notFound("the dev API server doesn't implement %s %s", r.Method, r.URL.Path))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// handlerFunc handles a request with the server locked. It returns the body
+// of the response, or nil for an empty response.
+type handlerFunc func(r *http.Request) (any, error)
+
+func (s *Server) handle(pattern string, h handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		v, err := h(r)
+		s.mu.Unlock()
+
+		switch {
+		case err != nil:
+			writeError(w, err)
+		case v == nil:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeJSON(w, http.StatusOK, v)
+		}
+	})
+}
+
+func (s *Server) now() time.Time {
+	if s.Now != nil {
+		return s.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// newID returns a new ID. IDs increase, so they sort in creation order.
+func (s *Server) newID() string {
+	s.lastID++
+	return fmt.Sprintf("dev%09d", s.lastID)
+}
+
+// apiError is an error response, in the format of the PlanetScale API.
+type apiError struct {
+	status  int
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string { return e.Message }
+
+func notFound(format string, args ...any) error {
+	return &apiError{status: http.StatusNotFound, Code: "not_found", Message: fmt.Sprintf(format, args...)}
+}
+
+func badRequest(format string, args ...any) error {
+	return &apiError{status: http.StatusBadRequest, Code: "bad_request", Message: fmt.Sprintf(format, args...)}
+}
+
+func invalid(format string, args ...any) error {
+	return &apiError{status: http.StatusUnprocessableEntity, Code: "invalid_params", Message: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	apiErr, ok := errors.AsType[*apiError](err)
+	if !ok {
+		apiErr = &apiError{status: http.StatusInternalServerError, Code: "internal", Message: err.Error()}
+	}
+	writeJSON(w, apiErr.status, apiErr)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// decode decodes the JSON body of r into v. An empty body leaves v as is.
+func decode(r *http.Request, v any) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return badRequest("invalid JSON body: %s", err)
+	}
+	return nil
+}
+
+// listResponse is a page of a list, in the format of the PlanetScale API.
+type listResponse struct {
+	CurrentPage int  `json:"current_page"`
+	NextPage    *int `json:"next_page"`
+	PrevPage    *int `json:"prev_page"`
+	Data        any  `json:"data"`
+}
+
+// paginate returns the page of items selected by the page and per_page
+// parameters of r.
+func paginate[T any](r *http.Request, items []T) (*listResponse, error) {
+	page, err := intParam(r, "page", 1)
+	if err != nil {
+		return nil, err
+	}
+	perPage, err := intParam(r, "per_page", defaultPerPage)
+	if err != nil {
+		return nil, err
+	}
+	perPage = min(perPage, maxPerPage)
+
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+
+	res := &listResponse{CurrentPage: page, Data: slices.Clone(items[start:end])}
+	if end < len(items) {
+		next := page + 1
+		res.NextPage = &next
+	}
+	if page > 1 {
+		prev := page - 1
+		res.PrevPage = &prev
+	}
+	return res, nil
+}
+
+func intParam(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, badRequest("%s must be a positive number", name)
+	}
+	return n, nil
+}
+
+// htmlURL returns the URL of the web page of a resource. The server doesn't
+// serve web pages, but pscale prints these URLs.
+func htmlURL(r *http.Request, elem ...string) string {
+	return "http://" + r.Host + "/" + path.Join(elem...)
+}
+
+// secret returns a random secret starting with prefix.
+func secret(prefix string) string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
@@ -0,0 +1,208 @@
+package devserver
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/planetscale/cli/internal/cmdutil"
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+const org = "my-org"
+
+func newClient(c *qt.C) *ps.Client {
+	srv := New()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv.Now = func() time.Time { return now }
+
+	ts := httptest.NewServer(srv)
+	c.Cleanup(ts.Close)
+
+	client, err := ps.NewClient(ps.WithBaseURL(ts.URL), ps.WithAccessToken("dev"))
+	c.Assert(err, qt.IsNil)
+	return client
+}
+
+func createDatabase(c *qt.C, client *ps.Client, name string) {
+	_, err := client.Databases.Create(context.Background(), &ps.CreateDatabaseRequest{Organization: org, Name: name})
+	c.Assert(err, qt.IsNil)
+}
+
+func TestDatabasesAndBranches(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+	client := newClient(c)
+
+	db, err := client.Databases.Create(ctx, &ps.CreateDatabaseRequest{Organization: org, Name: "db"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(db.State, qt.Equals, ps.DatabaseReady)
+	c.Assert(db.Kind, qt.Equals, ps.DatabaseEngineMySQL)
+
+	_, err = client.Databases.Create(ctx, &ps.CreateDatabaseRequest{Organization: org, Name: "db"})
+	c.Assert(err, qt.ErrorMatches, "name db has already been taken")
+
+	branch, err := client.DatabaseBranches.Create(ctx, &ps.CreateDatabaseBranchRequest{Organization: org, Database: "db", Name: "feature"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(branch.ParentBranch, qt.Equals, "main")
+	c.Assert(branch.Ready, qt.IsTrue)
+	c.Assert(branch.Production, qt.IsFalse)
+
+	branches, err := client.DatabaseBranches.List(ctx, &ps.ListDatabaseBranchesRequest{Organization: org, Database: "db"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(branches, qt.HasLen, 2)
+	c.Assert(branches[0].Name, qt.Equals, "main")
+	c.Assert(branches[1].Name, qt.Equals, "feature")
+
+	err = client.DatabaseBranches.Delete(ctx, &ps.DeleteDatabaseBranchRequest{Organization: org, Database: "db", Branch: "main"})
+	c.Assert(err, qt.ErrorMatches, "production branch main can't be deleted, demote it first")
+
+	err = client.DatabaseBranches.Delete(ctx, &ps.DeleteDatabaseBranchRequest{Organization: org, Database: "db", Branch: "feature"})
+	c.Assert(err, qt.IsNil)
+
+	_, err = client.DatabaseBranches.Get(ctx, &ps.GetDatabaseBranchRequest{Organization: org, Database: "db", Branch: "feature"})
+	c.Assert(cmdutil.ErrCode(err), qt.Equals, ps.ErrNotFound)
+
+	_, err = client.Databases.Delete(ctx, &ps.DeleteDatabaseRequest{Organization: org, Database: "db"})
+	c.Assert(err, qt.IsNil)
+
+	_, err = client.Databases.Get(ctx, &ps.GetDatabaseRequest{Organization: org, Database: "db"})
+	c.Assert(cmdutil.ErrCode(err), qt.Equals, ps.ErrNotFound)
+}
+
+func TestDeployRequests(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+	client := newClient(c)
+
+	createDatabase(c, client, "db")
+	for _, name := range []string{"manual", "auto"} {
+		_, err := client.DatabaseBranches.Create(ctx, &ps.CreateDatabaseBranchRequest{Organization: org, Database: "db", Name: name})
+		c.Assert(err, qt.IsNil)
+	}
+
+	dr, err := client.DeployRequests.Create(ctx, &ps.CreateDeployRequestRequest{Organization: org, Database: "db", Branch: "manual"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(dr.Number, qt.Equals, uint64(1))
+	c.Assert(dr.IntoBranch, qt.Equals, "main")
+	c.Assert(dr.State, qt.Equals, "open")
+	c.Assert(dr.DeploymentState, qt.Equals, "ready")
+
+	_, err = client.DeployRequests.Create(ctx, &ps.CreateDeployRequestRequest{Organization: org, Database: "db", Branch: "manual"})
+	c.Assert(err, qt.ErrorMatches, "branch manual already has an open deploy request, #1")
+
+	_, err = client.DeployRequests.ApplyDeploy(ctx, &ps.ApplyDeployRequestRequest{Organization: org, Database: "db", Number: 1})
+	c.Assert(err, qt.ErrorMatches, "deploy request 1 can't go from ready to complete_pending_revert")
+
+	dr, err = client.DeployRequests.Deploy(ctx, &ps.PerformDeployRequest{Organization: org, Database: "db", Number: 1})
+	c.Assert(err, qt.IsNil)
+	c.Assert(dr.DeploymentState, qt.Equals, "pending_cutover")
+	c.Assert(dr.State, qt.Equals, "open")
+
+	dr, err = client.DeployRequests.ApplyDeploy(ctx, &ps.ApplyDeployRequestRequest{Organization: org, Database: "db", Number: 1})
+	c.Assert(err, qt.IsNil)
+	c.Assert(dr.DeploymentState, qt.Equals, "complete_pending_revert")
+	c.Assert(dr.State, qt.Equals, "closed")
+	c.Assert(dr.DeployedAt, qt.IsNotNil)
+
+	dr, err = client.DeployRequests.RevertDeploy(ctx, &ps.RevertDeployRequestRequest{Organization: org, Database: "db", Number: 1})
+	c.Assert(err, qt.IsNil)
+	c.Assert(dr.DeploymentState, qt.Equals, "complete_revert")
+
+	dr, err = client.DeployRequests.Create(ctx, &ps.CreateDeployRequestRequest{Organization: org, Database: "db", Branch: "auto", AutoCutover: true, AutoDeleteBranch: true})
+	c.Assert(err, qt.IsNil)
+	c.Assert(dr.Number, qt.Equals, uint64(2))
+
+	review, err := client.DeployRequests.CreateReview(ctx, &ps.ReviewDeployRequestRequest{Organization: org, Database: "db", Number: 2, ReviewAction: ps.ReviewApprove})
+	c.Assert(err, qt.IsNil)
+	c.Assert(review.State, qt.Equals, "approved")
+
+	dr, err = client.DeployRequests.Deploy(ctx, &ps.PerformDeployRequest{Organization: org, Database: "db", Number: 2})
+	c.Assert(err, qt.IsNil)
+	c.Assert(dr.Approved, qt.IsTrue)
+	c.Assert(dr.DeploymentState, qt.Equals, "complete_pending_revert")
+	c.Assert(dr.BranchDeletedBy, qt.IsNotNil)
+
+	dr, err = client.DeployRequests.SkipRevertDeploy(ctx, &ps.SkipRevertDeployRequestRequest{Organization: org, Database: "db", Number: 2})
+	c.Assert(err, qt.IsNil)
+	c.Assert(dr.DeploymentState, qt.Equals, "complete")
+
+	_, err = client.DatabaseBranches.Get(ctx, &ps.GetDatabaseBranchRequest{Organization: org, Database: "db", Branch: "auto"})
+	c.Assert(cmdutil.ErrCode(err), qt.Equals, ps.ErrNotFound)
+
+	drs, err := client.DeployRequests.List(ctx, &ps.ListDeployRequestsRequest{Organization: org, Database: "db", Branch: "manual"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(drs, qt.HasLen, 1)
+	c.Assert(drs[0].Number, qt.Equals, uint64(1))
+
+	_, err = client.DeployRequests.Get(ctx, &ps.GetDeployRequestRequest{Organization: org, Database: "db", Number: 3})
+	c.Assert(cmdutil.ErrCode(err), qt.Equals, ps.ErrNotFound)
+}
+
+func TestPasswordsBackupsWebhooks(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+	client := newClient(c)
+
+	createDatabase(c, client, "db")
+
+	pw, err := client.Passwords.Create(ctx, &ps.DatabaseBranchPasswordRequest{Organization: org, Database: "db", Branch: "main", Name: "app", Role: "reader", TTL: 3600})
+	c.Assert(err, qt.IsNil)
+	c.Assert(pw.PlainText, qt.Not(qt.Equals), "")
+	c.Assert(pw.Renewable, qt.IsTrue)
+
+	got, err := client.Passwords.Get(ctx, &ps.GetDatabaseBranchPasswordRequest{Organization: org, Database: "db", Branch: "main", PasswordId: pw.PublicID})
+	c.Assert(err, qt.IsNil)
+	c.Assert(got.Role, qt.Equals, "reader")
+	c.Assert(got.PlainText, qt.Equals, "")
+
+	_, err = client.Passwords.Create(ctx, &ps.DatabaseBranchPasswordRequest{Organization: org, Database: "db", Branch: "main", Name: "bad", Role: "owner"})
+	c.Assert(err, qt.ErrorMatches, `invalid role "owner".*`)
+
+	passwords, err := client.Passwords.List(ctx, &ps.ListDatabaseBranchPasswordRequest{Organization: org, Database: "db"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(passwords, qt.HasLen, 1)
+
+	err = client.Passwords.Delete(ctx, &ps.DeleteDatabaseBranchPasswordRequest{Organization: org, Database: "db", Branch: "main", PasswordId: pw.PublicID})
+	c.Assert(err, qt.IsNil)
+
+	bkp, err := client.Backups.Create(ctx, &ps.CreateBackupRequest{Organization: org, Database: "db", Branch: "main", RetentionUnit: "week", RetentionValue: 1})
+	c.Assert(err, qt.IsNil)
+	c.Assert(bkp.State, qt.Equals, "success")
+	c.Assert(bkp.ExpiresAt.Sub(bkp.CreatedAt), qt.Equals, 7*24*time.Hour)
+
+	wh, err := client.Webhooks.Create(ctx, &ps.CreateWebhookRequest{Organization: org, Database: "db", URL: "https://example.com/hook", Events: []string{"branch.ready"}})
+	c.Assert(err, qt.IsNil)
+	c.Assert(wh.Enabled, qt.IsTrue)
+	c.Assert(wh.Secret, qt.Not(qt.Equals), "")
+
+	disabled := false
+	wh, err = client.Webhooks.Update(ctx, &ps.UpdateWebhookRequest{Organization: org, Database: "db", ID: wh.ID, Enabled: &disabled})
+	c.Assert(err, qt.IsNil)
+	c.Assert(wh.Enabled, qt.IsFalse)
+	c.Assert(wh.Events, qt.DeepEquals, []string{"branch.ready"})
+
+	_, err = client.Webhooks.Create(ctx, &ps.CreateWebhookRequest{Organization: org, Database: "db", URL: "example.com"})
+	c.Assert(err, qt.ErrorMatches, `url "example.com" must be an http or https URL`)
+}
+
+func TestPagination(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+	client := newClient(c)
+
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		createDatabase(c, client, name)
+	}
+
+	var names []string
+	for db, err := range ps.All(ctx, 2, func(ctx context.Context, opts ...ps.ListOption) ([]*ps.Database, error) {
+		return client.Databases.List(ctx, &ps.ListDatabasesRequest{Organization: org}, opts...)
+	}) {
+		c.Assert(err, qt.IsNil)
+		names = append(names, db.Name)
+	}
+	c.Assert(names, qt.DeepEquals, []string{"a", "b", "c", "d", "e"})
+}
@@ -0,0 +1,130 @@
+package devserver
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+// webhook returns the database and webhook of the path of r.
+func (s *Server) webhook(r *http.Request) (*database, *ps.Webhook, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id := r.PathValue("id")
+	for _, wh := range db.webhooks {
+		if wh.ID == id {
+			return db, wh, nil
+		}
+	}
+	return nil, nil, notFound("webhook %s does not exist in database %s", id, db.db.Name)
+}
+
+func validWebhookURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return invalid("url %q must be an http or https URL", u)
+	}
+	return nil
+}
+
+func (s *Server) listWebhooks(r *http.Request) (any, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(r, db.webhooks)
+}
+
+func (s *Server) createWebhook(r *http.Request) (any, error) {
+	db, err := s.database(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req ps.CreateWebhookRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if err := validWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	wh := &ps.Webhook{
+		ID:        s.newID(),
+		URL:       req.URL,
+		Secret:    secret(""),
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Events:    slices.Clone(req.Events),
+	}
+	if wh.Events == nil {
+		wh.Events = []string{}
+	}
+	db.webhooks = append(db.webhooks, wh)
+	return wh, nil
+}
+
+func (s *Server) getWebhook(r *http.Request) (any, error) {
+	_, wh, err := s.webhook(r)
+	if err != nil {
+		return nil, err
+	}
+	return wh, nil
+}
+
+func (s *Server) updateWebhook(r *http.Request) (any, error) {
+	_, wh, err := s.webhook(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req ps.UpdateWebhookRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		wh.URL = *req.URL
+	}
+	if req.Enabled != nil {
+		wh.Enabled = *req.Enabled
+	}
+	if req.Events != nil {
+		wh.Events = slices.Clone(req.Events)
+	}
+	wh.UpdatedAt = s.now()
+	return wh, nil
+}
+
+func (s *Server) deleteWebhook(r *http.Request) (any, error) {
+	db, wh, err := s.webhook(r)
+	if err != nil {
+		return nil, err
+	}
+
+	db.webhooks = slices.DeleteFunc(db.webhooks, func(other *ps.Webhook) bool { return other == wh })
+	return nil, nil
+}
+
+// testWebhook records a successful test event without sending it.
+func (s *Server) testWebhook(r *http.Request) (any, error) {
+	_, wh, err := s.webhook(r)
+	if err != nil {
+		return nil, err
+	}
+
+	wh.LastSentAt = s.now()
+	wh.LastSentResult = "200 OK"
+	wh.LastSentSuccess = true
+	return nil, nil
+}
@@ -3,7 +3,6 @@ package dumper
 import (
 	"bytes"
 	"encoding/csv"
-	"fmt"
 
 	"github.com/xelabs/go-mysqlstack/sqlparser/depends/sqltypes"
 )
@@ -60,7 +59,7 @@ func (w *csvWriter) ShouldFlush() bool {
 }
 
 func (w *csvWriter) Flush(outdir, database, table string, fileNo int) error {
-	file := fmt.Sprintf("%s/%s.%s.%05d.csv", outdir, database, table, fileNo)
+	file := w.cfg.dataFilePath(outdir, database, table, fileNo, "csv")
 	err := writeFile(file, w.csvBuffer.String())
 	if err != nil {
 		return err
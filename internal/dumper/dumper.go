@@ -57,6 +57,15 @@ type Config struct {
 	Filters                   map[string]map[string]string
 	ColumnIncludes            map[string]map[string]bool
 
+	// DataFileName names data file chunks. When nil, chunks are named
+	// <outdir>/<database>.<table>.<fileNo>.<ext>.
+	DataFileName func(outdir, database, table string, fileNo int, ext string) string
+
+	// TableDumped, if set, is called once each table is dumped: its data, or
+	// its schema with SchemaOnly. Tables are dumped concurrently, so it's
+	// called from several goroutines.
+	TableDumped func(TableProgress)
+
 	// Interval in millisecond.
 	IntervalMs int
 	Debug      bool
@@ -70,6 +79,23 @@ func NewDefaultConfig() *Config {
 	}
 }
 
+func (c *Config) dataFilePath(outdir, database, table string, fileNo int, ext string) string {
+	if c.DataFileName != nil {
+		return c.DataFileName(outdir, database, table, fileNo, ext)
+	}
+	return fmt.Sprintf("%s/%s.%s.%05d.%s", outdir, database, table, fileNo, ext)
+}
+
+// TableProgress reports a dumped table, see Config.TableDumped.
+type TableProgress struct {
+	Database string
+	Table    string
+	Rows     uint64
+	// Done tables were dumped out of Total, including this one.
+	Done  int
+	Total int
+}
+
 type Dumper struct {
 	cfg *Config
 	log *zap.Logger
@@ -144,6 +170,23 @@ func (d *Dumper) Run(ctx context.Context) error {
 	}
 	initPool.Put(conn)
 
+	ghostTable := regexp.MustCompile(VITESS_GHOST_TABLE_REGEX)
+	total := 0
+	for i := range databases {
+		for _, table := range tables[i] {
+			if _, ok := views[i][table]; !ok && !ghostTable.MatchString(table) {
+				total++
+			}
+		}
+	}
+	var done atomic.Int64
+	tableDumped := func(database, table string, rows uint64) {
+		n := done.Add(1)
+		if d.cfg.TableDumped != nil {
+			d.cfg.TableDumped(TableProgress{Database: database, Table: table, Rows: rows, Done: int(n), Total: total})
+		}
+	}
+
 	// Adding the context here helps down below if a query issue is encountered to prevent further processing:
 	eg, egCtx := errgroup.WithContext(ctx)
 	for i, database := range databases {
@@ -155,7 +198,7 @@ func (d *Dumper) Run(ctx context.Context) error {
 		defer pool.Close()
 		for _, table := range tables[i] {
 			// Skip vitess ghost tables
-			if regexp.MustCompile(VITESS_GHOST_TABLE_REGEX).MatchString(table) {
+			if ghostTable.MatchString(table) {
 				continue
 			}
 
@@ -179,6 +222,7 @@ func (d *Dumper) Run(ctx context.Context) error {
 
 			// Skip data dumping if schema-only mode is enabled
 			if d.cfg.SchemaOnly {
+				tableDumped(database, table, 0)
 				continue
 			}
 
@@ -198,11 +242,13 @@ func (d *Dumper) Run(ctx context.Context) error {
 					zap.Int("thread_conn_id", conn.ID),
 				)
 
-				err := d.dumpTable(ctx, conn, database, table)
+				rows, err := d.dumpTable(ctx, conn, database, table)
 				if err != nil {
 					d.log.Error("error dumping table", zap.Error(err))
+					return nil
 				}
 
+				tableDumped(database, table, rows)
 				return nil
 			})
 		}
@@ -271,8 +317,9 @@ func (d *Dumper) dumpTableSchema(conn *Connection, database string, table string
 	return nil
 }
 
-// Dump a table in the configured output format
-func (d *Dumper) dumpTable(ctx context.Context, conn *Connection, database string, table string) error {
+// Dump a table in the configured output format and return how many rows it
+// had.
+func (d *Dumper) dumpTable(ctx context.Context, conn *Connection, database string, table string) (uint64, error) {
 	var writer TableWriter
 
 	switch d.cfg.OutputFormat {
@@ -286,16 +333,16 @@ func (d *Dumper) dumpTable(ctx context.Context, conn *Connection, database strin
 
 	dumpCtx, err := d.tableDumpContext(conn, table)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if err := writer.Initialize(dumpCtx.fieldNames); err != nil {
-		return err
+		return 0, err
 	}
 
 	cursor, err := conn.StreamFetch(fmt.Sprintf("SELECT %s FROM %s.%s %s", strings.Join(dumpCtx.selfields, ", "), quoteIdentifier(database), quoteIdentifier(table), dumpCtx.where))
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer cursor.Close()
 
@@ -305,17 +352,17 @@ func (d *Dumper) dumpTable(ctx context.Context, conn *Connection, database strin
 	for cursor.Next() {
 		row, err := cursor.RowValues()
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		// Allows for quicker exit when using Ctrl+C at the Terminal:
 		if ctx.Err() != nil {
-			return ctx.Err()
+			return 0, ctx.Err()
 		}
 
 		bytesAdded, err := writer.WriteRow(row)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		allRows++
@@ -325,7 +372,7 @@ func (d *Dumper) dumpTable(ctx context.Context, conn *Connection, database strin
 
 		if writer.ShouldFlush() {
 			if err := writer.Flush(d.cfg.Outdir, database, table, fileNo); err != nil {
-				return err
+				return 0, err
 			}
 
 			d.log.Info(
@@ -343,7 +390,7 @@ func (d *Dumper) dumpTable(ctx context.Context, conn *Connection, database strin
 	}
 
 	if err := writer.Close(d.cfg.Outdir, database, table, fileNo); err != nil {
-		return err
+		return 0, err
 	}
 
 	d.log.Info(
@@ -354,7 +401,7 @@ func (d *Dumper) dumpTable(ctx context.Context, conn *Connection, database strin
 		zap.Any("all_bytes", (allBytes/1024/1024)),
 		zap.Int("thread_conn_id", conn.ID),
 	)
-	return nil
+	return allRows, nil
 }
 
 func (d *Dumper) tableDumpContext(conn *Connection, table string) (*dumpContext, error) {
@@ -5,7 +5,9 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/xelabs/go-mysqlstack/driver"
@@ -178,6 +180,16 @@ func TestDumper(t *testing.T) {
 		SessionVars:   []string{"SET @@radon_streaming_fetch='ON', @@xx=1"},
 	}
 
+	var (
+		mu     sync.Mutex
+		dumped []TableProgress
+	)
+	cfg.TableDumped = func(p TableProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		dumped = append(dumped, p)
+	}
+
 	d, err := NewDumper(cfg)
 	c.Assert(err, qt.IsNil)
 
@@ -189,6 +201,16 @@ func TestDumper(t *testing.T) {
 
 	want := strings.Contains(string(dat), `(11,"11\"xx\"","",NULL,210.01,NULL)`)
 	c.Assert(want, qt.IsTrue)
+
+	// Views aren't dumped, so they aren't reported.
+	c.Assert(dumped, qt.HasLen, 2)
+	slices.SortFunc(dumped, func(a, b TableProgress) int { return a.Done - b.Done })
+	for i, p := range dumped {
+		c.Assert(p.Database, qt.Equals, "test")
+		c.Assert(p.Rows, qt.Equals, uint64(201710))
+		c.Assert(p.Done, qt.Equals, i+1)
+		c.Assert(p.Total, qt.Equals, 2)
+	}
 }
 
 func TestDumperGeneratedFields(t *testing.T) {
@@ -0,0 +1,250 @@
+package dumper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	querypb "github.com/xelabs/go-mysqlstack/sqlparser/depends/query"
+	"github.com/xelabs/go-mysqlstack/sqlparser/depends/sqltypes"
+)
+
+// Rows is a stream of typed result rows. The rows returned by
+// Connection.StreamFetch satisfy it, and NewPostgresRows adapts pgx rows.
+type Rows interface {
+	Next() bool
+	RowValues() ([]sqltypes.Value, error)
+	Fields() []*querypb.Field
+	LastError() error
+}
+
+// ExportConfig describes an export of a single result set to data files.
+type ExportConfig struct {
+	// Path is the output file. When the result spans several chunks, the
+	// chunks are numbered before the extension instead (out.00001.csv, ...).
+	Path string
+	// Format is csv, json (newline-delimited), parquet, or sql.
+	Format string
+	// Table is the target table of the INSERT statements in sql format.
+	Table string
+	// ChunksizeInMB starts a new file once a chunk holds this much data.
+	ChunksizeInMB int
+	// StmtSize bounds a single INSERT statement in sql format.
+	StmtSize int
+	// Replace are the files of an earlier export to the same path, see
+	// ExportFiles. Those this export doesn't write are removed once it wrote
+	// its first chunk, so a failed export leaves them alone.
+	Replace []string
+}
+
+// ExportStats summarizes a finished export.
+type ExportStats struct {
+	Rows  uint64   `json:"rows"`
+	Bytes uint64   `json:"bytes"`
+	Files []string `json:"files"`
+	// ZeroDates counts MySQL zero dates (0000-00-00) written as NULL, which
+	// parquet can't represent.
+	ZeroDates uint64 `json:"zero_dates,omitempty"`
+}
+
+var exportExtensions = map[string]string{
+	".csv":     "csv",
+	".json":    "json",
+	".ndjson":  "json",
+	".jsonl":   "json",
+	".parquet": "parquet",
+	".sql":     "sql",
+}
+
+// ExportFormat infers the export format from the file extension of path.
+func ExportFormat(path string) (string, error) {
+	format, ok := exportExtensions[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", fmt.Errorf("can't infer the format of %q; use a .csv, .ndjson, .json, .parquet, or .sql file or pass --type", path)
+	}
+	return format, nil
+}
+
+// Export streams rows into data files using the same writers as Dumper, so
+// column types survive into formats that keep them (parquet, sql). progress,
+// if set, is called after every flushed chunk.
+func Export(ctx context.Context, cfg *ExportConfig, rows Rows, progress func(*ExportStats)) (*ExportStats, error) {
+	ext := filepath.Ext(cfg.Path)
+	stem := strings.TrimSuffix(cfg.Path, ext)
+
+	// Writers name a chunk right before writing it, so these are the chunks
+	// of this export, whatever else is in the directory.
+	var written []string
+	wcfg := &Config{
+		ChunksizeInMB: cfg.ChunksizeInMB,
+		StmtSize:      cfg.StmtSize,
+		DataFileName: func(_, _, _ string, fileNo int, _ string) string {
+			path := chunkPath(stem, ext, fileNo)
+			if !slices.Contains(written, path) {
+				written = append(written, path)
+			}
+			return path
+		},
+	}
+
+	var writer TableWriter
+	switch cfg.Format {
+	case "csv":
+		writer = newCSVWriter(wcfg)
+	case "json":
+		writer = newJSONWriter(wcfg)
+	case "parquet":
+		writer = newParquetWriter(wcfg)
+	case "sql":
+		if cfg.Table == "" {
+			return nil, fmt.Errorf("sql exports need a target table name")
+		}
+		writer = newSQLWriter(wcfg, cfg.Table)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", cfg.Format)
+	}
+
+	fields := rows.Fields()
+	if tw, ok := writer.(TypedTableWriter); ok {
+		if err := tw.InitializeFields(fields); err != nil {
+			return nil, err
+		}
+	} else {
+		names := make([]string, len(fields))
+		for i, f := range fields {
+			names[i] = f.Name
+		}
+		if err := writer.Initialize(names); err != nil {
+			return nil, err
+		}
+	}
+
+	replaced := false
+	replace := func() error {
+		if replaced {
+			return nil
+		}
+		replaced = true
+		for _, file := range cfg.Replace {
+			if slices.Contains(written, file) {
+				continue
+			}
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	stats := &ExportStats{}
+	fileNo := 1
+	pending := false
+	for rows.Next() {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		row, err := rows.RowValues()
+		if err != nil {
+			return nil, err
+		}
+
+		bytesAdded, err := writer.WriteRow(row)
+		if err != nil {
+			return nil, err
+		}
+		stats.Rows++
+		stats.Bytes += uint64(bytesAdded)
+		pending = true
+
+		if writer.ShouldFlush() {
+			if err := writer.Flush("", "", "", fileNo); err != nil {
+				return nil, err
+			}
+			if err := replace(); err != nil {
+				return nil, err
+			}
+			fileNo++
+			pending = false
+			if progress != nil {
+				progress(stats)
+			}
+		}
+	}
+	if err := rows.LastError(); err != nil {
+		return nil, err
+	}
+
+	// Some writers leave a header-only chunk behind after a flush; only close
+	// into a new file when rows are pending or nothing was written yet (so an
+	// empty CSV result still gets its header).
+	if pending || fileNo == 1 {
+		if err := writer.Close("", "", "", fileNo); err != nil {
+			return nil, err
+		}
+	}
+	if err := replace(); err != nil {
+		return nil, err
+	}
+
+	stats.Files = written
+	if pw, ok := writer.(*parquetWriter); ok {
+		stats.ZeroDates = uint64(pw.zeroDates)
+	}
+
+	switch len(stats.Files) {
+	case 0:
+		// Writers skip empty chunks; an empty result still gets its file.
+		if err := writeFile(cfg.Path, ""); err != nil {
+			return nil, err
+		}
+		stats.Files = []string{cfg.Path}
+	case 1:
+		if err := os.Rename(stats.Files[0], cfg.Path); err != nil {
+			return nil, err
+		}
+		stats.Files = []string{cfg.Path}
+	}
+
+	return stats, nil
+}
+
+func chunkPath(stem, ext string, fileNo int) string {
+	return fmt.Sprintf("%s.%05d%s", stem, fileNo, ext)
+}
+
+// ExportFiles returns the files an export to path would overwrite or leave
+// next to its own: path itself and chunks named after it.
+func ExportFiles(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(filepath.Base(path), ext)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if name == filepath.Base(path) {
+			files = append(files, path)
+			continue
+		}
+		n, ok := strings.CutPrefix(name, stem+".")
+		if !ok {
+			continue
+		}
+		n, ok = strings.CutSuffix(n, ext)
+		if ok && len(n) == 5 && strings.Trim(n, "0123456789") == "" {
+			files = append(files, filepath.Join(filepath.Dir(path), name))
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,279 @@
+package dumper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	querypb "github.com/xelabs/go-mysqlstack/sqlparser/depends/query"
+	"github.com/xelabs/go-mysqlstack/sqlparser/depends/sqltypes"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type sliceRows struct {
+	fields []*querypb.Field
+	rows   [][]sqltypes.Value
+	pos    int
+}
+
+func (r *sliceRows) Next() bool {
+	r.pos++
+	return r.pos <= len(r.rows)
+}
+
+func (r *sliceRows) RowValues() ([]sqltypes.Value, error) { return r.rows[r.pos-1], nil }
+func (r *sliceRows) Fields() []*querypb.Field             { return r.fields }
+func (r *sliceRows) LastError() error                     { return nil }
+
+func exportTestRows() *sliceRows {
+	return &sliceRows{
+		fields: []*querypb.Field{
+			{Name: "id", Type: querypb.Type_INT64},
+			{Name: "email", Type: querypb.Type_VARCHAR},
+			{Name: "total", Type: querypb.Type_DECIMAL},
+			{Name: "created_at", Type: querypb.Type_DATETIME},
+			{Name: "id", Type: querypb.Type_UINT32},
+		},
+		rows: [][]sqltypes.Value{
+			{
+				sqltypes.MakeTrusted(querypb.Type_INT64, []byte("1")),
+				sqltypes.MakeTrusted(querypb.Type_VARCHAR, []byte("a@example.com")),
+				sqltypes.MakeTrusted(querypb.Type_DECIMAL, []byte("10.50")),
+				sqltypes.MakeTrusted(querypb.Type_DATETIME, []byte("2024-03-01 12:30:00")),
+				sqltypes.MakeTrusted(querypb.Type_UINT32, []byte("7")),
+			},
+			{
+				sqltypes.MakeTrusted(querypb.Type_INT64, []byte("2")),
+				sqltypes.NULL,
+				sqltypes.MakeTrusted(querypb.Type_DECIMAL, []byte("3.00")),
+				sqltypes.MakeTrusted(querypb.Type_DATETIME, []byte("2024-03-02 08:00:00.250000")),
+				sqltypes.MakeTrusted(querypb.Type_UINT32, []byte("8")),
+			},
+		},
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(t.TempDir(), "orders.parquet")
+	stats, err := Export(context.Background(), &ExportConfig{Path: path, Format: "parquet", ChunksizeInMB: 128}, exportTestRows(), nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats.Rows, qt.Equals, uint64(2))
+	c.Assert(stats.Files, qt.DeepEquals, []string{path})
+
+	f, err := os.Open(path)
+	c.Assert(err, qt.IsNil)
+	defer f.Close()
+	info, err := f.Stat()
+	c.Assert(err, qt.IsNil)
+
+	pf, err := parquet.OpenFile(f, info.Size())
+	c.Assert(err, qt.IsNil)
+	c.Assert(pf.NumRows(), qt.Equals, int64(2))
+
+	// The columns keep the order of the result.
+	schema := pf.Schema()
+	var names []string
+	for _, f := range schema.Fields() {
+		names = append(names, f.Name())
+	}
+	c.Assert(names, qt.DeepEquals, []string{"id", "email", "total", "created_at", "id_2"})
+
+	idCol, _ := schema.Lookup("id")
+	c.Assert(idCol.Node.Type().Kind(), qt.Equals, parquet.Int64)
+	tsCol, _ := schema.Lookup("created_at")
+	emailCol, _ := schema.Lookup("email")
+
+	rows := make([]parquet.Row, 2)
+	reader := parquet.NewReader(pf)
+	n, _ := reader.ReadRows(rows)
+	c.Assert(n, qt.Equals, 2)
+
+	value := func(row parquet.Row, col int) parquet.Value {
+		for _, v := range row {
+			if v.Column() == col {
+				return v
+			}
+		}
+		t.Fatalf("row has no column %d", col)
+		return parquet.Value{}
+	}
+
+	c.Assert(value(rows[0], idCol.ColumnIndex).Int64(), qt.Equals, int64(1))
+	c.Assert(value(rows[0], tsCol.ColumnIndex).Int64(), qt.Equals, int64(1709296200000000))
+	c.Assert(value(rows[1], tsCol.ColumnIndex).Int64(), qt.Equals, int64(1709366400250000))
+	c.Assert(value(rows[1], emailCol.ColumnIndex).IsNull(), qt.IsTrue)
+}
+
+func TestExportChunks(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "orders.csv")
+
+	var flushes int
+	// A chunk size of 0 flushes after every row.
+	stats, err := Export(context.Background(), &ExportConfig{Path: path, Format: "csv"}, exportTestRows(),
+		func(*ExportStats) { flushes++ })
+	c.Assert(err, qt.IsNil)
+	c.Assert(flushes, qt.Equals, 2)
+	c.Assert(stats.Files, qt.DeepEquals, []string{
+		filepath.Join(dir, "orders.00001.csv"),
+		filepath.Join(dir, "orders.00002.csv"),
+	})
+
+	data, err := os.ReadFile(stats.Files[1])
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Contains, "id,email,total,created_at,id\n")
+	c.Assert(string(data), qt.Contains, "2,,3.00,2024-03-02 08:00:00.250000,8\n")
+
+	_, err = os.Stat(path)
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+}
+
+func TestExportIgnoresStaleChunks(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "orders.csv")
+	stale := filepath.Join(dir, "orders.00003.csv")
+	c.Assert(os.WriteFile(stale, []byte("old"), 0o644), qt.IsNil)
+
+	files, err := ExportFiles(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(files, qt.DeepEquals, []string{stale})
+
+	// Both rows flush, so the export ends one chunk past its last file.
+	stats, err := Export(context.Background(), &ExportConfig{Path: path, Format: "csv"}, exportTestRows(), nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats.Files, qt.DeepEquals, []string{
+		filepath.Join(dir, "orders.00001.csv"),
+		filepath.Join(dir, "orders.00002.csv"),
+	})
+}
+
+// failingRows fails before yielding a row.
+type failingRows struct{ sliceRows }
+
+func (r *failingRows) Next() bool       { return false }
+func (r *failingRows) LastError() error { return errors.New("connection lost") }
+
+func TestExportReplacesEarlierFiles(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "orders.csv")
+	stale := filepath.Join(dir, "orders.00003.csv")
+	for _, file := range []string{path, stale} {
+		c.Assert(os.WriteFile(file, []byte("old"), 0o644), qt.IsNil)
+	}
+	files, err := ExportFiles(path)
+	c.Assert(err, qt.IsNil)
+
+	// A failed export leaves the earlier files alone.
+	_, err = Export(context.Background(), &ExportConfig{Path: path, Format: "csv", Replace: files},
+		&failingRows{sliceRows: *exportTestRows()}, nil)
+	c.Assert(err, qt.ErrorMatches, "connection lost")
+	for _, file := range files {
+		_, err := os.Stat(file)
+		c.Assert(err, qt.IsNil)
+	}
+
+	stats, err := Export(context.Background(), &ExportConfig{Path: path, Format: "csv", Replace: files}, exportTestRows(), nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats.Files, qt.HasLen, 2)
+	for _, file := range files {
+		_, err := os.Stat(file)
+		c.Assert(os.IsNotExist(err), qt.IsTrue, qt.Commentf("%s", file))
+	}
+}
+
+func TestExportParquetZeroDates(t *testing.T) {
+	c := qt.New(t)
+
+	rows := exportTestRows()
+	rows.rows[1][3] = sqltypes.MakeTrusted(querypb.Type_DATETIME, []byte("0000-00-00 00:00:00"))
+	stats, err := Export(context.Background(), &ExportConfig{Path: filepath.Join(t.TempDir(), "orders.parquet"), Format: "parquet", ChunksizeInMB: 128}, rows, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats.ZeroDates, qt.Equals, uint64(1))
+
+	rows = exportTestRows()
+	rows.rows[1][3] = sqltypes.MakeTrusted(querypb.Type_DATETIME, []byte("yesterday"))
+	_, err = Export(context.Background(), &ExportConfig{Path: filepath.Join(t.TempDir(), "orders.parquet"), Format: "parquet", ChunksizeInMB: 128}, rows, nil)
+	c.Assert(err, qt.ErrorMatches, `invalid date "yesterday": .*`)
+}
+
+func TestExportEmptyResult(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(t.TempDir(), "empty.ndjson")
+	rows := exportTestRows()
+	rows.rows = nil
+
+	stats, err := Export(context.Background(), &ExportConfig{Path: path, Format: "json", ChunksizeInMB: 128}, rows, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(stats.Rows, qt.Equals, uint64(0))
+	c.Assert(stats.Files, qt.DeepEquals, []string{path})
+
+	data, err := os.ReadFile(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(data, qt.HasLen, 0)
+}
+
+func TestExportSQLNeedsTable(t *testing.T) {
+	c := qt.New(t)
+
+	_, err := Export(context.Background(), &ExportConfig{Path: filepath.Join(t.TempDir(), "x.sql"), Format: "sql"}, exportTestRows(), nil)
+	c.Assert(err, qt.ErrorMatches, "sql exports need a target table name")
+}
+
+func TestExportFormat(t *testing.T) {
+	c := qt.New(t)
+
+	for path, want := range map[string]string{
+		"out.csv":     "csv",
+		"out.NDJSON":  "json",
+		"out.jsonl":   "json",
+		"out.parquet": "parquet",
+		"dir/out.sql": "sql",
+	} {
+		got, err := ExportFormat(path)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.Equals, want, qt.Commentf("path %s", path))
+	}
+
+	_, err := ExportFormat("out.xlsx")
+	c.Assert(err, qt.ErrorMatches, `can't infer the format of "out.xlsx".*`)
+}
+
+func TestPostgresValue(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		typ  querypb.Type
+		raw  string
+		want string
+	}{
+		{querypb.Type_INT8, "t", "1"},
+		{querypb.Type_INT8, "f", "0"},
+		{querypb.Type_TIMESTAMP, "2024-03-01 14:30:00.5+02", "2024-03-01 12:30:00.5"},
+		{querypb.Type_TIMESTAMP, "2024-03-01 12:30:00+05:30", "2024-03-01 07:00:00"},
+		{querypb.Type_DATETIME, "2024-03-01 12:30:00", "2024-03-01 12:30:00"},
+		{querypb.Type_TIMESTAMP, "infinity", "infinity"},
+		{querypb.Type_VARBINARY, `\x6869`, "hi"},
+		{querypb.Type_DECIMAL, "10.50", "10.50"},
+	}
+	for _, tt := range tests {
+		got, err := postgresValue(tt.typ, []byte(tt.raw))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(got), qt.Equals, tt.want, qt.Commentf("%s %q", tt.typ, tt.raw))
+	}
+
+	_, err := postgresValue(querypb.Type_VARBINARY, []byte(`\001`))
+	c.Assert(err, qt.ErrorMatches, "unexpected bytea format.*")
+}
@@ -2,7 +2,6 @@ package dumper
 
 import (
 	"encoding/json"
-	"fmt"
 	"strings"
 
 	"github.com/xelabs/go-mysqlstack/sqlparser/depends/sqltypes"
@@ -55,7 +54,7 @@ func (w *jsonWriter) ShouldFlush() bool {
 }
 
 func (w *jsonWriter) Flush(outdir, database, table string, fileNo int) error {
-	file := fmt.Sprintf("%s/%s.%s.%05d.json", outdir, database, table, fileNo)
+	file := w.cfg.dataFilePath(outdir, database, table, fileNo, "json")
 	err := writeFile(file, strings.Join(w.jsonLines, ""))
 	if err != nil {
 		return err
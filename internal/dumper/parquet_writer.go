@@ -0,0 +1,268 @@
+package dumper
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	querypb "github.com/xelabs/go-mysqlstack/sqlparser/depends/query"
+	"github.com/xelabs/go-mysqlstack/sqlparser/depends/sqltypes"
+)
+
+// mysqlTimeLayout parses DATETIME and TIMESTAMP values in MySQL's text
+// protocol format, with or without fractional seconds.
+const mysqlTimeLayout = "2006-01-02 15:04:05.999999"
+
+// TypedTableWriter is a TableWriter that needs the column types up front,
+// e.g. to build a file schema. Writers that implement it are initialized with
+// InitializeFields instead of Initialize.
+type TypedTableWriter interface {
+	TableWriter
+	InitializeFields(fields []*querypb.Field) error
+}
+
+// parquetColumn maps one result column to a leaf of the parquet schema.
+type parquetColumn struct {
+	index int
+	kind  parquetKind
+}
+
+type parquetKind int
+
+const (
+	parquetString parquetKind = iota
+	parquetBytes
+	parquetInt
+	parquetUint
+	parquetDouble
+	parquetDate
+	parquetTimestamp
+	parquetJSON
+)
+
+type parquetWriter struct {
+	cfg     *Config
+	schema  *parquet.Schema
+	columns []parquetColumn
+	buf     bytes.Buffer
+	writer  *parquet.Writer
+	rows    int
+	// written is set once a file was flushed, so Close only writes an empty
+	// file when the whole result was empty.
+	written    bool
+	chunkbytes int
+	// zeroDates counts the zero dates written as NULL.
+	zeroDates int
+}
+
+func newParquetWriter(cfg *Config) *parquetWriter {
+	return &parquetWriter{cfg: cfg}
+}
+
+func (w *parquetWriter) Initialize(fieldNames []string) error {
+	fields := make([]*querypb.Field, len(fieldNames))
+	for i, name := range fieldNames {
+		fields[i] = &querypb.Field{Name: name, Type: querypb.Type_VARCHAR}
+	}
+	return w.InitializeFields(fields)
+}
+
+// InitializeFields builds a schema with one optional column per field, in the
+// order of the fields. Column names must be unique in parquet, so duplicates
+// (e.g. "id" from two joined tables) get a numeric suffix.
+func (w *parquetWriter) InitializeFields(fields []*querypb.Field) error {
+	group := parquet.Group{}
+	names := make([]string, len(fields))
+	kinds := make([]parquetKind, len(fields))
+	for i, f := range fields {
+		name := f.Name
+		for n := 2; ; n++ {
+			if _, ok := group[name]; !ok {
+				break
+			}
+			name = fmt.Sprintf("%s_%d", f.Name, n)
+		}
+
+		node, kind := parquetNode(f.Type)
+		group[name] = parquet.Optional(node)
+		names[i] = name
+		kinds[i] = kind
+	}
+
+	w.schema = parquet.NewSchema("row", orderedGroup{Group: group, names: names})
+	w.columns = make([]parquetColumn, len(fields))
+	for i, name := range names {
+		leaf, ok := w.schema.Lookup(name)
+		if !ok {
+			return fmt.Errorf("parquet schema is missing column %q", name)
+		}
+		w.columns[i] = parquetColumn{index: leaf.ColumnIndex, kind: kinds[i]}
+	}
+
+	w.reset()
+	return nil
+}
+
+// orderedGroup is a parquet group whose fields keep the order of names,
+// where parquet.Group sorts them by name.
+type orderedGroup struct {
+	parquet.Group
+	names []string
+}
+
+func (g orderedGroup) Fields() []parquet.Field {
+	fields := make([]parquet.Field, len(g.names))
+	for i, name := range g.names {
+		fields[i] = &orderedField{Node: g.Group[name], name: name}
+	}
+	return fields
+}
+
+type orderedField struct {
+	parquet.Node
+	name string
+}
+
+func (f *orderedField) Name() string { return f.name }
+
+func (f *orderedField) Value(base reflect.Value) reflect.Value {
+	return base.MapIndex(reflect.ValueOf(f.name))
+}
+
+func (w *parquetWriter) reset() {
+	w.buf.Reset()
+	w.writer = parquet.NewWriter(&w.buf, w.schema)
+	w.rows = 0
+	w.chunkbytes = 0
+}
+
+func (w *parquetWriter) WriteRow(row []sqltypes.Value) (int, error) {
+	out := make(parquet.Row, len(row))
+	size := 0
+	for i, v := range row {
+		col := w.columns[i]
+		pv, err := w.value(col.kind, v)
+		if err != nil {
+			return 0, err
+		}
+		if pv.IsNull() {
+			out[col.index] = pv.Level(0, 0, col.index)
+		} else {
+			out[col.index] = pv.Level(0, 1, col.index)
+		}
+		size += len(v.Raw())
+	}
+
+	if _, err := w.writer.WriteRows([]parquet.Row{out}); err != nil {
+		return 0, err
+	}
+	w.rows++
+	w.chunkbytes += size
+	return size, nil
+}
+
+func (w *parquetWriter) ShouldFlush() bool {
+	return (w.chunkbytes / 1024 / 1024) >= w.cfg.ChunksizeInMB
+}
+
+func (w *parquetWriter) Flush(outdir, database, table string, fileNo int) error {
+	if err := w.writer.Close(); err != nil {
+		return err
+	}
+
+	file := w.cfg.dataFilePath(outdir, database, table, fileNo, "parquet")
+	if err := writeFile(file, w.buf.String()); err != nil {
+		return err
+	}
+
+	w.written = true
+	w.reset()
+	return nil
+}
+
+func (w *parquetWriter) Close(outdir, database, table string, fileNo int) error {
+	if w.rows > 0 || !w.written {
+		return w.Flush(outdir, database, table, fileNo)
+	}
+	return nil
+}
+
+// parquetNode picks the parquet type for a MySQL column type. Types without
+// a lossless parquet equivalent (DECIMAL, TIME, ENUM, ...) are written as
+// strings.
+func parquetNode(typ querypb.Type) (parquet.Node, parquetKind) {
+	switch typ {
+	case querypb.Type_INT8, querypb.Type_INT16, querypb.Type_INT24, querypb.Type_INT32, querypb.Type_INT64, querypb.Type_YEAR:
+		return parquet.Int(64), parquetInt
+	case querypb.Type_UINT8, querypb.Type_UINT16, querypb.Type_UINT24, querypb.Type_UINT32, querypb.Type_UINT64:
+		return parquet.Uint(64), parquetUint
+	case querypb.Type_FLOAT32, querypb.Type_FLOAT64:
+		return parquet.Leaf(parquet.DoubleType), parquetDouble
+	case querypb.Type_DATE:
+		return parquet.Date(), parquetDate
+	case querypb.Type_DATETIME, querypb.Type_TIMESTAMP:
+		return parquet.Timestamp(parquet.Microsecond), parquetTimestamp
+	case querypb.Type_JSON:
+		return parquet.JSON(), parquetJSON
+	case querypb.Type_BLOB, querypb.Type_BINARY, querypb.Type_VARBINARY, querypb.Type_BIT, querypb.Type_GEOMETRY:
+		return parquet.Leaf(parquet.ByteArrayType), parquetBytes
+	default:
+		return parquet.String(), parquetString
+	}
+}
+
+func (w *parquetWriter) value(kind parquetKind, v sqltypes.Value) (parquet.Value, error) {
+	raw := v.Raw()
+	if raw == nil {
+		return parquet.NullValue(), nil
+	}
+
+	switch kind {
+	case parquetInt:
+		i, err := strconv.ParseInt(string(raw), 10, 64)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		return parquet.Int64Value(i), nil
+	case parquetUint:
+		u, err := strconv.ParseUint(string(raw), 10, 64)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		return parquet.Int64Value(int64(u)), nil
+	case parquetDouble:
+		f, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		return parquet.DoubleValue(f), nil
+	case parquetDate:
+		t, err := time.Parse(time.DateOnly, string(raw))
+		if err != nil {
+			return w.zeroDate(raw, err)
+		}
+		return parquet.Int32Value(int32(t.Unix() / 86400)), nil
+	case parquetTimestamp:
+		t, err := time.Parse(mysqlTimeLayout, string(raw))
+		if err != nil {
+			return w.zeroDate(raw, err)
+		}
+		return parquet.Int64Value(t.UnixMicro()), nil
+	default:
+		return parquet.ByteArrayValue(raw), nil
+	}
+}
+
+// zeroDate returns NULL for MySQL zero dates such as 0000-00-00 or
+// 2024-00-00, which have no parquet representation, and counts them. Other
+// values that fail to parse are errors.
+func (w *parquetWriter) zeroDate(raw []byte, err error) (parquet.Value, error) {
+	if len(raw) < 10 || (string(raw[:4]) != "0000" && string(raw[5:7]) != "00" && string(raw[8:10]) != "00") {
+		return parquet.Value{}, fmt.Errorf("invalid date %q: %w", raw, err)
+	}
+	w.zeroDates++
+	return parquet.NullValue(), nil
+}
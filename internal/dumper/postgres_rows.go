@@ -0,0 +1,128 @@
+package dumper
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	querypb "github.com/xelabs/go-mysqlstack/sqlparser/depends/query"
+	"github.com/xelabs/go-mysqlstack/sqlparser/depends/sqltypes"
+)
+
+// postgresTimeLayouts parse timestamptz and timestamp values in PostgreSQL's
+// text format with the default ISO DateStyle.
+var postgresTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999Z07:00:00",
+	"2006-01-02 15:04:05.999999Z07:00",
+	"2006-01-02 15:04:05.999999Z07",
+	mysqlTimeLayout,
+}
+
+// postgresRows adapts pgx rows to the MySQL-typed values the writers expect.
+// The query must run with the simple protocol, so every value arrives in
+// PostgreSQL's text format.
+type postgresRows struct {
+	rows   pgx.Rows
+	fields []*querypb.Field
+}
+
+// NewPostgresRows wraps rows from a query run with
+// pgx.QueryExecModeSimpleProtocol. Column types are mapped to their closest
+// MySQL equivalent; timestamps are normalized to UTC and booleans to 1/0.
+func NewPostgresRows(rows pgx.Rows) Rows {
+	descs := rows.FieldDescriptions()
+	fields := make([]*querypb.Field, len(descs))
+	for i, d := range descs {
+		fields[i] = &querypb.Field{Name: d.Name, Type: postgresFieldType(d.DataTypeOID)}
+	}
+	return &postgresRows{rows: rows, fields: fields}
+}
+
+func (r *postgresRows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r *postgresRows) Fields() []*querypb.Field {
+	return r.fields
+}
+
+func (r *postgresRows) LastError() error {
+	return r.rows.Err()
+}
+
+func (r *postgresRows) RowValues() ([]sqltypes.Value, error) {
+	raw := r.rows.RawValues()
+	values := make([]sqltypes.Value, len(raw))
+	for i, v := range raw {
+		if v == nil {
+			values[i] = sqltypes.NULL
+			continue
+		}
+
+		val, err := postgresValue(r.fields[i].Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", r.fields[i].Name, err)
+		}
+		// pgx reuses the buffer behind RawValues on the next row.
+		values[i] = sqltypes.MakeTrusted(r.fields[i].Type, val)
+	}
+	return values, nil
+}
+
+func postgresFieldType(oid uint32) querypb.Type {
+	switch oid {
+	case pgtype.Int2OID, pgtype.Int4OID, pgtype.Int8OID:
+		return querypb.Type_INT64
+	case pgtype.OIDOID:
+		return querypb.Type_UINT32
+	case pgtype.Float4OID, pgtype.Float8OID:
+		return querypb.Type_FLOAT64
+	case pgtype.NumericOID:
+		return querypb.Type_DECIMAL
+	case pgtype.BoolOID:
+		return querypb.Type_INT8
+	case pgtype.DateOID:
+		return querypb.Type_DATE
+	case pgtype.TimestampOID:
+		return querypb.Type_DATETIME
+	case pgtype.TimestamptzOID:
+		return querypb.Type_TIMESTAMP
+	case pgtype.JSONOID, pgtype.JSONBOID:
+		return querypb.Type_JSON
+	case pgtype.ByteaOID:
+		return querypb.Type_VARBINARY
+	default:
+		return querypb.Type_VARCHAR
+	}
+}
+
+// postgresValue converts a text-format value to the MySQL text protocol
+// representation of typ. The result never aliases raw.
+func postgresValue(typ querypb.Type, raw []byte) ([]byte, error) {
+	s := string(raw)
+	switch typ {
+	case querypb.Type_INT8:
+		if s == "t" {
+			return []byte("1"), nil
+		}
+		return []byte("0"), nil
+	case querypb.Type_DATETIME, querypb.Type_TIMESTAMP:
+		for _, layout := range postgresTimeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return []byte(t.UTC().Format(mysqlTimeLayout)), nil
+			}
+		}
+		// infinity, BC dates, ... are kept as PostgreSQL wrote them.
+		return []byte(s), nil
+	case querypb.Type_VARBINARY:
+		if !strings.HasPrefix(s, `\x`) {
+			return nil, fmt.Errorf("unexpected bytea format; set bytea_output to hex")
+		}
+		return hex.DecodeString(s[2:])
+	default:
+		return []byte(s), nil
+	}
+}
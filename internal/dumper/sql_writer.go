@@ -73,7 +73,7 @@ func (w *sqlWriter) ShouldFlush() bool {
 
 func (w *sqlWriter) Flush(outdir, database, table string, fileNo int) error {
 	query := strings.Join(w.inserts, ";\n") + ";\n"
-	file := fmt.Sprintf("%s/%s.%s.%05d.sql", outdir, database, table, fileNo)
+	file := w.cfg.dataFilePath(outdir, database, table, fileNo, "sql")
 	err := writeFile(file, query)
 	if err != nil {
 		return err
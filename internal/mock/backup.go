@@ -13,7 +13,7 @@ type BackupsService struct {
 	GetFn        func(context.Context, *ps.GetBackupRequest) (*ps.Backup, error)
 	GetFnInvoked bool
 
-	ListFn        func(context.Context, *ps.ListBackupsRequest) ([]*ps.Backup, error)
+	ListFn        func(context.Context, *ps.ListBackupsRequest, ...ps.ListOption) ([]*ps.Backup, error)
 	ListFnInvoked bool
 
 	DeleteFn        func(context.Context, *ps.DeleteBackupRequest) error
@@ -25,9 +25,9 @@ func (b *BackupsService) Create(ctx context.Context, req *ps.CreateBackupRequest
 	return b.CreateFn(ctx, req)
 }
 
-func (b *BackupsService) List(ctx context.Context, req *ps.ListBackupsRequest) ([]*ps.Backup, error) {
+func (b *BackupsService) List(ctx context.Context, req *ps.ListBackupsRequest, opts ...ps.ListOption) ([]*ps.Backup, error) {
 	b.ListFnInvoked = true
-	return b.ListFn(ctx, req)
+	return b.ListFn(ctx, req, opts...)
 }
 
 func (b *BackupsService) Get(ctx context.Context, req *ps.GetBackupRequest) (*ps.Backup, error) {
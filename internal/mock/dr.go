@@ -34,7 +34,7 @@ type DeployRequestsService struct {
 	GetFn        func(context.Context, *ps.GetDeployRequestRequest) (*ps.DeployRequest, error)
 	GetFnInvoked bool
 
-	ListFn        func(context.Context, *ps.ListDeployRequestsRequest) ([]*ps.DeployRequest, error)
+	ListFn        func(context.Context, *ps.ListDeployRequestsRequest, ...ps.ListOption) ([]*ps.DeployRequest, error)
 	ListFnInvoked bool
 
 	RevertDeployFn        func(context.Context, *ps.RevertDeployRequestRequest) (*ps.DeployRequest, error)
@@ -95,9 +95,9 @@ func (d *DeployRequestsService) Get(ctx context.Context, req *ps.GetDeployReques
 	return d.GetFn(ctx, req)
 }
 
-func (d *DeployRequestsService) List(ctx context.Context, req *ps.ListDeployRequestsRequest) ([]*ps.DeployRequest, error) {
+func (d *DeployRequestsService) List(ctx context.Context, req *ps.ListDeployRequestsRequest, opts ...ps.ListOption) ([]*ps.DeployRequest, error) {
 	d.ListFnInvoked = true
-	return d.ListFn(ctx, req)
+	return d.ListFn(ctx, req, opts...)
 }
 
 func (d *DeployRequestsService) RevertDeploy(ctx context.Context, req *ps.RevertDeployRequestRequest) (*ps.DeployRequest, error) {
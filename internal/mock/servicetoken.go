@@ -10,7 +10,7 @@ type ServiceTokenService struct {
 	CreateFn        func(context.Context, *ps.CreateServiceTokenRequest) (*ps.ServiceToken, error)
 	CreateFnInvoked bool
 
-	ListFn        func(context.Context, *ps.ListServiceTokensRequest) ([]*ps.ServiceToken, error)
+	ListFn        func(context.Context, *ps.ListServiceTokensRequest, ...ps.ListOption) ([]*ps.ServiceToken, error)
 	ListFnInvoked bool
 
 	DeleteFn        func(context.Context, *ps.DeleteServiceTokenRequest) error
@@ -34,9 +34,9 @@ func (s *ServiceTokenService) Create(ctx context.Context, req *ps.CreateServiceT
 	return s.CreateFn(ctx, req)
 }
 
-func (s *ServiceTokenService) List(ctx context.Context, req *ps.ListServiceTokensRequest) ([]*ps.ServiceToken, error) {
+func (s *ServiceTokenService) List(ctx context.Context, req *ps.ListServiceTokensRequest, opts ...ps.ListOption) ([]*ps.ServiceToken, error) {
 	s.ListFnInvoked = true
-	return s.ListFn(ctx, req)
+	return s.ListFn(ctx, req, opts...)
 }
 
 func (s *ServiceTokenService) Delete(ctx context.Context, req *ps.DeleteServiceTokenRequest) error {
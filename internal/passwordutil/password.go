@@ -29,8 +29,10 @@ type Options struct {
 
 type Password struct {
 	Password *ps.DatabaseBranchPassword
-	cleanup  func(context.Context) error
-	renew    func(context.Context) error
+	// OnRenew, if set, is called with the outcome of every renewal attempt.
+	OnRenew func(error)
+	cleanup func(context.Context) error
+	renew   func(context.Context) error
 }
 
 func (p *Password) Cleanup(ctx context.Context) error {
@@ -58,7 +60,11 @@ func (p *Password) Renew(ctx context.Context) error {
 		case <-timer.C:
 		}
 
-		if err := p.renew(ctx); err != nil {
+		err := p.renew(ctx)
+		if p.OnRenew != nil {
+			p.OnRenew(err)
+		}
+		if err != nil {
 			switch cmdutil.ErrCode(err) {
 			case ps.ErrNotFound, ps.ErrRetry:
 				// either of these indicate there's no ability to retry
@@ -60,7 +60,7 @@ type DeleteBackupRequest struct {
 // backup API endpoint.
 type BackupsService interface {
 	Create(context.Context, *CreateBackupRequest) (*Backup, error)
-	List(context.Context, *ListBackupsRequest) ([]*Backup, error)
+	List(context.Context, *ListBackupsRequest, ...ListOption) ([]*Backup, error)
 	Get(context.Context, *GetBackupRequest) (*Backup, error)
 	Delete(context.Context, *DeleteBackupRequest) error
 }
@@ -110,8 +110,9 @@ func (d *backupsService) Get(ctx context.Context, getReq *GetBackupRequest) (*Ba
 }
 
 // Returns all of the backups for a branch.
-func (d *backupsService) List(ctx context.Context, listReq *ListBackupsRequest) ([]*Backup, error) {
-	req, err := d.client.newRequest(http.MethodGet, backupsAPIPath(listReq.Organization, listReq.Database, listReq.Branch), nil)
+func (d *backupsService) List(ctx context.Context, listReq *ListBackupsRequest, opts ...ListOption) ([]*Backup, error) {
+	listOpts := defaultListOptions(opts...)
+	req, err := d.client.newRequest(http.MethodGet, backupsAPIPath(listReq.Organization, listReq.Database, listReq.Branch), nil, WithQueryParams(*listOpts.URLValues))
 	if err != nil {
 		return nil, fmt.Errorf("error creating http request: %w", err)
 	}
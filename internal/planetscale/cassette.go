@@ -0,0 +1,397 @@
+package planetscale
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// CassetteEnv names the environment variable with the path of the
+	// cassette file new clients record to or replay from.
+	CassetteEnv = "PSCALE_HTTP_CASSETTE"
+
+	// CassetteModeEnv names the environment variable with the CassetteMode,
+	// replay if it's not set.
+	CassetteModeEnv = "PSCALE_HTTP_CASSETTE_MODE"
+
+	redacted = "REDACTED"
+)
+
+// CassetteMode selects whether a cassette records or replays requests.
+type CassetteMode string
+
+const (
+	// CassetteRecord sends requests to the API and adds them with their
+	// responses to the cassette. Runs of pscale append to the same cassette,
+	// remove it to record again.
+	CassetteRecord CassetteMode = "record"
+
+	// CassetteReplay answers requests with the responses saved in the
+	// cassette, without sending them. A request that wasn't recorded fails.
+	CassetteReplay CassetteMode = "replay"
+)
+
+// secretHeaders are the headers whose values aren't saved in cassettes.
+var secretHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// secretFields are the JSON fields whose values aren't saved in cassettes,
+// such as the token of a new service token, the password of a new branch
+// password, or the private key of an import source.
+var secretFields = map[string]bool{
+	"access_token":  true,
+	"client_secret": true,
+	"download_url":  true,
+	"password":      true,
+	"plain_text":    true,
+	"private_key":   true,
+	"refresh_token": true,
+	"secret":        true,
+	"ssl_key":       true,
+	"token":         true,
+}
+
+// signatureParams are query parameters that make a URL a credential of its
+// own, like the presigned URLs reports are downloaded from.
+var signatureParams = []string{"x-amz-signature", "x-amz-credential", "x-goog-signature", "signature", "sig"}
+
+// Interaction is a request and the response to it saved in a cassette.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request saved in a cassette.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response saved in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// cassette is the content of a cassette file.
+type cassette struct {
+	path string
+	mode CassetteMode
+
+	mu           sync.Mutex
+	Interactions []*Interaction `json:"interactions"`
+	used         []bool
+}
+
+var (
+	cassettesMu sync.Mutex
+	cassettes   = map[string]*cassette{}
+)
+
+// openCassette returns the cassette at path. Clients of the same process
+// share it, so recording from several clients doesn't lose requests and
+// replaying doesn't answer a request twice.
+func openCassette(path string, mode CassetteMode) (*cassette, error) {
+	switch mode {
+	case CassetteRecord, CassetteReplay:
+	default:
+		return nil, fmt.Errorf("invalid cassette mode %q, must be %q or %q", mode, CassetteRecord, CassetteReplay)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	if c, ok := cassettes[path]; ok {
+		if c.mode != mode {
+			return nil, fmt.Errorf("cassette %s is already open in %s mode", path, c.mode)
+		}
+		return c, nil
+	}
+
+	c := &cassette{path: path, mode: mode}
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err) && mode == CassetteRecord:
+	case err != nil:
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	default:
+		if err := json.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("can't unmarshal cassette %s: %w", path, err)
+		}
+	}
+	c.used = make([]bool, len(c.Interactions))
+
+	cassettes[path] = c
+	return c, nil
+}
+
+// WithCassette records the requests of the client to the cassette file at
+// path, or replays them from it, depending on mode. By default, clients use
+// the cassette named by the PSCALE_HTTP_CASSETTE environment variable.
+func WithCassette(path string, mode CassetteMode) ClientOption {
+	return func(c *Client) error {
+		cas, err := openCassette(path, mode)
+		if err != nil {
+			return err
+		}
+
+		rt := c.client.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		c.client.Transport = &cassetteTransport{rt: rt, cassette: cas}
+		c.hasCassette = true
+		return nil
+	}
+}
+
+// cassetteFromEnv returns the option selecting the cassette of the
+// environment, or nil.
+func cassetteFromEnv() ClientOption {
+	path := os.Getenv(CassetteEnv)
+	if path == "" {
+		return nil
+	}
+
+	mode := CassetteMode(os.Getenv(CassetteModeEnv))
+	if mode == "" {
+		mode = CassetteReplay
+	}
+	return WithCassette(path, mode)
+}
+
+// cassetteTransport records requests sent with rt to a cassette, or answers
+// them from it.
+type cassetteTransport struct {
+	rt       http.RoundTripper
+	cassette *cassette
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: redactHeader(req.Header),
+		Body:   redactBody(body),
+	}
+
+	if t.cassette.mode == CassetteReplay {
+		i, err := t.cassette.find(&recorded)
+		if err != nil {
+			return nil, err
+		}
+		return i.Response.toResponse(req), nil
+	}
+
+	res, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	err = t.cassette.record(&Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     redactHeader(res.Header),
+			Body:       redactBody(resBody),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// find returns the first interaction that wasn't replayed yet matching req.
+// Requests match if they have the same method, path, query and body.
+func (c *cassette) find(req *RecordedRequest) (*Interaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for n, i := range c.Interactions {
+		if !c.used[n] && sameRequest(&i.Request, req) {
+			c.used[n] = true
+			return i, nil
+		}
+	}
+	return nil, fmt.Errorf("cassette %s has no response left for %s %s", c.path, req.Method, req.URL)
+}
+
+// record adds i to the cassette and saves it. The cassette is saved after
+// every request, so it's complete even if the process exits abruptly.
+func (c *cassette) record(i *Interaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Interactions = append(c.Interactions, i)
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+	// Cassettes hold organization data even once credentials are redacted.
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	// WriteFile keeps the mode of a cassette that already existed.
+	if err := os.Chmod(c.path, 0o600); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return nil
+}
+
+func sameRequest(a, b *RecordedRequest) bool {
+	if a.Method != b.Method || a.Body != b.Body {
+		return false
+	}
+	ua, errA := url.Parse(a.URL)
+	ub, errB := url.Parse(b.URL)
+	if errA != nil || errB != nil {
+		return false
+	}
+	// The query is compared once encoded again, which sorts it.
+	return ua.Path == ub.Path && ua.Query().Encode() == ub.Query().Encode()
+}
+
+func (r *RecordedResponse) toResponse(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// readBody returns the body of req and replaces it so it can be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for _, name := range secretHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, redacted)
+		}
+	}
+	// Redirects to downloads point at presigned URLs.
+	if presignedURL(out.Get("Location")) {
+		out.Set("Location", redacted)
+	}
+	return out
+}
+
+// redactBody returns body with the values of secret JSON fields replaced.
+// Bodies that aren't JSON are kept as is.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return string(body)
+	}
+	if !redactValue(v) {
+		return string(body)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return string(body)
+	}
+	return buf.String()
+}
+
+// redactValue replaces the secret fields in v and reports whether there were
+// any.
+func redactValue(v interface{}) bool {
+	found := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if s, ok := field.(string); ok && (secretFields[k] || presignedURL(s)) {
+				v[k] = redacted
+				found = true
+				continue
+			}
+			found = redactValue(field) || found
+		}
+	case []interface{}:
+		for _, item := range v {
+			found = redactValue(item) || found
+		}
+	}
+	return found
+}
+
+// presignedURL reports whether s is a URL signed in its query string.
+func presignedURL(s string) bool {
+	if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	for param := range u.Query() {
+		if slices.Contains(signatureParams, strings.ToLower(param)) {
+			return true
+		}
+	}
+	return false
+}
@@ -49,6 +49,13 @@ type Client struct {
 	// base URL for the API
 	baseURL *url.URL
 
+	// retry is the policy for retrying requests that failed transiently
+	retry RetryPolicy
+
+	// hasCassette is set if requests are recorded to or replayed from a
+	// cassette
+	hasCassette bool
+
 	AuditLogs             AuditLogsService
 	Backups               BackupsService
 	BranchInfrastructure  BranchInfrastructureService
@@ -303,6 +310,7 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		baseURL:   baseURL,
 		UserAgent: defaultUserAgent(),
 		headers:   make(map[string]string, 0),
+		retry:     DefaultRetryPolicy,
 	}
 
 	for _, opt := range opts {
@@ -312,6 +320,12 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		}
 	}
 
+	if opt := cassetteFromEnv(); opt != nil && !c.hasCassette {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
 	// Access and service tokens are attached by RoundTrippers on every hop.
 	// net/http only strips Authorization from Request.Header on cross-host
 	// redirects, so without this policy a 3xx Location to another host would
@@ -355,13 +369,41 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 // do makes an HTTP request and populates the given struct v from the response.
 func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) error {
 	req = req.WithContext(ctx)
-	res, err := c.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
+	for attempt := 1; ; attempt++ {
+		res, err := c.client.Do(req)
+
+		reason := retryReason(req, res, err)
+		if reason == "" || attempt >= c.retry.MaxAttempts {
+			if err != nil {
+				return err
+			}
+			defer res.Body.Close()
 
-	return c.handleResponse(ctx, res, v)
+			return c.handleResponse(ctx, res, v)
+		}
+
+		wait, ok := c.retry.retryWait(attempt, res)
+		if !ok {
+			defer res.Body.Close()
+
+			return c.handleResponse(ctx, res, v)
+		}
+		if res != nil {
+			// Drain the body so the connection can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+		}
+
+		if c.retry.OnRetry != nil {
+			c.retry.OnRetry(req, attempt, wait, reason)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+		if err := rewindBody(req); err != nil {
+			return err
+		}
+	}
 }
 
 // handleResponse makes an HTTP request and populates the given struct v from
@@ -470,6 +512,8 @@ func (c *Client) handleResponse(ctx context.Context, res *http.Response, v inter
 		}
 	}
 
+	recordPage(ctx, out)
+
 	// this means we don't care about unmarshaling the response body into v
 	if v == nil || res.StatusCode == http.StatusNoContent {
 		return nil
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"net/url"
 	"path"
 	"time"
 )
@@ -27,7 +26,7 @@ type DeployRequestsService interface {
 	Deploy(context.Context, *PerformDeployRequest) (*DeployRequest, error)
 	Diff(ctx context.Context, diffReq *DiffRequest) ([]*Diff, error)
 	Get(context.Context, *GetDeployRequestRequest) (*DeployRequest, error)
-	List(context.Context, *ListDeployRequestsRequest) ([]*DeployRequest, error)
+	List(context.Context, *ListDeployRequestsRequest, ...ListOption) ([]*DeployRequest, error)
 	GetDeployOperations(context.Context, *GetDeployOperationsRequest) ([]*DeployOperation, error)
 	SkipRevertDeploy(context.Context, *SkipRevertDeployRequestRequest) (*DeployRequest, error)
 	RevertDeploy(context.Context, *RevertDeployRequestRequest) (*DeployRequest, error)
@@ -455,10 +454,10 @@ func (d *deployRequestsService) Diff(ctx context.Context, diffReq *DiffRequest)
 	return diffs.Diffs, nil
 }
 
-func (d *deployRequestsService) List(ctx context.Context, listReq *ListDeployRequestsRequest) ([]*DeployRequest, error) {
+func (d *deployRequestsService) List(ctx context.Context, listReq *ListDeployRequestsRequest, opts ...ListOption) ([]*DeployRequest, error) {
 	baseURL := deployRequestsAPIPath(listReq.Organization, listReq.Database)
 
-	queryParams := url.Values{}
+	queryParams := *defaultListOptions(opts...).URLValues
 	if listReq.State != "" {
 		queryParams.Set("state", listReq.State)
 	}
@@ -0,0 +1,101 @@
+package planetscale
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+)
+
+// MaxPerPage is the largest page size the API returns.
+const MaxPerPage = 100
+
+// PageFunc lists one page of resources, selected with the given options.
+type PageFunc[T any] func(ctx context.Context, opts ...ListOption) ([]T, error)
+
+// Pages returns an iterator over the pages list returns, starting at the
+// first one. perPage is clamped to MaxPerPage. Like pscale api --paginate,
+// it follows the next_page of each response and stops once it is null.
+// Responses without next_page, such as those of fakes in tests, stop after a
+// page that isn't full. Iteration also stops after the first error.
+//
+//	pages := planetscale.Pages(ctx, 100, func(ctx context.Context, opts ...planetscale.ListOption) ([]*planetscale.Database, error) {
+//		return client.Databases.List(ctx, req, opts...)
+//	})
+//	for dbs, err := range pages {
+//		...
+//	}
+func Pages[T any](ctx context.Context, perPage int, list PageFunc[T]) iter.Seq2[[]T, error] {
+	if perPage <= 0 || perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+
+	return func(yield func([]T, error) bool) {
+		for page := 1; ; {
+			info := &pageInfo{}
+			items, err := list(context.WithValue(ctx, pageInfoKey{}, info), WithPage(page), WithPerPage(perPage))
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(items) > 0 && !yield(items, nil) {
+				return
+			}
+
+			switch {
+			case info.nextPage == nil && info.paginated:
+				return
+			case info.nextPage != nil && *info.nextPage > page:
+				page = *info.nextPage
+			case len(items) < perPage:
+				return
+			default:
+				page++
+			}
+		}
+	}
+}
+
+// pageInfo is the pagination of a list response, recorded by the client for
+// Pages.
+type pageInfo struct {
+	// paginated reports whether the response had a next_page field.
+	paginated bool
+	nextPage  *int
+}
+
+type pageInfoKey struct{}
+
+// recordPage stores the pagination of the response body out in the
+// pageInfo of ctx, if Pages set one.
+func recordPage(ctx context.Context, out []byte) {
+	info, ok := ctx.Value(pageInfoKey{}).(*pageInfo)
+	if !ok {
+		return
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(out, &fields) != nil {
+		return
+	}
+	if _, info.paginated = fields["next_page"]; info.paginated {
+		json.Unmarshal(fields["next_page"], &info.nextPage) // nolint:errcheck
+	}
+}
+
+// All returns an iterator over every resource list returns, fetching the
+// pages as needed, see Pages.
+func All[T any](ctx context.Context, perPage int, list PageFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for items, err := range Pages(ctx, perPage, list) {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package planetscale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakePages lists n items, numbered from 0, a page at a time.
+func fakePages(c *qt.C, n int, requested *[]int) PageFunc[int] {
+	return func(ctx context.Context, opts ...ListOption) ([]int, error) {
+		listOpts := defaultListOptions(opts...)
+		page, err := strconv.Atoi(listOpts.URLValues.Get("page"))
+		c.Assert(err, qt.IsNil)
+		perPage, err := strconv.Atoi(listOpts.URLValues.Get("per_page"))
+		c.Assert(err, qt.IsNil)
+		*requested = append(*requested, page)
+
+		var items []int
+		for i := (page - 1) * perPage; i < min(page*perPage, n); i++ {
+			items = append(items, i)
+		}
+		return items, nil
+	}
+}
+
+func TestPages(t *testing.T) {
+	tests := []struct {
+		desc          string
+		items         int
+		perPage       int
+		wantPages     [][]int
+		wantRequested []int
+	}{
+		{
+			desc:          "stops after a page that isn't full",
+			items:         5,
+			perPage:       2,
+			wantPages:     [][]int{{0, 1}, {2, 3}, {4}},
+			wantRequested: []int{1, 2, 3},
+		},
+		{
+			desc:          "stops after an empty page",
+			items:         4,
+			perPage:       2,
+			wantPages:     [][]int{{0, 1}, {2, 3}},
+			wantRequested: []int{1, 2, 3},
+		},
+		{
+			desc:          "yields nothing for an empty list",
+			items:         0,
+			perPage:       2,
+			wantRequested: []int{1},
+		},
+		{
+			desc:          "clamps the page size",
+			items:         150,
+			perPage:       500,
+			wantRequested: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := qt.New(t)
+
+			var requested []int
+			var pages [][]int
+			for page, err := range Pages(context.Background(), tt.perPage, fakePages(c, tt.items, &requested)) {
+				c.Assert(err, qt.IsNil)
+				pages = append(pages, page)
+			}
+
+			if tt.wantPages != nil {
+				c.Assert(pages, qt.DeepEquals, tt.wantPages)
+			}
+			c.Assert(requested, qt.DeepEquals, tt.wantRequested)
+		})
+	}
+}
+
+func TestPagesFollowsNextPage(t *testing.T) {
+	c := qt.New(t)
+
+	// The API caps the page size below per_page, so every page is short,
+	// and next_page tells whether there are more.
+	var requested []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		requested = append(requested, page)
+		next := map[string]string{"1": "2", "2": "3", "3": "null"}[page]
+		fmt.Fprintf(w, `{"type":"list","current_page":%s,"next_page":%s,"data":[{"name":"db%s"}]}`, page, next, page)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithBaseURL(ts.URL))
+	c.Assert(err, qt.IsNil)
+
+	var names []string
+	for db, err := range All(context.Background(), 100, func(ctx context.Context, opts ...ListOption) ([]*Database, error) {
+		return client.Databases.List(ctx, &ListDatabasesRequest{Organization: "acme"}, opts...)
+	}) {
+		c.Assert(err, qt.IsNil)
+		names = append(names, db.Name)
+	}
+	c.Assert(names, qt.DeepEquals, []string{"db1", "db2", "db3"})
+	c.Assert(requested, qt.DeepEquals, []string{"1", "2", "3"})
+}
+
+func TestAll(t *testing.T) {
+	c := qt.New(t)
+
+	var requested []int
+	var items []int
+	for item, err := range All(context.Background(), 3, fakePages(c, 7, &requested)) {
+		c.Assert(err, qt.IsNil)
+		items = append(items, item)
+		if item == 4 {
+			break
+		}
+	}
+	c.Assert(items, qt.DeepEquals, []int{0, 1, 2, 3, 4})
+	c.Assert(requested, qt.DeepEquals, []int{1, 2})
+
+	wantErr := errors.New("boom")
+	var gotErr error
+	for _, err := range All(context.Background(), 3, func(ctx context.Context, opts ...ListOption) ([]int, error) {
+		return nil, wantErr
+	}) {
+		gotErr = err
+	}
+	c.Assert(gotErr, qt.Equals, wantErr)
+}
@@ -0,0 +1,165 @@
+package planetscale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how the client retries requests that failed for a
+// transient reason: rate limiting (429), a 502, 503 or 504 from the API
+// gateway, or a connection reset. Only rate limited requests are retried
+// whatever their method, the others only when the method is idempotent.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent, including the
+	// first one. Zero or one disable retries.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry. Later retries double
+	// it, up to MaxBackoff, and add up to 50% of jitter.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// MaxRetryAfter caps the delay requested by a Retry-After header. A
+	// request asking for a longer delay isn't retried.
+	MaxRetryAfter time.Duration
+
+	// OnRetry, if set, is called before waiting to retry a request.
+	OnRetry func(req *http.Request, attempt int, wait time.Duration, reason string)
+}
+
+// DefaultRetryPolicy is the retry policy of new clients.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:   4,
+	MinBackoff:    500 * time.Millisecond,
+	MaxBackoff:    8 * time.Second,
+	MaxRetryAfter: 30 * time.Second,
+}
+
+// WithRetryPolicy overrides the retry policy of the client.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		if policy.MaxAttempts < 0 || policy.MinBackoff < 0 || policy.MaxBackoff < 0 {
+			return errors.New("retry policy can't have negative values")
+		}
+
+		c.retry = policy
+		return nil
+	}
+}
+
+// retryReason returns why the request that got res or err should be
+// retried, or an empty string if it shouldn't.
+func retryReason(req *http.Request, res *http.Response, err error) string {
+	if err != nil {
+		if req.Context().Err() != nil || !isIdempotent(req.Method) {
+			return ""
+		}
+		if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return "connection reset"
+		}
+		return ""
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests:
+		return res.Status
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if isIdempotent(req.Method) {
+			return res.Status
+		}
+	}
+	return ""
+}
+
+// isIdempotent reports whether sending a request with method twice has the
+// same effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry, starting at 1.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxBackoff)
+	if d <= 0 {
+		return 0
+	}
+	return d + rand.N(d/2+1)
+}
+
+// retryWait returns how long to wait before retrying a request that got res,
+// and false if the request shouldn't be retried.
+func (p *RetryPolicy) retryWait(retry int, res *http.Response) (time.Duration, bool) {
+	if res != nil {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			if p.MaxRetryAfter > 0 && d > p.MaxRetryAfter {
+				return 0, false
+			}
+			return d, true
+		}
+	}
+	return p.backoff(retry), true
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(h string) (time.Duration, bool) {
+	h = strings.TrimSpace(h)
+	if h == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(h); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	t, err := http.ParseTime(h)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(t), 0), true
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// rewindBody resets the body of req so it can be sent again.
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.GetBody == nil {
+		return fmt.Errorf("can't retry %s %s, its body can't be rewound", req.Method, req.URL.Path)
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
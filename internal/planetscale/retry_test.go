@@ -0,0 +1,159 @@
+package planetscale
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDo_Retries(t *testing.T) {
+	tests := []struct {
+		desc         string
+		method       string
+		statuses     []int
+		retryAfter   string
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			desc:         "retries GET requests on 503 until they succeed",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			wantAttempts: 3,
+		},
+		{
+			desc:         "retries rate limited POST requests",
+			method:       http.MethodPost,
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:   "0",
+			wantAttempts: 2,
+		},
+		{
+			desc:         "doesn't retry POST requests on 503",
+			method:       http.MethodPost,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			desc:         "doesn't retry other errors",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusInternalServerError, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			desc:         "gives up after the maximum attempts",
+			method:       http.MethodDelete,
+			statuses:     []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusOK},
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			desc:         "doesn't wait longer than the maximum Retry-After",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:   "3600",
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := qt.New(t)
+
+			var attempts atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1))
+				body, err := io.ReadAll(r.Body)
+				c.Check(err, qt.IsNil)
+				if tt.method == http.MethodPost {
+					c.Check(string(body), qt.Equals, "{\"name\":\"foo\"}\n")
+				}
+
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statuses[n-1])
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			t.Cleanup(ts.Close)
+
+			var retries []string
+			client, err := NewClient(WithBaseURL(ts.URL), WithRetryPolicy(RetryPolicy{
+				MaxAttempts:   3,
+				MinBackoff:    time.Millisecond,
+				MaxBackoff:    5 * time.Millisecond,
+				MaxRetryAfter: time.Second,
+				OnRetry: func(req *http.Request, attempt int, wait time.Duration, reason string) {
+					retries = append(retries, reason)
+				},
+			}))
+			c.Assert(err, qt.IsNil)
+
+			var body interface{}
+			if tt.method == http.MethodPost {
+				body = map[string]string{"name": "foo"}
+			}
+			req, err := client.newRequest(tt.method, "/api-endpoint", body)
+			c.Assert(err, qt.IsNil)
+
+			err = client.do(context.Background(), req, nil)
+			if tt.wantErr {
+				c.Assert(err, qt.IsNotNil)
+			} else {
+				c.Assert(err, qt.IsNil)
+			}
+			c.Assert(int(attempts.Load()), qt.Equals, tt.wantAttempts)
+			c.Assert(retries, qt.HasLen, tt.wantAttempts-1)
+		})
+	}
+}
+
+func TestDo_RetryStopsWhenCanceled(t *testing.T) {
+	c := qt.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client, err := NewClient(WithBaseURL(ts.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Hour,
+		MaxBackoff:  time.Hour,
+		OnRetry: func(req *http.Request, attempt int, wait time.Duration, reason string) {
+			cancel()
+		},
+	}))
+	c.Assert(err, qt.IsNil)
+
+	req, err := client.newRequest(http.MethodGet, "/api-endpoint", nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(client.do(ctx, req, nil), qt.ErrorIs, context.Canceled)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	c := qt.New(t)
+
+	d, ok := parseRetryAfter("7")
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(d, qt.Equals, 7*time.Second)
+
+	d, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(d, qt.Equals, time.Duration(0))
+
+	_, ok = parseRetryAfter("soon")
+	c.Assert(ok, qt.IsFalse)
+	_, ok = parseRetryAfter("")
+	c.Assert(ok, qt.IsFalse)
+}
@@ -13,7 +13,7 @@ var _ ServiceTokenService = &serviceTokenService{}
 // Service Token API.
 type ServiceTokenService interface {
 	Create(context.Context, *CreateServiceTokenRequest) (*ServiceToken, error)
-	List(context.Context, *ListServiceTokensRequest) ([]*ServiceToken, error)
+	List(context.Context, *ListServiceTokensRequest, ...ListOption) ([]*ServiceToken, error)
 	ListGrants(context.Context, *ListServiceTokenGrantsRequest) ([]*ServiceTokenGrant, error)
 	Delete(context.Context, *DeleteServiceTokenRequest) error
 	GetAccess(context.Context, *GetServiceTokenAccessRequest) ([]*ServiceTokenAccess, error)
@@ -39,8 +39,9 @@ func (s *serviceTokenService) Create(ctx context.Context, createReq *CreateServi
 	return st, nil
 }
 
-func (s *serviceTokenService) List(ctx context.Context, listReq *ListServiceTokensRequest) ([]*ServiceToken, error) {
-	req, err := s.client.newRequest(http.MethodGet, serviceTokensAPIPath(listReq.Organization), nil)
+func (s *serviceTokenService) List(ctx context.Context, listReq *ListServiceTokensRequest, opts ...ListOption) ([]*ServiceToken, error) {
+	listOpts := defaultListOptions(opts...)
+	req, err := s.client.newRequest(http.MethodGet, serviceTokensAPIPath(listReq.Organization), nil, WithQueryParams(*listOpts.URLValues))
 	if err != nil {
 		return nil, err
 	}
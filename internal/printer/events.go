@@ -0,0 +1,101 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types of the ndjson format.
+const (
+	// EventProgress reports what a command is doing, with the message of
+	// its spinner.
+	EventProgress = "progress"
+	// EventState reports that a resource a command waits for changed state.
+	EventState = "state"
+	// EventMessage is a line a command prints for humans.
+	EventMessage = "message"
+	// EventResult is the resource a command prints at the end.
+	EventResult = "result"
+)
+
+// Event is a line of the ndjson format, which reports the progress of a
+// command as it happens.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Message string    `json:"message,omitempty"`
+	// State is the state of the resource for state events, or the stage of
+	// the command for progress events.
+	State string `json:"state,omitempty"`
+	// Current and Total measure the progress of progress events, when
+	// they're known.
+	Current  int64       `json:"current,omitempty"`
+	Total    int64       `json:"total,omitempty"`
+	Resource interface{} `json:"resource,omitempty"`
+}
+
+var eventsMu sync.Mutex
+
+// ansiEscape matches the color and style sequences messages built for
+// human output may have.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// PrintEvent prints e in the ndjson format, and does nothing in the other
+// formats. The time of e is set if it's zero.
+func (p *Printer) PrintEvent(e *Event) error {
+	if p.format == nil || *p.format != NDJSON {
+		return nil
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	e.Message = ansiEscape.ReplaceAllString(e.Message, "")
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(e); err != nil {
+		return err
+	}
+
+	// Commands may print events from several goroutines.
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	_, err := p.ResourceOutput().Write(buf.Bytes())
+	return err
+}
+
+// PrintState prints a state event for state, unless it's the same as last,
+// and returns state. Commands that wait for a resource call it with each
+// state they get.
+func (p *Printer) PrintState(last, state string) string {
+	if state != last {
+		p.PrintEvent(&Event{Type: EventState, State: state}) // nolint:errcheck
+	}
+	return state
+}
+
+// messageWriter prints what's written to it as message events, a line per
+// event.
+type messageWriter struct {
+	p *Printer
+}
+
+func (w messageWriter) Write(b []byte) (int, error) {
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := w.p.PrintEvent(&Event{Type: EventMessage, Message: line}); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
@@ -0,0 +1,80 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decodeEvents(t *testing.T, out *bytes.Buffer) []Event {
+	t.Helper()
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("event %q has no time", line)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestPrintEvents(t *testing.T) {
+	format := NDJSON
+	var out bytes.Buffer
+	p := NewPrinter(&format)
+	p.SetResourceOutput(&out)
+
+	progress := p.StartProgress("Creating branch " + BoldBlue("dev") + "...")
+	var state string
+	for _, s := range []string{"pending", "pending", "ready"} {
+		state = p.PrintState(state, s)
+	}
+	progress.Stop()
+	p.Printf("Branch %s was created.\n\n", BoldBlue("dev"))
+	if err := p.PrintResource(&streamRow{Name: "dev", Count: 1}); err != nil {
+		t.Fatalf("print resource: %v", err)
+	}
+
+	events := decodeEvents(t, &out)
+	want := []Event{
+		{Type: EventProgress, Message: "Creating branch dev..."},
+		{Type: EventState, State: "pending"},
+		{Type: EventState, State: "ready"},
+		{Type: EventMessage, Message: "Branch dev was created."},
+		{Type: EventResult},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %q", len(events), len(want), out.String())
+	}
+	for i, e := range events {
+		if e.Type != want[i].Type || e.Message != want[i].Message || e.State != want[i].State {
+			t.Errorf("event %d: got %+v, want %+v", i, e, want[i])
+		}
+	}
+
+	result, ok := events[4].Resource.(map[string]interface{})
+	if !ok || result["name"] != "dev" {
+		t.Errorf("result resource = %#v", events[4].Resource)
+	}
+}
+
+func TestPrintEventOtherFormats(t *testing.T) {
+	for _, format := range []Format{Human, JSON, CSV} {
+		var out bytes.Buffer
+		p := NewPrinter(&format)
+		p.SetResourceOutput(&out)
+
+		if err := p.PrintEvent(&Event{Type: EventProgress, Message: "working"}); err != nil {
+			t.Fatalf("%s: print event: %v", format, err)
+		}
+		p.PrintState("", "ready")
+		if out.Len() != 0 {
+			t.Errorf("%s: got %q, want no output", format, out.String())
+		}
+	}
+}
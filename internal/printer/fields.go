@@ -0,0 +1,218 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fieldName normalizes the name of a field given with --fields or in a header
+// tag, so "created_at", "created at" and "Created-At" are the same field.
+func fieldName(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(s)
+}
+
+// selection is a resource struct type restricted to some of its fields.
+type selection struct {
+	typ   reflect.Type // struct with the selected fields, in their order
+	index []int        // field of the resource for every field of typ
+	names []string     // normalized header names of the fields of typ
+}
+
+// newSelection returns the selection of fields, named after the header tags
+// of the resource struct type typ.
+func newSelection(typ reflect.Type, fields []string) (*selection, error) {
+	available := make(map[string]int)
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		header, _, _ := strings.Cut(field.Tag.Get("header"), ",")
+		if header == "" || field.PkgPath != "" {
+			continue
+		}
+		name := fieldName(header)
+		if _, ok := available[name]; !ok {
+			available[name] = i
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("--fields isn't supported by this command")
+	}
+
+	s := &selection{}
+	var structFields []reflect.StructField
+	for _, f := range fields {
+		name := fieldName(f)
+		i, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q, available fields: %s", f, strings.Join(names, ", "))
+		}
+		structFields = append(structFields, typ.Field(i))
+		s.index = append(s.index, i)
+		s.names = append(s.names, name)
+	}
+	s.typ = reflect.StructOf(structFields)
+	return s, nil
+}
+
+// value returns the selected fields of rv, a resource struct.
+func (s *selection) value(rv reflect.Value) reflect.Value {
+	out := reflect.New(s.typ).Elem()
+	for i, index := range s.index {
+		out.Field(i).Set(rv.Field(index))
+	}
+	return out
+}
+
+// resources returns the resource structs of v, a resource or a slice of
+// resources, and the selection of fields for their type. Nil resources are
+// returned as invalid values.
+func resources(v interface{}, fields []string) (sel *selection, rows []reflect.Value, list bool, err error) {
+	rv := reflect.ValueOf(v)
+	typ := rv.Type()
+
+	list = typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array
+	if list {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, nil, false, fmt.Errorf("--fields isn't supported by this command")
+	}
+
+	sel, err = newSelection(typ, fields)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if !list {
+		rv = reflect.Append(reflect.MakeSlice(reflect.SliceOf(rv.Type()), 0, 1), rv)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		row := rv.Index(i)
+		if row.Kind() == reflect.Pointer {
+			if row.IsNil() {
+				rows = append(rows, reflect.Value{})
+				continue
+			}
+			row = row.Elem()
+		}
+		rows = append(rows, row)
+	}
+	return sel, rows, list, nil
+}
+
+// selectFields returns v, a resource or a slice of resources, with only the
+// given fields, for table and CSV output.
+func selectFields(v interface{}, fields []string) (interface{}, error) {
+	sel, rows, list, err := resources(v, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	out := reflect.MakeSlice(reflect.SliceOf(sel.typ), 0, len(rows))
+	for _, row := range rows {
+		if !row.IsValid() {
+			out = reflect.Append(out, reflect.New(sel.typ).Elem())
+			continue
+		}
+		out = reflect.Append(out, sel.value(row))
+	}
+
+	if !list {
+		return out.Index(0).Interface(), nil
+	}
+	return out.Interface(), nil
+}
+
+// selectJSONFields returns v, a resource or a slice of resources, as JSON
+// objects with only the given fields. A field has the value of the key of the
+// same name in the resource's JSON, or else the value of the struct field.
+func selectJSONFields(v interface{}, fields []string) (interface{}, error) {
+	sel, rows, list, err := resources(v, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]jsonObject, 0, len(rows))
+	for _, row := range rows {
+		if !row.IsValid() {
+			objects = append(objects, nil)
+			continue
+		}
+		obj, err := sel.jsonObject(row)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+
+	if !list {
+		return objects[0], nil
+	}
+	return objects, nil
+}
+
+func (s *selection) jsonObject(row reflect.Value) (jsonObject, error) {
+	// Resources often marshal to the API's JSON, which is what JSON
+	// output shows, so prefer its values.
+	marshaled := row.Interface()
+	if row.CanAddr() {
+		marshaled = row.Addr().Interface()
+	}
+	var full map[string]json.RawMessage
+	if b, err := json.Marshal(marshaled); err == nil {
+		_ = json.Unmarshal(b, &full)
+	}
+
+	obj := make(jsonObject, 0, len(s.index))
+	for i, name := range s.names {
+		value, ok := full[name]
+		if !ok {
+			var err error
+			value, err = json.Marshal(row.Field(s.index[i]).Interface())
+			if err != nil {
+				return nil, err
+			}
+		}
+		obj = append(obj, jsonField{name: name, value: value})
+	}
+	return obj, nil
+}
+
+type jsonField struct {
+	name  string
+	value json.RawMessage
+}
+
+// jsonObject is a JSON object that keeps the order of its fields.
+type jsonObject []jsonField
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	if o == nil {
+		return []byte("null"), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(f.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
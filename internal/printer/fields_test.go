@@ -0,0 +1,159 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type fieldsRow struct {
+	Name      string `header:"name" json:"-" csv:"name"`
+	State     string `header:"deploy state" json:"-" csv:"state"`
+	Replicas  *int   `header:"replicas,n/a" json:"-" csv:"replicas"`
+	CreatedAt int64  `header:"created_at" json:"-" csv:"created_at"`
+
+	orig map[string]interface{}
+}
+
+func (r *fieldsRow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.orig)
+}
+
+func TestPrintResourceFields(t *testing.T) {
+	rows := []*fieldsRow{
+		{Name: "a", State: "ready", CreatedAt: 42, orig: map[string]interface{}{"name": "a", "created_at": "2026-01-02T03:04:05Z"}},
+		{Name: "b", State: "complete", CreatedAt: 43, orig: map[string]interface{}{"name": "b"}},
+	}
+
+	tests := []struct {
+		format Format
+		fields []string
+		want   string
+	}{
+		{
+			format: Human,
+			fields: []string{"deploy_state", "name", "replicas"},
+			want: `  DEPLOY STATE   NAME   REPLICAS  
+ -------------- ------ ---------- 
+  ready          a           n/a  
+  complete       b           n/a  
+
+`,
+		},
+		{
+			format: CSV,
+			fields: []string{"name", "Deploy-State"},
+			want:   "name,state\na,ready\nb,complete\n\n",
+		},
+		{
+			// Values come from the resource's JSON when it has the field.
+			format: JSON,
+			fields: []string{"created_at", "name", "deploy state"},
+			want: `[
+  {
+    "created_at": "2026-01-02T03:04:05Z",
+    "name": "a",
+    "deploy_state": "ready"
+  },
+  {
+    "created_at": 43,
+    "name": "b",
+    "deploy_state": "complete"
+  }
+]
+`,
+		},
+		{
+			format: YAML,
+			fields: []string{"name", "created_at"},
+			want:   "- name: a\n  created_at: \"2026-01-02T03:04:05Z\"\n- name: b\n  created_at: 43\n",
+		},
+		{
+			format: Markdown,
+			fields: []string{"name", "deploy_state"},
+			want:   "| NAME | DEPLOY STATE |\n| --- | --- |\n| a | ready |\n| b | complete |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		p := NewPrinter(&tt.format)
+		p.SetResourceOutput(&out)
+		p.SetFields(tt.fields)
+
+		if err := p.PrintResource(rows); err != nil {
+			t.Fatalf("%s: print resource: %v", tt.format, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.format, out.String(), tt.want)
+		}
+	}
+}
+
+func TestPrintResourceFieldsSingleResource(t *testing.T) {
+	format := JSON
+	var out bytes.Buffer
+	p := NewPrinter(&format)
+	p.SetResourceOutput(&out)
+	p.SetFields([]string{"name"})
+
+	if err := p.PrintResource(&fieldsRow{Name: "a", orig: map[string]interface{}{"name": "a"}}); err != nil {
+		t.Fatalf("print resource: %v", err)
+	}
+	if want := "{\n  \"name\": \"a\"\n}\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestPrintResourceUnknownField(t *testing.T) {
+	format := Human
+	p := NewPrinter(&format)
+	p.SetResourceOutput(&bytes.Buffer{})
+	p.SetFields([]string{"name", "size"})
+
+	err := p.PrintResource([]*fieldsRow{{Name: "a"}})
+	want := `unknown field "size", available fields: name, deploy_state, replicas, created_at`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
+
+func TestResourceStreamFields(t *testing.T) {
+	pages := [][]*streamRow{
+		{{Name: "a", Count: 1}, {Name: "b", Count: 2}},
+		{{Name: "c", Count: 3}},
+	}
+	var all []*streamRow
+	for _, page := range pages {
+		all = append(all, page...)
+	}
+
+	for _, format := range []Format{Human, JSON, CSV} {
+		var want, got bytes.Buffer
+		p := NewPrinter(&format)
+		p.SetFields([]string{"count"})
+		p.SetResourceOutput(&want)
+		if err := p.PrintResource(all); err != nil {
+			t.Fatalf("%s: print resource: %v", format, err)
+		}
+
+		p.SetResourceOutput(&got)
+		stream := p.StreamResources()
+		for _, page := range pages {
+			if err := stream.Print(page); err != nil {
+				t.Fatalf("%s: print page: %v", format, err)
+			}
+		}
+		if err := stream.Close(); err != nil {
+			t.Fatalf("%s: close: %v", format, err)
+		}
+
+		if got.String() != want.String() {
+			t.Errorf("%s: got %q, want %q", format, got.String(), want.String())
+		}
+		if strings.Contains(got.String(), "a") {
+			t.Errorf("%s: got the name column in %q", format, got.String())
+		}
+	}
+}
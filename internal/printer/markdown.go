@@ -0,0 +1,71 @@
+package printer
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// errNotTable is returned for values that have no table to print as Markdown.
+var errNotTable = errors.New("this output isn't a table and can't be printed as markdown, use --format json or --format yaml instead")
+
+// printMarkdown prints v, a resource or a slice of resources, as a GitHub
+// flavored Markdown table. The header is left out if header is false, to
+// continue a table. An empty list prints nothing.
+func printMarkdown(out io.Writer, v interface{}, header bool) error {
+	headers, rows, numbers := tableCells(tableValue(v))
+	if len(headers) == 0 {
+		if rv := reflect.Indirect(reflect.ValueOf(v)); rv.IsValid() &&
+			(rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array || rv.Len() > 0) {
+			return errNotTable
+		}
+		return nil
+	}
+	if !header {
+		headers = nil
+	}
+	for i, h := range headers {
+		headers[i] = strings.ToUpper(h)
+	}
+	return MarkdownTable(out, headers, rows, numbers...)
+}
+
+// MarkdownTable writes a GitHub flavored Markdown table, for commands that
+// render resources themselves. The columns at the indexes of numbers are
+// right-aligned. Without headers only the rows are written.
+func MarkdownTable(w io.Writer, headers []string, rows [][]string, numbers ...int) error {
+	var b strings.Builder
+	if len(headers) > 0 {
+		writeMarkdownRow(&b, headers)
+		b.WriteString("|")
+		for i := range headers {
+			if slices.Contains(numbers, i) {
+				b.WriteString(" ---: |")
+			} else {
+				b.WriteString(" --- |")
+			}
+		}
+		b.WriteString("\n")
+	}
+	for _, row := range rows {
+		writeMarkdownRow(&b, row)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscaper keeps cells on their row and pipes from ending them.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, cell := range cells {
+		b.WriteString(" ")
+		b.WriteString(markdownEscaper.Replace(strings.TrimSpace(cell)))
+		b.WriteString(" |")
+	}
+	b.WriteString("\n")
+}
@@ -34,8 +34,20 @@ const (
 	Human Format = iota
 	JSON
 	CSV
+	// Template prints resources with a Go template, see Printer.SetTemplate.
+	Template
+	// YAML prints the fields of the JSON format as YAML.
+	YAML
+	// Markdown prints the table of the human format as a Markdown table.
+	Markdown
+	// NDJSON prints events as JSON lines while a command runs, and the
+	// resource as the last event, see Printer.PrintEvent.
+	NDJSON
 )
 
+// Formats are the names of the formats, for flag help and completion.
+var Formats = []string{"human", "json", "csv", "template", "yaml", "markdown", "ndjson"}
+
 // NewFormatValue is used to define a flag that can be used to define a custom
 // flag via the flagset.Var() method.
 func NewFormatValue(val Format, p *Format) *Format {
@@ -51,6 +63,14 @@ func (f Format) String() string {
 		return "json"
 	case CSV:
 		return "csv"
+	case Template:
+		return "template"
+	case YAML:
+		return "yaml"
+	case Markdown:
+		return "markdown"
+	case NDJSON:
+		return "ndjson"
 	}
 
 	return "unknown format"
@@ -65,9 +85,17 @@ func (f *Format) Set(s string) error {
 		v = JSON
 	case "csv":
 		v = CSV
+	case "template":
+		v = Template
+	case "yaml":
+		v = YAML
+	case "markdown":
+		v = Markdown
+	case "ndjson":
+		v = NDJSON
 	default:
 		return fmt.Errorf("failed to parse Format: %q. Valid values: %+v",
-			s, []string{"human", "json", "csv"})
+			s, Formats)
 	}
 
 	*f = Format(v)
@@ -83,7 +111,10 @@ type Printer struct {
 	humanOut    io.Writer
 	resourceOut io.Writer
 
-	format *Format
+	format   *Format
+	template string
+	fields   []string
+	capture  func(v interface{}) error
 }
 
 // NewPrinter returns a new Printer for the given output and format.
@@ -110,14 +141,17 @@ func (p *Printer) Print(i ...interface{}) {
 
 // out defines the output to write human readable text. If format is not set to
 // human, out returns io.Discard, which means that any output will be
-// discarded
+// discarded, or prints message events for the ndjson format.
 func (p *Printer) out() io.Writer {
 	if p.humanOut != nil {
 		return p.humanOut
 	}
 
-	if *p.format == Human {
+	switch *p.format {
+	case Human:
 		return color.Output
+	case NDJSON:
+		return messageWriter{p}
 	}
 
 	return io.Discard // /dev/nullj
@@ -152,7 +186,16 @@ func (h *ProgressHandle) Stop() {
 }
 
 // StartProgress starts a spinner or line-based progress on w when not a TTY.
+// In the ndjson format, the message and its updates are progress events.
 func (p *Printer) StartProgress(message string) *ProgressHandle {
+	if p.humanOut == nil && *p.format == NDJSON {
+		p.PrintEvent(&Event{Type: EventProgress, Message: message}) // nolint:errcheck
+		return &ProgressHandle{
+			update: func(msg string) {
+				p.PrintEvent(&Event{Type: EventProgress, Message: msg}) // nolint:errcheck
+			},
+		}
+	}
 	return p.startProgressOn(p.out(), message)
 }
 
@@ -194,6 +237,27 @@ func (p *Printer) SetResourceOutput(out io.Writer) {
 	p.resourceOut = out
 }
 
+// SetTemplate sets the Go template resources are printed with in the
+// template format. The template is executed with the resource, or the slice
+// of resources, passed to PrintResource.
+func (p *Printer) SetTemplate(text string) {
+	p.template = text
+}
+
+// SetFields restricts the fields of resources printed in every format but
+// the template format to fields, in that order. Fields are named after the header
+// tags of the resource struct.
+func (p *Printer) SetFields(fields []string) {
+	p.fields = fields
+}
+
+// SetCapture makes PrintResource pass resources to capture instead of
+// printing them, for callers that print what a command prints themselves,
+// such as --watch. A nil capture restores printing.
+func (p *Printer) SetCapture(capture func(v interface{}) error) {
+	p.capture = capture
+}
+
 // ResourceOutput returns the writer PrintResource writes to, for commands that
 // render resources themselves (e.g. dynamic column sets that can't use struct
 // tags).
@@ -209,12 +273,31 @@ func (p *Printer) PrintResource(v interface{}) error {
 	if p.format == nil {
 		return errors.New("printer.Format is not set")
 	}
+	if p.capture != nil {
+		return p.capture(v)
+	}
 
 	var out io.Writer = os.Stdout
 	if p.resourceOut != nil {
 		out = p.resourceOut
 	}
 
+	if *p.format == Template {
+		if p.template == "" {
+			return errors.New("--format template requires --template")
+		}
+		return ExecuteTemplate(out, p.template, v)
+	}
+	if p.template != "" {
+		return fmt.Errorf("--template can't be used with --format %s", *p.format)
+	}
+
+	var err error
+	v, err = p.selectFields(v)
+	if err != nil {
+		return err
+	}
+
 	switch *p.format {
 	case Human:
 		var b strings.Builder
@@ -238,11 +321,183 @@ func (p *Printer) PrintResource(v interface{}) error {
 		}
 		fmt.Fprintln(out, buf)
 		return nil
+	case YAML:
+		return p.PrintYAML(v)
+	case Markdown:
+		return printMarkdown(out, v, true)
+	case NDJSON:
+		return p.PrintEvent(&Event{Type: EventResult, Resource: v})
 	}
 
 	return fmt.Errorf("unknown printer.Format: %T", *p.format)
 }
 
+// selectFields returns v restricted to the fields set with SetFields, if any.
+func (p *Printer) selectFields(v interface{}) (interface{}, error) {
+	if len(p.fields) == 0 {
+		return v, nil
+	}
+
+	switch *p.format {
+	case JSON, YAML, NDJSON:
+		return selectJSONFields(v, p.fields)
+	case CSV:
+		type csvvaluer interface {
+			MarshalCSVValue() interface{}
+		}
+
+		if c, ok := v.(csvvaluer); ok {
+			v = c.MarshalCSVValue()
+		}
+		return selectFields(v, p.fields)
+	}
+	return selectFields(v, p.fields)
+}
+
+// ResourceStream prints a list of resources that arrives a page at a time,
+// see StreamResources.
+type ResourceStream struct {
+	p    *Printer
+	n    int
+	rows reflect.Value
+}
+
+// StreamResources returns a stream printing the pages of a list of resources
+// in the format it was specified. JSON, CSV, YAML and Markdown output is
+// written as each page arrives, as a single array, document or table. Tables
+// need every row to size their columns, and templates and the result event of
+// the ndjson format the whole list, so they are printed when the stream is
+// closed.
+func (p *Printer) StreamResources() *ResourceStream {
+	return &ResourceStream{p: p}
+}
+
+// Print prints page, a slice of resources.
+func (s *ResourceStream) Print(page interface{}) error {
+	rv := reflect.ValueOf(page)
+	if rv.Kind() != reflect.Slice {
+		return fmt.Errorf("can't stream %T, it's not a slice", page)
+	}
+	if rv.Len() == 0 {
+		return nil
+	}
+	defer func() { s.n += rv.Len() }()
+
+	if s.buffered() {
+		if !s.rows.IsValid() {
+			s.rows = reflect.MakeSlice(rv.Type(), 0, rv.Len())
+		}
+		s.rows = reflect.AppendSlice(s.rows, rv)
+		return nil
+	}
+
+	page, err := s.p.selectFields(page)
+	if err != nil {
+		return err
+	}
+	rv = reflect.ValueOf(page)
+
+	out := s.p.ResourceOutput()
+	switch s.p.Format() {
+	case JSON:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("  ", "  ")
+		for i := 0; i < rv.Len(); i++ {
+			if s.n == 0 && i == 0 {
+				buf.WriteString("[\n  ")
+			} else {
+				buf.WriteString(",\n  ")
+			}
+			if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+			buf.Truncate(buf.Len() - 1)
+		}
+		_, err := out.Write(buf.Bytes())
+		return err
+	case CSV:
+		var (
+			buf string
+			err error
+		)
+		if s.n == 0 {
+			buf, err = gocsv.MarshalString(page)
+		} else {
+			buf, err = gocsv.MarshalStringWithoutHeaders(page)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(out, buf)
+		return err
+	case YAML:
+		// The sequences of the pages add up to the sequence of the list.
+		buf, err := marshalYAML(page)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(buf)
+		return err
+	case Markdown:
+		return printMarkdown(out, page, s.n == 0)
+	}
+
+	return fmt.Errorf("unknown printer.Format: %T", s.p.Format())
+}
+
+// buffered reports whether the stream prints the whole list when it's closed.
+func (s *ResourceStream) buffered() bool {
+	format := s.p.Format()
+	return format == Human || format == Template || format == NDJSON || s.p.capture != nil
+}
+
+// Len returns the number of resources printed so far.
+func (s *ResourceStream) Len() int { return s.n }
+
+// Close ends the output of the stream.
+func (s *ResourceStream) Close() error {
+	if s.buffered() {
+		if !s.rows.IsValid() {
+			return nil
+		}
+		return s.p.PrintResource(s.rows.Interface())
+	}
+
+	out := s.p.ResourceOutput()
+	switch s.p.Format() {
+	case JSON:
+		if s.n == 0 {
+			_, err := io.WriteString(out, "[]\n")
+			return err
+		}
+		_, err := io.WriteString(out, "\n]\n")
+		return err
+	case CSV:
+		if s.n > 0 {
+			_, err := io.WriteString(out, "\n")
+			return err
+		}
+	case YAML:
+		if s.n == 0 {
+			_, err := io.WriteString(out, "[]\n")
+			return err
+		}
+	}
+	return nil
+}
+
+// Abort ends the output of a stream that failed partway, so that the pages
+// printed so far still make a complete document. Buffered resources are
+// dropped.
+func (s *ResourceStream) Abort() error {
+	if s.buffered() || s.n == 0 {
+		return nil
+	}
+	return s.Close()
+}
+
 func (p *Printer) ConfirmCommand(confirmationName, commandShortName, confirmFailedName string) error {
 	if p.Format() != Human {
 		return fmt.Errorf(`cannot %s with the output format "%s" (run with --force to override)`, commandShortName, p.Format())
@@ -358,6 +613,25 @@ func tableValue(v interface{}) interface{} {
 	return v
 }
 
+// tableCells returns the header and rows of v, a table value, as the
+// tableprinter renders them for human output. numbers are the indexes of the
+// numeric columns.
+func tableCells(v interface{}) (headers []string, rows [][]string, numbers []int) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, nil, nil
+	}
+
+	parser := tableprinter.WhichParser(rv.Type())
+	if parser == nil {
+		return nil, nil, nil
+	}
+	return parser.Parse(rv, nil)
+}
+
 func flattenStruct(rv reflect.Value) reflect.Value {
 	flat := flatStructType(rv.Type())
 	if flat == rv.Type() {
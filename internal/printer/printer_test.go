@@ -64,3 +64,155 @@ func TestPrintJSONDoesNotEscapeHTMLPlaceholders(t *testing.T) {
 		t.Fatalf("expected no escaped angle brackets, got %s", got)
 	}
 }
+
+type streamRow struct {
+	Name  string `header:"name" json:"name" csv:"name"`
+	Count int    `header:"count" json:"count" csv:"count"`
+}
+
+// Streaming pages must print the same document as printing the whole list.
+func TestResourceStreamMatchesPrintResource(t *testing.T) {
+	pages := [][]*streamRow{
+		{{Name: "a", Count: 1}, {Name: "<b>", Count: 2}},
+		{},
+		{{Name: "c", Count: 3}},
+	}
+	var all []*streamRow
+	for _, page := range pages {
+		all = append(all, page...)
+	}
+
+	for _, format := range []Format{Human, JSON, CSV, YAML, Markdown} {
+		var want, got bytes.Buffer
+		p := NewPrinter(&format)
+		p.SetResourceOutput(&want)
+		if err := p.PrintResource(all); err != nil {
+			t.Fatalf("%s: print resource: %v", format, err)
+		}
+
+		p.SetResourceOutput(&got)
+		stream := p.StreamResources()
+		for _, page := range pages {
+			if err := stream.Print(page); err != nil {
+				t.Fatalf("%s: print page: %v", format, err)
+			}
+		}
+		if err := stream.Close(); err != nil {
+			t.Fatalf("%s: close: %v", format, err)
+		}
+
+		if got.String() != want.String() {
+			t.Errorf("%s: got %q, want %q", format, got.String(), want.String())
+		}
+		if stream.Len() != len(all) {
+			t.Errorf("%s: got %d rows, want %d", format, stream.Len(), len(all))
+		}
+	}
+}
+
+func TestResourceStreamEmptyJSON(t *testing.T) {
+	format := JSON
+	var out bytes.Buffer
+	p := NewPrinter(&format)
+	p.SetResourceOutput(&out)
+
+	if err := p.StreamResources().Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if out.String() != "[]\n" {
+		t.Errorf("got %q, want an empty array", out.String())
+	}
+}
+
+func TestResourceStreamEmptyYAML(t *testing.T) {
+	format := YAML
+	var out bytes.Buffer
+	p := NewPrinter(&format)
+	p.SetResourceOutput(&out)
+
+	if err := p.StreamResources().Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if out.String() != "[]\n" {
+		t.Errorf("got %q, want an empty sequence", out.String())
+	}
+}
+
+func TestPrintResourceYAML(t *testing.T) {
+	format := YAML
+	var out bytes.Buffer
+	p := NewPrinter(&format)
+	p.SetResourceOutput(&out)
+
+	// Fields keep their JSON order, and strings that would read as another
+	// type stay strings.
+	rows := []*nilCellRow{
+		{Name: "yes", Enabled: true, CreatedAt: 42},
+		{Name: "main\ndev <b>", MaxCount: new(3)},
+	}
+	if err := p.PrintResource(rows); err != nil {
+		t.Fatalf("print resource: %v", err)
+	}
+
+	want := `- name: "yes"
+  max_count: null
+  enabled: true
+  target: null
+  created_at: 42
+- name: |-
+    main
+    dev <b>
+  max_count: 3
+  enabled: false
+  target: null
+  created_at: 0
+`
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestPrintResourceMarkdown(t *testing.T) {
+	format := Markdown
+	var out bytes.Buffer
+	p := NewPrinter(&format)
+	p.SetResourceOutput(&out)
+
+	rows := []*nilCellRow{
+		{Name: "a|b", Enabled: true, CreatedAt: 42},
+		{Name: "multi\nline", MaxCount: new(3)},
+	}
+	if err := p.PrintResource(rows); err != nil {
+		t.Fatalf("print resource: %v", err)
+	}
+
+	want := `| NAME | MAX_COUNT | ENABLED | TARGET | CREATED_AT |
+| --- | ---: | --- | ---: | ---: |
+| a\|b | n/a | Yes | n/a | 42 |
+| multi<br>line | 3 | No | n/a | 0 |
+`
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestPrintResourceMarkdownNotTable(t *testing.T) {
+	format := Markdown
+	var out bytes.Buffer
+	p := NewPrinter(&format)
+	p.SetResourceOutput(&out)
+
+	if err := p.PrintResource([]*nilCellRow{}); err != nil {
+		t.Fatalf("print empty list: %v", err)
+	}
+
+	resp := struct {
+		Status string `json:"status"`
+	}{Status: "ok"}
+	if err := p.PrintResource(resp); err == nil {
+		t.Fatal("expected an error for a value without a table")
+	}
+	if out.String() != "" {
+		t.Errorf("got %q, want no output", out.String())
+	}
+}
@@ -0,0 +1,148 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Snapshot is a resource, or a list of resources, as the human and JSON
+// formats print it, for comparing what a command prints over time.
+type Snapshot struct {
+	// Headers and Rows are the cells of the table of the human format.
+	// Numbers are the indexes of the numeric columns.
+	Headers []string
+	Rows    [][]string
+	Numbers []int
+
+	// JSON is the resource as the JSON format prints it.
+	JSON json.RawMessage
+
+	// values are the struct fields the cells are rendered from, when
+	// they're known. Cells such as "3 minutes ago" change while their value
+	// doesn't.
+	values [][]interface{}
+}
+
+// Snapshot returns the snapshot of v, a resource or a slice of resources,
+// with the fields set with SetFields.
+func (p *Printer) Snapshot(v interface{}) (*Snapshot, error) {
+	table, data := v, v
+	if len(p.fields) > 0 {
+		var err error
+		if table, err = selectFields(v, p.fields); err != nil {
+			return nil, err
+		}
+		if data, err = selectJSONFields(v, p.fields); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return nil, err
+	}
+
+	table = tableValue(table)
+	s := &Snapshot{JSON: bytes.TrimSpace(buf.Bytes())}
+	s.Headers, s.Rows, s.Numbers = tableCells(table)
+	s.values = tableValues(table, len(s.Headers))
+	return s, nil
+}
+
+// Changed returns which cells of s changed since prev. Rows are matched by
+// their first cell, and rows that are new in s changed entirely. Nothing
+// changed if prev is nil.
+func (s *Snapshot) Changed(prev *Snapshot) [][]bool {
+	changed := make([][]bool, len(s.Rows))
+	for i, row := range s.Rows {
+		changed[i] = make([]bool, len(row))
+		if prev == nil {
+			continue
+		}
+
+		j := prev.row(row)
+		for k := range row {
+			switch {
+			case j < 0 || k >= len(prev.Rows[j]):
+				changed[i][k] = true
+			case s.values != nil && prev.values != nil:
+				changed[i][k] = !reflect.DeepEqual(s.values[i][k], prev.values[j][k])
+			default:
+				changed[i][k] = row[k] != prev.Rows[j][k]
+			}
+		}
+	}
+	return changed
+}
+
+// Column returns the index of the column named name, as with SetFields, or
+// -1.
+func (s *Snapshot) Column(name string) int {
+	name = fieldName(name)
+	for i, h := range s.Headers {
+		if fieldName(h) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// row returns the index of the row of s with the same first cell as row, or
+// -1.
+func (s *Snapshot) row(row []string) int {
+	if len(row) == 0 {
+		return -1
+	}
+	for i, r := range s.Rows {
+		if len(r) > 0 && r[0] == row[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+// tableValues returns the struct field of every cell of v, a table value, or
+// nil if they don't line up with the columns tableprinter renders.
+func tableValues(v interface{}, columns int) [][]interface{} {
+	rv := reflect.ValueOf(v)
+	var rows []reflect.Value
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Struct:
+		rows = append(rows, rv)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, rv.Index(i))
+		}
+	default:
+		return nil
+	}
+
+	values := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		row = reflect.Indirect(row)
+		if row.Kind() != reflect.Struct {
+			return nil
+		}
+
+		// These are the fields tableprinter renders a cell for.
+		var cells []interface{}
+		for i := 0; i < row.NumField(); i++ {
+			field := row.Type().Field(i)
+			if field.PkgPath != "" || field.Tag.Get("header") == "" {
+				continue
+			}
+			if field.Type.Kind() == reflect.Struct {
+				return nil
+			}
+			cells = append(cells, row.Field(i).Interface())
+		}
+		if len(cells) != columns {
+			return nil
+		}
+		values = append(values, cells)
+	}
+	return values
+}
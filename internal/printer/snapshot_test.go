@@ -0,0 +1,56 @@
+package printer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type snapshotRow struct {
+	Name      string `header:"name" json:"name"`
+	State     string `header:"state" json:"state"`
+	UpdatedAt int64  `header:"updated_at,timestamp(ms|utc|human)" json:"updated_at"`
+}
+
+func TestSnapshotChanged(t *testing.T) {
+	format := Human
+	p := NewPrinter(&format)
+
+	updated := GetMilliseconds(time.Now().Add(-time.Hour))
+	prev, err := p.Snapshot([]*snapshotRow{
+		{Name: "a", State: "pending", UpdatedAt: updated},
+		{Name: "b", State: "ready", UpdatedAt: updated},
+	})
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	snap, err := p.Snapshot([]*snapshotRow{
+		{Name: "c", State: "pending", UpdatedAt: updated},
+		{Name: "b", State: "ready", UpdatedAt: updated},
+		{Name: "a", State: "ready", UpdatedAt: updated},
+	})
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	// Rows are matched by their first cell, and timestamps by their value
+	// rather than by how long ago they were.
+	want := [][]bool{
+		{true, true, true},
+		{false, false, false},
+		{false, true, false},
+	}
+	if got := snap.Changed(prev); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := snap.Changed(nil); !reflect.DeepEqual(got, [][]bool{make([]bool, 3), make([]bool, 3), make([]bool, 3)}) {
+		t.Errorf("got %v without a previous snapshot", got)
+	}
+
+	if i := snap.Column("Updated-At"); i != 2 {
+		t.Errorf("got column %d, want 2", i)
+	}
+	if want := `[{"name":"c","state":"pending","updated_at":`; string(snap.JSON[:len(want)]) != want {
+		t.Errorf("got JSON %s", snap.JSON)
+	}
+}
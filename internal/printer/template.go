@@ -0,0 +1,124 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+)
+
+// templateColors are the attributes the color template function accepts.
+var templateColors = map[string]color.Attribute{
+	"bold":    color.Bold,
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+// templateFuncs are the functions templates can use in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	// json encodes a value as compact JSON.
+	"json": func(v interface{}) (string, error) {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	},
+	// join joins the elements of a list with sep.
+	"join": func(sep string, list interface{}) (string, error) {
+		switch l := list.(type) {
+		case []string:
+			return strings.Join(l, sep), nil
+		case []interface{}:
+			parts := make([]string, len(l))
+			for i, v := range l {
+				parts[i] = fmt.Sprint(v)
+			}
+			return strings.Join(parts, sep), nil
+		}
+		return "", fmt.Errorf("join: can't join %T", list)
+	},
+	// timeago formats a time relative to now, like "3 minutes ago". It
+	// accepts times, RFC 3339 strings and the millisecond timestamps of
+	// resources.
+	"timeago": func(v interface{}) (string, error) {
+		var t time.Time
+		switch v := v.(type) {
+		case time.Time:
+			t = v
+		case *time.Time:
+			if v != nil {
+				t = *v
+			}
+		case int64:
+			if v != 0 {
+				t = time.UnixMilli(v)
+			}
+		case *int64:
+			if v != nil && *v != 0 {
+				t = time.UnixMilli(*v)
+			}
+		case string:
+			if v != "" {
+				var err error
+				if t, err = time.Parse(time.RFC3339, v); err != nil {
+					return "", fmt.Errorf("timeago: %w", err)
+				}
+			}
+		default:
+			return "", fmt.Errorf("timeago: can't format %T", v)
+		}
+		if t.IsZero() {
+			return "", nil
+		}
+		return humanize.Time(t), nil
+	},
+	// color formats a value with space separated colors and bold, like
+	// "bold green". Colors are disabled with --no-color or when the output
+	// isn't a terminal.
+	"color": func(style string, v interface{}) (string, error) {
+		c := color.New()
+		for _, name := range strings.Fields(style) {
+			attr, ok := templateColors[name]
+			if !ok {
+				return "", fmt.Errorf("color: unknown color %q", name)
+			}
+			c.Add(attr)
+		}
+		return c.Sprint(v), nil
+	},
+}
+
+// ParseTemplate parses text as a Go template with the printer's template
+// functions.
+func ParseTemplate(text string) (*template.Template, error) {
+	t, err := template.New("").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	return t, nil
+}
+
+// ExecuteTemplate renders the Go template text with data to w.
+func ExecuteTemplate(w io.Writer, text string, data interface{}) error {
+	t, err := ParseTemplate(text)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, data)
+}
@@ -0,0 +1,69 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestPrintResourceTemplate(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	created := time.Now().Add(-3 * time.Hour)
+	rows := []*streamRow{{Name: "a", Count: 1}, {Name: "b", Count: 2}}
+
+	tests := []struct {
+		template string
+		data     interface{}
+		want     string
+	}{
+		{`{{range .}}{{.Name}}{{"\n"}}{{end}}`, rows, "a\nb\n"},
+		{`{{(index . 0) | json}}`, rows, `{"name":"a","count":1}`},
+		{`{{timeago .}}`, created, "3 hours ago"},
+		{`{{timeago .}}`, GetMilliseconds(created), "3 hours ago"},
+		{`{{timeago .}}`, int64(0), ""},
+		{`{{color "bold green" .Name}}`, rows[0], "a"},
+	}
+
+	for _, tt := range tests {
+		format := Template
+		var out bytes.Buffer
+		p := NewPrinter(&format)
+		p.SetResourceOutput(&out)
+		p.SetTemplate(tt.template)
+
+		if err := p.PrintResource(tt.data); err != nil {
+			t.Fatalf("%s: print resource: %v", tt.template, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.template, out.String(), tt.want)
+		}
+	}
+}
+
+func TestPrintResourceTemplateErrors(t *testing.T) {
+	tests := []struct {
+		format   Format
+		template string
+		want     string
+	}{
+		{Template, "", "--format template requires --template"},
+		{JSON, "{{.}}", "--template can't be used with --format json"},
+		{Template, `{{color "pink" .}}`, `template: :1:2: executing "" at <color "pink" .>: error calling color: color: unknown color "pink"`},
+	}
+
+	for _, tt := range tests {
+		p := NewPrinter(&tt.format)
+		p.SetResourceOutput(&bytes.Buffer{})
+		p.SetTemplate(tt.template)
+
+		err := p.PrintResource("x")
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%q: got error %v, want %q", tt.template, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// PrintYAML prints v as YAML. It has the same fields as the JSON output of
+// v, in the same order.
+func (p *Printer) PrintYAML(v interface{}) error {
+	buf, err := marshalYAML(v)
+	if err != nil {
+		return err
+	}
+	_, err = p.ResourceOutput().Write(buf)
+	return err
+}
+
+// marshalYAML returns the YAML of the JSON representation of v, so resources
+// print the API's fields whatever their format.
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	doc, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
+
+// decodeOrdered decodes the next JSON value of dec with its objects as
+// yaml.MapSlice, which keeps the order of the fields.
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			m := yaml.MapSlice{}
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, yaml.MapItem{Key: key, Value: value})
+			}
+			_, err := dec.Token()
+			return m, err
+		case '[':
+			s := []interface{}{}
+			for dec.More() {
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				s = append(s, value)
+			}
+			_, err := dec.Token()
+			return s, err
+		}
+		return nil, fmt.Errorf("unexpected JSON delimiter %s", tok)
+	case json.Number:
+		if n, err := tok.Int64(); err == nil {
+			return n, nil
+		}
+		if n, err := strconv.ParseUint(tok.String(), 10, 64); err == nil {
+			return n, nil
+		}
+		if n, err := tok.Float64(); err == nil {
+			return n, nil
+		}
+		return tok.String(), nil
+	default:
+		return tok, nil
+	}
+}
@@ -0,0 +1,267 @@
+package proxyutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics counts the connections, traffic and credential renewals of one or
+// more local proxies and serves them in the Prometheus text format.
+type Metrics struct {
+	mu      sync.Mutex
+	proxies []*ProxyMetrics
+}
+
+// NewMetrics returns an empty set of proxy metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// Proxy registers the metrics of the proxy for a database branch.
+func (m *Metrics) Proxy(database, branch string) *ProxyMetrics {
+	p := &ProxyMetrics{database: database, branch: branch}
+	m.mu.Lock()
+	m.proxies = append(m.proxies, p)
+	m.mu.Unlock()
+	return p
+}
+
+func (m *Metrics) snapshot() []*ProxyMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*ProxyMetrics(nil), m.proxies...)
+}
+
+// ProxyMetrics are the metrics of one proxy. It is safe for concurrent use.
+type ProxyMetrics struct {
+	database string
+	branch   string
+
+	open            atomic.Int64
+	accepted        atomic.Int64
+	failed          atomic.Int64
+	bytesIn         atomic.Int64
+	bytesOut        atomic.Int64
+	renewals        atomic.Int64
+	renewalFailures atomic.Int64
+	primaryStmts    atomic.Int64
+	replicaStmts    atomic.Int64
+
+	mu       sync.Mutex
+	probed   bool
+	probeErr error
+	// renewErr is the error of the last credential renewal.
+	renewErr error
+}
+
+// Listener wraps l to count the connections it accepts and their traffic.
+func (p *ProxyMetrics) Listener(l net.Listener) net.Listener {
+	return &metricsListener{Listener: l, metrics: p}
+}
+
+// ConnectionFailed records a client connection that couldn't be relayed
+// upstream.
+func (p *ProxyMetrics) ConnectionFailed() {
+	p.failed.Add(1)
+}
+
+// Renewed records a credential renewal attempt and its outcome. Until a
+// later renewal succeeds, a failed one is reported by /healthz.
+func (p *ProxyMetrics) Renewed(err error) {
+	p.mu.Lock()
+	p.renewErr = err
+	p.mu.Unlock()
+	if err != nil {
+		p.renewalFailures.Add(1)
+		return
+	}
+	p.renewals.Add(1)
+}
+
+// Routed records a statement a SplitProxy sent to the primary or the
+// replica.
+func (p *ProxyMetrics) Routed(replica bool) {
+	if replica {
+		p.replicaStmts.Add(1)
+		return
+	}
+	p.primaryStmts.Add(1)
+}
+
+// Probe dials the upstream address every interval until ctx is done. The
+// outcome of the last dial is reported by /healthz and as
+// pscale_connect_upstream_up.
+func (p *ProxyMetrics) Probe(ctx context.Context, addr string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		var d net.Dialer
+		conn, err := d.DialContext(dialCtx, "tcp", addr)
+		cancel()
+		if err == nil {
+			conn.Close()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.mu.Lock()
+		p.probed, p.probeErr = true, err
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// upstream returns nil when the last probe reached the upstream address.
+func (p *ProxyMetrics) upstream() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.probed {
+		return fmt.Errorf("upstream not probed yet")
+	}
+	return p.probeErr
+}
+
+// health returns nil when the last probe reached the upstream address and
+// the last credential renewal, if any, succeeded.
+func (p *ProxyMetrics) health() error {
+	if err := p.upstream(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.renewErr != nil {
+		return fmt.Errorf("renewing credentials: %w", p.renewErr)
+	}
+	return nil
+}
+
+// Handler serves the metrics at /metrics and the health of the proxies at
+// /healthz. /healthz answers 503 while any upstream is unreachable or its
+// credentials failed to renew.
+func (m *Metrics) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.write(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		var failures []string
+		for _, p := range m.snapshot() {
+			if err := p.health(); err != nil {
+				failures = append(failures, fmt.Sprintf("%s/%s: %s", p.database, p.branch, err))
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if len(failures) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, strings.Join(failures, "\n"))
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+var metricDescs = []struct {
+	name  string
+	typ   string
+	help  string
+	value func(p *ProxyMetrics) int64
+}{
+	{"pscale_connect_open_connections", "gauge", "Client connections currently open.",
+		func(p *ProxyMetrics) int64 { return p.open.Load() }},
+	{"pscale_connect_accepted_connections_total", "counter", "Client connections accepted.",
+		func(p *ProxyMetrics) int64 { return p.accepted.Load() }},
+	{"pscale_connect_failed_connections_total", "counter", "Client connections that couldn't be relayed upstream.",
+		func(p *ProxyMetrics) int64 { return p.failed.Load() }},
+	{"pscale_connect_received_bytes_total", "counter", "Bytes received from clients.",
+		func(p *ProxyMetrics) int64 { return p.bytesIn.Load() }},
+	{"pscale_connect_sent_bytes_total", "counter", "Bytes sent to clients.",
+		func(p *ProxyMetrics) int64 { return p.bytesOut.Load() }},
+	{"pscale_connect_credential_renewals_total", "counter", "Successful renewals of the upstream credentials.",
+		func(p *ProxyMetrics) int64 { return p.renewals.Load() }},
+	{"pscale_connect_credential_renewal_failures_total", "counter", "Failed renewals of the upstream credentials.",
+		func(p *ProxyMetrics) int64 { return p.renewalFailures.Load() }},
+	{"pscale_connect_primary_statements_total", "counter", "Statements sent to the primary by read/write splitting.",
+		func(p *ProxyMetrics) int64 { return p.primaryStmts.Load() }},
+	{"pscale_connect_replica_statements_total", "counter", "Statements sent to replicas by read/write splitting.",
+		func(p *ProxyMetrics) int64 { return p.replicaStmts.Load() }},
+	{"pscale_connect_upstream_up", "gauge", "Whether the last probe reached the upstream address.",
+		func(p *ProxyMetrics) int64 {
+			if p.upstream() != nil {
+				return 0
+			}
+			return 1
+		}},
+}
+
+func (m *Metrics) write(w io.Writer) {
+	proxies := m.snapshot()
+	for _, desc := range metricDescs {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", desc.name, desc.help, desc.name, desc.typ)
+		for _, p := range proxies {
+			fmt.Fprintf(w, "%s{database=\"%s\",branch=\"%s\"} %d\n",
+				desc.name, escapeLabel(p.database), escapeLabel(p.branch), desc.value(p))
+		}
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+type metricsListener struct {
+	net.Listener
+	metrics *ProxyMetrics
+}
+
+func (l *metricsListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.metrics.accepted.Add(1)
+	l.metrics.open.Add(1)
+	return &metricsConn{Conn: conn, metrics: l.metrics}, nil
+}
+
+type metricsConn struct {
+	net.Conn
+	metrics *ProxyMetrics
+	closed  sync.Once
+}
+
+func (c *metricsConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.metrics.bytesIn.Add(int64(n))
+	return n, err
+}
+
+func (c *metricsConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.metrics.bytesOut.Add(int64(n))
+	return n, err
+}
+
+func (c *metricsConn) Close() error {
+	c.closed.Do(func() { c.metrics.open.Add(-1) })
+	return c.Conn.Close()
+}
@@ -0,0 +1,144 @@
+package proxyutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestMetricsListener(t *testing.T) {
+	c := qt.New(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+
+	metrics := NewMetrics()
+	pm := metrics.Proxy("app", "main")
+	ml := pm.Listener(l)
+	defer ml.Close()
+
+	go func() {
+		conn, err := ml.Accept()
+		if err != nil {
+			return
+		}
+		buf := make([]byte, 5)
+		io.ReadFull(conn, buf)     // nolint:errcheck
+		conn.Write([]byte("pong")) // nolint:errcheck
+		conn.Close()
+		conn.Close()
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	c.Assert(err, qt.IsNil)
+	_, err = conn.Write([]byte("hello"))
+	c.Assert(err, qt.IsNil)
+	reply, err := io.ReadAll(conn)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(reply), qt.Equals, "pong")
+	conn.Close()
+
+	pm.ConnectionFailed()
+	pm.Renewed(nil)
+	pm.Renewed(nil)
+	pm.Renewed(errors.New("boom"))
+
+	c.Assert(pm.accepted.Load(), qt.Equals, int64(1))
+	c.Assert(pm.open.Load(), qt.Equals, int64(0))
+	c.Assert(pm.bytesIn.Load(), qt.Equals, int64(5))
+	c.Assert(pm.bytesOut.Load(), qt.Equals, int64(4))
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE pscale_connect_open_connections gauge",
+		`pscale_connect_open_connections{database="app",branch="main"} 0`,
+		`pscale_connect_accepted_connections_total{database="app",branch="main"} 1`,
+		`pscale_connect_failed_connections_total{database="app",branch="main"} 1`,
+		`pscale_connect_received_bytes_total{database="app",branch="main"} 5`,
+		`pscale_connect_sent_bytes_total{database="app",branch="main"} 4`,
+		`pscale_connect_credential_renewals_total{database="app",branch="main"} 2`,
+		`pscale_connect_credential_renewal_failures_total{database="app",branch="main"} 1`,
+		`pscale_connect_upstream_up{database="app",branch="main"} 0`,
+	} {
+		c.Assert(strings.Contains(body, line+"\n"), qt.IsTrue, qt.Commentf("missing %q in\n%s", line, body))
+	}
+}
+
+func TestMetricsHealth(t *testing.T) {
+	c := qt.New(t)
+
+	up, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	defer up.Close()
+	down, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	downAddr := down.Addr().String()
+	down.Close()
+
+	metrics := NewMetrics()
+	healthz := func() (int, string) {
+		rec := httptest.NewRecorder()
+		metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	healthy := metrics.Proxy("app", "main")
+	code, body := healthz()
+	c.Assert(code, qt.Equals, http.StatusServiceUnavailable)
+	c.Assert(body, qt.Equals, "app/main: upstream not probed yet\n")
+
+	go healthy.Probe(ctx, up.Addr().String(), time.Hour)
+	waitProbed(c, healthy)
+	code, body = healthz()
+	c.Assert(code, qt.Equals, http.StatusOK)
+	c.Assert(body, qt.Equals, "ok\n")
+
+	unhealthy := metrics.Proxy("analytics", `dev"1`)
+	go unhealthy.Probe(ctx, downAddr, time.Hour)
+	waitProbed(c, unhealthy)
+	code, body = healthz()
+	c.Assert(code, qt.Equals, http.StatusServiceUnavailable)
+	c.Assert(body, qt.Matches, `analytics/dev"1: dial tcp .*\n`)
+
+	// A failed credential renewal makes a reachable upstream unhealthy
+	// until the next renewal succeeds.
+	healthy.Renewed(errors.New("boom"))
+	_, body = healthz()
+	c.Assert(body, qt.Matches, `(?s)app/main: renewing credentials: boom\n.*`)
+	healthy.Renewed(nil)
+	_, body = healthz()
+	c.Assert(body, qt.Not(qt.Contains), "app/main")
+
+	var out strings.Builder
+	metrics.write(&out)
+	c.Assert(strings.Contains(out.String(), `pscale_connect_upstream_up{database="app",branch="main"} 1`+"\n"), qt.IsTrue)
+	c.Assert(strings.Contains(out.String(), `pscale_connect_upstream_up{database="analytics",branch="dev\"1"} 0`+"\n"), qt.IsTrue)
+}
+
+func waitProbed(c *qt.C, p *ProxyMetrics) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p.mu.Lock()
+		probed := p.probed
+		p.mu.Unlock()
+		if probed {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Fatal("upstream not probed in time")
+}
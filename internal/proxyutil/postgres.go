@@ -0,0 +1,326 @@
+package proxyutil
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"go.uber.org/zap"
+)
+
+// PostgresCredentials are the upstream address and role a PostgresProxy
+// authenticates with.
+type PostgresCredentials struct {
+	// Addr is the upstream host and port. The port defaults to 5432.
+	Addr     string
+	Username string
+	Password string
+}
+
+// PostgresConfig configures a PostgresProxy.
+type PostgresConfig struct {
+	Logger *zap.Logger
+	// Credentials is called for every new client connection, so callers can
+	// replace an expiring role while the proxy runs.
+	Credentials func() PostgresCredentials
+	// Database is used when a client doesn't ask for a database.
+	Database string
+	// ConnectFailed, if set, is called when a client connection couldn't be
+	// relayed upstream.
+	ConnectFailed func(err error)
+	// RootCAs verifies the upstream server's certificate. Nil uses the
+	// system roots.
+	RootCAs *x509.CertPool
+}
+
+// PostgresProxy accepts plaintext PostgreSQL connections without
+// authentication and relays them to the upstream server over TLS, logged in
+// with the configured credentials. Clients are expected to be local, like the
+// MySQL proxy.
+type PostgresProxy struct {
+	cfg PostgresConfig
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	conns     map[net.Conn]struct{}
+	// backends maps the cancel keys handed to clients to the upstream
+	// connection settings, so cancel requests can be forwarded.
+	backends map[cancelKey]*pgconn.Config
+	closed   bool
+}
+
+type cancelKey struct {
+	pid    uint32
+	secret uint32
+}
+
+// NewPostgres returns a proxy for PostgreSQL connections.
+func NewPostgres(cfg PostgresConfig) *PostgresProxy {
+	if cfg.Database == "" {
+		cfg.Database = "postgres"
+	}
+	return &PostgresProxy{
+		cfg:      cfg,
+		conns:    map[net.Conn]struct{}{},
+		backends: map[cancelKey]*pgconn.Config{},
+	}
+}
+
+// Serve accepts connections on l until the listener or the proxy is closed.
+func (p *PostgresProxy) Serve(l net.Listener) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return net.ErrClosed
+	}
+	p.listeners = append(p.listeners, l)
+	p.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			p.mu.Lock()
+			closed := p.closed
+			p.mu.Unlock()
+			if closed || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go p.handle(conn)
+	}
+}
+
+// Close stops accepting connections and closes all open ones.
+func (p *PostgresProxy) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for _, l := range p.listeners {
+		l.Close()
+	}
+	for c := range p.conns {
+		c.Close()
+	}
+	return nil
+}
+
+func (p *PostgresProxy) track(c net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.conns[c] = struct{}{}
+	return true
+}
+
+func (p *PostgresProxy) untrack(c net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.conns, c)
+}
+
+func (p *PostgresProxy) handle(client net.Conn) {
+	defer client.Close()
+	if !p.track(client) {
+		return
+	}
+	defer p.untrack(client)
+
+	backend := pgproto3.NewBackend(client, client)
+	startup, err := p.receiveStartup(client, backend)
+	if err != nil || startup == nil {
+		if err != nil {
+			p.cfg.Logger.Debug("reading startup message", zap.Error(err))
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	upstream, err := p.connect(ctx, startup)
+	if err != nil {
+		p.cfg.Logger.Debug("connecting upstream", zap.Error(err))
+		if p.cfg.ConnectFailed != nil {
+			p.cfg.ConnectFailed(err)
+		}
+		sendError(backend, err)
+		return
+	}
+	defer upstream.Conn.Close()
+	if !p.track(upstream.Conn) {
+		return
+	}
+	defer p.untrack(upstream.Conn)
+
+	key := cancelKey{pid: upstream.PID, secret: upstream.SecretKey}
+	p.mu.Lock()
+	p.backends[key] = upstream.Config
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.backends, key)
+		p.mu.Unlock()
+	}()
+
+	// The upstream handshake is done; replay its outcome to the client.
+	backend.Send(&pgproto3.AuthenticationOk{})
+	for name, value := range upstream.ParameterStatuses {
+		backend.Send(&pgproto3.ParameterStatus{Name: name, Value: value})
+	}
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: upstream.PID, SecretKey: upstream.SecretKey})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: upstream.TxStatus})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream.Conn, client) // nolint:errcheck
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, upstream.Conn) // nolint:errcheck
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// receiveStartup reads the client's startup message, declining TLS and GSS
+// encryption. It returns nil after handling a cancel request.
+func (p *PostgresProxy) receiveStartup(client net.Conn, backend *pgproto3.Backend) (*pgproto3.StartupMessage, error) {
+	for {
+		msg, err := backend.ReceiveStartupMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.SSLRequest, *pgproto3.GSSEncRequest:
+			// The local side is plaintext; the upstream side is always TLS.
+			if _, err := client.Write([]byte{'N'}); err != nil {
+				return nil, err
+			}
+		case *pgproto3.CancelRequest:
+			p.forwardCancel(msg)
+			return nil, nil
+		case *pgproto3.StartupMessage:
+			return msg, nil
+		default:
+			return nil, fmt.Errorf("unexpected startup message %T", msg)
+		}
+	}
+}
+
+func (p *PostgresProxy) connect(ctx context.Context, startup *pgproto3.StartupMessage) (*pgconn.HijackedConn, error) {
+	creds := p.cfg.Credentials()
+
+	host, port, err := net.SplitHostPort(creds.Addr)
+	if err != nil {
+		host, port = creds.Addr, "5432"
+	}
+
+	database := startup.Parameters["database"]
+	if database == "" {
+		database = p.cfg.Database
+	}
+
+	u := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(creds.Username, creds.Password),
+		Host:     net.JoinHostPort(host, port),
+		Path:     "/" + database,
+		RawQuery: "sslmode=verify-full",
+	}
+	config, err := pgconn.ParseConfig(u.String())
+	if err != nil {
+		return nil, err
+	}
+	if p.cfg.RootCAs != nil {
+		config.TLSConfig.RootCAs = p.cfg.RootCAs
+	}
+	for name, value := range startup.Parameters {
+		switch name {
+		case "user", "database", "replication":
+		default:
+			config.RuntimeParams[name] = value
+		}
+	}
+
+	conn, err := pgconn.ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SyncConn(ctx); err != nil {
+		conn.Close(ctx) // nolint:errcheck
+		return nil, err
+	}
+	return conn.Hijack()
+}
+
+// forwardCancel relays a client's cancel request to the upstream server that
+// runs its query.
+func (p *PostgresProxy) forwardCancel(req *pgproto3.CancelRequest) {
+	p.mu.Lock()
+	config := p.backends[cancelKey{pid: req.ProcessID, secret: req.SecretKey}]
+	p.mu.Unlock()
+	if config == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	network, addr := pgconn.NetworkAddress(config.Host, config.Port)
+	conn, err := config.DialFunc(ctx, network, addr)
+	if err != nil {
+		p.cfg.Logger.Debug("forwarding cancel request", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint32(buf[0:4], 16)
+	binary.BigEndian.PutUint32(buf[4:8], 80877102)
+	binary.BigEndian.PutUint32(buf[8:12], req.ProcessID)
+	binary.BigEndian.PutUint32(buf[12:16], req.SecretKey)
+	if _, err := conn.Write(buf); err != nil {
+		p.cfg.Logger.Debug("forwarding cancel request", zap.Error(err))
+		return
+	}
+	// The server closes the connection once it has read the request.
+	io.Copy(io.Discard, conn) // nolint:errcheck
+}
+
+// sendError reports a failed upstream connection to the client, passing on
+// the server's error when there is one.
+func sendError(backend *pgproto3.Backend, err error) {
+	resp := &pgproto3.ErrorResponse{
+		Severity: "FATAL",
+		Code:     "08006", // connection_failure
+		Message:  fmt.Sprintf("pscale connect: %s", err),
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		resp = &pgproto3.ErrorResponse{
+			Severity: pgErr.Severity,
+			Code:     pgErr.Code,
+			Message:  pgErr.Message,
+			Detail:   pgErr.Detail,
+			Hint:     pgErr.Hint,
+		}
+	}
+	backend.Send(resp)
+	backend.Flush() // nolint:errcheck
+}
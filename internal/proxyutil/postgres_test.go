@@ -0,0 +1,235 @@
+package proxyutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"go.uber.org/zap"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakePostgres is an upstream server that requires TLS and a cleartext
+// password, and answers every query with a single row.
+type fakePostgres struct {
+	l   net.Listener
+	tls *tls.Config
+	// roots trusts the server's self-signed certificate.
+	roots   *x509.CertPool
+	startup chan map[string]string
+	auth    chan string
+}
+
+func newFakePostgres(t *testing.T) *fakePostgres {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	f := &fakePostgres{
+		l:       l,
+		tls:     &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}},
+		roots:   roots,
+		startup: make(chan map[string]string, 1),
+		auth:    make(chan string, 1),
+	}
+	go f.serve()
+	return f
+}
+
+func (f *fakePostgres) serve() {
+	conn, err := f.l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	msg, err := pgproto3.NewBackend(conn, conn).ReceiveStartupMessage()
+	if _, ok := msg.(*pgproto3.SSLRequest); err != nil || !ok {
+		return
+	}
+	if _, err := conn.Write([]byte{'S'}); err != nil {
+		return
+	}
+	tlsConn := tls.Server(conn, f.tls)
+	backend := pgproto3.NewBackend(tlsConn, tlsConn)
+
+	msg, err = backend.ReceiveStartupMessage()
+	startup, ok := msg.(*pgproto3.StartupMessage)
+	if err != nil || !ok {
+		return
+	}
+	f.startup <- startup.Parameters
+
+	backend.Send(&pgproto3.AuthenticationCleartextPassword{})
+	if backend.Flush() != nil {
+		return
+	}
+	if err := backend.SetAuthType(pgproto3.AuthTypeCleartextPassword); err != nil {
+		return
+	}
+	msg, err = backend.Receive()
+	pw, ok := msg.(*pgproto3.PasswordMessage)
+	if err != nil || !ok {
+		return
+	}
+	f.auth <- pw.Password
+
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ParameterStatus{Name: "server_version", Value: "17.2"})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 42, SecretKey: 7})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if backend.Flush() != nil {
+		return
+	}
+
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		switch msg.(type) {
+		case *pgproto3.Query:
+			backend.Send(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{{Name: []byte("answer"), DataTypeOID: 25, Format: 0}}})
+			backend.Send(&pgproto3.DataRow{Values: [][]byte{[]byte("42")}})
+			backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if backend.Flush() != nil {
+				return
+			}
+		case *pgproto3.Terminate:
+			return
+		}
+	}
+}
+
+func TestPostgresProxy(t *testing.T) {
+	c := qt.New(t)
+
+	upstream := newFakePostgres(t)
+	proxy := NewPostgres(PostgresConfig{
+		Logger: zap.NewNop(),
+		Credentials: func() PostgresCredentials {
+			return PostgresCredentials{Addr: upstream.l.Addr().String(), Username: "pscale_api_role", Password: "secret"}
+		},
+		Database: "postgres",
+		RootCAs:  upstream.roots,
+	})
+	defer proxy.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	go proxy.Serve(l) // nolint:errcheck
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Clients connect without TLS and with any user.
+	conn, err := pgconn.Connect(ctx, "postgres://someone:ignored@"+l.Addr().String()+"/app?sslmode=prefer&application_name=test")
+	c.Assert(err, qt.IsNil)
+	defer conn.Close(ctx)
+
+	params := <-upstream.startup
+	c.Assert(params["user"], qt.Equals, "pscale_api_role")
+	c.Assert(params["database"], qt.Equals, "app")
+	c.Assert(params["application_name"], qt.Equals, "test")
+	c.Assert(<-upstream.auth, qt.Equals, "secret")
+
+	c.Assert(conn.ParameterStatus("server_version"), qt.Equals, "17.2")
+	c.Assert(conn.PID(), qt.Equals, uint32(42))
+
+	results, err := conn.Exec(ctx, "SELECT 42 AS answer").ReadAll()
+	c.Assert(err, qt.IsNil)
+	c.Assert(results, qt.HasLen, 1)
+	c.Assert(string(results[0].Rows[0][0]), qt.Equals, "42")
+}
+
+func TestPostgresProxyVerifiesUpstream(t *testing.T) {
+	c := qt.New(t)
+
+	// The upstream's self-signed certificate isn't in the system roots.
+	upstream := newFakePostgres(t)
+	pm := NewMetrics().Proxy("app", "main")
+	proxy := NewPostgres(PostgresConfig{
+		Logger: zap.NewNop(),
+		Credentials: func() PostgresCredentials {
+			return PostgresCredentials{Addr: upstream.l.Addr().String(), Username: "u", Password: "p"}
+		},
+		ConnectFailed: func(error) { pm.ConnectionFailed() },
+	})
+	defer proxy.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	go proxy.Serve(l) // nolint:errcheck
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = pgconn.Connect(ctx, "postgres://someone@"+l.Addr().String()+"/postgres?sslmode=disable")
+	c.Assert(err, qt.ErrorMatches, `.*certificate.*`)
+	c.Assert(pm.failed.Load(), qt.Equals, int64(1))
+}
+
+func TestPostgresProxyUpstreamError(t *testing.T) {
+	c := qt.New(t)
+
+	// Nothing listens on the upstream address.
+	unused, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	addr := unused.Addr().String()
+	unused.Close()
+
+	pm := NewMetrics().Proxy("app", "main")
+	proxy := NewPostgres(PostgresConfig{
+		Logger: zap.NewNop(),
+		Credentials: func() PostgresCredentials {
+			return PostgresCredentials{Addr: addr, Username: "u", Password: "p"}
+		},
+		ConnectFailed: func(error) { pm.ConnectionFailed() },
+	})
+	defer proxy.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	go proxy.Serve(l) // nolint:errcheck
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = pgconn.Connect(ctx, "postgres://someone@"+l.Addr().String()+"/postgres?sslmode=disable")
+	c.Assert(err, qt.ErrorMatches, `.*pscale connect: .*`)
+	c.Assert(pm.failed.Load(), qt.Equals, int64(1))
+}
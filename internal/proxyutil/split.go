@@ -0,0 +1,528 @@
+package proxyutil
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/planetscale/cli/internal/querylog"
+	"go.uber.org/zap"
+)
+
+// MySQL commands the split proxy looks at, see
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_command_phase.html
+const (
+	comQuit            = 0x01
+	comInitDB          = 0x02
+	comQuery           = 0x03
+	comChangeUser      = 0x11
+	comStmtExecute     = 0x17
+	comResetConnection = 0x1f
+)
+
+// SplitConfig configures a SplitProxy.
+type SplitConfig struct {
+	Logger *zap.Logger
+	// Primary and Replica are the addresses of MySQL servers that accept any
+	// credentials, such as psdbproxy servers logged in with a primary and a
+	// replica password.
+	Primary string
+	Replica string
+	// Routed, if set, is called for every statement with whether it was sent
+	// to the replica.
+	Routed func(replica bool)
+	// ConnectFailed, if set, is called when a client connection couldn't be
+	// relayed to the primary or the replica.
+	ConnectFailed func(err error)
+}
+
+// SplitProxy relays MySQL connections to a primary and a replica server.
+// Every client connection is backed by one connection to each. Autocommit
+// SELECT statements outside transactions go to the replica and everything
+// else to the primary. SET and USE statements are sent to both, so the two
+// sessions agree on variables and the default database.
+type SplitProxy struct {
+	cfg SplitConfig
+
+	primary atomic.Int64
+	replica atomic.Int64
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// NewSplit returns a read/write splitting proxy.
+func NewSplit(cfg SplitConfig) *SplitProxy {
+	return &SplitProxy{cfg: cfg, conns: map[net.Conn]struct{}{}}
+}
+
+// Stats returns the number of statements sent to the primary and to the
+// replica.
+func (p *SplitProxy) Stats() (primary, replica int64) {
+	return p.primary.Load(), p.replica.Load()
+}
+
+// Serve accepts connections on l until the listener or the proxy is closed.
+func (p *SplitProxy) Serve(l net.Listener) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return net.ErrClosed
+	}
+	p.listeners = append(p.listeners, l)
+	p.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			p.mu.Lock()
+			closed := p.closed
+			p.mu.Unlock()
+			if closed || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go p.handle(conn)
+	}
+}
+
+// Close stops accepting connections and closes all open ones.
+func (p *SplitProxy) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for _, l := range p.listeners {
+		l.Close()
+	}
+	for c := range p.conns {
+		c.Close()
+	}
+	return nil
+}
+
+func (p *SplitProxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	for _, c := range conns {
+		p.conns[c] = struct{}{}
+	}
+	return true
+}
+
+func (p *SplitProxy) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range conns {
+		delete(p.conns, c)
+	}
+}
+
+func (p *SplitProxy) connectFailed(err error) {
+	if p.cfg.ConnectFailed != nil {
+		p.cfg.ConnectFailed(err)
+	}
+}
+
+func (p *SplitProxy) routed(replica bool) {
+	if replica {
+		p.replica.Add(1)
+	} else {
+		p.primary.Add(1)
+	}
+	if p.cfg.Routed != nil {
+		p.cfg.Routed(replica)
+	}
+}
+
+func (p *SplitProxy) handle(client net.Conn) {
+	defer client.Close()
+
+	d := net.Dialer{Timeout: 10 * time.Second}
+	primary, err := d.Dial("tcp", p.cfg.Primary)
+	if err != nil {
+		p.cfg.Logger.Debug("connecting to primary", zap.Error(err))
+		p.connectFailed(err)
+		return
+	}
+	defer primary.Close()
+	replica, err := d.Dial("tcp", p.cfg.Replica)
+	if err != nil {
+		p.cfg.Logger.Debug("connecting to replica", zap.Error(err))
+		p.connectFailed(err)
+		return
+	}
+	defer replica.Close()
+
+	if !p.track(client, primary, replica) {
+		return
+	}
+	defer p.untrack(client, primary, replica)
+
+	s := &splitSession{
+		proxy:        p,
+		client:       client,
+		primary:      primary,
+		replica:      replica,
+		replicaReady: make(chan struct{}),
+		handshake:    make(chan []byte, 1),
+		mirrored:     make(chan []byte, 1),
+		autocommit:   true,
+	}
+	s.run()
+}
+
+// splitSession is one client connection of a SplitProxy. The server
+// connections are read by pumps that copy packets to the client, while run
+// reads the client's packets and picks the server for each command. MySQL
+// clients wait for the response to a command before sending the next one,
+// so only one server answers at a time.
+type splitSession struct {
+	proxy   *SplitProxy
+	client  net.Conn
+	primary net.Conn
+	replica net.Conn
+
+	// writeMu serializes writes to the client.
+	writeMu sync.Mutex
+
+	// replicaReady is closed when the replica handshake is over. replicaOK
+	// tells whether it succeeded.
+	replicaReady chan struct{}
+	replicaOK    bool
+	// handshake is the client's handshake response, replayed to the replica.
+	handshake chan []byte
+	// mirroring is set while the replica runs a command whose response goes
+	// to mirrored instead of the client.
+	mirroring atomic.Bool
+	mirrored  chan []byte
+
+	// target is the server that gets the client's follow-up packets, such as
+	// LOCAL INFILE data.
+	target net.Conn
+
+	inTransaction bool
+	autocommit    bool
+	locked        bool
+	// primaryOnly is set when the replica session can no longer follow the
+	// primary one, e.g. after COM_CHANGE_USER or a failed mirrored command.
+	// From then on the replica's packets are dropped.
+	primaryOnly atomic.Bool
+}
+
+func (s *splitSession) run() {
+	done := make(chan struct{}, 2)
+	go func() {
+		s.pumpPrimary()
+		done <- struct{}{}
+	}()
+	go func() {
+		// Without a replica the session carries on with the primary alone.
+		r := bufio.NewReader(s.replica)
+		s.replicaHandshake(r)
+		if s.replicaOK {
+			s.pumpReplica(r)
+			done <- struct{}{}
+		}
+	}()
+	go func() {
+		s.readClient()
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// pumpPrimary copies packets from the primary to the client.
+func (s *splitSession) pumpPrimary() {
+	r := bufio.NewReader(s.primary)
+	for {
+		packet, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		if err := s.writeClient(packet); err != nil {
+			return
+		}
+	}
+}
+
+// pumpReplica copies packets from the replica to the client, or to mirrored
+// while a mirrored command runs. Once the session is primary only, late
+// responses to mirrored commands are dropped rather than interleaved with
+// the primary's.
+func (s *splitSession) pumpReplica(r *bufio.Reader) {
+	for {
+		packet, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		if s.primaryOnly.Load() {
+			continue
+		}
+		if s.mirroring.Load() {
+			select {
+			case s.mirrored <- packet:
+			default:
+			}
+			continue
+		}
+		if err := s.writeClient(packet); err != nil {
+			return
+		}
+	}
+}
+
+func (s *splitSession) writeClient(packet []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err := s.client.Write(packet)
+	return err
+}
+
+// replicaHandshake logs in to the replica with the client's handshake
+// response. The replica accepts any credentials, so auth plugin switches are
+// answered with empty auth data.
+func (s *splitSession) replicaHandshake(r *bufio.Reader) {
+	defer close(s.replicaReady)
+
+	if _, err := readPacket(r); err != nil { // greeting
+		return
+	}
+	response, ok := <-s.handshake
+	if !ok {
+		return
+	}
+	if _, err := s.replica.Write(response); err != nil {
+		return
+	}
+	for {
+		packet, err := readPacket(r)
+		if err != nil || len(packet) < 5 {
+			return
+		}
+		switch packet[4] {
+		case 0x00:
+			s.replicaOK = true
+			return
+		case 0xfe:
+			// AuthSwitchRequest
+			if _, err := s.replica.Write([]byte{0, 0, 0, packet[3] + 1}); err != nil {
+				return
+			}
+		case 0x01:
+			// AuthMoreData, e.g. fast auth success
+		default:
+			s.proxy.cfg.Logger.Debug("replica handshake failed", zap.ByteString("packet", packet[4:]))
+			return
+		}
+	}
+}
+
+// readClient reads the client's packets and routes them.
+func (s *splitSession) readClient() {
+	r := bufio.NewReader(s.client)
+	s.target = s.primary
+
+	// The handshake response goes to both servers.
+	response, err := readPacket(r)
+	if err != nil {
+		close(s.handshake)
+		return
+	}
+	s.handshake <- response
+	if _, err := s.primary.Write(response); err != nil {
+		return
+	}
+
+	for {
+		packet, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		if packet[3] != 0 || len(packet) < 5 {
+			// Auth exchanges, LOCAL INFILE data and continuation packets.
+			if _, err := s.target.Write(packet); err != nil {
+				return
+			}
+			continue
+		}
+		if err := s.command(packet); err != nil {
+			return
+		}
+	}
+}
+
+// command routes a command packet.
+func (s *splitSession) command(packet []byte) error {
+	s.target = s.primary
+	payload := packet[4:]
+
+	switch payload[0] {
+	case comQuit:
+		s.replica.Write(packet) // nolint:errcheck
+	case comInitDB:
+		s.mirror(packet)
+	case comResetConnection:
+		s.mirror(packet)
+		s.inTransaction, s.autocommit, s.locked = false, true, false
+	case comChangeUser:
+		s.primaryOnly.Store(true)
+	case comStmtExecute:
+		s.proxy.routed(false)
+	case comQuery:
+		switch s.query(string(payload[1:])) {
+		case routeReplica:
+			s.target = s.replica
+			s.proxy.routed(true)
+		case routeBoth:
+			s.mirror(packet)
+			s.proxy.routed(false)
+		default:
+			s.proxy.routed(false)
+		}
+	}
+
+	_, err := s.target.Write(packet)
+	return err
+}
+
+// mirrorTimeout is how long mirror waits for the replica's response.
+var mirrorTimeout = 30 * time.Second
+
+// mirror runs a command on the replica and drops its single-packet
+// response, so it can be sent to the primary next. If the replica fails the
+// command or doesn't answer in time, its session may have diverged from the
+// primary's and the rest of the session goes to the primary.
+func (s *splitSession) mirror(packet []byte) {
+	if !s.useReplica() {
+		return
+	}
+	s.mirroring.Store(true)
+	defer s.mirroring.Store(false)
+	if _, err := s.replica.Write(packet); err != nil {
+		s.primaryOnly.Store(true)
+		return
+	}
+	select {
+	case response := <-s.mirrored:
+		if len(response) < 5 || response[4] == 0xff {
+			s.proxy.cfg.Logger.Debug("mirrored command failed on the replica", zap.ByteString("packet", response))
+			s.primaryOnly.Store(true)
+		}
+	case <-time.After(mirrorTimeout):
+		s.proxy.cfg.Logger.Debug("mirrored command timed out on the replica")
+		s.primaryOnly.Store(true)
+	}
+}
+
+// useReplica reports whether the replica session is usable, waiting for its
+// handshake if needed.
+func (s *splitSession) useReplica() bool {
+	if s.primaryOnly.Load() {
+		return false
+	}
+	<-s.replicaReady
+	return s.replicaOK
+}
+
+type route int
+
+const (
+	routePrimary route = iota
+	routeReplica
+	routeBoth
+)
+
+var (
+	autocommitOffRe = regexp.MustCompile(`autocommit\s*=\s*('?off'?|0|false)\b`)
+	autocommitOnRe  = regexp.MustCompile(`autocommit\s*=\s*('?on'?|1|true)\b`)
+
+	// replicaUnsafe are fragments of SELECT statements that lock rows, write,
+	// or depend on the session's previous statements.
+	replicaUnsafe = []string{
+		" for update", " for share", " lock in share mode", " into ", ":=",
+		"last_insert_id(", "found_rows(", "row_count(", "sql_calc_found_rows",
+		"get_lock(", "release_lock(", "release_all_locks(", "is_used_lock(", "is_free_lock(",
+	}
+)
+
+// query picks the server for a COM_QUERY statement and tracks the
+// transaction state it implies.
+func (s *splitSession) query(query string) route {
+	fp := strings.TrimLeft(querylog.Fingerprint(query), "( ")
+	if strings.Contains(fp, ";") {
+		// Multiple statements run together on the primary.
+		return routePrimary
+	}
+
+	verb, _, _ := strings.Cut(fp, " ")
+	switch verb {
+	case "select":
+		for _, fragment := range replicaUnsafe {
+			if strings.Contains(fp, fragment) {
+				return routePrimary
+			}
+		}
+		if s.inTransaction || !s.autocommit || s.locked || !s.useReplica() {
+			return routePrimary
+		}
+		return routeReplica
+	case "begin":
+		s.inTransaction = true
+	case "start":
+		if strings.HasPrefix(fp, "start transaction") {
+			s.inTransaction = true
+		}
+	case "commit":
+		s.inTransaction = false
+	case "rollback":
+		if !strings.HasPrefix(fp, "rollback to") {
+			s.inTransaction = false
+		}
+	case "lock":
+		s.locked = true
+	case "unlock":
+		s.locked = false
+	case "create", "alter", "drop", "rename", "truncate":
+		// DDL commits implicitly.
+		s.inTransaction = false
+	case "set":
+		lower := strings.ToLower(query)
+		if autocommitOffRe.MatchString(lower) {
+			s.autocommit = false
+		} else if autocommitOnRe.MatchString(lower) {
+			s.autocommit = true
+			s.inTransaction = false
+		}
+		return routeBoth
+	case "use":
+		return routeBoth
+	}
+	return routePrimary
+}
+
+// readPacket reads one MySQL packet, header included.
+func readPacket(r *bufio.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	n := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	packet := make([]byte, 4+n)
+	copy(packet, header)
+	if _, err := io.ReadFull(r, packet[4:]); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
@@ -0,0 +1,268 @@
+package proxyutil
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeMySQL accepts any login and answers every command with an OK packet
+// whose info names the server and echoes the statement.
+type fakeMySQL struct {
+	name string
+	l    net.Listener
+	// fail, if set, picks the statements answered with an ERR packet, and
+	// delay how long to wait before answering a statement.
+	fail  func(query string) bool
+	delay func(query string) time.Duration
+
+	mu      sync.Mutex
+	queries []string
+}
+
+func newFakeMySQL(c *qt.C, name string) *fakeMySQL {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	c.Cleanup(func() { l.Close() })
+
+	return &fakeMySQL{name: name, l: l}
+}
+
+func (f *fakeMySQL) start() {
+	go func() {
+		for {
+			conn, err := f.l.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+}
+
+func (f *fakeMySQL) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	greeting := append([]byte{10}, "8.0.34\x00"...)
+	conn.Write(mysqlTestPacket(0, greeting...)) // nolint:errcheck
+	if _, err := readPacket(r); err != nil {
+		return
+	}
+	// Ask for an auth switch, like caching_sha2_password clients may see.
+	conn.Write(mysqlTestPacket(2, append([]byte{0xfe}, "caching_sha2_password\x00"...)...)) // nolint:errcheck
+	if _, err := readPacket(r); err != nil {
+		return
+	}
+	conn.Write(mysqlTestPacket(4, 0x00, 0, 0, 2, 0, 0, 0)) // nolint:errcheck
+
+	for {
+		packet, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		payload := packet[4:]
+		if payload[0] == comQuit {
+			return
+		}
+		query := string(payload[1:])
+		f.mu.Lock()
+		f.queries = append(f.queries, query)
+		f.mu.Unlock()
+
+		if f.delay != nil {
+			time.Sleep(f.delay(query))
+		}
+		if f.fail != nil && f.fail(query) {
+			conn.Write(mysqlTestPacket(1, append([]byte{0xff, 0x28, 0x04, '#'}, "HY000failed"...)...)) // nolint:errcheck
+			continue
+		}
+		info := fmt.Sprintf("%s: %s", f.name, query)
+		conn.Write(mysqlTestPacket(1, append([]byte{0x00, 0, 0, 2, 0, 0, 0}, info...)...)) // nolint:errcheck
+	}
+}
+
+func (f *fakeMySQL) received() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.queries...)
+}
+
+func mysqlTestPacket(seq byte, payload ...byte) []byte {
+	n := len(payload)
+	return append([]byte{byte(n), byte(n >> 8), byte(n >> 16), seq}, payload...)
+}
+
+// startSplit starts a split proxy in front of primary and replica, logs in
+// to it and returns a function that runs a query and returns the info of its
+// OK packet.
+func startSplit(c *qt.C, cfg SplitConfig, primary, replica *fakeMySQL) (*SplitProxy, func(string) string) {
+	primary.start()
+	replica.start()
+
+	cfg.Logger = zap.NewNop()
+	cfg.Primary = primary.l.Addr().String()
+	cfg.Replica = replica.l.Addr().String()
+	proxy := NewSplit(cfg)
+	c.Cleanup(func() { proxy.Close() })
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	go proxy.Serve(l) // nolint:errcheck
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	c.Assert(err, qt.IsNil)
+	c.Cleanup(func() { conn.Close() })
+	r := bufio.NewReader(conn)
+
+	// The client sees the primary's handshake.
+	packet, err := readPacket(r)
+	c.Assert(err, qt.IsNil)
+	c.Assert(packet[4], qt.Equals, byte(10))
+	_, err = conn.Write(mysqlTestPacket(1, 0x0d, 0xa2, 0x0f, 0x01))
+	c.Assert(err, qt.IsNil)
+	packet, err = readPacket(r)
+	c.Assert(err, qt.IsNil)
+	c.Assert(packet[4], qt.Equals, byte(0xfe))
+	_, err = conn.Write(mysqlTestPacket(3))
+	c.Assert(err, qt.IsNil)
+	packet, err = readPacket(r)
+	c.Assert(err, qt.IsNil)
+	c.Assert(packet[4], qt.Equals, byte(0x00))
+
+	return proxy, func(q string) string {
+		_, err := conn.Write(mysqlTestPacket(0, append([]byte{comQuery}, q...)...))
+		c.Assert(err, qt.IsNil)
+		packet, err := readPacket(r)
+		c.Assert(err, qt.IsNil)
+		return string(packet[4+7:])
+	}
+}
+
+func TestSplitProxy(t *testing.T) {
+	c := qt.New(t)
+
+	primary := newFakeMySQL(c, "primary")
+	replica := newFakeMySQL(c, "replica")
+
+	var routed []bool
+	var routedMu sync.Mutex
+	proxy, query := startSplit(c, SplitConfig{
+		Routed: func(replica bool) {
+			routedMu.Lock()
+			routed = append(routed, replica)
+			routedMu.Unlock()
+		},
+	}, primary, replica)
+	c.Assert(query("SELECT * FROM t"), qt.Equals, "replica: SELECT * FROM t")
+	c.Assert(query("/* app */ (SELECT 1)"), qt.Equals, "replica: /* app */ (SELECT 1)")
+	c.Assert(query("SELECT * FROM t FOR UPDATE"), qt.Equals, "primary: SELECT * FROM t FOR UPDATE")
+	c.Assert(query("SELECT LAST_INSERT_ID()"), qt.Equals, "primary: SELECT LAST_INSERT_ID()")
+	c.Assert(query("INSERT INTO t VALUES (1)"), qt.Equals, "primary: INSERT INTO t VALUES (1)")
+	c.Assert(query("SET NAMES utf8mb4"), qt.Equals, "primary: SET NAMES utf8mb4")
+	c.Assert(query("BEGIN"), qt.Equals, "primary: BEGIN")
+	c.Assert(query("SELECT * FROM t"), qt.Equals, "primary: SELECT * FROM t")
+	c.Assert(query("COMMIT"), qt.Equals, "primary: COMMIT")
+	c.Assert(query("SET autocommit = 0"), qt.Equals, "primary: SET autocommit = 0")
+	c.Assert(query("SELECT 2"), qt.Equals, "primary: SELECT 2")
+	c.Assert(query("SET autocommit=ON"), qt.Equals, "primary: SET autocommit=ON")
+	c.Assert(query("select 3"), qt.Equals, "replica: select 3")
+	c.Assert(query("SELECT 1; DELETE FROM t"), qt.Equals, "primary: SELECT 1; DELETE FROM t")
+
+	c.Assert(replica.received(), qt.DeepEquals, []string{
+		"SELECT * FROM t",
+		"/* app */ (SELECT 1)",
+		"SET NAMES utf8mb4",
+		"SET autocommit = 0",
+		"SET autocommit=ON",
+		"select 3",
+	})
+	c.Assert(primary.received(), qt.HasLen, 11)
+
+	p, rep := proxy.Stats()
+	c.Assert(p, qt.Equals, int64(11))
+	c.Assert(rep, qt.Equals, int64(3))
+	routedMu.Lock()
+	c.Assert(routed, qt.HasLen, 14)
+	routedMu.Unlock()
+}
+
+func TestSplitProxyMirrorError(t *testing.T) {
+	c := qt.New(t)
+
+	primary := newFakeMySQL(c, "primary")
+	replica := newFakeMySQL(c, "replica")
+	replica.fail = func(query string) bool { return query == "USE missing" }
+	_, query := startSplit(c, SplitConfig{}, primary, replica)
+
+	c.Assert(query("SELECT 1"), qt.Equals, "replica: SELECT 1")
+	c.Assert(query("USE missing"), qt.Equals, "primary: USE missing")
+	// The replica session is no longer in step with the primary's.
+	c.Assert(query("SELECT 2"), qt.Equals, "primary: SELECT 2")
+	c.Assert(replica.received(), qt.DeepEquals, []string{"SELECT 1", "USE missing"})
+}
+
+func TestSplitProxyMirrorTimeout(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(&mirrorTimeout, 50*time.Millisecond)
+
+	primary := newFakeMySQL(c, "primary")
+	replica := newFakeMySQL(c, "replica")
+	replica.delay = func(query string) time.Duration {
+		if query == "SET NAMES utf8mb4" {
+			return 200 * time.Millisecond
+		}
+		return 0
+	}
+	_, query := startSplit(c, SplitConfig{}, primary, replica)
+
+	c.Assert(query("SET NAMES utf8mb4"), qt.Equals, "primary: SET NAMES utf8mb4")
+	c.Assert(query("SELECT 1"), qt.Equals, "primary: SELECT 1")
+
+	// The replica's late response must not reach the client.
+	time.Sleep(300 * time.Millisecond)
+	c.Assert(query("SELECT 2"), qt.Equals, "primary: SELECT 2")
+	c.Assert(replica.received(), qt.DeepEquals, []string{"SET NAMES utf8mb4"})
+}
+
+func TestSplitProxyConnectFailed(t *testing.T) {
+	c := qt.New(t)
+
+	primary := newFakeMySQL(c, "primary")
+	primary.start()
+	// Nothing listens on the replica address.
+	unused, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	unused.Close()
+
+	failed := make(chan error, 1)
+	proxy := NewSplit(SplitConfig{
+		Logger:        zap.NewNop(),
+		Primary:       primary.l.Addr().String(),
+		Replica:       unused.Addr().String(),
+		ConnectFailed: func(err error) { failed <- err },
+	})
+	defer proxy.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, qt.IsNil)
+	go proxy.Serve(l) // nolint:errcheck
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	c.Assert(err, qt.IsNil)
+	defer conn.Close()
+
+	select {
+	case err := <-failed:
+		c.Assert(err, qt.IsNotNil)
+	case <-time.After(10 * time.Second):
+		c.Fatal("ConnectFailed wasn't called")
+	}
+}
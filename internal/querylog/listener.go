@@ -0,0 +1,79 @@
+package querylog
+
+import (
+	"net"
+	"sync"
+)
+
+// Target identifies the database branch behind a proxy in log entries.
+type Target struct {
+	Database string
+	Branch   string
+}
+
+// protocolTap follows one client connection's traffic and logs the
+// statements it sees.
+type protocolTap interface {
+	// fromClient is called with bytes the client sent.
+	fromClient(b []byte)
+	// toClient is called with bytes sent to the client.
+	toClient(b []byte)
+}
+
+// Listener wraps l so the traffic of every accepted connection is decoded
+// and its statements logged. engine is "mysql" or "postgresql" and selects
+// the wire protocol. The proxy behind the listener must talk to clients
+// without TLS, which is the case for pscale connect.
+func Listener(l net.Listener, engine string, log *Logger, target Target) net.Listener {
+	return &listener{Listener: l, engine: engine, log: log, target: target}
+}
+
+type listener struct {
+	net.Listener
+	engine string
+	log    *Logger
+	target Target
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	id := l.log.nextConn()
+	var tap protocolTap
+	if l.engine == "mysql" {
+		tap = newMySQLTap(l.log, l.target, id)
+	} else {
+		tap = newPostgresTap(l.log, l.target, id)
+	}
+	return &tapConn{Conn: conn, tap: tap}, nil
+}
+
+// tapConn passes the bytes of a connection to a protocolTap. Reads and
+// writes may happen on different goroutines, so calls into the tap are
+// serialized.
+type tapConn struct {
+	net.Conn
+
+	mu  sync.Mutex
+	tap protocolTap
+}
+
+func (c *tapConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.mu.Lock()
+		c.tap.fromClient(b[:n])
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+func (c *tapConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	c.tap.toClient(b)
+	c.mu.Unlock()
+	return c.Conn.Write(b)
+}
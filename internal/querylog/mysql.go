@@ -0,0 +1,343 @@
+package querylog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// MySQL protocol constants, see
+// https://dev.mysql.com/doc/dev/mysql-server/latest/PAGE_PROTOCOL.html
+const (
+	mysqlComQuery       = 0x03
+	mysqlComStmtPrepare = 0x16
+	mysqlComStmtExecute = 0x17
+	mysqlComStmtClose   = 0x19
+
+	mysqlClientSSL          = 1 << 11
+	mysqlClientDeprecateEOF = 1 << 24
+
+	mysqlServerMoreResults = 0x0008
+
+	// mysqlMaxPacket is the payload size at which a packet continues in the
+	// next one.
+	mysqlMaxPacket = 1<<24 - 1
+)
+
+type mysqlPhase int
+
+const (
+	mysqlHandshake mysqlPhase = iota
+	mysqlAuth
+	mysqlCommand
+	// mysqlOpaque stops decoding, e.g. after the connection switched to TLS.
+	mysqlOpaque
+)
+
+// mysqlResponse is the part of a command response the tap expects next.
+type mysqlResponse int
+
+const (
+	mysqlIgnore mysqlResponse = iota
+	mysqlFirst
+	mysqlColumns
+	mysqlColumnsEOF
+	mysqlRows
+	mysqlPrepareFirst
+	mysqlPrepareDefs
+)
+
+// mysqlTap decodes the MySQL client/server protocol. Commands and responses
+// strictly alternate, so one pending statement is enough.
+type mysqlTap struct {
+	log    *Logger
+	target Target
+	conn   uint64
+
+	client, server mysqlPackets
+
+	phase        mysqlPhase
+	serverCaps   uint32
+	deprecateEOF bool
+
+	response  mysqlResponse
+	remaining int
+	pending   *pendingStatement
+	prepare   string
+	stmts     map[uint32]string
+}
+
+func newMySQLTap(log *Logger, target Target, conn uint64) *mysqlTap {
+	return &mysqlTap{log: log, target: target, conn: conn, stmts: map[uint32]string{}}
+}
+
+func (t *mysqlTap) fromClient(b []byte) {
+	if t.phase == mysqlOpaque {
+		return
+	}
+	t.client.feed(b, t.clientPacket)
+}
+
+func (t *mysqlTap) toClient(b []byte) {
+	if t.phase == mysqlOpaque {
+		return
+	}
+	t.server.feed(b, t.serverPacket)
+}
+
+func (t *mysqlTap) clientPacket(seq byte, p []byte) {
+	switch t.phase {
+	case mysqlHandshake:
+		if len(p) < 4 {
+			t.phase = mysqlOpaque
+			return
+		}
+		caps := binary.LittleEndian.Uint32(p)
+		if caps&mysqlClientSSL != 0 {
+			// The rest of the connection is encrypted.
+			t.phase = mysqlOpaque
+			return
+		}
+		t.deprecateEOF = caps&t.serverCaps&mysqlClientDeprecateEOF != 0
+		t.phase = mysqlAuth
+		return
+	case mysqlAuth:
+		return
+	}
+
+	if seq != 0 || len(p) == 0 {
+		// LOCAL INFILE contents and other follow-up packets.
+		return
+	}
+
+	t.pending = nil
+	t.response = mysqlIgnore
+	switch p[0] {
+	case mysqlComQuery:
+		t.pending = &pendingStatement{statement: string(p[1:]), start: time.Now()}
+		t.response = mysqlFirst
+	case mysqlComStmtPrepare:
+		t.prepare = string(p[1:])
+		t.pending = &pendingStatement{statement: t.prepare, start: time.Now()}
+		t.response = mysqlPrepareFirst
+	case mysqlComStmtExecute:
+		if len(p) < 5 {
+			return
+		}
+		stmt, ok := t.stmts[binary.LittleEndian.Uint32(p[1:])]
+		if !ok {
+			stmt = "<unknown prepared statement>"
+		}
+		t.pending = &pendingStatement{statement: stmt, start: time.Now()}
+		t.response = mysqlFirst
+	case mysqlComStmtClose:
+		if len(p) >= 5 {
+			delete(t.stmts, binary.LittleEndian.Uint32(p[1:]))
+		}
+	}
+}
+
+func (t *mysqlTap) serverPacket(_ byte, p []byte) {
+	if len(p) == 0 {
+		return
+	}
+
+	switch t.phase {
+	case mysqlHandshake:
+		t.serverCaps = mysqlGreetingCaps(p)
+		return
+	case mysqlAuth:
+		switch p[0] {
+		case 0x00:
+			t.phase = mysqlCommand
+		case 0xff:
+			t.phase = mysqlOpaque
+		}
+		return
+	}
+
+	switch t.response {
+	case mysqlFirst:
+		switch p[0] {
+		case 0x00:
+			affected, _ := mysqlLenEnc(p[1:])
+			t.pending.rowsAffected += int64(affected)
+			if mysqlOKStatus(p)&mysqlServerMoreResults == 0 {
+				t.finish()
+			}
+		case 0xff:
+			t.fail(p)
+		case 0xfb:
+			// LOCAL INFILE request; the OK or error follows the file.
+		default:
+			n, _ := mysqlLenEnc(p)
+			t.remaining = int(n)
+			t.response = mysqlColumns
+		}
+	case mysqlColumns:
+		t.remaining--
+		if t.remaining <= 0 {
+			t.response = mysqlRows
+			if !t.deprecateEOF {
+				t.response = mysqlColumnsEOF
+			}
+		}
+	case mysqlColumnsEOF:
+		t.response = mysqlRows
+	case mysqlRows:
+		switch {
+		case p[0] == 0xfe && len(p) < mysqlMaxPacket:
+			var status uint16
+			if t.deprecateEOF {
+				status = mysqlOKStatus(p)
+			} else if len(p) >= 5 {
+				status = binary.LittleEndian.Uint16(p[3:])
+			}
+			if status&mysqlServerMoreResults != 0 {
+				t.response = mysqlFirst
+			} else {
+				t.finish()
+			}
+		case p[0] == 0xff:
+			t.fail(p)
+		default:
+			t.pending.rows++
+		}
+	case mysqlPrepareFirst:
+		if p[0] == 0xff {
+			// Failed prepares are logged, successful ones only when executed.
+			t.fail(p)
+			return
+		}
+		if len(p) < 9 {
+			t.response = mysqlIgnore
+			return
+		}
+		t.stmts[binary.LittleEndian.Uint32(p[1:])] = t.prepare
+		columns := int(binary.LittleEndian.Uint16(p[5:]))
+		params := int(binary.LittleEndian.Uint16(p[7:]))
+		t.remaining = columns + params
+		if !t.deprecateEOF {
+			if columns > 0 {
+				t.remaining++
+			}
+			if params > 0 {
+				t.remaining++
+			}
+		}
+		t.pending = nil
+		t.response = mysqlIgnore
+		if t.remaining > 0 {
+			t.response = mysqlPrepareDefs
+		}
+	case mysqlPrepareDefs:
+		t.remaining--
+		if t.remaining <= 0 {
+			t.response = mysqlIgnore
+		}
+	}
+}
+
+func (t *mysqlTap) finish() {
+	if t.pending != nil {
+		t.log.Log(t.pending.entry(t.target, t.conn))
+	}
+	t.pending = nil
+	t.response = mysqlIgnore
+}
+
+func (t *mysqlTap) fail(p []byte) {
+	if t.pending != nil {
+		t.pending.err = mysqlError(p)
+	}
+	t.finish()
+}
+
+// mysqlPackets reassembles packets from a byte stream.
+type mysqlPackets struct {
+	buf []byte
+}
+
+func (m *mysqlPackets) feed(b []byte, packet func(seq byte, payload []byte)) {
+	m.buf = append(m.buf, b...)
+	for len(m.buf) >= 4 {
+		n := int(m.buf[0]) | int(m.buf[1])<<8 | int(m.buf[2])<<16
+		if len(m.buf) < 4+n {
+			break
+		}
+		packet(m.buf[3], m.buf[4:4+n])
+		m.buf = m.buf[4+n:]
+	}
+	if len(m.buf) == 0 {
+		m.buf = nil
+	}
+}
+
+// mysqlGreetingCaps returns the capability flags of a server greeting.
+func mysqlGreetingCaps(p []byte) uint32 {
+	// protocol version, server version, connection id, auth data part 1, filler
+	i := 1
+	for i < len(p) && p[i] != 0 {
+		i++
+	}
+	i += 1 + 4 + 8 + 1
+	if len(p) < i+2 {
+		return 0
+	}
+	caps := uint32(binary.LittleEndian.Uint16(p[i:]))
+	// character set and status flags come before the upper capability bytes
+	if len(p) >= i+7 {
+		caps |= uint32(binary.LittleEndian.Uint16(p[i+5:])) << 16
+	}
+	return caps
+}
+
+// mysqlLenEnc decodes a length-encoded integer and returns it with its size.
+func mysqlLenEnc(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	switch b[0] {
+	case 0xfc:
+		if len(b) >= 3 {
+			return uint64(binary.LittleEndian.Uint16(b[1:])), 3
+		}
+	case 0xfd:
+		if len(b) >= 4 {
+			return uint64(b[1]) | uint64(b[2])<<8 | uint64(b[3])<<16, 4
+		}
+	case 0xfe:
+		if len(b) >= 9 {
+			return binary.LittleEndian.Uint64(b[1:]), 9
+		}
+	default:
+		return uint64(b[0]), 1
+	}
+	return 0, len(b)
+}
+
+// mysqlOKStatus returns the status flags of an OK packet (header 0x00 or
+// 0xfe).
+func mysqlOKStatus(p []byte) uint16 {
+	i := 1
+	_, n := mysqlLenEnc(p[i:]) // affected rows
+	i += n
+	_, n = mysqlLenEnc(p[i:]) // last insert id
+	i += n
+	if len(p) < i+2 {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(p[i:])
+}
+
+// mysqlError formats an ERR packet like the mysql client does.
+func mysqlError(p []byte) string {
+	if len(p) < 3 {
+		return "unknown error"
+	}
+	code := binary.LittleEndian.Uint16(p[1:])
+	if len(p) >= 9 && p[3] == '#' {
+		return fmt.Sprintf("ERROR %d (%s): %s", code, p[4:9], p[9:])
+	}
+	return fmt.Sprintf("ERROR %d: %s", code, p[3:])
+}
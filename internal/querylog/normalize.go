@@ -0,0 +1,193 @@
+package querylog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redact replaces string and numeric literals in query with ?, keeping
+// everything else as written. Double-quoted and backquoted text is treated as
+// an identifier, as in PostgreSQL and ANSI-mode MySQL.
+func Redact(query string) string {
+	return normalize(query, false)
+}
+
+var (
+	valueListRe = regexp.MustCompile(`\(\s*\?(\s*,\s*\?)*\s*\)`)
+	rowListRe   = regexp.MustCompile(`\(\.\.\.\)(\s*,\s*\(\.\.\.\))+`)
+)
+
+// Fingerprint normalizes query so that statements differing only in their
+// literals, comments, whitespace, letter case, or the length of IN and
+// VALUES lists share a fingerprint.
+func Fingerprint(query string) string {
+	fp := normalize(query, true)
+	fp = valueListRe.ReplaceAllString(fp, "(...)")
+	fp = rowListRe.ReplaceAllString(fp, "(...)")
+	return strings.TrimSuffix(strings.TrimSpace(fp), ";")
+}
+
+// normalize replaces literals with ?. For fingerprints it also drops comments,
+// collapses whitespace, and lower-cases everything outside quoted
+// identifiers.
+func normalize(query string, fingerprint bool) string {
+	var out strings.Builder
+	out.Grow(len(query))
+
+	space := false
+	emit := func(s string) {
+		if space {
+			if out.Len() > 0 {
+				out.WriteByte(' ')
+			}
+			space = false
+		}
+		out.WriteString(s)
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case fingerprint && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+			space = true
+			i++
+		case c == '\'':
+			i = skipQuoted(query, i)
+			emit("?")
+		case c == '"' || c == '`':
+			end := skipQuoted(query, i)
+			emit(query[i:end])
+			i = end
+		case c == '$' && i+1 < len(query) && !isDigit(query[i+1]):
+			// PostgreSQL dollar quoting: $$...$$ or $tag$...$tag$.
+			if end := skipDollarQuoted(query, i); end > i {
+				emit("?")
+				i = end
+			} else {
+				emit("$")
+				i++
+			}
+		case isDigit(c) && (i == 0 || !isIdentChar(query[i-1])):
+			i = skipNumber(query, i)
+			emit("?")
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			if fingerprint {
+				space = true
+			} else {
+				emit(query[i : i+end])
+			}
+			i += end
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query)
+			} else {
+				end += i + 4
+			}
+			if fingerprint {
+				space = true
+			} else {
+				emit(query[i:end])
+			}
+			i = end
+		default:
+			// Copy identifiers and placeholders such as $1 whole, so digits
+			// inside them aren't taken for numbers.
+			j := i + 1
+			if isIdentChar(c) || c == '$' {
+				for j < len(query) && isIdentChar(query[j]) {
+					j++
+				}
+			}
+			if fingerprint {
+				emit(strings.ToLower(query[i:j]))
+			} else {
+				emit(query[i:j])
+			}
+			i = j
+		}
+	}
+	return out.String()
+}
+
+// skipQuoted returns the index after the quoted text starting at i. Doubled
+// quotes and backslash escapes stay inside the quotes.
+func skipQuoted(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if quote == '\'' {
+				j++
+			}
+		case quote:
+			if j+1 < len(s) && s[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// skipDollarQuoted returns the index after the dollar-quoted string starting
+// at i, or i if there is none.
+func skipDollarQuoted(s string, i int) int {
+	j := i + 1
+	for j < len(s) && isIdentChar(s[j]) {
+		j++
+	}
+	if j >= len(s) || s[j] != '$' {
+		return i
+	}
+	tag := s[i : j+1]
+	end := strings.Index(s[j+1:], tag)
+	if end < 0 {
+		return len(s)
+	}
+	return j + 1 + end + len(tag)
+}
+
+func skipNumber(s string, i int) int {
+	j := i
+	if s[j] == '0' && j+1 < len(s) && (s[j+1] == 'x' || s[j+1] == 'X') {
+		j += 2
+		for j < len(s) && isHexDigit(s[j]) {
+			j++
+		}
+		return j
+	}
+	for j < len(s) && (isDigit(s[j]) || s[j] == '.') {
+		j++
+	}
+	if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+		k := j + 1
+		if k < len(s) && (s[k] == '+' || s[k] == '-') {
+			k++
+		}
+		if k < len(s) && isDigit(s[k]) {
+			j = k
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+		}
+	}
+	return j
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
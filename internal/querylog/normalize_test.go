@@ -0,0 +1,74 @@
+package querylog
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = ?"},
+		{"SELECT 'it''s', 'a\\'b' FROM t", "SELECT ?, ? FROM t"},
+		{`SELECT "col1", ` + "`t2`" + ` FROM t3`, `SELECT "col1", ` + "`t2`" + ` FROM t3`},
+		{"SELECT 1.5e10, 0xff, -3", "SELECT ?, ?, -?"},
+		{"SELECT $1, $$body$$, $tag$x$tag$", "SELECT $1, ?, ?"},
+		{"SELECT col1 FROM t2 -- 'comment'\n", "SELECT col1 FROM t2 -- 'comment'\n"},
+		{"SELECT /* 5 */ 5", "SELECT /* 5 */ ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(Redact(tt.query), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM users WHERE id = 42;", "select * from users where id = ?"},
+		{"select *\n  from USERS\twhere id=7 -- lookup", "select * from users where id=?"},
+		{"SELECT /* app:web */ name FROM t WHERE id IN (1, 2, 3)", "select name from t where id in (...)"},
+		{"INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'z')", "insert into t (a, b) values (...)"},
+		{`SELECT "MixedCase" FROM t`, `select "MixedCase" from t`},
+		{"SELECT col1 FROM t2 WHERE x = $1", "select col1 from t2 where x = $1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(Fingerprint(tt.query), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	c := qt.New(t)
+
+	log := strings.Join([]string{
+		`{"time":"2024-01-01T00:00:00Z","conn":1,"statement":"SELECT * FROM t WHERE id = 1","duration_ms":1.5,"rows":1,"rows_affected":0}`,
+		`{"time":"2024-01-01T00:00:01Z","conn":1,"statement":"select * from t where id = 2","duration_ms":2.5,"rows":0,"rows_affected":0}`,
+		``,
+		`{"time":"2024-01-01T00:00:02Z","conn":2,"statement":"UPDATE t SET a = 1","duration_ms":10,"rows":0,"rows_affected":4}`,
+		`{"time":"2024-01-01T00:00:03Z","conn":2,"statement":"SELECT nope","duration_ms":0.333,"rows":0,"rows_affected":0,"error":"ERROR 1054"}`,
+	}, "\n")
+
+	summaries, err := Summarize(strings.NewReader(log))
+	c.Assert(err, qt.IsNil)
+	c.Assert(summaries, qt.DeepEquals, []*Summary{
+		{Fingerprint: "update t set a = ?", Count: 1, TotalMS: 10, MeanMS: 10, MaxMS: 10, Rows: 4},
+		{Fingerprint: "select * from t where id = ?", Count: 2, TotalMS: 4, MeanMS: 2, MaxMS: 2.5, Rows: 1},
+		{Fingerprint: "select nope", Count: 1, Errors: 1, TotalMS: 0.33, MeanMS: 0.33, MaxMS: 0.33},
+	})
+
+	_, err = Summarize(strings.NewReader("{\"conn\":1}\nnot json\n"))
+	c.Assert(err, qt.ErrorMatches, "line 2: .*")
+}
@@ -0,0 +1,248 @@
+package querylog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Codes of the untyped messages a PostgreSQL client may send before its
+// startup message.
+const (
+	pgCancelRequest = 80877102
+	pgSSLRequest    = 80877103
+	pgGSSEncRequest = 80877104
+)
+
+// pgItem is a client message that the server answers later: a statement, or
+// a Sync that ends an extended-protocol batch.
+type pgItem struct {
+	stmt *pendingStatement
+	// simple statements are Query messages, which end with ReadyForQuery
+	// rather than with their CommandComplete.
+	simple bool
+	sync   bool
+}
+
+// postgresTap decodes the PostgreSQL frontend/backend protocol. Clients may
+// pipeline messages, so statements wait in a queue for their responses.
+type postgresTap struct {
+	log    *Logger
+	target Target
+	conn   uint64
+
+	client, server []byte
+
+	startup bool
+	opaque  bool
+	// rawReplies counts the single-byte answers to SSL and GSS encryption
+	// requests the server still owes.
+	rawReplies int
+
+	stmts   map[string]string
+	portals map[string]string
+	queue   []*pgItem
+}
+
+func newPostgresTap(log *Logger, target Target, conn uint64) *postgresTap {
+	return &postgresTap{
+		log:     log,
+		target:  target,
+		conn:    conn,
+		startup: true,
+		stmts:   map[string]string{},
+		portals: map[string]string{},
+	}
+}
+
+func (t *postgresTap) fromClient(b []byte) {
+	if t.opaque {
+		return
+	}
+	t.client = append(t.client, b...)
+
+	for t.startup {
+		if len(t.client) < 8 {
+			return
+		}
+		n := int(binary.BigEndian.Uint32(t.client))
+		if len(t.client) < n {
+			return
+		}
+		switch binary.BigEndian.Uint32(t.client[4:]) {
+		case pgSSLRequest, pgGSSEncRequest:
+			t.rawReplies++
+		case pgCancelRequest:
+			t.opaque = true
+			return
+		default:
+			t.startup = false
+		}
+		t.client = t.client[n:]
+	}
+
+	t.client = pgMessages(t.client, t.clientMessage)
+}
+
+func (t *postgresTap) toClient(b []byte) {
+	if t.opaque {
+		return
+	}
+	for t.rawReplies > 0 && len(b) > 0 {
+		if b[0] != 'N' {
+			// The connection switches to TLS or GSS encryption.
+			t.opaque = true
+			return
+		}
+		b = b[1:]
+		t.rawReplies--
+	}
+	t.server = append(t.server, b...)
+	t.server = pgMessages(t.server, t.serverMessage)
+}
+
+func (t *postgresTap) clientMessage(typ byte, body []byte) {
+	switch typ {
+	case 'Q':
+		query, _ := pgString(body)
+		t.queue = append(t.queue, &pgItem{stmt: &pendingStatement{statement: query, start: time.Now()}, simple: true})
+	case 'P':
+		name, rest := pgString(body)
+		query, _ := pgString(rest)
+		t.stmts[name] = query
+	case 'B':
+		portal, rest := pgString(body)
+		stmt, _ := pgString(rest)
+		t.portals[portal] = t.stmts[stmt]
+	case 'E':
+		portal, _ := pgString(body)
+		t.queue = append(t.queue, &pgItem{stmt: &pendingStatement{statement: t.portals[portal], start: time.Now()}})
+	case 'S':
+		t.queue = append(t.queue, &pgItem{sync: true})
+	}
+}
+
+func (t *postgresTap) serverMessage(typ byte, body []byte) {
+	var head *pgItem
+	if len(t.queue) > 0 {
+		head = t.queue[0]
+	}
+	if head != nil && head.sync && typ != 'Z' {
+		// Responses to Parse, Bind and friends.
+		head = nil
+	}
+
+	switch typ {
+	case 'D':
+		if head != nil {
+			head.stmt.rows++
+		}
+	case 'C':
+		if head == nil {
+			return
+		}
+		tag, _ := pgString(body)
+		applyCommandTag(head.stmt, tag)
+		if !head.simple {
+			t.finish()
+		}
+	case 's', 'I':
+		// PortalSuspended and EmptyQueryResponse
+		if head != nil && !head.simple {
+			t.finish()
+		}
+	case 'E':
+		if head == nil {
+			return
+		}
+		head.stmt.err = pgError(body)
+		if !head.simple {
+			t.finish()
+		}
+	case 'Z':
+		// ReadyForQuery ends a simple query, or a batch up to its Sync.
+		// Statements left before the Sync were skipped after an error.
+		for len(t.queue) > 0 {
+			item := t.queue[0]
+			if item.simple {
+				t.finish()
+				return
+			}
+			t.queue = t.queue[1:]
+			if item.sync {
+				return
+			}
+		}
+	}
+}
+
+// finish logs the statement at the head of the queue.
+func (t *postgresTap) finish() {
+	item := t.queue[0]
+	t.queue = t.queue[1:]
+	t.log.Log(item.stmt.entry(t.target, t.conn))
+}
+
+// applyCommandTag adds the affected rows of a CommandComplete tag such as
+// "INSERT 0 3". Returned rows are counted from the DataRow messages instead.
+func applyCommandTag(stmt *pendingStatement, tag string) {
+	fields := strings.Fields(tag)
+	if len(fields) < 2 {
+		return
+	}
+	switch fields[0] {
+	case "SELECT", "FETCH", "MOVE":
+		return
+	}
+	if n, err := strconv.ParseInt(fields[len(fields)-1], 10, 64); err == nil {
+		stmt.rowsAffected += n
+	}
+}
+
+// pgMessages calls fn for every complete typed message in buf and returns
+// the incomplete rest.
+func pgMessages(buf []byte, fn func(typ byte, body []byte)) []byte {
+	for len(buf) >= 5 {
+		n := int(binary.BigEndian.Uint32(buf[1:]))
+		if len(buf) < 1+n {
+			break
+		}
+		fn(buf[0], buf[5:1+n])
+		buf = buf[1+n:]
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	return buf
+}
+
+// pgString splits a NUL-terminated string off b.
+func pgString(b []byte) (string, []byte) {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return string(b), nil
+	}
+	return string(b[:i]), b[i+1:]
+}
+
+// pgError formats the severity, code and message of an ErrorResponse.
+func pgError(body []byte) string {
+	var severity, code, message string
+	for len(body) > 0 && body[0] != 0 {
+		field := body[0]
+		var value string
+		value, body = pgString(body[1:])
+		switch field {
+		case 'S':
+			severity = value
+		case 'C':
+			code = value
+		case 'M':
+			message = value
+		}
+	}
+	return fmt.Sprintf("%s: %s (SQLSTATE %s)", severity, message, code)
+}
@@ -0,0 +1,121 @@
+// Package querylog records the statements that pass through the local
+// database proxies of pscale connect.
+package querylog
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Entry is one logged statement. Entries are written as newline-delimited
+// JSON.
+type Entry struct {
+	// Time is when the client sent the statement.
+	Time     time.Time `json:"time"`
+	Database string    `json:"database,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	// Conn numbers the client connections of a pscale connect process.
+	Conn       uint64  `json:"conn"`
+	Statement  string  `json:"statement"`
+	DurationMS float64 `json:"duration_ms"`
+	// Rows is the number of rows returned to the client.
+	Rows         int64  `json:"rows"`
+	RowsAffected int64  `json:"rows_affected"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Logger writes entries to a file. It is safe for concurrent use.
+type Logger struct {
+	redact bool
+	conns  atomic.Uint64
+
+	mu     sync.Mutex
+	w      io.Writer
+	enc    *json.Encoder
+	err    error
+	closed bool
+}
+
+// New returns a logger that writes to w. With redact, literals in statements
+// are replaced with ?.
+func New(w io.Writer, redact bool) *Logger {
+	return &Logger{w: w, enc: json.NewEncoder(w), redact: redact}
+}
+
+// Open appends to the log file at path, creating it if needed.
+func Open(path string, redact bool) (*Logger, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return New(f, redact), nil
+}
+
+// Log writes e. Write errors are kept and returned by Close, so a full disk
+// doesn't interrupt the proxied connections. Entries logged after Close are
+// dropped.
+func (l *Logger) Log(e *Entry) {
+	if l.redact {
+		e.Statement = Redact(e.Statement)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	if err := l.enc.Encode(e); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+// nextConn returns the id of a new client connection.
+func (l *Logger) nextConn() uint64 {
+	return l.conns.Add(1)
+}
+
+// Close closes the underlying file, if the logger owns one, and reports the
+// first write error.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+
+	err := l.err
+	if c, ok := l.w.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// pendingStatement is a statement waiting for the server's response.
+type pendingStatement struct {
+	statement    string
+	start        time.Time
+	rows         int64
+	rowsAffected int64
+	err          string
+}
+
+func (p *pendingStatement) entry(target Target, conn uint64) *Entry {
+	return &Entry{
+		Time:         p.start,
+		Database:     target.Database,
+		Branch:       target.Branch,
+		Conn:         conn,
+		Statement:    p.statement,
+		DurationMS:   float64(time.Since(p.start).Microseconds()) / 1000,
+		Rows:         p.rows,
+		RowsAffected: p.rowsAffected,
+		Error:        p.err,
+	}
+}
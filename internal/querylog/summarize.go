@@ -0,0 +1,77 @@
+package querylog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Summary aggregates the log entries of one statement fingerprint.
+type Summary struct {
+	Fingerprint string  `header:"fingerprint" json:"fingerprint"`
+	Count       int64   `header:"count" json:"count"`
+	Errors      int64   `header:"errors" json:"errors"`
+	TotalMS     float64 `header:"total_ms" json:"total_ms"`
+	MeanMS      float64 `header:"mean_ms" json:"mean_ms"`
+	MaxMS       float64 `header:"max_ms" json:"max_ms"`
+	Rows        int64   `header:"rows" json:"rows"`
+}
+
+// Summarize reads a query log and groups its entries by fingerprint, most
+// expensive in total first.
+func Summarize(r io.Reader) ([]*Summary, error) {
+	groups := map[string]*Summary{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		fp := Fingerprint(e.Statement)
+		s, ok := groups[fp]
+		if !ok {
+			s = &Summary{Fingerprint: fp}
+			groups[fp] = s
+		}
+		s.Count++
+		if e.Error != "" {
+			s.Errors++
+		}
+		s.TotalMS += e.DurationMS
+		s.MaxMS = math.Max(s.MaxMS, e.DurationMS)
+		s.Rows += e.Rows + e.RowsAffected
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*Summary, 0, len(groups))
+	for _, s := range groups {
+		s.MeanMS = round(s.TotalMS / float64(s.Count))
+		s.TotalMS = round(s.TotalMS)
+		s.MaxMS = round(s.MaxMS)
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].TotalMS != summaries[j].TotalMS {
+			return summaries[i].TotalMS > summaries[j].TotalMS
+		}
+		return summaries[i].Fingerprint < summaries[j].Fingerprint
+	})
+	return summaries, nil
+}
+
+func round(ms float64) float64 {
+	return math.Round(ms*100) / 100
+}
@@ -0,0 +1,196 @@
+package querylog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func decodeEntries(c *qt.C, buf *bytes.Buffer) []Entry {
+	var entries []Entry
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var e Entry
+		c.Assert(dec.Decode(&e), qt.IsNil)
+		c.Assert(e.Time.IsZero(), qt.IsFalse)
+		c.Assert(e.DurationMS >= 0, qt.IsTrue)
+		// Times and durations vary between runs.
+		e.Time, e.DurationMS = time.Time{}, 0
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func mysqlPacket(seq byte, payload ...byte) []byte {
+	n := len(payload)
+	return append([]byte{byte(n), byte(n >> 8), byte(n >> 16), seq}, payload...)
+}
+
+func mysqlQuery(cmd byte, q string) []byte {
+	return mysqlPacket(0, append([]byte{cmd}, q...)...)
+}
+
+func TestMySQLTap(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	target := Target{Database: "app", Branch: "main"}
+	tap := newMySQLTap(New(&buf, false), target, 7)
+
+	// Greeting without CLIENT_DEPRECATE_EOF, so result sets end in EOF packets.
+	greeting := append([]byte{10}, "8.0.0-vitess\x00"...)
+	greeting = append(greeting, 1, 0, 0, 0)                      // connection id
+	greeting = append(greeting, bytes.Repeat([]byte{'a'}, 8)...) // auth data
+	greeting = append(greeting, 0, 0xff, 0xf7, 0x21, 2, 0, 0x00, 0x00)
+	tap.toClient(mysqlPacket(0, greeting...))
+	tap.fromClient(mysqlPacket(1, 0x0d, 0xa2, 0x0f, 0x01))
+	tap.toClient(mysqlPacket(2, 0x00, 0, 0, 2, 0, 0, 0))
+
+	// A result set with one column and two rows, split across writes.
+	tap.fromClient(mysqlQuery(mysqlComQuery, "SELECT id FROM t"))
+	result := mysqlPacket(1, 1)
+	result = append(result, mysqlPacket(2, []byte("\x03def")...)...)
+	result = append(result, mysqlPacket(3, 0xfe, 0, 0, 2, 0)...)
+	result = append(result, mysqlPacket(4, 1, '1')...)
+	result = append(result, mysqlPacket(5, 1, '2')...)
+	result = append(result, mysqlPacket(6, 0xfe, 0, 0, 2, 0)...)
+	tap.toClient(result[:5])
+	tap.toClient(result[5:])
+
+	tap.fromClient(mysqlQuery(mysqlComQuery, "UPDATE t SET a = 1"))
+	tap.toClient(mysqlPacket(1, 0x00, 3, 0, 2, 0, 0, 0))
+
+	tap.fromClient(mysqlQuery(mysqlComQuery, "SELEC 1"))
+	tap.toClient(mysqlPacket(1, append([]byte{0xff, 0x28, 0x04, '#'}, "42000syntax error"...)...))
+
+	// A prepared statement with one parameter and one column.
+	tap.fromClient(mysqlQuery(mysqlComStmtPrepare, "SELECT ?"))
+	tap.toClient(mysqlPacket(1, 0x00, 1, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0))
+	tap.toClient(mysqlPacket(2, []byte("\x03def")...))
+	tap.toClient(mysqlPacket(3, 0xfe, 0, 0, 2, 0))
+	tap.toClient(mysqlPacket(4, []byte("\x03def")...))
+	tap.toClient(mysqlPacket(5, 0xfe, 0, 0, 2, 0))
+	tap.fromClient(mysqlPacket(0, mysqlComStmtExecute, 1, 0, 0, 0, 0, 1, 0, 0, 0))
+	tap.toClient(mysqlPacket(1, 1))
+	tap.toClient(mysqlPacket(2, []byte("\x03def")...))
+	tap.toClient(mysqlPacket(3, 0xfe, 0, 0, 2, 0))
+	tap.toClient(mysqlPacket(4, 0x00, 0x00, 0x05, 0, 0, 0, 0, 0, 0, 0))
+	tap.toClient(mysqlPacket(5, 0xfe, 0, 0, 2, 0))
+
+	entries := decodeEntries(c, &buf)
+	c.Assert(entries, qt.HasLen, 4)
+	want := func(stmt string, rows, affected int64, err string) Entry {
+		return Entry{Database: "app", Branch: "main", Conn: 7, Statement: stmt, Rows: rows, RowsAffected: affected, Error: err}
+	}
+	c.Assert(entries, qt.DeepEquals, []Entry{
+		want("SELECT id FROM t", 2, 0, ""),
+		want("UPDATE t SET a = 1", 0, 3, ""),
+		want("SELEC 1", 0, 0, "ERROR 1064 (42000): syntax error"),
+		want("SELECT ?", 1, 0, ""),
+	})
+}
+
+func TestMySQLTapTLS(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	tap := newMySQLTap(New(&buf, false), Target{}, 1)
+	tap.toClient(mysqlPacket(0, append([]byte{10}, "8.0.0\x00"...)...))
+	tap.fromClient(mysqlPacket(1, 0x0d, 0xaa, 0x0f, 0x01)) // CLIENT_SSL
+	tap.fromClient([]byte{0x16, 0x03, 0x01, 0x02, 0x00})
+	tap.fromClient(mysqlQuery(mysqlComQuery, "SELECT 1"))
+	tap.toClient(mysqlPacket(1, 0x00, 0, 0, 2, 0, 0, 0))
+	c.Assert(buf.Len(), qt.Equals, 0)
+}
+
+func pgMessage(typ byte, body ...string) []byte {
+	var b []byte
+	for _, s := range body {
+		b = append(b, s...)
+	}
+	msg := []byte{typ, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(msg[1:], uint32(4+len(b)))
+	return append(msg, b...)
+}
+
+func pgUntyped(code uint32, body string) []byte {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint32(msg, uint32(8+len(body)))
+	binary.BigEndian.PutUint32(msg[4:], code)
+	return append(msg, body...)
+}
+
+func TestPostgresTap(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	tap := newPostgresTap(New(&buf, true), Target{Database: "app", Branch: "main"}, 3)
+
+	tap.fromClient(pgUntyped(pgSSLRequest, ""))
+	tap.toClient([]byte{'N'})
+	tap.fromClient(pgUntyped(196608, "user\x00postgres\x00\x00"))
+	tap.toClient(append(pgMessage('R', "\x00\x00\x00\x00"), pgMessage('Z', "I")...))
+
+	// A simple query with two rows, split across reads.
+	q := pgMessage('Q', "SELECT id FROM t WHERE a = 'x'\x00")
+	tap.fromClient(q[:3])
+	tap.fromClient(q[3:])
+	var resp []byte
+	resp = append(resp, pgMessage('T', "\x00\x00")...)
+	resp = append(resp, pgMessage('D', "\x00\x00")...)
+	resp = append(resp, pgMessage('D', "\x00\x00")...)
+	resp = append(resp, pgMessage('C', "SELECT 2\x00")...)
+	resp = append(resp, pgMessage('Z', "I")...)
+	tap.toClient(resp)
+
+	// A pipelined extended-protocol batch.
+	var batch []byte
+	batch = append(batch, pgMessage('P', "ins\x00", "INSERT INTO t VALUES ($1, 5)\x00", "\x00\x00")...)
+	batch = append(batch, pgMessage('B', "\x00", "ins\x00", "\x00\x00\x00\x00\x00\x00")...)
+	batch = append(batch, pgMessage('E', "\x00", "\x00\x00\x00\x00")...)
+	batch = append(batch, pgMessage('S')...)
+	batch = append(batch, pgMessage('P', "\x00", "SELEC 1\x00", "\x00\x00")...)
+	batch = append(batch, pgMessage('B', "\x00", "\x00", "\x00\x00\x00\x00\x00\x00")...)
+	batch = append(batch, pgMessage('E', "\x00", "\x00\x00\x00\x00")...)
+	batch = append(batch, pgMessage('P', "\x00", "SELECT 2\x00", "\x00\x00")...)
+	batch = append(batch, pgMessage('B', "\x00", "\x00", "\x00\x00\x00\x00\x00\x00")...)
+	batch = append(batch, pgMessage('E', "\x00", "\x00\x00\x00\x00")...)
+	batch = append(batch, pgMessage('S')...)
+	tap.fromClient(batch)
+
+	resp = nil
+	resp = append(resp, pgMessage('1')...)
+	resp = append(resp, pgMessage('2')...)
+	resp = append(resp, pgMessage('C', "INSERT 0 1\x00")...)
+	resp = append(resp, pgMessage('Z', "I")...)
+	resp = append(resp, pgMessage('E', "SERROR\x00", "C42601\x00", "Msyntax error at or near \"SELEC\"\x00", "\x00")...)
+	resp = append(resp, pgMessage('Z', "I")...)
+	tap.toClient(resp)
+
+	entries := decodeEntries(c, &buf)
+	c.Assert(entries, qt.HasLen, 3)
+	want := func(stmt string, rows, affected int64, err string) Entry {
+		return Entry{Database: "app", Branch: "main", Conn: 3, Statement: stmt, Rows: rows, RowsAffected: affected, Error: err}
+	}
+	c.Assert(entries, qt.DeepEquals, []Entry{
+		want("SELECT id FROM t WHERE a = ?", 2, 0, ""),
+		want("INSERT INTO t VALUES ($1, ?)", 0, 1, ""),
+		want("SELEC ?", 0, 0, `ERROR: syntax error at or near "SELEC" (SQLSTATE 42601)`),
+	})
+}
+
+func TestPostgresTapTLS(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	tap := newPostgresTap(New(&buf, false), Target{}, 1)
+	tap.fromClient(pgUntyped(pgSSLRequest, ""))
+	tap.toClient([]byte{'S'})
+	tap.fromClient(pgMessage('Q', "SELECT 1\x00"))
+	tap.toClient(pgMessage('Z', "I"))
+	c.Assert(buf.Len(), qt.Equals, 0)
+}
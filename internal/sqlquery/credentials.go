@@ -0,0 +1,349 @@
+package sqlquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/passwordutil"
+	ps "github.com/planetscale/cli/internal/planetscale"
+	"github.com/planetscale/cli/internal/roleutil"
+)
+
+// ephemeralCredentialTTL is the lifetime of the per-invocation credentials
+// minted when no session is cached.
+const ephemeralCredentialTTL = 5 * time.Minute
+
+// sessionReuseMargin is how much lifetime a cached credential must have left
+// to be reused, so a query never starts on a credential that is about to
+// expire.
+const sessionReuseMargin = 30 * time.Second
+
+// Credential is a branch password (MySQL) or role (PostgreSQL). Credentials
+// created by `pscale sql session start` are cached and reused by later
+// pscale sql invocations for the same organization, database, branch, and
+// role until they expire.
+type Credential struct {
+	Organization string `json:"organization"`
+	Database     string `json:"database"`
+	Branch       string `json:"branch"`
+	// Engine is "mysql" or "postgresql".
+	Engine   string `json:"engine"`
+	Role     string `json:"role"`
+	Replica  bool   `json:"replica,omitempty"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Host     string `json:"host"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Successor takes over objects owned by a PostgreSQL role when the role
+	// is deleted.
+	Successor string    `json:"successor,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the credential is too close to its expiry to be
+// reused at now.
+func (c *Credential) Expired(now time.Time) bool {
+	return !now.Add(sessionReuseMargin).Before(c.ExpiresAt)
+}
+
+func (c *Credential) matches(org, database, branch, role string, replica bool) bool {
+	return c.Organization == org && c.Database == database && c.Branch == branch &&
+		c.Role == role && c.Replica == replica
+}
+
+// These are variables so tests can keep the cache in memory instead of the
+// user's keyring.
+var (
+	readCredentialCache   = config.ReadSQLSessions
+	writeCredentialCache  = config.WriteSQLSessions
+	deleteCredentialCache = config.DeleteSQLSessions
+)
+
+// SessionOptions selects the branch and access level for StartSession.
+type SessionOptions struct {
+	Organization string
+	Database     string
+	Branch       string
+	Role         string
+	Replica      bool
+	TTL          time.Duration
+}
+
+// StartSession creates a credential with the given TTL and caches it, so
+// pscale sql invocations with the same organization, database, branch, role,
+// and replica setting reuse it instead of minting their own. A session that
+// is already cached for the same target is revoked and replaced.
+func StartSession(ctx context.Context, ch *cmdutil.Helper, opts SessionOptions) (*Credential, error) {
+	if opts.Organization == "" {
+		return nil, fmt.Errorf("organization is required (use --org or set org in pscale.yml)")
+	}
+	if opts.Database == "" || opts.Branch == "" {
+		return nil, fmt.Errorf("database and branch are required")
+	}
+
+	role, err := cmdutil.ResolveAccessRole(opts.Role, opts.Replica, cmdutil.ReaderRole)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := ch.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	dbInfo, err := client.Databases.Get(ctx, &ps.GetDatabaseRequest{
+		Organization: opts.Organization,
+		Database:     opts.Database,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("database lookup: %w", err)
+	}
+
+	var engine string
+	switch string(dbInfo.Kind) {
+	case "mysql":
+		engine = "mysql"
+	case "postgresql", "horizon":
+		engine = "postgresql"
+	default:
+		return nil, fmt.Errorf("unsupported database kind %q", dbInfo.Kind)
+	}
+
+	if _, err := EndSessions(ctx, ch, func(c *Credential) bool {
+		return c.matches(opts.Organization, opts.Database, opts.Branch, role.ToString(), opts.Replica)
+	}); err != nil {
+		return nil, err
+	}
+
+	cred, err := mintCredential(ctx, client, Options{
+		Organization: opts.Organization,
+		Database:     opts.Database,
+		Branch:       opts.Branch,
+		Replica:      opts.Replica,
+	}, engine, role, "pscale-cli-sql-session", opts.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := loadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if err := saveCredentials(append(creds, cred)); err != nil {
+		_ = revokeCredential(client, cred)
+		return nil, err
+	}
+	return cred, nil
+}
+
+// ListSessions returns the cached credentials that haven't expired yet.
+func ListSessions() ([]*Credential, error) {
+	return loadCredentials()
+}
+
+// EndSessions revokes the cached credentials selected by match and removes
+// them from the cache. It returns the credentials that were ended.
+func EndSessions(ctx context.Context, ch *cmdutil.Helper, match func(*Credential) bool) ([]*Credential, error) {
+	creds, err := loadCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	var ended, kept []*Credential
+	for _, c := range creds {
+		if match(c) {
+			ended = append(ended, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	if len(ended) == 0 {
+		return nil, nil
+	}
+
+	client, err := ch.Client()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range ended {
+		if err := revokeCredential(client, c); err != nil && cmdutil.ErrCode(err) != ps.ErrNotFound {
+			return nil, fmt.Errorf("revoking %s: %w", c.Name, err)
+		}
+	}
+
+	if err := saveCredentials(kept); err != nil {
+		return nil, err
+	}
+	return ended, nil
+}
+
+// cachedCredential returns the session credential to use for opts, or nil
+// when there is none. A session that would expire before a long-running
+// caller's CredentialTTL is not reused. Reading the cache is best-effort: any
+// failure falls back to an ephemeral credential.
+func cachedCredential(opts Options, engine string, role cmdutil.PasswordRole) *Credential {
+	// Additional built-in roles (used by inspect) change what the credential
+	// can do, so they always get their own ephemeral role.
+	if len(opts.PostgresAdditionalRoles) > 0 {
+		return nil
+	}
+
+	creds, err := loadCredentials()
+	if err != nil {
+		return nil
+	}
+	until := time.Now().Add(opts.CredentialTTL)
+	for _, c := range creds {
+		if c.Engine == engine && c.matches(opts.Organization, opts.Database, opts.Branch, role.ToString(), opts.Replica) && !c.Expired(until) {
+			return c
+		}
+	}
+	return nil
+}
+
+// loadCredentials reads the cache and drops expired credentials. The API
+// expires them on its own, so there is nothing to revoke.
+func loadCredentials() ([]*Credential, error) {
+	data, err := readCredentialCache()
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+
+	var creds []*Credential
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("can't read cached sql sessions: %w", err)
+	}
+
+	now := time.Now()
+	live := creds[:0]
+	for _, c := range creds {
+		if !c.Expired(now) {
+			live = append(live, c)
+		}
+	}
+	return live, nil
+}
+
+func saveCredentials(creds []*Credential) error {
+	if len(creds) == 0 {
+		return deleteCredentialCache()
+	}
+
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return writeCredentialCache(data)
+}
+
+// mintCredential creates a branch password or PostgreSQL role that expires
+// after ttl.
+func mintCredential(ctx context.Context, client *ps.Client, opts Options, engine string, role cmdutil.PasswordRole, namePrefix string, ttl time.Duration) (*Credential, error) {
+	cred := &Credential{
+		Organization: opts.Organization,
+		Database:     opts.Database,
+		Branch:       opts.Branch,
+		Engine:       engine,
+		Role:         role.ToString(),
+		Replica:      opts.Replica,
+		Name:         passwordutil.GenerateName(namePrefix),
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+
+	if engine == "mysql" {
+		pw, err := passwordutil.New(ctx, client, passwordutil.Options{
+			Organization: opts.Organization,
+			Database:     opts.Database,
+			Branch:       opts.Branch,
+			Role:         role,
+			Name:         cred.Name,
+			TTL:          ttl,
+			Replica:      opts.Replica,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cred.ID = pw.Password.PublicID
+		cred.Name = pw.Password.Name
+		cred.Host = pw.Password.Hostname
+		cred.Username = pw.Password.Username
+		cred.Password = pw.Password.PlainText
+		return cred, nil
+	}
+
+	inheritedRoles, successor := cmdutil.PostgresInheritedRoles(role)
+	inheritedRoles = append(inheritedRoles, opts.PostgresAdditionalRoles...)
+
+	pgRole, err := roleutil.New(ctx, client, roleutil.Options{
+		Organization:   opts.Organization,
+		Database:       opts.Database,
+		Branch:         opts.Branch,
+		Name:           cred.Name,
+		TTL:            ttl,
+		InheritedRoles: inheritedRoles,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cred.ID = pgRole.Role.ID
+	cred.Host = pgRole.Role.AccessHostURL
+	cred.Username = pgRole.Role.Username
+	cred.Password = pgRole.Role.Password
+	cred.Successor = successor
+	return cred, nil
+}
+
+// revokeCredential deletes the credential with a fresh, bounded context so
+// cleanup still happens after the command's context is cancelled.
+func revokeCredential(client *ps.Client, cred *Credential) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if cred.Engine == "mysql" {
+		return client.Passwords.Delete(ctx, &ps.DeleteDatabaseBranchPasswordRequest{
+			Organization: cred.Organization,
+			Database:     cred.Database,
+			Branch:       cred.Branch,
+			Name:         cred.Name,
+			PasswordId:   cred.ID,
+		})
+	}
+
+	return client.PostgresRoles.Delete(ctx, &ps.DeletePostgresRoleRequest{
+		Organization: cred.Organization,
+		Database:     cred.Database,
+		Branch:       cred.Branch,
+		RoleId:       cred.ID,
+		Successor:    cred.Successor,
+	})
+}
+
+// credential returns the cached session credential for opts or mints an
+// ephemeral one. The returned release revokes ephemeral credentials and is a
+// no-op for cached ones, which live until they expire or the session ends.
+func credential(ctx context.Context, ch *cmdutil.Helper, opts Options, engine string, role cmdutil.PasswordRole) (*Credential, func(), error) {
+	if cred := cachedCredential(opts, engine, role); cred != nil {
+		return cred, func() {}, nil
+	}
+
+	client, err := ch.Client()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ttl := ephemeralCredentialTTL
+	if opts.CredentialTTL > 0 {
+		ttl = opts.CredentialTTL
+	}
+
+	cred, err := mintCredential(ctx, client, opts, engine, role, "pscale-cli-sql", ttl)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cred, func() { _ = revokeCredential(client, cred) }, nil
+}
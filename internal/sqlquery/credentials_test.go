@@ -0,0 +1,107 @@
+package sqlquery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/planetscale/cli/internal/cmdutil"
+	"github.com/planetscale/cli/internal/config"
+	"github.com/planetscale/cli/internal/mock"
+	ps "github.com/planetscale/cli/internal/planetscale"
+)
+
+// memoryCredentialCache swaps the keyring-backed cache for an in-memory one
+// for the duration of the test.
+func memoryCredentialCache(t *testing.T) *[]byte {
+	var data []byte
+	origRead, origWrite, origDelete := readCredentialCache, writeCredentialCache, deleteCredentialCache
+	readCredentialCache = func() ([]byte, error) { return data, nil }
+	writeCredentialCache = func(b []byte) error { data = b; return nil }
+	deleteCredentialCache = func() error { data = nil; return nil }
+	t.Cleanup(func() {
+		readCredentialCache, writeCredentialCache, deleteCredentialCache = origRead, origWrite, origDelete
+	})
+	return &data
+}
+
+func TestCachedCredentialMatchesTargetAndSkipsExpired(t *testing.T) {
+	c := qt.New(t)
+	memoryCredentialCache(t)
+
+	live := &Credential{Organization: "acme", Database: "db", Branch: "main", Engine: "mysql", Role: "reader", ExpiresAt: time.Now().Add(time.Hour)}
+	expired := &Credential{Organization: "acme", Database: "db", Branch: "dev", Engine: "mysql", Role: "reader", ExpiresAt: time.Now().Add(10 * time.Second)}
+	c.Assert(saveCredentials([]*Credential{live, expired}), qt.IsNil)
+
+	opts := Options{Organization: "acme", Database: "db", Branch: "main"}
+	got := cachedCredential(opts, "mysql", cmdutil.ReaderRole)
+	c.Assert(got, qt.IsNotNil)
+	c.Assert(got.Branch, qt.Equals, "main")
+
+	c.Assert(cachedCredential(opts, "mysql", cmdutil.AdministratorRole), qt.IsNil)
+	c.Assert(cachedCredential(Options{Organization: "acme", Database: "db", Branch: "main", Replica: true}, "mysql", cmdutil.ReaderRole), qt.IsNil)
+	c.Assert(cachedCredential(Options{Organization: "acme", Database: "db", Branch: "dev"}, "mysql", cmdutil.ReaderRole), qt.IsNil)
+	c.Assert(cachedCredential(Options{Organization: "acme", Database: "db", Branch: "main", PostgresAdditionalRoles: []string{"pg_read_all_stats"}}, "mysql", cmdutil.ReaderRole), qt.IsNil)
+
+	sessions, err := ListSessions()
+	c.Assert(err, qt.IsNil)
+	c.Assert(sessions, qt.HasLen, 1)
+}
+
+func TestCachedCredentialMustOutliveCredentialTTL(t *testing.T) {
+	c := qt.New(t)
+	memoryCredentialCache(t)
+
+	c.Assert(saveCredentials([]*Credential{
+		{Organization: "acme", Database: "db", Branch: "main", Engine: "mysql", Role: "reader", ExpiresAt: time.Now().Add(time.Hour)},
+	}), qt.IsNil)
+
+	opts := Options{Organization: "acme", Database: "db", Branch: "main"}
+	c.Assert(cachedCredential(opts, "mysql", cmdutil.ReaderRole), qt.IsNotNil)
+
+	opts.CredentialTTL = 30 * time.Minute
+	c.Assert(cachedCredential(opts, "mysql", cmdutil.ReaderRole), qt.IsNotNil)
+
+	opts.CredentialTTL = 12 * time.Hour
+	c.Assert(cachedCredential(opts, "mysql", cmdutil.ReaderRole), qt.IsNil)
+}
+
+func TestEndSessionsRevokesMatchingCredentials(t *testing.T) {
+	c := qt.New(t)
+	data := memoryCredentialCache(t)
+
+	c.Assert(saveCredentials([]*Credential{
+		{Organization: "acme", Database: "db", Branch: "main", Engine: "mysql", Role: "reader", ID: "pw1", Name: "one", ExpiresAt: time.Now().Add(time.Hour)},
+		{Organization: "acme", Database: "db", Branch: "dev", Engine: "mysql", Role: "reader", ID: "pw2", Name: "two", ExpiresAt: time.Now().Add(time.Hour)},
+	}), qt.IsNil)
+
+	var deleted []string
+	svc := &mock.PasswordsService{
+		DeleteFn: func(ctx context.Context, req *ps.DeleteDatabaseBranchPasswordRequest) error {
+			deleted = append(deleted, req.PasswordId)
+			return nil
+		},
+	}
+	ch := &cmdutil.Helper{
+		Config: &config.Config{Organization: "acme"},
+		Client: func() (*ps.Client, error) {
+			return &ps.Client{Passwords: svc}, nil
+		},
+	}
+
+	ended, err := EndSessions(context.Background(), ch, func(cred *Credential) bool { return cred.Branch == "dev" })
+	c.Assert(err, qt.IsNil)
+	c.Assert(ended, qt.HasLen, 1)
+	c.Assert(deleted, qt.DeepEquals, []string{"pw2"})
+
+	remaining, err := ListSessions()
+	c.Assert(err, qt.IsNil)
+	c.Assert(remaining, qt.HasLen, 1)
+	c.Assert(remaining[0].Branch, qt.Equals, "main")
+
+	_, err = EndSessions(context.Background(), ch, func(*Credential) bool { return true })
+	c.Assert(err, qt.IsNil)
+	c.Assert(*data, qt.IsNil)
+}
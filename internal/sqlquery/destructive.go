@@ -58,6 +58,36 @@ func splitSQLStatements(query string) []string {
 	return out
 }
 
+// SplitStatements splits a SQL script into its statements on top-level
+// semicolons. Semicolons inside quotes and comments don't end a statement, and
+// statements made up only of comments are dropped. The returned statements
+// keep their original text, including comments.
+func SplitStatements(script string) []string {
+	stripped := stripSQLGuardIgnoredText(script)
+	var out []string
+	start := 0
+	for i := 0; i <= len(stripped); i++ {
+		if i < len(stripped) && stripped[i] != ';' {
+			continue
+		}
+		if strings.TrimSpace(stripped[start:i]) != "" {
+			out = append(out, strings.TrimSpace(script[start:i]))
+		}
+		start = i + 1
+	}
+	return out
+}
+
+// IsStatementComplete reports whether script ends with a top-level semicolon,
+// ignoring trailing whitespace and comments. A script with an unterminated
+// quote or block comment is never complete.
+func IsStatementComplete(script string) bool {
+	// A semicolon appended on its own line survives stripping unless the
+	// script ends inside a quote or block comment.
+	stripped := stripSQLGuardIgnoredText(script + "\n;")
+	return strings.HasSuffix(strings.TrimSpace(stripped[:len(stripped)-2]), ";") && stripped[len(stripped)-1] == ';'
+}
+
 func isDestructiveStatement(stmt string) bool {
 	return slices.ContainsFunc(splitDestructiveSegments(stmt), isDestructiveSegment)
 }
@@ -89,3 +89,53 @@ func TestExecuteBlocksDestructiveWithoutForce(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{name: "single without terminator", script: "SELECT 1", want: []string{"SELECT 1"}},
+		{name: "multiple", script: "SELECT 1;\nSELECT 2;\n", want: []string{"SELECT 1", "SELECT 2"}},
+		{name: "semicolon in string", script: "SELECT ';' AS s; SELECT 2", want: []string{"SELECT ';' AS s", "SELECT 2"}},
+		{name: "semicolon in comment", script: "-- one; two\nSELECT 1;", want: []string{"-- one; two\nSELECT 1"}},
+		{name: "comment only statement", script: "SELECT 1;\n-- trailing note\n", want: []string{"SELECT 1"}},
+		{name: "empty", script: " ;; \n", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitStatements(tt.script)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SplitStatements(%q) = %q, want %q", tt.script, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("SplitStatements(%q) = %q, want %q", tt.script, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIsStatementComplete(t *testing.T) {
+	tests := []struct {
+		script string
+		want   bool
+	}{
+		{script: "SELECT 1;", want: true},
+		{script: "SELECT 1;  -- done\n", want: true},
+		{script: "SELECT 1", want: false},
+		{script: "SELECT ';", want: false},
+		{script: "SELECT 1 /* ; */", want: false},
+		{script: "SELECT 1; /* open", want: false},
+		{script: "SELECT 1;\nSELECT", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsStatementComplete(tt.script); got != tt.want {
+			t.Errorf("IsStatementComplete(%q) = %v, want %v", tt.script, got, tt.want)
+		}
+	}
+}
@@ -27,21 +27,97 @@ type Session struct {
 // opens a connection with ephemeral credentials (a branch password for MySQL,
 // a temporary role for PostgreSQL).
 func NewSession(ctx context.Context, ch *cmdutil.Helper, opts Options) (*Session, error) {
+	kind, role, err := lookupBranch(ctx, ch, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var db *sql.DB
+	var cleanup func()
+
+	switch engineOf(kind) {
+	case "mysql":
+		db, cleanup, err = openMySQL(ctx, ch, opts, role)
+	default:
+		db, cleanup, err = openPostgres(ctx, ch, opts, postgresDB(opts), role)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{Kind: kind, db: db, cleanup: cleanup}, nil
+}
+
+// Endpoint is a ready branch and the credential to reach it, for callers that
+// connect with their own driver rather than through a Session (e.g. to stream
+// large result sets).
+type Endpoint struct {
+	// Kind is the database kind as reported by the API.
+	Kind       string
+	Credential *Credential
+
+	opts    Options
+	release func()
+}
+
+// OpenEndpoint validates the options, looks up the database and branch, and
+// returns the cached session credential or a new ephemeral one.
+func OpenEndpoint(ctx context.Context, ch *cmdutil.Helper, opts Options) (*Endpoint, error) {
+	kind, role, err := lookupBranch(ctx, ch, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cred, release, err := credential(ctx, ch, opts, engineOf(kind), role)
+	if err != nil {
+		return nil, err
+	}
+	return &Endpoint{Kind: kind, Credential: cred, opts: opts, release: release}, nil
+}
+
+// Engine normalizes Kind to "mysql" or "postgresql".
+func (e *Endpoint) Engine() string {
+	return engineOf(e.Kind)
+}
+
+// MySQLDatabase is the database name to connect to on MySQL, matching the
+// keyspace selection of pscale sql.
+func (e *Endpoint) MySQLDatabase() string {
+	return mysqlDSNDatabase(e.opts)
+}
+
+// PostgresConnString is a libpq keyword/value connection string for the
+// branch on PostgreSQL.
+func (e *Endpoint) PostgresConnString() string {
+	return postgresConnString(e.Credential, postgresDB(e.opts))
+}
+
+// Close deletes the credential unless it belongs to a session.
+func (e *Endpoint) Close() {
+	if e.release != nil {
+		e.release()
+		e.release = nil
+	}
+}
+
+// lookupBranch validates the options and checks that the database exists and
+// the branch is ready. It returns the database kind and the resolved role.
+func lookupBranch(ctx context.Context, ch *cmdutil.Helper, opts Options) (string, cmdutil.PasswordRole, error) {
 	if opts.Organization == "" {
-		return nil, fmt.Errorf("organization is required (use --org or set org in pscale.yml)")
+		return "", 0, fmt.Errorf("organization is required (use --org or set org in pscale.yml)")
 	}
 	if opts.Database == "" || opts.Branch == "" {
-		return nil, fmt.Errorf("database and branch are required")
+		return "", 0, fmt.Errorf("database and branch are required")
 	}
 
 	role, err := cmdutil.ResolveAccessRole(opts.Role, opts.Replica, cmdutil.ReaderRole)
 	if err != nil {
-		return nil, err
+		return "", 0, err
 	}
 
 	client, err := ch.Client()
 	if err != nil {
-		return nil, err
+		return "", 0, err
 	}
 
 	dbInfo, err := client.Databases.Get(ctx, &ps.GetDatabaseRequest{
@@ -49,7 +125,13 @@ func NewSession(ctx context.Context, ch *cmdutil.Helper, opts Options) (*Session
 		Database:     opts.Database,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("database lookup: %w", err)
+		return "", 0, fmt.Errorf("database lookup: %w", err)
+	}
+
+	switch string(dbInfo.Kind) {
+	case "mysql", "postgresql", "horizon":
+	default:
+		return "", 0, fmt.Errorf("unsupported database kind %q", dbInfo.Kind)
 	}
 
 	dbBranch, err := client.DatabaseBranches.Get(ctx, &ps.GetDatabaseBranchRequest{
@@ -58,40 +140,32 @@ func NewSession(ctx context.Context, ch *cmdutil.Helper, opts Options) (*Session
 		Branch:       opts.Branch,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("branch lookup: %w", err)
+		return "", 0, fmt.Errorf("branch lookup: %w", err)
 	}
 	if !dbBranch.Ready {
-		return nil, fmt.Errorf("database branch is not ready yet")
+		return "", 0, fmt.Errorf("database branch is not ready yet")
 	}
 
-	var db *sql.DB
-	var cleanup func()
+	return string(dbInfo.Kind), role, nil
+}
 
-	switch string(dbInfo.Kind) {
-	case "mysql":
-		db, cleanup, err = openMySQL(ctx, ch, opts, role)
-	case "postgresql", "horizon":
-		pgDB := opts.PostgresDB
-		if pgDB == "" {
-			pgDB = "postgres"
-		}
-		db, cleanup, err = openPostgres(ctx, ch, opts, pgDB, role)
-	default:
-		return nil, fmt.Errorf("unsupported database kind %q", dbInfo.Kind)
-	}
-	if err != nil {
-		return nil, err
+func engineOf(kind string) string {
+	if kind == "mysql" {
+		return "mysql"
 	}
+	return "postgresql"
+}
 
-	return &Session{Kind: string(dbInfo.Kind), db: db, cleanup: cleanup}, nil
+func postgresDB(opts Options) string {
+	if opts.PostgresDB == "" {
+		return "postgres"
+	}
+	return opts.PostgresDB
 }
 
 // Engine normalizes Kind to "mysql" or "postgresql".
 func (s *Session) Engine() string {
-	if s.Kind == "mysql" {
-		return "mysql"
-	}
-	return "postgresql"
+	return engineOf(s.Kind)
 }
 
 // Query runs a single query over the session's connection and returns the
@@ -104,6 +178,23 @@ func (s *Session) Query(ctx context.Context, query string) ([]string, []map[stri
 	return outcome.columns, outcome.rows, nil
 }
 
+// Explain returns the query plan for query. On PostgreSQL costs are left out
+// so plans stay comparable between branches with different amounts of data.
+func (s *Session) Explain(ctx context.Context, query string) ([]string, []map[string]any, error) {
+	prefix := "EXPLAIN "
+	if s.Engine() == "postgresql" {
+		prefix = "EXPLAIN (COSTS OFF) "
+	}
+	return s.Query(ctx, prefix+query)
+}
+
+// DB returns the session's connection pool for callers that need
+// parameterized queries, transactions, or driver-specific features such as
+// COPY.
+func (s *Session) DB() *sql.DB {
+	return s.db
+}
+
 // Close releases the connection and cleans up the ephemeral credentials.
 func (s *Session) Close() {
 	if s.cleanup != nil {
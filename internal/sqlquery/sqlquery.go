@@ -13,10 +13,8 @@ import (
 	_ "github.com/lib/pq"
 
 	"github.com/planetscale/cli/internal/cmdutil"
-	"github.com/planetscale/cli/internal/passwordutil"
 	ps "github.com/planetscale/cli/internal/planetscale"
 	"github.com/planetscale/cli/internal/proxyutil"
-	"github.com/planetscale/cli/internal/roleutil"
 	"vitess.io/vitess/go/mysql"
 )
 
@@ -38,6 +36,10 @@ type Options struct {
 	Replica bool
 	// Force allows destructive SQL (DELETE, DROP, TRUNCATE) after explicit user approval.
 	Force bool
+	// CredentialTTL overrides the lifetime of the ephemeral credential for
+	// long-running callers such as imports. Cached session credentials are
+	// only reused when they outlive it.
+	CredentialTTL time.Duration
 }
 
 // Result is returned for `pscale sql --format json`.
@@ -153,44 +155,27 @@ func queryMySQL(ctx context.Context, ch *cmdutil.Helper, opts Options, role cmdu
 	return runQuery(ctx, db, opts.Query)
 }
 
-// openMySQL mints an ephemeral branch password, starts an in-process proxy,
-// and opens a connection through it. The returned cleanup closes the
-// connection and proxy and deletes the password.
+// openMySQL starts an in-process proxy with the cached session credential or
+// a freshly minted ephemeral branch password, and opens a connection through
+// it. The returned cleanup closes the connection and proxy and deletes the
+// password unless it belongs to a session.
 func openMySQL(ctx context.Context, ch *cmdutil.Helper, opts Options, role cmdutil.PasswordRole) (*sql.DB, func(), error) {
-	client, err := ch.Client()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	pw, err := passwordutil.New(ctx, client, passwordutil.Options{
-		Organization: opts.Organization,
-		Database:     opts.Database,
-		Branch:       opts.Branch,
-		Role:         role,
-		Name:         passwordutil.GenerateName("pscale-cli-sql"),
-		TTL:          5 * time.Minute,
-		Replica:      opts.Replica,
-	})
+	cred, release, err := credential(ctx, ch, opts, "mysql", role)
 	if err != nil {
 		return nil, nil, err
 	}
-	cleanupPassword := func() {
-		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		_ = pw.Cleanup(cleanupCtx)
-	}
 
 	proxy := proxyutil.New(proxyutil.Config{
 		Logger:       cmdutil.NewZapLogger(ch.Debug()),
-		UpstreamAddr: pw.Password.Hostname,
-		Username:     pw.Password.Username,
-		Password:     pw.Password.PlainText,
+		UpstreamAddr: cred.Host,
+		Username:     cred.Username,
+		Password:     cred.Password,
 	})
 
 	l, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		proxy.Close()
-		cleanupPassword()
+		release()
 		return nil, nil, err
 	}
 
@@ -207,7 +192,7 @@ func openMySQL(ctx context.Context, ch *cmdutil.Helper, opts Options, role cmdut
 		proxy.Close()
 		l.Close()
 		<-errCh
-		cleanupPassword()
+		release()
 		return nil, nil, err
 	}
 	db.SetConnMaxLifetime(30 * time.Second)
@@ -217,7 +202,7 @@ func openMySQL(ctx context.Context, ch *cmdutil.Helper, opts Options, role cmdut
 		proxy.Close()
 		l.Close()
 		<-errCh
-		cleanupPassword()
+		release()
 	}
 	return db, cleanup, nil
 }
@@ -253,68 +238,70 @@ func queryPostgres(ctx context.Context, ch *cmdutil.Helper, opts Options, pgDB s
 	return runQuery(ctx, db, opts.Query)
 }
 
-// openPostgres mints an ephemeral role and opens a direct connection to the
-// branch. The returned cleanup closes the connection and deletes the role.
+// openPostgres opens a direct connection to the branch with the cached
+// session credential or a freshly minted ephemeral role. The returned cleanup
+// closes the connection and deletes the role unless it belongs to a session.
 func openPostgres(ctx context.Context, ch *cmdutil.Helper, opts Options, pgDB string, role cmdutil.PasswordRole) (*sql.DB, func(), error) {
-	client, err := ch.Client()
+	cred, release, err := credential(ctx, ch, opts, "postgresql", role)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	inheritedRoles, successor := cmdutil.PostgresInheritedRoles(role)
-	inheritedRoles = append(inheritedRoles, opts.PostgresAdditionalRoles...)
-
-	pgRole, err := roleutil.New(ctx, client, roleutil.Options{
-		Organization:   opts.Organization,
-		Database:       opts.Database,
-		Branch:         opts.Branch,
-		Name:           passwordutil.GenerateName("pscale-cli-sql"),
-		TTL:            5 * time.Minute,
-		InheritedRoles: inheritedRoles,
-	})
-	if err != nil {
-		return nil, nil, err
-	}
-	cleanupRole := func() {
-		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		_ = pgRole.Cleanup(cleanupCtx, successor)
-	}
-
-	username := pgRole.Role.Username
-	if opts.Replica {
-		username = username + "|replica"
-	}
-
-	remoteHost, remotePort, err := net.SplitHostPort(pgRole.Role.AccessHostURL)
-	if err != nil {
-		remoteHost = pgRole.Role.AccessHostURL
-		remotePort = "5432"
-	}
-
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=require",
-		remoteHost, remotePort, username, pgRole.Role.Password, pgDB)
+	connStr := postgresConnString(cred, pgDB)
 
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
-		cleanupRole()
+		release()
 		return nil, nil, err
 	}
 	db.SetConnMaxLifetime(30 * time.Second)
 
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		cleanupRole()
+		release()
 		return nil, nil, err
 	}
 
 	cleanup := func() {
 		db.Close()
-		cleanupRole()
+		release()
 	}
 	return db, cleanup, nil
 }
 
+// IsReadQuery reports whether the query only reads data (SELECT, SHOW,
+// EXPLAIN and friends, including SELECTs behind CTEs). Like the destructive
+// guard, this is a best-effort check and not a SQL parser.
+func IsReadQuery(query string) bool {
+	return isReadQuery(query)
+}
+
+// ReturnsRows reports whether the query produces a result set: reads, and
+// writes with a RETURNING clause. Other statements should be executed for
+// their affected row count instead.
+func ReturnsRows(query string) bool {
+	return isReadQuery(query) || queryReturnsRows(query)
+}
+
+// postgresConnString builds a libpq keyword/value connection string for a
+// PostgreSQL credential. Replica credentials route through the
+// "|replica" username suffix.
+func postgresConnString(cred *Credential, pgDB string) string {
+	username := cred.Username
+	if cred.Replica {
+		username = username + "|replica"
+	}
+
+	remoteHost, remotePort, err := net.SplitHostPort(cred.Host)
+	if err != nil {
+		remoteHost = cred.Host
+		remotePort = "5432"
+	}
+
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=require",
+		remoteHost, remotePort, username, cred.Password, pgDB)
+}
+
 var readQueryPrefixes = []string{"SELECT", "SHOW", "DESCRIBE", "DESC", "EXPLAIN", "TABLE"}
 
 func isReadQuery(query string) bool {
@@ -452,7 +439,7 @@ func isIdentifierChar(c byte) bool {
 }
 
 func runQuery(ctx context.Context, db *sql.DB, query string) (*queryOutcome, error) {
-	if isReadQuery(query) || queryReturnsRows(query) {
+	if ReturnsRows(query) {
 		rows, err := db.QueryContext(ctx, query)
 		if err != nil {
 			return nil, err